@@ -2,21 +2,44 @@ package main
 
 import (
 	"bufio"
+	"cmp"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
+	_ "time/tzdata" // for --timezone on systems without a zone database
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"github.com/aldehir/llm-serving-tests/internal/attest"
+	"github.com/aldehir/llm-serving-tests/internal/bench"
 	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/config"
+	"github.com/aldehir/llm-serving-tests/internal/drift"
 	"github.com/aldehir/llm-serving-tests/internal/eval"
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
 	evallog "github.com/aldehir/llm-serving-tests/internal/log"
+	"github.com/aldehir/llm-serving-tests/internal/mockmodel"
+	"github.com/aldehir/llm-serving-tests/internal/profile"
 	"github.com/aldehir/llm-serving-tests/internal/report"
+	"github.com/aldehir/llm-serving-tests/internal/textdiff"
+	"github.com/aldehir/llm-serving-tests/internal/tui"
 )
 
 var (
@@ -25,21 +48,134 @@ var (
 	model                 string
 	timeout               time.Duration
 	responseHeaderTimeout time.Duration
+	retries               int
 	verbose               bool
 	filter                string
+	tags                  []string
+	skipTags              []string
 	class                 string
+	flavor                string
 	mode                  string
 	all                   bool
+	vision                bool
 	extra                 []string
+	redactPatterns        []string
+	logDir                string
+	runLabel              string
+	noLogs                bool
+	noStability           bool
+	shrink                bool
 	jobs                  int
+	repeat                int
+	passThreshold         float64
+	failFastOnBasic       bool
+	failFast              bool
+	csvPath               string
+	outputs               []string
+	resultsEndpoint       string
+	resultsToken          string
+	profilePath           string
+	resumeDir             string
+	selectProfile         string
+	suiteName             string
+	configPath            string
+	timezone              string
+	semantic              bool
+	embeddingURL          string
+	embeddingModel        string
+	embeddingAPIKey       string
+	compareBaseURL        string
+	compareModel          string
+	compareAPIKey         string
+	compareProviderName   string
+	regressionPackPath    string
+	signKeyPath           string
+	shardSpec             string
+	needleLengths         []int
+	needleDepths          []int
+	contextSize           int
+	stressConcurrency     int
+	templateFamily        string
+	scanOutput            []string
+
+	// selectedEvals holds the evals picked interactively by select
+	selectedEvals []string
 
 	replayDelay time.Duration
+
+	benchConcurrency int
+	benchRequests    int
+	benchDuration    time.Duration
+	benchMaxTokens   int
+
+	accuracySamples int
+
+	driftMaxTokens   int
+	driftTopLogprobs int
+
+	schemaOutput string
+
+	reportOpen bool
+
+	packOutput string
+
+	verifyKeyPath string
+	verifyResults string
+
+	mergeOutput    string
+	mergeThreshold float64
+
+	mockListen     string
+	mockScript     string
+	mockChunkDelay time.Duration
+
+	cpuProfilePath string
+	memProfilePath string
+
+	userAgent    string
+	temperature  float64
+	providerName string
+	resolves     []string
+	headers      []string
+
+	// displayLoc is the zone of times shown to users, set by --timezone
+	displayLoc = time.UTC
+	// requestTemperature is the temperature of requests that set none, set
+	// by --temperature; nil leaves it to the server
+	requestTemperature *float64
+	// chatProvider is the API chat requests are sent in, set by --provider
+	chatProvider client.Provider
+	// dial opens connections to the server, resolving hosts as --resolve
+	// says; nil without --resolve
+	dial client.DialFunc
+	// requestHeaders are sent with every request to the server, set by
+	// --header
+	requestHeaders http.Header
 )
 
+// signKeyEnv names the environment variable holding a PEM-encoded signing
+// key, for CI systems that pass secrets by value rather than as files.
+const signKeyEnv = "LLM_SERVE_TEST_SIGN_KEY"
+
+// resultsTokenEnv names the environment variable holding the bearer token
+// for --results-endpoint, so that it stays out of shell history.
+const resultsTokenEnv = "LLM_SERVE_TEST_RESULTS_TOKEN"
+
+// defaultBenchRequests is the request count used when bench is given
+// neither --requests nor --duration.
+const defaultBenchRequests = 100
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		exit(1)
 	}
+	stopProfiling()
+}
+
+// exit writes the --cpu-profile and --mem-profile files before exiting.
+func exit(code int) {
+	stopProfiling()
+	os.Exit(code)
 }
 
 var rootCmd = &cobra.Command{
@@ -47,13 +183,73 @@ var rootCmd = &cobra.Command{
 	Short: "LLM inference server test suite",
 	Long:  "A tool for testing LLM inference server implementations against OpenAI-compatible APIs.",
 	RunE:  runEvals,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return fmt.Errorf("invalid --timezone %q: %w", timezone, err)
+		}
+		displayLoc = loc
+		if cmd.Flags().Changed("temperature") {
+			requestTemperature = &temperature
+		}
+		p, err := client.NewProvider(providerName)
+		if err != nil {
+			return fmt.Errorf("invalid --provider: %w", err)
+		}
+		chatProvider = p
+		if len(resolves) > 0 {
+			var overrides []client.Resolve
+			for _, spec := range resolves {
+				r, err := client.ParseResolve(spec)
+				if err != nil {
+					return fmt.Errorf("invalid --resolve: %w", err)
+				}
+				overrides = append(overrides, r)
+			}
+			dial = client.Dialer(overrides, &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+		}
+		if len(headers) > 0 {
+			requestHeaders = make(http.Header)
+			for _, spec := range headers {
+				name, value, err := client.ParseHeader(spec)
+				if err != nil {
+					return fmt.Errorf("invalid --header: %w", err)
+				}
+				requestHeaders.Add(name, value)
+			}
+		}
+		return startProfiling()
+	},
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available tests",
-	Long:  "List all available tests that can be filtered with --filter.",
-	Run:   listTests,
+	Long:  "List all available tests that can be filtered with --filter, --tag, and --skip-tag, and the tags they can be selected by.",
+	RunE:  listTests,
+}
+
+var selectCmd = &cobra.Command{
+	Use:   "select",
+	Short: "Interactively select tests to run",
+	Long:  "Pick tests from a checkbox list grouped by category, optionally saving the selection as a named profile, then run them.",
+	RunE:  runSelect,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "config-schema",
+	Short: "Print the JSON Schema for the config file",
+	Long:  "Print the JSON Schema for the config file, for editor completion and validation.",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigSchema,
+}
+
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate-config [path]",
+	Short: "Validate a config file",
+	Long:  "Check a config file for syntax errors, unknown fields, and invalid values. Defaults to --config or the default config path.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runValidateConfig,
 }
 
 var replayCmd = &cobra.Command{
@@ -64,6 +260,81 @@ var replayCmd = &cobra.Command{
 	RunE:  runReplay,
 }
 
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark server throughput and latency",
+	Long:  "Send concurrent streaming chat completions and report TTFT, inter-token latency, tokens/sec, and request latency percentiles.",
+	RunE:  runBench,
+}
+
+var accuracyCmd = &cobra.Command{
+	Use:   "accuracy",
+	Short: "Measure answer accuracy on a small math benchmark",
+	Long:  "Ask a fixed set of GSM8K-style math questions and report the exact-match accuracy of the final answers, as a quality smoke test.",
+	RunE:  runAccuracy,
+}
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Measure logprob drift between two servers",
+	Long:  "Send a fixed set of seeded, greedy conversations to the servers at --base-url and --compare-base-url with logprobs, and report how far apart the log probabilities of their generations are token by token, as a measure of numerical drift between server versions or quantizations beyond string equality.",
+	Args:  cobra.NoArgs,
+	RunE:  runDrift,
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report <log-dir>",
+	Short: "Generate the HTML report for a log directory",
+	Long:  "Regenerate report.html and badge.svg from the logs of a previous run, including runs made before evals.jsonl was recorded.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runReport,
+}
+
+var regressionPackCmd = &cobra.Command{
+	Use:   "regression-pack <log-dir>",
+	Short: "Snapshot a passing run into a regression pack",
+	Long:  "Record the /apply-template outputs and response shapes of a fully passing run, for later runs to check against with --regression-pack.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRegressionPack,
+}
+
+var verifyAttestationCmd = &cobra.Command{
+	Use:   "verify-attestation <attestation>",
+	Short: "Verify a signed results attestation",
+	Long:  "Check the signature of an attestation written with --sign-key and that the JSON results file it names is unchanged, then print what it attests.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runVerifyAttestation,
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <results.json...>",
+	Short: "Merge JSON results of shards or repeated runs",
+	Long:  "Combine the --output json results of the shards of a run, or of repeated runs, into one results.json and HTML report, reporting evals found in several files and missing shards.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runMerge,
+}
+
+var replayAgainstCmd = &cobra.Command{
+	Use:   "replay-against <log-dir>",
+	Short: "Replay a run's requests against another server",
+	Long:  "Re-send the chat completion and /apply-template requests recorded in a run's logs to the server at --base-url, and report where its responses differ from the captured ones in status, shape, tool calls, or rendered template.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runReplayAgainst,
+}
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Inspect the server's chat template",
+}
+
+var templateAnalyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Find whitespace-sensitive renderings in the chat template",
+	Long:  "Render conversations through the /apply-template endpoint of the server at --base-url with systematic variations, such as a trailing newline in a message or an empty reasoning_content rather than none, and report which variations change the rendered prompt and how.",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplateAnalyze,
+}
+
 var replayAllCmd = &cobra.Command{
 	Use:   "replay-all <log-dir>",
 	Short: "Replay all streaming responses from a log directory",
@@ -72,26 +343,130 @@ var replayAllCmd = &cobra.Command{
 	RunE:  runReplayAll,
 }
 
+var mockModelCmd = &cobra.Command{
+	Use:   "mockmodel",
+	Short: "Serve a scripted echo model for offline testing",
+	Long:  "Serve an echo model implementing enough of the OpenAI chat and text completions APIs, with scripted responses, streaming, tool calls, and logprobs, to exercise the tests, reports, and exports end to end without a real model, e.g. in CI.",
+	Args:  cobra.NoArgs,
+	RunE:  runMockModel,
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", "", "Server base URL (required for run)")
 	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "API key (optional)")
 	rootCmd.PersistentFlags().StringVar(&model, "model", "", "Model to test (required for run)")
 	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "Request timeout")
 	rootCmd.PersistentFlags().DurationVar(&responseHeaderTimeout, "response-header-timeout", 5*time.Minute, "Time to wait for response headers (prompt processing time)")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", "", "User-Agent header of requests (default: llm-serve-test/<version>)")
+	rootCmd.PersistentFlags().Float64Var(&temperature, "temperature", 0, "Temperature of requests that set none, e.g. 0 for deterministic reasoning evals (default: the server's)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 0, "Retry requests failing with 429/5xx or connection errors up to N times")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show full request/response for all tests")
-	rootCmd.PersistentFlags().StringVar(&filter, "filter", "", "Run only tests matching pattern")
-	rootCmd.PersistentFlags().StringVar(&class, "class", "", "Run only tests of specified class (standard, reasoning, interleaved)")
+	rootCmd.PersistentFlags().StringVar(&filter, "filter", "", "Run only tests whose names match a regular expression")
+	rootCmd.PersistentFlags().StringSliceVar(&tags, "tag", nil, "Run only tests with all of these tags, e.g. streaming or tools (see list)")
+	rootCmd.PersistentFlags().StringSliceVar(&skipTags, "skip-tag", nil, "Skip tests with any of these tags")
+	rootCmd.PersistentFlags().StringVar(&class, "class", "", "Run only tests of specified class (standard, reasoning, interleaved, performance)")
+	rootCmd.PersistentFlags().StringVar(&flavor, "flavor", eval.FlavorGeneric, "Server flavor, adding tests of its extensions (generic, llama.cpp, vllm, tgi, openrouter)")
+	rootCmd.PersistentFlags().StringVar(&providerName, "provider", client.ProviderOpenAI, "Chat API of the server (openai, anthropic, gemini, ollama)")
+	rootCmd.PersistentFlags().StringArrayVarP(&headers, "header", "H", nil, "Send a header with every request to the server, e.g. \"X-Tenant-ID: acme\", can be repeated")
+	rootCmd.PersistentFlags().StringArrayVar(&resolves, "resolve", nil, "Connect to addr for requests to host:port, as curl --resolve does (host:port:addr[,addr...]), can be repeated")
 	rootCmd.PersistentFlags().StringVar(&mode, "mode", "both", "Request mode: blocking, streaming, or both")
 	rootCmd.PersistentFlags().BoolVarP(&all, "all", "a", false, "Include tests that are disabled by default")
+	rootCmd.PersistentFlags().BoolVar(&vision, "vision", false, "Include tests that send images; the model must accept image input")
 	rootCmd.PersistentFlags().StringArrayVarP(&extra, "extra", "e", nil, "Extra request field (key=value or key:=json), can be repeated")
 	rootCmd.PersistentFlags().IntVarP(&jobs, "jobs", "j", 1, "Number of parallel test executions")
+	rootCmd.PersistentFlags().StringVar(&logDir, "log-dir", "logs", "Directory for logs and reports, grouped by model and run time")
+	rootCmd.PersistentFlags().StringVar(&runLabel, "run-label", "", "Name the run's log directory and report, e.g. nightly-2025-01-15 (default: the start time)")
+	rootCmd.PersistentFlags().BoolVar(&noLogs, "no-logs", false, "Write no logs or reports")
+	rootCmd.PersistentFlags().BoolVar(&noStability, "no-stability", false, "Skip the server stability probes before and after the run, each a short completion")
+	rootCmd.PersistentFlags().StringArrayVar(&redactPatterns, "redact", nil, "Mask text matching a regular expression in logs and reports, can be repeated")
+	rootCmd.PersistentFlags().StringVar(&timezone, "timezone", "UTC", "Time zone for times shown in reports and output, e.g. Local or Europe/Berlin (artifacts are always UTC)")
+	rootCmd.PersistentFlags().StringVar(&cpuProfilePath, "cpu-profile", "", "Write a CPU profile of the harness itself to a file, for go tool pprof")
+	rootCmd.PersistentFlags().StringVar(&memProfilePath, "mem-profile", "", "Write an allocation profile of the harness itself to a file on exit, for go tool pprof")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file path (default: <user config dir>/llm-serve-test/config.json)")
+	rootCmd.Flags().StringVar(&suiteName, "suite", "", "Run a named suite (smoke, full, nightly, or one from the config file)")
+	rootCmd.Flags().IntVar(&repeat, "repeat", 1, "Run each test N times")
+	rootCmd.Flags().Float64Var(&passThreshold, "pass-threshold", 1.0, "Fraction of repeated runs that must pass (with --repeat)")
+	rootCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop scheduling new tests after the first failure")
+	rootCmd.Flags().BoolVar(&failFastOnBasic, "fail-fast-on-basic", false, "Abort the run if fundamental tests (e.g. chat_completion) fail")
+	rootCmd.Flags().StringVar(&selectProfile, "profile", "", "Run only the tests saved in a named profile (see select)")
+	rootCmd.Flags().BoolVar(&semantic, "semantic", false, "Enable semantic similarity checks using the server's /embeddings")
+	rootCmd.Flags().StringVar(&embeddingURL, "embedding-url", "", "Embed with a separate endpoint for semantic checks (implies --semantic)")
+	rootCmd.Flags().StringVar(&embeddingModel, "embedding-model", "", "Model for semantic check embeddings (default: --model)")
+	rootCmd.Flags().StringVar(&embeddingAPIKey, "embedding-api-key", "", "API key for --embedding-url")
+	rootCmd.Flags().IntSliceVar(&needleLengths, "needle-lengths", eval.DefaultNeedleConfig.Lengths, "Context lengths in tokens for needle_in_haystack")
+	rootCmd.Flags().IntSliceVar(&needleDepths, "needle-depths", eval.DefaultNeedleConfig.Depths, "Needle depths in percent for needle_in_haystack")
+	rootCmd.Flags().IntVar(&contextSize, "context-size", eval.DefaultNeedleConfig.ContextSize, "Prompt length in thousands of tokens for long_context")
+	rootCmd.Flags().IntVar(&stressConcurrency, "stress-concurrency", eval.DefaultStressConcurrency, "Number of concurrent streams for concurrent_streams")
+	rootCmd.Flags().StringVar(&templateFamily, "template-family", "", "Model family whose template markers template_conformance checks (default: the config file family contained in --model)")
+	rootCmd.Flags().StringSliceVar(&scanOutput, "scan-output", nil, "Scan all outputs with packs (pii, credentials, system-prompt, moderation, or config file packs) and report what they flag")
+	rootCmd.Flags().StringVar(&csvPath, "csv", "", "Write per-eval metrics to a CSV file")
+	rootCmd.Flags().StringVar(&compareBaseURL, "compare-base-url", "", "Also run each test against a second server and report where outcomes diverge")
+	rootCmd.Flags().StringVar(&compareModel, "compare-model", "", "Model for --compare-base-url (default: --model)")
+	rootCmd.Flags().StringVar(&compareAPIKey, "compare-api-key", "", "API key for --compare-base-url (default: --api-key)")
+	rootCmd.Flags().StringVar(&compareProviderName, "compare-provider", "", "Chat API of --compare-base-url, e.g. to compare an Ollama server's native API with its OpenAI one (default: --provider)")
+	rootCmd.Flags().StringVar(&regressionPackPath, "regression-pack", "", "Fail tests whose rendered templates or response shapes differ from a regression pack")
+	rootCmd.Flags().BoolVar(&shrink, "shrink", false, "Minimize the last request of each failed test into a .repro.json file in the log directory")
+	rootCmd.Flags().StringVar(&shardSpec, "shard", "", "Run only shard i of n of the selected tests, e.g. 2/5, to split a run across machines")
+	rootCmd.Flags().StringVar(&resumeDir, "resume", "", "Resume an interrupted run from its log directory, skipping completed evals")
+	rootCmd.Flags().StringVar(&profilePath, "profile-run", "", "Write a flame-style JSON breakdown of eval time to a file")
+	rootCmd.Flags().StringArrayVar(&outputs, "output", nil, "Write results in another format (junit=<path> or json=<path>), can be repeated")
+	rootCmd.Flags().StringVar(&resultsEndpoint, "results-endpoint", "", "POST each result, and a final summary, as JSON to this URL as the run progresses")
+	rootCmd.Flags().StringVar(&resultsToken, "results-token", "", "Bearer token for --results-endpoint, also read from $"+resultsTokenEnv)
+	rootCmd.Flags().StringVar(&signKeyPath, "sign-key", "", "Sign --output json files with an ed25519 private key (PEM), also read from $"+signKeyEnv)
 
 	replayCmd.Flags().DurationVar(&replayDelay, "delay", 10*time.Millisecond, "Delay between chunks")
 	replayAllCmd.Flags().DurationVar(&replayDelay, "delay", 10*time.Millisecond, "Delay between chunks")
 
+	benchCmd.Flags().IntVarP(&benchConcurrency, "concurrency", "c", 1, "Number of concurrent requests")
+	benchCmd.Flags().IntVarP(&benchRequests, "requests", "n", 0, fmt.Sprintf("Total requests to send (default %d if --duration is not set)", defaultBenchRequests))
+	benchCmd.Flags().DurationVarP(&benchDuration, "duration", "d", 0, "Stop sending new requests after this long")
+	benchCmd.Flags().IntVar(&benchMaxTokens, "max-tokens", 256, "Maximum completion tokens per request")
+
+	accuracyCmd.Flags().IntVar(&accuracySamples, "samples", 1, "Number of samples per question")
+
+	driftCmd.Flags().StringVar(&compareBaseURL, "compare-base-url", "", "Base URL of the server to compare with (required)")
+	driftCmd.Flags().StringVar(&compareModel, "compare-model", "", "Model for --compare-base-url (default: --model)")
+	driftCmd.Flags().StringVar(&compareAPIKey, "compare-api-key", "", "API key for --compare-base-url (default: --api-key)")
+	driftCmd.Flags().StringVar(&compareProviderName, "compare-provider", "", "Chat API of --compare-base-url (default: --provider)")
+	driftCmd.Flags().IntVar(&driftMaxTokens, "max-tokens", 64, "Maximum completion tokens per request")
+	driftCmd.Flags().IntVar(&driftTopLogprobs, "top-logprobs", 5, "Number of most likely tokens compared at each position")
+
+	selectCmd.Flags().StringVar(&selectProfile, "profile", "", "Preselect the tests saved in a named profile")
+
+	reportCmd.Flags().BoolVar(&reportOpen, "open", false, "Open the report in a browser")
+
+	regressionPackCmd.Flags().StringVarP(&packOutput, "output", "o", "regression-pack.json", "Path of the regression pack to write")
+
+	verifyAttestationCmd.Flags().StringVar(&verifyKeyPath, "key", "", "Public key (PEM) the attestation must be signed with (required)")
+	verifyAttestationCmd.Flags().StringVar(&verifyResults, "results", "", "JSON results file attested (default: the attestation path without "+attest.Suffix+")")
+	_ = verifyAttestationCmd.MarkFlagRequired("key")
+
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "merged", "Directory to write the merged results.json and report.html to")
+	mergeCmd.Flags().Float64Var(&mergeThreshold, "pass-threshold", 1.0, "Fraction of an eval's runs that must pass, for evals in several files")
+
+	mockModelCmd.Flags().StringVar(&mockListen, "listen", "127.0.0.1:8080", "Address to listen on")
+	mockModelCmd.Flags().StringVar(&mockScript, "script", "", "JSON file of scripted responses, tried before echoing")
+	mockModelCmd.Flags().DurationVar(&mockChunkDelay, "chunk-delay", 0, "Delay before each streamed chunk")
+
+	configSchemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "Write the schema to a file instead of stdout")
+
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(configSchemaCmd)
+	rootCmd.AddCommand(validateConfigCmd)
+	rootCmd.AddCommand(selectCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(accuracyCmd)
+	rootCmd.AddCommand(driftCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(regressionPackCmd)
+	rootCmd.AddCommand(verifyAttestationCmd)
+	rootCmd.AddCommand(mergeCmd)
 	rootCmd.AddCommand(replayCmd)
 	rootCmd.AddCommand(replayAllCmd)
+	rootCmd.AddCommand(replayAgainstCmd)
+	templateCmd.AddCommand(templateAnalyzeCmd)
+	rootCmd.AddCommand(templateCmd)
+	rootCmd.AddCommand(mockModelCmd)
 }
 
 func runEvals(cmd *cobra.Command, args []string) error {
@@ -103,6 +478,35 @@ func runEvals(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--model is required")
 	}
 
+	if noLogs && resumeDir != "" {
+		return fmt.Errorf("--resume and --no-logs cannot be used together")
+	}
+	if runLabel != "" && resumeDir != "" {
+		return fmt.Errorf("--resume and --run-label cannot be used together (a resumed run keeps its label)")
+	}
+	if noLogs && shrink {
+		return fmt.Errorf("--shrink and --no-logs cannot be used together")
+	}
+
+	fileConfig, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Apply suite settings before validating the options they set
+	if suiteName != "" {
+		if selectProfile != "" {
+			return fmt.Errorf("--suite and --profile cannot be used together")
+		}
+		if err := applySuite(cmd, fileConfig, suiteName); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSelection(); err != nil {
+		return err
+	}
+
 	// Validate class if specified
 	if class != "" {
 		validClasses := eval.AllClasses()
@@ -118,6 +522,10 @@ func runEvals(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if !slices.Contains(eval.AllFlavors(), flavor) {
+		return fmt.Errorf("invalid --flavor %q (valid: %s)", flavor, strings.Join(eval.AllFlavors(), ", "))
+	}
+
 	// Validate mode
 	validModes := eval.AllModes()
 	validMode := false
@@ -131,18 +539,110 @@ func runEvals(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid --mode %q (valid: %s)", mode, strings.Join(validModes, ", "))
 	}
 
+	if repeat < 1 {
+		return fmt.Errorf("invalid --repeat %d (must be at least 1)", repeat)
+	}
+	if passThreshold <= 0 || passThreshold > 1 {
+		return fmt.Errorf("invalid --pass-threshold %g (must be in (0, 1])", passThreshold)
+	}
+	for _, l := range needleLengths {
+		if l < 1 {
+			return fmt.Errorf("invalid --needle-lengths value %d (must be positive)", l)
+		}
+	}
+	for _, d := range needleDepths {
+		if d < 0 || d > 100 {
+			return fmt.Errorf("invalid --needle-depths value %d (must be 0-100)", d)
+		}
+	}
+	if contextSize < 1 {
+		return fmt.Errorf("invalid --context-size %d (must be at least 1)", contextSize)
+	}
+	if stressConcurrency < 2 {
+		return fmt.Errorf("invalid --stress-concurrency %d (must be at least 2)", stressConcurrency)
+	}
+
+	templateMarkers, err := fileConfig.TemplateMarkers(model, templateFamily)
+	if err != nil {
+		return fmt.Errorf("invalid --template-family: %w", err)
+	}
+	outputScanners, err := fileConfig.OutputScanners(scanOutput)
+	if err != nil {
+		return fmt.Errorf("invalid --scan-output: %w", err)
+	}
+
 	// Parse extra fields
 	extraFields, err := parseExtraFields(extra)
 	if err != nil {
 		return fmt.Errorf("invalid --extra flag: %w", err)
 	}
 
-	// Initialize logger
-	logger, err := evallog.New(model)
+	// Load the saved selection unless select already chose the evals
+	if selectProfile != "" && selectedEvals == nil {
+		selectedEvals, err = loadProfile(selectProfile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Parse output formats
+	outputTargets, err := parseOutputs(outputs)
+	if err != nil {
+		return fmt.Errorf("invalid --output flag: %w", err)
+	}
+
+	var shard eval.Shard
+	if shardSpec != "" {
+		if shard, err = eval.ParseShard(shardSpec); err != nil {
+			return fmt.Errorf("invalid --shard flag: %w", err)
+		}
+	}
+
+	signKey, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+	if signKey != nil && !slices.ContainsFunc(outputTargets, func(o outputTarget) bool { return o.format == "json" }) {
+		return fmt.Errorf("--sign-key requires --output json=<path>")
+	}
+
+	var publisher *report.Publisher
+	if resultsEndpoint != "" {
+		publisher, err = report.NewPublisher(resultsEndpoint, cmp.Or(resultsToken, os.Getenv(resultsTokenEnv)))
+		if err != nil {
+			return fmt.Errorf("invalid --results-endpoint flag: %w", err)
+		}
+	} else if resultsToken != "" {
+		return fmt.Errorf("--results-token requires --results-endpoint")
+	}
+
+	var pack *eval.RegressionPack
+	if regressionPackPath != "" {
+		pack, err = eval.LoadRegressionPack(regressionPackPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Initialize logger, reopening the previous log directory when resuming
+	logger, err := openLogger(fileConfig, resumeDir)
 	if err != nil {
-		return fmt.Errorf("failed to initialize logger: %w", err)
+		return err
+	}
+
+	// Without logs there is no run state to resume from
+	var state *eval.RunState
+	if logger != nil {
+		defer logger.Close()
+		if resumeDir != "" {
+			state, err = eval.OpenRunState(logger.Dir())
+		} else {
+			state, err = eval.NewRunState(logger.Dir())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to open run state: %w", err)
+		}
 	}
-	defer logger.Close()
 
 	// Initialize client
 	c := client.New(client.Config{
@@ -152,26 +652,152 @@ func runEvals(cmd *cobra.Command, args []string) error {
 		Timeout:               timeout,
 		ResponseHeaderTimeout: responseHeaderTimeout,
 		Extra:                 extraFields,
+		RetryPolicy:           client.DefaultRetryPolicy(retries),
+		UserAgent:             clientUserAgent(),
+		Dial:                  dial,
+		Headers:               requestHeaders,
+		Temperature:           requestTemperature,
+		Provider:              chatProvider,
+		Embedding:             embeddingConfig(),
 	})
 
+	// Initialize the comparison server's client like the first
+	var compare *client.Client
+	if compareBaseURL != "" {
+		compareProvider := chatProvider
+		if compareProviderName != "" {
+			p, err := client.NewProvider(compareProviderName)
+			if err != nil {
+				return fmt.Errorf("invalid --compare-provider: %w", err)
+			}
+			compareProvider = p
+		}
+		compare = client.New(client.Config{
+			BaseURL:               compareBaseURL,
+			APIKey:                cmp.Or(compareAPIKey, apiKey),
+			Model:                 cmp.Or(compareModel, model),
+			Timeout:               timeout,
+			ResponseHeaderTimeout: responseHeaderTimeout,
+			Extra:                 extraFields,
+			RetryPolicy:           client.DefaultRetryPolicy(retries),
+			UserAgent:             clientUserAgent(),
+			Dial:                  dial,
+			Headers:               requestHeaders,
+			Temperature:           requestTemperature,
+			Provider:              compareProvider,
+			Embedding:             embeddingConfig(),
+		})
+	}
+
+	if err := checkExtraFields(extraFields, c, compare); err != nil {
+		return err
+	}
+
 	// Run evals
+	var onResult func(eval.Result)
+	if publisher != nil {
+		onResult = publisher.Result
+	}
 	runner := eval.NewRunner(c, eval.RunnerConfig{
 		Verbose: verbose,
 		Filter:  filter,
 		Class:   class,
+		Flavor:  flavor,
+		Evals:   selectedEvals,
 		Mode:    eval.StreamMode(mode),
 		All:     all,
+		Vision:  vision,
 		Logger:  logger,
 		Jobs:    jobs,
+		State:   state,
+
+		Tags:     tags,
+		SkipTags: skipTags,
+
+		Repeat:        repeat,
+		PassThreshold: passThreshold,
+
+		FailFastOnBasic: failFastOnBasic,
+		FailFast:        failFast,
+		Shrink:          shrink,
+		Compare:         compare,
+		RegressionPack:  pack,
+		Shard:           shard,
+		OnResult:        onResult,
+
+		Needle: eval.NeedleConfig{
+			Lengths:     needleLengths,
+			Depths:      needleDepths,
+			ContextSize: contextSize,
+		},
+		StressConcurrency: stressConcurrency,
+		Disabled:          fileConfig.Disabled(),
+		SLOs:              fileConfig.Objectives(),
+		OutputScanners:    outputScanners,
+		Template:          templateMarkers,
 	})
 
 	fmt.Println("LLM Serving Tests")
 	fmt.Println("=================")
 	fmt.Printf("Server: %s\n", baseURL)
 	fmt.Printf("Model: %s\n", model)
+	if compare != nil {
+		fmt.Printf("Comparing with: %s (%s)\n", compareBaseURL, compare.Model())
+	}
+	if pack != nil {
+		fmt.Printf("Regression pack: %s (%d evals, %s, made %s)\n", regressionPackPath, len(pack.Evals), pack.Model, pack.Created.In(displayLoc).Format(time.RFC3339))
+	}
+	if suiteName != "" {
+		fmt.Printf("Suite: %s\n", suiteName)
+	}
+	if shard.Count > 0 {
+		fmt.Printf("Shard: %s\n", shard)
+	}
+	if resumeDir != "" {
+		fmt.Printf("Resuming: %s (%d evals already completed)\n", logger.Dir(), state.Len())
+	}
+	if publisher != nil {
+		fmt.Printf("Results endpoint: %s (run %s)\n", resultsEndpoint, publisher.RunID())
+	}
 	fmt.Println()
 
+	// Probe the server before and after the run, to tell whether the run
+	// degraded it
+	var startProbe eval.Probe
+	if !noStability {
+		startProbe = eval.ProbeServer(c)
+	}
+
+	started := time.Now()
+	run := report.RunInfo{
+		Version: buildVersion(),
+		Server:  baseURL,
+		Model:   model,
+		Flavor:  flavor,
+		Suite:   suiteName,
+		Label:   runLabel,
+		Started: started.UTC(),
+	}
+	if shard.Count > 0 {
+		run.Shard = &shard
+	}
+	if logger != nil {
+		run.Label = logger.Label()
+	}
+	if publisher != nil {
+		publisher.Start(run)
+	}
+
 	results := runner.Run()
+	finished := time.Now()
+	run.Finished = finished.UTC()
+	run.DurationMS = finished.Sub(started).Milliseconds()
+	if !noStability {
+		stability := eval.CheckStability(startProbe, eval.ProbeServer(c))
+		run.Stability = &stability
+	}
+	run.Skipped = runner.Skipped()
+	run.OutputFindings = runner.OutputFindings()
 
 	// Print summary
 	passed := 0
@@ -181,29 +807,252 @@ func runEvals(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	fmt.Printf("\nResults: %d/%d passed\n", passed, len(results))
-	fmt.Printf("\nLogs written to: %s\n", logger.Dir())
+	fmt.Printf("\nResults: %d/%d passed", passed, len(results))
+	if len(run.Skipped) > 0 {
+		fmt.Printf(", %d skipped", len(run.Skipped))
+	}
+	fmt.Println()
+	if breakdown := eval.FailureBreakdown(results); len(breakdown) > 0 {
+		fmt.Println(eval.FormatFailureBreakdown(breakdown))
+	}
+	sloViolated := printSLOViolations(results)
 
-	if err := report.WriteReport(logger.Dir(), logger.Model(), logger.Evals()); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to generate report: %v\n", err)
-	} else {
-		fmt.Printf("Report: %s/report.html\n", logger.Dir())
+	if run.Stability != nil {
+		printStability(*run.Stability)
+	}
+
+	if len(outputScanners) > 0 {
+		printOutputFindings(run.OutputFindings)
+	}
+
+	if compare != nil {
+		printDivergences(results)
+	}
+
+	printTiming(results)
+	if logger != nil {
+		fmt.Printf("\nLogs written to: %s\n", logger.Dir())
+
+		if run.Stability != nil {
+			if err := report.WriteStability(logger.Dir(), *run.Stability); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to record server stability: %v\n", err)
+			}
+		}
+		if err := report.WriteSkipped(logger.Dir(), run.Skipped); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record skipped evals: %v\n", err)
+		}
+		if err := report.WriteOutputFindings(logger.Dir(), run.OutputFindings); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record output findings: %v\n", err)
+		}
+
+		if err := report.WriteReport(logger.Dir(), logger.Model(), logger.Label(), logger.Evals(), displayLoc); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to generate report: %v\n", err)
+		} else {
+			fmt.Printf("Report: %s/report.html\n", logger.Dir())
+		}
+	}
+
+	if profilePath != "" {
+		if err := report.WriteProfile(profilePath, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write profile: %v\n", err)
+		} else {
+			fmt.Printf("Profile: %s\n", profilePath)
+		}
+	}
+
+	if csvPath != "" {
+		if err := report.WriteCSV(csvPath, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write CSV: %v\n", err)
+		} else {
+			fmt.Printf("CSV: %s\n", csvPath)
+		}
+	}
+
+	for _, o := range outputTargets {
+		if err := writeOutput(o, run, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write %s output: %v\n", o.format, err)
+			continue
+		}
+		fmt.Printf("Output (%s): %s\n", o.format, o.path)
+
+		if signKey != nil && o.format == "json" {
+			path := o.path + attest.Suffix
+			env, err := attest.Sign(signKey, o.path, attest.NewPredicate(run, results))
+			if err == nil {
+				err = env.Write(path)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to sign %s: %v\n", o.path, err)
+			} else {
+				fmt.Printf("Attestation: %s\n", path)
+			}
+		}
+	}
+
+	if publisher != nil {
+		delivered, total, err := publisher.Finish(run, results)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: results endpoint: %d/%d events delivered, first failure: %v\n", delivered, total, err)
+		} else {
+			fmt.Printf("Results endpoint: %d events delivered\n", delivered)
+		}
+	}
+
+	if logger != nil {
+		modelDir := filepath.Dir(logger.Dir())
+		if err := report.WriteIndex(modelDir, displayLoc); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to generate index: %v\n", err)
+		} else {
+			fmt.Printf("Index: %s/index.html\n", modelDir)
+		}
 	}
 
-	if passed < len(results) {
-		os.Exit(1)
+	if passed < len(results) || sloViolated > 0 {
+		exit(1)
 	}
 
 	return nil
 }
 
-func listTests(cmd *cobra.Command, args []string) {
+// checkExtraFields fails fast if a server rejects requests carrying the
+// --extra fields, rather than letting every request fail the same way.
+func checkExtraFields(fields map[string]any, clients ...*client.Client) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	names := slices.Sorted(maps.Keys(fields))
+	for _, c := range clients {
+		if c == nil {
+			continue
+		}
+		if err := eval.CheckExtraFields(c, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printStability prints the server stability verdict and the reasons for
+// it.
+func printStability(s eval.Stability) {
+	verdict := color.GreenString(s.Verdict)
+	switch s.Verdict {
+	case eval.StabilityDegraded:
+		verdict = color.RedString(s.Verdict)
+	case eval.StabilityUnknown:
+		verdict = color.YellowString(s.Verdict)
+	}
+	fmt.Printf("Server stability: %s", verdict)
+	if s.Start.Error == "" && s.End.Error == "" {
+		fmt.Printf(" (TTFT %dms before, %dms after)", s.Start.TTFTMS, s.End.TTFTMS)
+	}
+	fmt.Println()
+	for _, r := range s.Reasons {
+		fmt.Printf("  %s\n", r)
+	}
+}
+
+// printOutputFindings prints the output hygiene section: what the output
+// scanners flagged, by eval.
+func printOutputFindings(findings []hygiene.Finding) {
+	if len(findings) == 0 {
+		fmt.Printf("\nOutput hygiene: %s\n", color.GreenString("nothing flagged"))
+		return
+	}
+	fmt.Printf("\nOutput hygiene: %s\n", color.YellowString("%d flagged", len(findings)))
+	current := ""
+	for _, f := range findings {
+		if f.Eval != current {
+			current = f.Eval
+			fmt.Printf("  %s\n", current)
+		}
+		fmt.Printf("    %s/%s: %s\n", f.Scanner, f.Kind, f.Excerpt)
+	}
+}
+
+// printSLOViolations lists the evals that exceeded their latency
+// objectives, returning how many did.
+func printSLOViolations(results []eval.Result) int {
+	var violated []eval.Result
+	for _, r := range results {
+		if len(r.SLOViolations) > 0 {
+			violated = append(violated, r)
+		}
+	}
+	if len(violated) == 0 {
+		return 0
+	}
+
+	fmt.Printf("\nSLO violations: %s\n", color.YellowString("%d/%d evals", len(violated), len(results)))
+	for _, r := range violated {
+		fmt.Printf("  %s\n", r.Name)
+		for _, v := range r.SLOViolations {
+			fmt.Printf("    %s\n", v)
+		}
+	}
+	return len(violated)
+}
+
+// printDivergences lists the evals whose outcome, tool calls, or rendered
+// templates diverged on the comparison server.
+func printDivergences(results []eval.Result) {
+	var diverged []eval.Result
+	for _, r := range results {
+		if r.Compare != nil && len(r.Compare.Divergences) > 0 {
+			diverged = append(diverged, r)
+		}
+	}
+
+	fmt.Printf("\nDivergences from %s: %d/%d evals\n", compareBaseURL, len(diverged), len(results))
+	for _, r := range diverged {
+		fmt.Printf("  %s\n", r.Name)
+		for _, d := range r.Compare.Divergences {
+			fmt.Printf("    %s\n", d)
+		}
+	}
+}
+
+// slowestCount is the number of slowest evals listed in the timing summary.
+const slowestCount = 5
+
+// printTiming prints per-category total time and the slowest evals.
+func printTiming(results []eval.Result) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println("\nTiming by category:")
+	for _, ct := range eval.CategoryTimes(results) {
+		fmt.Printf("  %-30s %8s (%d evals)\n", ct.Category, ct.Duration.Round(time.Millisecond), ct.Count)
+	}
+
+	fmt.Println("\nSlowest evals:")
+	for _, r := range eval.SlowestResults(results, slowestCount) {
+		fmt.Printf("  %-55s %8s\n", r.Name, r.Duration.Round(time.Millisecond))
+	}
+
+	if l := eval.MeanStreamingLatency(results); l.Count > 0 {
+		fmt.Printf("\nStreaming latency: mean TTFT %s, mean ITL %s (%d evals)\n",
+			formatLatency(l.TTFT), formatLatency(l.ITL), l.Count)
+	}
+}
+
+func listTests(cmd *cobra.Command, args []string) error {
+	if err := validateSelection(); err != nil {
+		return err
+	}
+
 	tests := eval.AllEvals()
 
 	currentCategory := ""
 	for _, t := range tests {
 		// Apply name filter if specified
-		if filter != "" && !strings.Contains(t.Name(), filter) {
+		if !eval.NameMatches(t.Name(), filter) {
+			continue
+		}
+
+		// Apply tag filters if specified
+		if !eval.AnyRunMatches(t, tags, skipTags) {
 			continue
 		}
 
@@ -212,12 +1061,22 @@ func listTests(cmd *cobra.Command, args []string) {
 			continue
 		}
 
+		// Skip extensions of other server flavors
+		if !eval.FlavorMatches(t, flavor) {
+			continue
+		}
+
 		// Skip disabled-by-default tests unless --all is set
 		isDisabled := eval.IsDefaultDisabled(t)
 		if !all && isDisabled {
 			continue
 		}
 
+		// Skip tests sending images unless --vision is set
+		if !vision && eval.RequiresVision(t) {
+			continue
+		}
+
 		// Print category header
 		if t.Category() != currentCategory {
 			if currentCategory != "" {
@@ -232,27 +1091,371 @@ func listTests(cmd *cobra.Command, args []string) {
 		if isDisabled {
 			disabledMarker = " (disabled by default)"
 		}
-		fmt.Printf("  %-45s [%s]%s\n", t.Name(), t.Class(), disabledMarker)
+		flavorMarker := ""
+		if f := eval.EvalFlavor(t); f != "" {
+			flavorMarker = " (" + f + " only)"
+		}
+		visionMarker := ""
+		if eval.RequiresVision(t) {
+			visionMarker = " (vision)"
+		}
+		fmt.Printf("  %-45s [%s]%s%s%s\n", t.Name(), t.Class(), flavorMarker, visionMarker, disabledMarker)
 	}
+
+	fmt.Printf("\nTags (--tag, --skip-tag): %s\n", strings.Join(eval.AllTags(), ", "))
+	return nil
 }
 
-// parseExtraFields parses --extra flags into a map.
-// Supports two formats:
-//   - key=value  (value is a string)
-//   - key:=json  (value is parsed as JSON)
-func parseExtraFields(extras []string) (map[string]any, error) {
-	if len(extras) == 0 {
-		return nil, nil
+// stopProfiling finishes the --cpu-profile and --mem-profile files. It is
+// set by startProfiling, and safe to call more than once.
+var stopProfiling = func() {}
+
+// startProfiling starts profiling the harness for --cpu-profile and
+// --mem-profile, to find where it spends time and memory on long runs.
+func startProfiling() error {
+	var cpuFile *os.File
+	if cpuProfilePath != "" {
+		f, err := os.Create(cpuProfilePath)
+		if err != nil {
+			return fmt.Errorf("create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("start CPU profile: %w", err)
+		}
+		cpuFile = f
 	}
 
-	result := make(map[string]any)
-	for _, e := range extras {
-		// Check for JSON value format (key:=value)
-		if idx := strings.Index(e, ":="); idx > 0 {
-			key := e[:idx]
-			value := e[idx+2:]
-			var parsed any
-			if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+	stopProfiling = sync.OnceFunc(func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write CPU profile: %v\n", err)
+			}
+		}
+		if memProfilePath != "" {
+			if err := writeAllocProfile(memProfilePath); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write memory profile: %v\n", err)
+			}
+		}
+	})
+	return nil
+}
+
+// writeAllocProfile writes the allocations made since the start of the
+// process, and the memory still in use, to path.
+func writeAllocProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// Collect garbage so the in-use figures are current
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// validateSelection checks the --filter regular expression and that every
+// --tag and --skip-tag is a tag of some eval.
+func validateSelection() error {
+	if _, err := regexp.Compile(filter); err != nil {
+		return fmt.Errorf("invalid --filter %q: %w", filter, err)
+	}
+	known := eval.AllTags()
+	for _, t := range append(slices.Clone(tags), skipTags...) {
+		if !slices.Contains(known, t) {
+			return fmt.Errorf("unknown tag %q (known: %s)", t, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// runSelect lets the user pick evals interactively and then runs them.
+func runSelect(cmd *cobra.Command, args []string) error {
+	if baseURL == "" {
+		return fmt.Errorf("--base-url is required")
+	}
+
+	if model == "" {
+		return fmt.Errorf("--model is required")
+	}
+
+	var initial []string
+	if selectProfile != "" {
+		var err error
+		if initial, err = loadProfile(selectProfile); err != nil {
+			return err
+		}
+	}
+
+	if err := validateSelection(); err != nil {
+		return err
+	}
+
+	var items []tui.Item
+	for _, e := range eval.AllEvals() {
+		if !eval.NameMatches(e.Name(), filter) || !eval.AnyRunMatches(e, tags, skipTags) {
+			continue
+		}
+		if !eval.ClassMatches(e.Class(), class) {
+			continue
+		}
+		if !eval.FlavorMatches(e, flavor) {
+			continue
+		}
+		if !vision && eval.RequiresVision(e) {
+			continue
+		}
+		items = append(items, tui.Item{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Class:    e.Class(),
+			Disabled: eval.IsDefaultDisabled(e),
+		})
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no tests match the given filters")
+	}
+
+	save := func(name string, selected []string) error {
+		return profile.Save(name, &profile.Profile{Evals: selected})
+	}
+
+	selected, err := tui.Select(items, initial, selectProfile, save)
+	if errors.Is(err, tui.ErrCancelled) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("select tests: %w", err)
+	}
+
+	selectedEvals = selected
+	return runEvals(cmd, args)
+}
+
+// loadProfile returns the eval names saved in a profile, warning about
+// names that no longer match a registered eval.
+func loadProfile(name string) ([]string, error) {
+	prof, err := profile.Load(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile: %w", err)
+	}
+
+	warnUnknownEvals(fmt.Sprintf("profile %q", name), prof.Evals)
+
+	if len(prof.Evals) == 0 {
+		return nil, fmt.Errorf("profile %q selects no tests", name)
+	}
+	return prof.Evals, nil
+}
+
+// openLogger creates the logger for a run under --log-dir, or reopens the
+// log directory resume when it is set, redacting secrets known from cfg.
+// It returns nil with --no-logs.
+func openLogger(cfg *config.Config, resume string) (*evallog.Logger, error) {
+	if noLogs {
+		return nil, nil
+	}
+
+	redactor, err := newRedactor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var logger *evallog.Logger
+	if resume != "" {
+		logger, err = evallog.Resume(resume, model)
+	} else {
+		logger, err = evallog.New(logDir, model, runLabel)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	logger.SetRedactor(redactor)
+	return logger, nil
+}
+
+// newRedactor returns the Redactor for log artifacts, masking the API keys
+// in use and the patterns given by --redact and cfg.
+func newRedactor(cfg *config.Config) (*evallog.Redactor, error) {
+	patterns := append(slices.Clone(cfg.Redact), redactPatterns...)
+	secrets := []string{apiKey, embeddingAPIKey, compareAPIKey, resultsToken, os.Getenv(resultsTokenEnv), cfg.ModerationAPIKey()}
+	secrets = append(secrets, secretHeaderValues()...)
+	r, err := evallog.NewRedactor(secrets, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --redact flag: %w", err)
+	}
+	return r, nil
+}
+
+// secretHeaderValues returns the values of --header headers that carry
+// credentials, judged by their names, such as Authorization or X-API-Key.
+func secretHeaderValues() []string {
+	var values []string
+	for name, vs := range requestHeaders {
+		lower := strings.ToLower(name)
+		for _, s := range []string{"auth", "key", "token", "secret", "cookie", "password"} {
+			if strings.Contains(lower, s) {
+				values = append(values, vs...)
+				break
+			}
+		}
+	}
+	return values
+}
+
+// applySuite sets run options from a suite of cfg. Options given
+// explicitly on the command line take precedence over the suite.
+func applySuite(cmd *cobra.Command, cfg *config.Config, name string) error {
+	s, err := cfg.Suite(name)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if len(s.Evals) > 0 {
+		warnUnknownEvals(fmt.Sprintf("suite %q", name), s.Evals)
+		selectedEvals = s.Evals
+	}
+	if s.All && !flags.Changed("all") {
+		all = true
+	}
+	if s.Vision && !flags.Changed("vision") {
+		vision = true
+	}
+	if s.Class != "" && !flags.Changed("class") {
+		class = s.Class
+	}
+	if s.Mode != "" && !flags.Changed("mode") {
+		mode = s.Mode
+	}
+	if s.Flavor != "" && !flags.Changed("flavor") {
+		flavor = s.Flavor
+	}
+	if len(s.Tags) > 0 && !flags.Changed("tag") {
+		tags = s.Tags
+	}
+	if len(s.SkipTags) > 0 && !flags.Changed("skip-tag") {
+		skipTags = s.SkipTags
+	}
+	if s.Timeout > 0 && !flags.Changed("timeout") {
+		timeout = time.Duration(s.Timeout)
+	}
+	if s.Repeat > 0 && !flags.Changed("repeat") {
+		repeat = s.Repeat
+	}
+	if s.PassThreshold > 0 && !flags.Changed("pass-threshold") {
+		passThreshold = s.PassThreshold
+	}
+	return nil
+}
+
+// runMockModel serves the mock model until interrupted.
+func runMockModel(cmd *cobra.Command, args []string) error {
+	var script *mockmodel.Script
+	if mockScript != "" {
+		var err error
+		if script, err = mockmodel.LoadScript(mockScript); err != nil {
+			return err
+		}
+	}
+
+	served := model
+	if served == "" {
+		served = mockmodel.DefaultModel
+	}
+	server := mockmodel.New(mockmodel.Config{
+		Model:      served,
+		Script:     script,
+		ChunkDelay: mockChunkDelay,
+	})
+
+	fmt.Printf("Serving mock model %q at http://%s/v1\n", served, mockListen)
+	return http.ListenAndServe(mockListen, server)
+}
+
+// runConfigSchema prints the config file JSON Schema.
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal schema: %w", err)
+	}
+	data = append(data, '\n')
+
+	if schemaOutput == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(schemaOutput, data, 0644); err != nil {
+		return fmt.Errorf("write schema: %w", err)
+	}
+	return nil
+}
+
+// runValidateConfig validates a config file and reports every problem found.
+func runValidateConfig(cmd *cobra.Command, args []string) error {
+	path := configPath
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if path == "" {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	errs := cfg.Validate()
+	for _, err := range errs {
+		fmt.Printf("  %s %v\n", color.RedString("✗"), err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %d problem(s) found", path, len(errs))
+	}
+
+	fmt.Printf("%s %s is valid\n", color.GreenString("✓"), path)
+	return nil
+}
+
+// warnUnknownEvals warns about names in a saved selection that do not match
+// a registered eval.
+func warnUnknownEvals(source string, names []string) {
+	known := make(map[string]bool)
+	for _, e := range eval.AllEvals() {
+		known[e.Name()] = true
+	}
+	for _, n := range names {
+		if !known[n] {
+			fmt.Fprintf(os.Stderr, "Warning: %s names unknown test %q\n", source, n)
+		}
+	}
+}
+
+// parseExtraFields parses --extra flags into a map.
+// Supports two formats:
+//   - key=value  (value is a string)
+//   - key:=json  (value is parsed as JSON)
+func parseExtraFields(extras []string) (map[string]any, error) {
+	if len(extras) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]any)
+	for _, e := range extras {
+		// Check for JSON value format (key:=value)
+		if idx := strings.Index(e, ":="); idx > 0 {
+			key := e[:idx]
+			value := e[idx+2:]
+			var parsed any
+			if err := json.Unmarshal([]byte(value), &parsed); err != nil {
 				return nil, fmt.Errorf("invalid JSON for %q: %w", key, err)
 			}
 			result[key] = parsed
@@ -273,7 +1476,638 @@ func parseExtraFields(extras []string) (map[string]any, error) {
 	return result, nil
 }
 
+// embeddingConfig returns the embedding endpoint for semantic similarity
+// checks, or nil if they are disabled.
+func embeddingConfig() *client.EmbeddingConfig {
+	if !semantic && embeddingURL == "" {
+		return nil
+	}
+	return &client.EmbeddingConfig{
+		BaseURL: embeddingURL,
+		APIKey:  embeddingAPIKey,
+		Model:   embeddingModel,
+	}
+}
+
+// outputTarget is a parsed --output flag.
+type outputTarget struct {
+	format string
+	path   string
+}
+
+// outputFormats lists the formats accepted by --output.
+var outputFormats = []string{"junit", "json"}
+
+// parseOutputs parses --output flags of the form format=path.
+func parseOutputs(values []string) ([]outputTarget, error) {
+	var targets []outputTarget
+	for _, v := range values {
+		idx := strings.Index(v, "=")
+		if idx <= 0 || idx == len(v)-1 {
+			return nil, fmt.Errorf("invalid format %q (expected format=path)", v)
+		}
+
+		format := v[:idx]
+		valid := false
+		for _, f := range outputFormats {
+			if format == f {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown output format %q (valid: %s)", format, strings.Join(outputFormats, ", "))
+		}
+
+		targets = append(targets, outputTarget{format: format, path: v[idx+1:]})
+	}
+	return targets, nil
+}
+
+// writeOutput writes results to a single output target.
+func writeOutput(o outputTarget, run report.RunInfo, results []eval.Result) error {
+	switch o.format {
+	case "junit":
+		return report.WriteJUnit(o.path, results, run.Skipped)
+	case "json":
+		return report.WriteJSON(o.path, run, results)
+	default:
+		return fmt.Errorf("unknown output format %q", o.format)
+	}
+}
+
+// runBench runs a load test against the server and prints latency statistics.
+func runBench(cmd *cobra.Command, args []string) error {
+	if baseURL == "" {
+		return fmt.Errorf("--base-url is required")
+	}
+
+	if model == "" {
+		return fmt.Errorf("--model is required")
+	}
+
+	if benchConcurrency < 1 {
+		return fmt.Errorf("invalid --concurrency %d (must be at least 1)", benchConcurrency)
+	}
+
+	if benchRequests < 0 || benchDuration < 0 {
+		return fmt.Errorf("--requests and --duration must not be negative")
+	}
+
+	requests := benchRequests
+	if requests == 0 && benchDuration == 0 {
+		requests = defaultBenchRequests
+	}
+
+	extraFields, err := parseExtraFields(extra)
+	if err != nil {
+		return fmt.Errorf("invalid --extra flag: %w", err)
+	}
+
+	c := client.New(client.Config{
+		BaseURL:               baseURL,
+		APIKey:                apiKey,
+		Model:                 model,
+		Timeout:               timeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		Extra:                 extraFields,
+		RetryPolicy:           client.DefaultRetryPolicy(retries),
+		UserAgent:             clientUserAgent(),
+		Dial:                  dial,
+		Headers:               requestHeaders,
+		Temperature:           requestTemperature,
+		Provider:              chatProvider,
+	})
+
+	if err := checkExtraFields(extraFields, c); err != nil {
+		return err
+	}
+
+	fmt.Println("LLM Serving Benchmark")
+	fmt.Println("=====================")
+	fmt.Printf("Server: %s\n", baseURL)
+	fmt.Printf("Model: %s\n", model)
+	fmt.Printf("Concurrency: %d\n", benchConcurrency)
+	if requests > 0 {
+		fmt.Printf("Requests: %d\n", requests)
+	}
+	if benchDuration > 0 {
+		fmt.Printf("Duration: %s\n", benchDuration)
+	}
+	fmt.Println()
+
+	summary := bench.Run(cmd.Context(), c, bench.Config{
+		Concurrency: benchConcurrency,
+		Requests:    requests,
+		Duration:    benchDuration,
+		MaxTokens:   benchMaxTokens,
+	})
+
+	printBenchSummary(summary)
+
+	if summary.Errors == summary.Requests {
+		return fmt.Errorf("all %d requests failed", summary.Requests)
+	}
+	return nil
+}
+
+// printBenchSummary prints throughput and latency percentiles for a benchmark run.
+func printBenchSummary(s bench.Summary) {
+	fmt.Printf("Requests:    %d completed, %d failed in %s\n", s.Requests-s.Errors, s.Errors, s.Elapsed.Round(time.Millisecond))
+	if s.FirstError != nil {
+		fmt.Printf("First error: %s\n", color.RedString(s.FirstError.Error()))
+	}
+	fmt.Printf("Throughput:  %.2f req/s, %.1f tokens/s (%d completion tokens)\n", s.RequestsPerSecond(), s.TokensPerSecond(), s.CompletionTokens)
+
+	fmt.Printf("\n%-10s %10s %10s %10s %10s\n", "", "mean", "p50", "p90", "p99")
+	row := func(label string, p bench.Percentiles) {
+		fmt.Printf("%-10s %10s %10s %10s %10s\n", label,
+			formatLatency(p.Mean), formatLatency(p.P50), formatLatency(p.P90), formatLatency(p.P99))
+	}
+	row("TTFT", s.TTFT)
+	row("ITL", s.ITL)
+	row("Latency", s.Latency)
+}
+
+// formatLatency formats a duration in milliseconds with sub-millisecond precision.
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// runAccuracy asks the accuracy benchmark questions and reports the fraction
+// answered correctly. Wrong answers do not fail the command, since accuracy
+// is a quality measure rather than a correctness check.
+func runAccuracy(cmd *cobra.Command, args []string) error {
+	if baseURL == "" {
+		return fmt.Errorf("--base-url is required")
+	}
+
+	if model == "" {
+		return fmt.Errorf("--model is required")
+	}
+
+	if accuracySamples < 1 {
+		return fmt.Errorf("invalid --samples %d (must be at least 1)", accuracySamples)
+	}
+	if _, err := regexp.Compile(filter); err != nil {
+		return fmt.Errorf("invalid --filter %q: %w", filter, err)
+	}
+
+	extraFields, err := parseExtraFields(extra)
+	if err != nil {
+		return fmt.Errorf("invalid --extra flag: %w", err)
+	}
+
+	fileConfig, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	logger, err := openLogger(fileConfig, "")
+	if err != nil {
+		return err
+	}
+	if logger != nil {
+		defer logger.Close()
+	}
+
+	c := client.New(client.Config{
+		BaseURL:               baseURL,
+		APIKey:                apiKey,
+		Model:                 model,
+		Timeout:               timeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		Extra:                 extraFields,
+		RetryPolicy:           client.DefaultRetryPolicy(retries),
+		UserAgent:             clientUserAgent(),
+		Dial:                  dial,
+		Headers:               requestHeaders,
+		Temperature:           requestTemperature,
+		Provider:              chatProvider,
+	})
+
+	if err := checkExtraFields(extraFields, c); err != nil {
+		return err
+	}
+
+	// Answers don't depend on the transport, so only ask in blocking mode
+	runner := eval.NewRunnerWithEvals(c, eval.RunnerConfig{
+		Verbose: verbose,
+		Filter:  filter,
+		Mode:    eval.ModeBlocking,
+		Logger:  logger,
+		Jobs:    jobs,
+
+		// A question passes if at least half its samples are right; the
+		// accuracy figure counts every sample
+		Repeat:        accuracySamples,
+		PassThreshold: 0.5,
+	}, eval.AccuracyEvals())
+
+	fmt.Println("LLM Accuracy Benchmark")
+	fmt.Println("======================")
+	fmt.Printf("Server: %s\n", baseURL)
+	fmt.Printf("Model: %s\n", model)
+	fmt.Printf("Samples per question: %d\n", accuracySamples)
+	fmt.Println()
+
+	results := runner.Run()
+
+	correct, total := eval.Accuracy(results)
+	if total == 0 {
+		return fmt.Errorf("no questions matched --filter %q", filter)
+	}
+	fmt.Printf("\nAccuracy: %d/%d (%.1f%%)\n", correct, total, 100*float64(correct)/float64(total))
+	if breakdown := eval.FailureBreakdown(results); len(breakdown) > 0 {
+		fmt.Println(eval.FormatFailureBreakdown(breakdown))
+	}
+	if logger != nil {
+		fmt.Printf("\nLogs written to: %s\n", logger.Dir())
+
+		if err := report.WriteReport(logger.Dir(), logger.Model(), logger.Label(), logger.Evals(), displayLoc); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to generate report: %v\n", err)
+		} else {
+			fmt.Printf("Report: %s/report.html\n", logger.Dir())
+		}
+	}
+
+	return nil
+}
+
+// runDrift compares the logprobs of two servers' generations. Drift does
+// not fail the command, since it is a measurement rather than a check.
+func runDrift(cmd *cobra.Command, args []string) error {
+	if baseURL == "" || compareBaseURL == "" {
+		return fmt.Errorf("--base-url and --compare-base-url are required")
+	}
+
+	if model == "" {
+		return fmt.Errorf("--model is required")
+	}
+
+	if driftMaxTokens < 1 {
+		return fmt.Errorf("invalid --max-tokens %d (must be at least 1)", driftMaxTokens)
+	}
+	if driftTopLogprobs < 0 || driftTopLogprobs > 20 {
+		return fmt.Errorf("invalid --top-logprobs %d (must be 0 to 20)", driftTopLogprobs)
+	}
+
+	compareProvider := chatProvider
+	if compareProviderName != "" {
+		p, err := client.NewProvider(compareProviderName)
+		if err != nil {
+			return fmt.Errorf("invalid --compare-provider: %w", err)
+		}
+		compareProvider = p
+	}
+
+	extraFields, err := parseExtraFields(extra)
+	if err != nil {
+		return fmt.Errorf("invalid --extra flag: %w", err)
+	}
+
+	c := client.New(client.Config{
+		BaseURL:               baseURL,
+		APIKey:                apiKey,
+		Model:                 model,
+		Timeout:               timeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		Extra:                 extraFields,
+		RetryPolicy:           client.DefaultRetryPolicy(retries),
+		UserAgent:             clientUserAgent(),
+		Dial:                  dial,
+		Headers:               requestHeaders,
+		Provider:              chatProvider,
+	})
+	compare := client.New(client.Config{
+		BaseURL:               compareBaseURL,
+		APIKey:                cmp.Or(compareAPIKey, apiKey),
+		Model:                 cmp.Or(compareModel, model),
+		Timeout:               timeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		Extra:                 extraFields,
+		RetryPolicy:           client.DefaultRetryPolicy(retries),
+		UserAgent:             clientUserAgent(),
+		Dial:                  dial,
+		Headers:               requestHeaders,
+		Provider:              compareProvider,
+	})
+
+	if err := checkExtraFields(extraFields, c, compare); err != nil {
+		return err
+	}
+
+	fmt.Println("LLM Logprob Drift")
+	fmt.Println("=================")
+	fmt.Printf("Server: %s (%s)\n", baseURL, model)
+	fmt.Printf("Comparing with: %s (%s)\n", compareBaseURL, compare.Model())
+	fmt.Println()
+
+	summary := drift.Run(cmd.Context(), c, compare, drift.Config{
+		MaxTokens:   driftMaxTokens,
+		TopLogprobs: driftTopLogprobs,
+	})
+
+	printDriftSummary(summary)
+
+	if summary.Errors == len(summary.Conversations) {
+		return fmt.Errorf("all %d conversations failed", summary.Errors)
+	}
+	return nil
+}
+
+// printDriftSummary prints each conversation's comparison and the logprob
+// difference percentiles across them.
+func printDriftSummary(s drift.Summary) {
+	for _, conv := range s.Conversations {
+		prompt := conv.Prompt
+		if r := []rune(prompt); len(r) > 40 {
+			prompt = string(r[:39]) + "…"
+		}
+		switch {
+		case conv.Err != nil:
+			fmt.Printf("  %s %-42q %s\n", color.RedString("✗"), prompt, color.RedString(conv.Err.Error()))
+		case conv.DivergedAt < 0:
+			fmt.Printf("  %s %-42q identical, %d tokens compared\n", color.GreenString("="), prompt, conv.Tokens)
+		default:
+			fmt.Printf("  %s %-42q diverged at token %d, %d tokens compared\n", color.YellowString("≠"), prompt, conv.DivergedAt, conv.Tokens)
+		}
+	}
+
+	fmt.Printf("\nConversations:  %d compared, %d failed, %d identical\n", len(s.Conversations)-s.Errors, s.Errors, s.Identical)
+	if s.FirstError != nil {
+		fmt.Printf("First error:    %s\n", color.RedString(s.FirstError.Error()))
+	}
+	if s.Tokens == 0 {
+		return
+	}
+	fmt.Printf("Tokens:         %d compared\n", s.Tokens)
+	fmt.Printf("Top overlap:    %.1f%%\n", 100*s.TopOverlap)
+
+	fmt.Printf("\n%-10s %10s %10s %10s %10s %10s\n", "", "mean", "p50", "p95", "p99", "max")
+	d := s.Deltas
+	fmt.Printf("%-10s %10.4f %10.4f %10.4f %10.4f %10.4f\n", "|Δlogprob|", d.Mean, d.P50, d.P95, d.P99, d.Max)
+}
+
 // runReplay replays a streaming response from a JSONL capture file.
+func runReport(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+
+	evals, err := evallog.Load(dir)
+	if err != nil {
+		return err
+	}
+	if len(evals) == 0 {
+		return fmt.Errorf("no eval logs found in %s", dir)
+	}
+
+	var label string
+	if meta, err := evallog.ReadRunMeta(dir); err == nil && meta != nil {
+		label = meta.Label
+	}
+	if err := report.WriteReport(dir, reportModel(dir, evals), label, evals, displayLoc); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	reportPath := filepath.Join(dir, "report.html")
+	fmt.Printf("Report: %s\n", reportPath)
+
+	// Refresh the model's run index if the run is in one
+	modelDir := filepath.Dir(filepath.Clean(dir))
+	if _, err := os.Stat(filepath.Join(modelDir, "index.html")); err == nil {
+		if err := report.WriteIndex(modelDir, displayLoc); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to generate index: %v\n", err)
+		} else {
+			fmt.Printf("Index: %s\n", filepath.Join(modelDir, "index.html"))
+		}
+	}
+
+	if reportOpen {
+		if err := openBrowser(reportPath); err != nil {
+			return fmt.Errorf("open report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func runRegressionPack(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+
+	evals, err := evallog.Load(dir)
+	if err != nil {
+		return err
+	}
+	pack, err := eval.BuildRegressionPack(dir, reportModel(dir, evals))
+	if err != nil {
+		return err
+	}
+	if err := pack.Write(packOutput); err != nil {
+		return err
+	}
+
+	templates := 0
+	for _, snap := range pack.Evals {
+		templates += len(snap.Templates)
+	}
+	fmt.Printf("Regression pack: %s (%d evals, %d templates)\n", packOutput, len(pack.Evals), templates)
+	return nil
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	if mergeThreshold <= 0 || mergeThreshold > 1 {
+		return fmt.Errorf("invalid --pass-threshold %g (must be in (0, 1])", mergeThreshold)
+	}
+
+	var files []*report.ResultsFile
+	for _, path := range args {
+		f, err := report.LoadJSON(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	merged, err := report.Merge(files, mergeThreshold)
+	if err != nil {
+		return err
+	}
+	if err := merged.Write(mergeOutput, displayLoc); err != nil {
+		return err
+	}
+
+	fmt.Printf("Merged %d files: %s (%s)\n", len(files), merged.Run.Model, merged.Run.Server)
+	for _, d := range merged.Duplicates {
+		fmt.Printf("  %s %s is in %d files, combined as repeated runs: %s\n",
+			color.YellowString("duplicate:"), d.Name, len(d.Files), strings.Join(d.Files, ", "))
+	}
+	for _, w := range merged.Warnings {
+		fmt.Printf("  %s %s\n", color.YellowString("warning:"), w)
+	}
+
+	passed := merged.Passed()
+	fmt.Printf("\nResults: %d/%d passed\n", passed, len(merged.Results))
+	if breakdown := eval.FailureBreakdown(merged.Results); len(breakdown) > 0 {
+		fmt.Println(eval.FormatFailureBreakdown(breakdown))
+	}
+	sloViolated := printSLOViolations(merged.Results)
+	fmt.Printf("Results: %s\n", filepath.Join(mergeOutput, "results.json"))
+	fmt.Printf("Report: %s\n", filepath.Join(mergeOutput, "report.html"))
+
+	if merged.Incomplete {
+		fmt.Printf("\n%s shards are missing, so the results do not cover the whole run\n", color.RedString("Incomplete:"))
+	}
+	if merged.Incomplete || passed < len(merged.Results) || sloViolated > 0 {
+		exit(1)
+	}
+	return nil
+}
+
+// loadSigningKey returns the key given by --sign-key, or else by the
+// environment, or nil if neither is set.
+func loadSigningKey() (ed25519.PrivateKey, error) {
+	var data []byte
+	switch {
+	case signKeyPath != "":
+		var err error
+		if data, err = os.ReadFile(signKeyPath); err != nil {
+			return nil, fmt.Errorf("read --sign-key: %w", err)
+		}
+	case os.Getenv(signKeyEnv) != "":
+		data = []byte(os.Getenv(signKeyEnv))
+	default:
+		return nil, nil
+	}
+	return attest.ParseSigningKey(data)
+}
+
+// clientUserAgent returns the User-Agent of requests: --user-agent, or
+// one naming this suite and its version, so that server operators can tell
+// test traffic apart.
+func clientUserAgent() string {
+	if userAgent != "" {
+		return userAgent
+	}
+	return "llm-serve-test/" + buildVersion()
+}
+
+// buildVersion identifies this build: its module version if installed from
+// a release, or else its VCS revision.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision == "" {
+		return "devel"
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+func runVerifyAttestation(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	results := verifyResults
+	if results == "" {
+		var ok bool
+		if results, ok = strings.CutSuffix(path, attest.Suffix); !ok {
+			return fmt.Errorf("cannot tell the results file of %s; pass --results", path)
+		}
+	}
+
+	pub, err := attest.LoadVerifyKey(verifyKeyPath)
+	if err != nil {
+		return err
+	}
+	env, err := attest.LoadEnvelope(path)
+	if err != nil {
+		return err
+	}
+	st, err := attest.Verify(env, pub)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := st.CheckSubject(results); err != nil {
+		return err
+	}
+
+	p := st.Predicate
+	fmt.Printf("%s %s is signed by key %s\n", color.GreenString("✓"), path, attest.KeyID(pub))
+	fmt.Printf("Results file: %s\n", results)
+	fmt.Printf("Suite version: %s\n", p.Run.Version)
+	if p.Run.Suite != "" {
+		fmt.Printf("Suite: %s\n", p.Run.Suite)
+	}
+	if p.Run.Shard != nil {
+		fmt.Printf("Shard: %s\n", p.Run.Shard)
+	}
+	fmt.Printf("Server: %s (flavor %s)\n", p.Run.Server, p.Run.Flavor)
+	fmt.Printf("Model: %s\n", p.Run.Model)
+	fmt.Printf("Ran: %s for %s\n", p.Run.Started.In(displayLoc).Format(time.RFC3339), time.Duration(p.Run.DurationMS)*time.Millisecond)
+	fmt.Printf("Results: %d/%d passed\n", p.Passed, p.Total)
+	for _, o := range p.Evals {
+		if !o.Passed {
+			fmt.Printf("  %s %s (%s)\n", color.RedString("✗"), o.Name, o.Code)
+		}
+	}
+	return nil
+}
+
+// reportModel returns the model a run tested: the model recorded in its
+// run.json, else the model of its first logged request, or else the name of
+// the model directory holding the run.
+func reportModel(dir string, evals []evallog.EvalResult) string {
+	if meta, err := evallog.ReadRunMeta(dir); err == nil && meta != nil && meta.Model != "" {
+		return meta.Model
+	}
+	for _, ev := range evals {
+		for _, t := range ev.Turns {
+			var req struct {
+				Model string `json:"model"`
+			}
+			if json.Unmarshal(t.RequestBody, &req) == nil && req.Model != "" {
+				return req.Model
+			}
+		}
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return filepath.Base(filepath.Dir(dir))
+	}
+	return filepath.Base(filepath.Dir(abs))
+}
+
+// openBrowser opens a file in the default browser.
+func openBrowser(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", abs)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", abs)
+	default:
+		cmd = exec.Command("xdg-open", abs)
+	}
+	return cmd.Start()
+}
+
 func runReplay(cmd *cobra.Command, args []string) error {
 	return replayFile(args[0])
 }
@@ -282,10 +2116,9 @@ func runReplay(cmd *cobra.Command, args []string) error {
 func runReplayAll(cmd *cobra.Command, args []string) error {
 	dir := args[0]
 
-	pattern := filepath.Join(dir, "*.stream.jsonl")
-	files, err := filepath.Glob(pattern)
+	files, err := evallog.FilesWithSuffix(dir, ".stream.jsonl")
 	if err != nil {
-		return fmt.Errorf("glob: %w", err)
+		return err
 	}
 
 	if len(files) == 0 {
@@ -314,6 +2147,164 @@ func runReplayAll(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// replayDiffContext is the number of unchanged words kept on each side of
+// a change in content diffs printed by replay-against.
+const replayDiffContext = 5
+
+// runReplayAgainst re-sends the requests recorded in a log directory to the
+// server at --base-url and prints where its responses diverge from the
+// capture.
+func runReplayAgainst(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+
+	if baseURL == "" {
+		return fmt.Errorf("--base-url is required")
+	}
+	if _, err := regexp.Compile(filter); err != nil {
+		return fmt.Errorf("invalid --filter %q: %w", filter, err)
+	}
+
+	replayModel := model
+	if replayModel == "" {
+		evals, err := evallog.Load(dir)
+		if err != nil {
+			return err
+		}
+		replayModel = reportModel(dir, evals)
+	}
+
+	extraFields, err := parseExtraFields(extra)
+	if err != nil {
+		return fmt.Errorf("invalid --extra flag: %w", err)
+	}
+
+	c := client.New(client.Config{
+		BaseURL:               baseURL,
+		APIKey:                apiKey,
+		Model:                 replayModel,
+		Timeout:               timeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		Extra:                 extraFields,
+		RetryPolicy:           client.DefaultRetryPolicy(retries),
+		UserAgent:             clientUserAgent(),
+		Dial:                  dial,
+		Headers:               requestHeaders,
+	})
+
+	fmt.Printf("Replaying %s against %s (%s)\n\n", dir, baseURL, replayModel)
+	outcomes, err := eval.ReplayAgainst(cmd.Context(), dir, c, filter)
+	if err != nil {
+		return err
+	}
+	if len(outcomes) == 0 {
+		return fmt.Errorf("no evals in %s matched --filter %q", dir, filter)
+	}
+
+	requests, diverged := 0, 0
+	for _, o := range outcomes {
+		requests += o.Requests
+		if o.Diverged() {
+			diverged++
+			fmt.Printf("  %s %s\n", color.RedString("✗"), o.Name)
+		} else {
+			fmt.Printf("  %s %s (%d replayed)\n", color.GreenString("✓"), o.Name, o.Requests)
+		}
+		for _, d := range o.Divergences {
+			fmt.Printf("    %s %s\n", color.MagentaString("diverged:"), d)
+		}
+		if len(o.Diffs) > 0 && !verbose {
+			fmt.Printf("    %s content differed in %d responses (-v to show)\n", color.YellowString("note:"), len(o.Diffs))
+		}
+		if verbose {
+			for _, d := range o.Diffs {
+				spans := textdiff.Compact(textdiff.Words(d.A, d.B), replayDiffContext)
+				fmt.Printf("    %s %s\n", color.CyanString("diff (%s → %s):", d.ALabel, d.BLabel), textdiff.Console(spans))
+			}
+		}
+	}
+
+	fmt.Printf("\nReplayed %d requests of %d evals: %d diverged\n", requests, len(outcomes), diverged)
+	if diverged > 0 {
+		exit(1)
+	}
+	return nil
+}
+
+// templateDiffContext is the number of unchanged words kept on each side
+// of a change in prompt diffs printed by template analyze.
+const templateDiffContext = 4
+
+// visibleWhitespace escapes line breaks and tabs, so that prompt diffs
+// print on one line and whitespace changes show.
+var visibleWhitespace = strings.NewReplacer("\r", `\r`, "\n", `\n`, "\t", `\t`)
+
+// runTemplateAnalyze renders variations of conversations through the
+// server's chat template and prints how each changed the prompt.
+func runTemplateAnalyze(cmd *cobra.Command, args []string) error {
+	if baseURL == "" {
+		return fmt.Errorf("--base-url is required")
+	}
+	if _, err := regexp.Compile(filter); err != nil {
+		return fmt.Errorf("invalid --filter %q: %w", filter, err)
+	}
+
+	c := client.New(client.Config{
+		BaseURL:               baseURL,
+		APIKey:                apiKey,
+		Model:                 model,
+		Timeout:               timeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		RetryPolicy:           client.DefaultRetryPolicy(retries),
+		UserAgent:             clientUserAgent(),
+		Dial:                  dial,
+		Headers:               requestHeaders,
+	})
+
+	fmt.Printf("Analyzing the chat template of %s\n\n", baseURL)
+	results, err := eval.AnalyzeTemplate(cmd.Context(), c, filter)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no variations matched --filter %q", filter)
+	}
+
+	changed, findings := 0, 0
+	for _, r := range results {
+		effect := "unchanged"
+		if r.Verbatim {
+			effect = "rendered verbatim"
+		} else if r.Changed {
+			effect = "changed"
+		}
+		if r.Changed {
+			changed++
+		}
+		mark := color.GreenString("✓")
+		if r.Finding != "" {
+			findings++
+			mark = color.RedString("✗")
+		}
+		fmt.Printf("  %s %s: %s (%s)\n", mark, r.Name, r.Description, effect)
+		if r.Finding != "" {
+			fmt.Printf("    %s %s\n", color.MagentaString("finding:"), r.Finding)
+		}
+		if d := r.Diff; d != nil && (r.Finding != "" || verbose) {
+			spans := textdiff.Compact(textdiff.Words(d.A, d.B), templateDiffContext)
+			for i := range spans {
+				spans[i].Text = visibleWhitespace.Replace(spans[i].Text)
+			}
+			fmt.Printf("    %s %s\n", color.CyanString("diff:"), textdiff.Console(spans))
+		}
+	}
+
+	fmt.Printf("\nRendered %d variations: %d changed the prompt, %d with findings\n", len(results), changed, findings)
+	if findings > 0 {
+		exit(1)
+	}
+	return nil
+}
+
 // replayFile replays a single JSONL file.
 func replayFile(filename string) error {
 	file, err := os.Open(filename)
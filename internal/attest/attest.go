@@ -0,0 +1,258 @@
+// Package attest signs JSON result exports as in-toto attestations, so that
+// published compatibility claims can be checked against the run that made
+// them. An attestation is an in-toto Statement whose subject is the results
+// file, wrapped in a DSSE envelope signed with an ed25519 key.
+package attest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+	"github.com/aldehir/llm-serving-tests/internal/report"
+)
+
+const (
+	// StatementType is the in-toto Statement version written.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType identifies the predicate describing a test run.
+	PredicateType = "https://github.com/aldehir/llm-serving-tests/attestation/results/v1"
+	// PayloadType is the DSSE payload type of in-toto statements.
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// Suffix is appended to a results file's path to name its attestation.
+const Suffix = ".intoto.jsonl"
+
+// Envelope is a DSSE envelope. Payload and signatures are base64 encoded
+// when marshaled.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is one signature of an envelope.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   []byte `json:"sig"`
+}
+
+// Statement is an in-toto Statement about a results file.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject names an attested file by its digests.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Predicate describes a run: which build of the suite ran against which
+// server, and which evals passed.
+type Predicate struct {
+	Run    report.RunInfo `json:"run"`
+	Passed int            `json:"passed"`
+	Total  int            `json:"total"`
+	Evals  []Outcome      `json:"evals"`
+}
+
+// Outcome is the outcome of one eval in a predicate.
+type Outcome struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Code   string `json:"code,omitempty"`
+}
+
+// NewPredicate describes a run and its results.
+func NewPredicate(run report.RunInfo, results []eval.Result) Predicate {
+	p := Predicate{
+		Run:   run,
+		Total: len(results),
+		Evals: make([]Outcome, 0, len(results)),
+	}
+	for _, r := range results {
+		if r.Passed {
+			p.Passed++
+		}
+		p.Evals = append(p.Evals, Outcome{Name: r.Name, Passed: r.Passed, Code: r.Code})
+	}
+	return p
+}
+
+// Sign makes an attestation of the results file at path, signed with key.
+func Sign(key ed25519.PrivateKey, path string, pred Predicate) (*Envelope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read results: %w", err)
+	}
+	digest := sha256.Sum256(data)
+
+	payload, err := json.Marshal(Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   filepath.Base(path),
+			Digest: map[string]string{"sha256": hex.EncodeToString(digest[:])},
+		}},
+		PredicateType: PredicateType,
+		Predicate:     pred,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal statement: %w", err)
+	}
+
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures: []Signature{{
+			KeyID: KeyID(key.Public().(ed25519.PublicKey)),
+			Sig:   ed25519.Sign(key, pae(PayloadType, payload)),
+		}},
+	}, nil
+}
+
+// Verify checks that the envelope is signed by pub and holds a statement
+// of this suite's predicate type, and returns the statement.
+func Verify(env *Envelope, pub ed25519.PublicKey) (*Statement, error) {
+	if env.PayloadType != PayloadType {
+		return nil, fmt.Errorf("payload type is %q, not %q", env.PayloadType, PayloadType)
+	}
+
+	msg := pae(env.PayloadType, env.Payload)
+	verified := false
+	for _, s := range env.Signatures {
+		if ed25519.Verify(pub, msg, s.Sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("no signature by key %s", KeyID(pub))
+	}
+
+	var st Statement
+	if err := json.Unmarshal(env.Payload, &st); err != nil {
+		return nil, fmt.Errorf("parse statement: %w", err)
+	}
+	if st.Type != StatementType {
+		return nil, fmt.Errorf("statement type is %q, not %q", st.Type, StatementType)
+	}
+	if st.PredicateType != PredicateType {
+		return nil, fmt.Errorf("predicate type is %q, not %q", st.PredicateType, PredicateType)
+	}
+	if len(st.Subject) != 1 {
+		return nil, fmt.Errorf("statement has %d subjects, expected 1", len(st.Subject))
+	}
+	return &st, nil
+}
+
+// CheckSubject checks that the file at path is the statement's subject.
+func (s *Statement) CheckSubject(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read results: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	want := s.Subject[0].Digest["sha256"]
+	if got := hex.EncodeToString(digest[:]); got != want {
+		return fmt.Errorf("%s has sha256 %s, but the attestation is of %s", path, got, want)
+	}
+	return nil
+}
+
+// Write saves the envelope as a single line of JSON.
+func (e *Envelope) Write(path string) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal attestation: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write attestation: %w", err)
+	}
+	return nil
+}
+
+// LoadEnvelope reads an attestation written by Write.
+func LoadEnvelope(path string) (*Envelope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read attestation: %w", err)
+	}
+	var env Envelope
+	if err := json.Unmarshal(bytes.TrimSpace(data), &env); err != nil {
+		return nil, fmt.Errorf("parse attestation %s: %w", path, err)
+	}
+	return &env, nil
+}
+
+// ParseSigningKey parses a PEM-encoded PKCS #8 ed25519 private key, as
+// written by "openssl genpkey -algorithm ed25519".
+func ParseSigningKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("signing key is not a PEM-encoded PRIVATE KEY")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is %T, not ed25519", key)
+	}
+	return edKey, nil
+}
+
+// LoadVerifyKey reads a PEM-encoded PKIX ed25519 public key, as written by
+// "openssl pkey -pubout".
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("%s is not a PEM-encoded PUBLIC KEY", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is %T, not ed25519", key)
+	}
+	return edKey, nil
+}
+
+// KeyID identifies a public key by the hex SHA-256 of its PKIX encoding.
+func KeyID(pub ed25519.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		panic(err) // ed25519 keys always marshal
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// pae returns the DSSE pre-authentication encoding of a payload, which is
+// what gets signed.
+func pae(payloadType string, payload []byte) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	b.Write(payload)
+	return b.Bytes()
+}
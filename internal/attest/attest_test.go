@@ -0,0 +1,237 @@
+package attest
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+	"github.com/aldehir/llm-serving-tests/internal/report"
+)
+
+// signedResults writes a results file and returns its path, a key, and an
+// attestation of the file signed with the key.
+func signedResults(t *testing.T) (string, ed25519.PrivateKey, *Envelope) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path, []byte(`{"passed": 1, "total": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pred := NewPredicate(report.RunInfo{}, []eval.Result{
+		{Name: "single_tool_call", Passed: true},
+		{Name: "reasoning_present", Passed: false, Code: "REASONING_EMPTY"},
+	})
+	env, err := Sign(key, path, pred)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return path, key, env
+}
+
+func TestSignVerify(t *testing.T) {
+	path, key, env := signedResults(t)
+
+	// Through the file format, as verify-attestation reads it
+	envPath := path + Suffix
+	if err := env.Write(envPath); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	loaded, err := LoadEnvelope(envPath)
+	if err != nil {
+		t.Fatalf("LoadEnvelope: %v", err)
+	}
+
+	st, err := Verify(loaded, key.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := st.CheckSubject(path); err != nil {
+		t.Errorf("CheckSubject: %v", err)
+	}
+	if st.Subject[0].Name != "results.json" {
+		t.Errorf("subject name = %q, want results.json", st.Subject[0].Name)
+	}
+	if p := st.Predicate; p.Passed != 1 || p.Total != 2 || len(p.Evals) != 2 || p.Evals[1].Code != "REASONING_EMPTY" {
+		t.Errorf("predicate = %+v, want the signed outcomes", p)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(env *Envelope, pub *ed25519.PublicKey)
+	}{
+		{
+			name: "tampered payload",
+			modify: func(env *Envelope, _ *ed25519.PublicKey) {
+				env.Payload = append([]byte{}, env.Payload...)
+				env.Payload[len(env.Payload)-2] ^= 1
+			},
+		},
+		{
+			name: "wrong key",
+			modify: func(_ *Envelope, pub *ed25519.PublicKey) {
+				*pub = otherKey.Public().(ed25519.PublicKey)
+			},
+		},
+		{
+			name: "wrong payload type",
+			modify: func(env *Envelope, _ *ed25519.PublicKey) {
+				env.PayloadType = "application/json"
+			},
+		},
+		{
+			name: "no signatures",
+			modify: func(env *Envelope, _ *ed25519.PublicKey) {
+				env.Signatures = nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, key, env := signedResults(t)
+			pub := key.Public().(ed25519.PublicKey)
+			tt.modify(env, &pub)
+			if _, err := Verify(env, pub); err == nil {
+				t.Error("Verify succeeded")
+			}
+		})
+	}
+}
+
+func TestCheckSubjectMismatch(t *testing.T) {
+	path, key, env := signedResults(t)
+	st, err := Verify(env, key.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"passed": 2, "total": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.CheckSubject(path); err == nil {
+		t.Error("CheckSubject accepted a results file with a different digest")
+	}
+}
+
+// TestPAE checks the pre-authentication encoding against the example of
+// the DSSE specification.
+func TestPAE(t *testing.T) {
+	got := string(pae("http://example.com/HelloWorld", []byte("hello world")))
+	want := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != want {
+		t.Errorf("pae = %q, want %q", got, want)
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ParseSigningKey(privateKeyPEM(t, key))
+	if err != nil {
+		t.Fatalf("ParseSigningKey: %v", err)
+	}
+	if !got.Equal(key) {
+		t.Error("parsed key differs from the encoded one")
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not pem", []byte("not a key")},
+		{"public key", publicKeyPEM(t, key.Public())},
+		{"not ed25519", privateKeyPEM(t, ecKey)},
+		{"corrupt", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("corrupt")})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSigningKey(tt.data); err == nil {
+				t.Error("ParseSigningKey succeeded")
+			}
+		})
+	}
+}
+
+func TestLoadVerifyKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	got, err := LoadVerifyKey(write("key.pub", publicKeyPEM(t, key.Public())))
+	if err != nil {
+		t.Fatalf("LoadVerifyKey: %v", err)
+	}
+	if !got.Equal(key.Public()) {
+		t.Error("loaded key differs from the written one")
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"missing", filepath.Join(dir, "missing.pub")},
+		{"private key", write("key.pem", privateKeyPEM(t, key))},
+		{"not ed25519", write("ec.pub", publicKeyPEM(t, ecKey.Public()))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadVerifyKey(tt.path); err == nil {
+				t.Error("LoadVerifyKey succeeded")
+			}
+		})
+	}
+}
+
+func privateKeyPEM(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicKeyPEM(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
@@ -0,0 +1,234 @@
+// Package bench measures server throughput and latency under concurrent load.
+package bench
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// prompts is the fixed prompt set cycled through by benchmark requests.
+// The prompts ask for open-ended prose so that responses run to max_tokens.
+var prompts = []string{
+	"Write a short story about a lighthouse keeper who discovers a message in a bottle.",
+	"Explain how a hash map works, including collision handling and resizing.",
+	"Describe the water cycle in detail, from evaporation to precipitation.",
+	"Write a letter from a traveler describing their first day in a new city.",
+	"Explain the differences between TCP and UDP and when to use each.",
+	"Summarize the history of the printing press and its impact on society.",
+	"Describe how to plan and plant a small vegetable garden.",
+	"Explain what a compiler does, stage by stage, for a beginner.",
+}
+
+// Config configures a benchmark run.
+type Config struct {
+	// Concurrency is the number of requests in flight at once.
+	Concurrency int
+	// Requests is the total number of requests to send (0 = no limit).
+	Requests int
+	// Duration stops issuing new requests after this long (0 = no limit).
+	// Requests in flight when it elapses still complete.
+	Duration time.Duration
+	// MaxTokens caps the completion length of each request.
+	MaxTokens int
+}
+
+// Sample is the measurement of a single streaming request.
+type Sample struct {
+	Latency time.Duration
+	TTFT    time.Duration
+	// ITL is the mean delay between chunks carrying generated tokens.
+	ITL              time.Duration
+	CompletionTokens int
+	Err              error
+}
+
+// Percentiles summarizes a latency distribution.
+type Percentiles struct {
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+}
+
+// Summary aggregates the samples of a benchmark run.
+type Summary struct {
+	Requests int
+	Errors   int
+	// FirstError is the first request error, for diagnosing failed runs.
+	FirstError       error
+	Elapsed          time.Duration
+	CompletionTokens int
+
+	TTFT    Percentiles
+	ITL     Percentiles
+	Latency Percentiles
+}
+
+// RequestsPerSecond returns the rate of successful requests.
+func (s Summary) RequestsPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Requests-s.Errors) / s.Elapsed.Seconds()
+}
+
+// TokensPerSecond returns the aggregate completion token throughput.
+func (s Summary) TokensPerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.CompletionTokens) / s.Elapsed.Seconds()
+}
+
+// Run issues streaming chat completions with the configured concurrency until
+// the request count or duration limit is reached, and summarizes the results.
+// If neither limit is set, Run sends one request per worker.
+func Run(ctx context.Context, c *client.Client, cfg Config) Summary {
+	concurrency := max(cfg.Concurrency, 1)
+	limit := cfg.Requests
+	if limit == 0 && cfg.Duration == 0 {
+		limit = concurrency
+	}
+
+	var deadline time.Time
+	if cfg.Duration > 0 {
+		deadline = time.Now().Add(cfg.Duration)
+	}
+
+	var (
+		next    atomic.Int64
+		mu      sync.Mutex
+		samples []Sample
+		wg      sync.WaitGroup
+	)
+
+	start := time.Now()
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if ctx.Err() != nil || (!deadline.IsZero() && time.Now().After(deadline)) {
+					return
+				}
+				n := int(next.Add(1))
+				if limit > 0 && n > limit {
+					return
+				}
+
+				s := measure(ctx, c, prompts[(n-1)%len(prompts)], cfg.MaxTokens)
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return summarize(samples, time.Since(start))
+}
+
+// measure sends one streaming request and records its timings.
+func measure(ctx context.Context, c *client.Client, prompt string, maxTokens int) Sample {
+	start := time.Now()
+	result, err := c.ChatCompletionStream(ctx, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens: maxTokens,
+	})
+	s := Sample{Latency: time.Since(start)}
+	if err != nil {
+		s.Err = err
+		return s
+	}
+
+	s.TTFT = result.TTFT
+	s.ITL = result.ITL
+	s.CompletionTokens = completionTokens(result)
+	return s
+}
+
+// completionTokens returns the completion token count from usage, falling
+// back to the number of chunks carrying generated text when the server does
+// not report usage.
+func completionTokens(result *client.StreamResult) int {
+	if result.Usage != nil && result.Usage.CompletionTokens > 0 {
+		return result.Usage.CompletionTokens
+	}
+	n := 0
+	for _, chunk := range result.Chunks {
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		if delta.Content != "" || delta.ReasoningContent != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// summarize aggregates samples into a Summary.
+func summarize(samples []Sample, elapsed time.Duration) Summary {
+	s := Summary{
+		Requests: len(samples),
+		Elapsed:  elapsed,
+	}
+
+	var ttft, itl, latency []time.Duration
+	for _, sample := range samples {
+		if sample.Err != nil {
+			if s.FirstError == nil {
+				s.FirstError = sample.Err
+			}
+			s.Errors++
+			continue
+		}
+		s.CompletionTokens += sample.CompletionTokens
+		latency = append(latency, sample.Latency)
+		if sample.TTFT > 0 {
+			ttft = append(ttft, sample.TTFT)
+		}
+		if sample.ITL > 0 {
+			itl = append(itl, sample.ITL)
+		}
+	}
+
+	s.TTFT = percentiles(ttft)
+	s.ITL = percentiles(itl)
+	s.Latency = percentiles(latency)
+	return s
+}
+
+// percentiles computes the mean and nearest-rank percentiles of ds.
+func percentiles(ds []time.Duration) Percentiles {
+	if len(ds) == 0 {
+		return Percentiles{}
+	}
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+
+	rank := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(i, 0)]
+	}
+
+	return Percentiles{
+		Mean: total / time.Duration(len(sorted)),
+		P50:  rank(0.50),
+		P90:  rank(0.90),
+		P99:  rank(0.99),
+	}
+}
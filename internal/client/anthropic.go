@@ -0,0 +1,483 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropicVersion is the Messages API version sent with every request.
+const anthropicVersion = "2023-06-01"
+
+// anthropicMaxTokens is the max_tokens of requests that set none, since
+// the Messages API requires it.
+const anthropicMaxTokens = 4096
+
+// Anthropic is the Anthropic Messages API (/v1/messages). System messages
+// become the system prompt, reasoning becomes thinking blocks, tool calls
+// and results become tool_use and tool_result blocks, and stop becomes
+// stop_sequences. Fields the API
+// has no equivalent for, such as response_format, logprobs, and n, are
+// dropped; extra fields are sent as they are, e.g. thinking to enable
+// extended thinking.
+type Anthropic struct{}
+
+func (Anthropic) Name() string {
+	return ProviderAnthropic
+}
+
+func (Anthropic) ChatPath(ChatCompletionRequest) string {
+	return "/messages"
+}
+
+func (Anthropic) SetAuth(h http.Header, apiKey string) {
+	if apiKey != "" {
+		h.Set("x-api-key", apiKey)
+	}
+	h.Set("anthropic-version", anthropicVersion)
+}
+
+// anthropicMessage is a message of a Messages API request.
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []map[string]any `json:"content"`
+}
+
+func (Anthropic) EncodeChat(req ChatCompletionRequest) ([]byte, error) {
+	m := map[string]any{"model": req.Model}
+
+	var system []string
+	var messages []anthropicMessage
+	for _, msg := range req.Messages {
+		if msg.Role == "system" || msg.Role == "developer" {
+			system = append(system, messageText(msg))
+			continue
+		}
+		role, blocks := anthropicBlocks(msg)
+
+		// Consecutive messages of one role, such as the results of
+		// parallel tool calls, are merged, since roles must alternate
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			messages[n-1].Content = append(messages[n-1].Content, blocks...)
+		} else {
+			messages = append(messages, anthropicMessage{Role: role, Content: blocks})
+		}
+	}
+	// Nil messages are omitted, for requests testing a missing field
+	if req.Messages != nil {
+		if messages == nil {
+			messages = []anthropicMessage{}
+		}
+		m["messages"] = messages
+	}
+	if len(system) > 0 {
+		m["system"] = strings.Join(system, "\n\n")
+	}
+
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]any, len(req.Tools))
+		for i, t := range req.Tools {
+			schema := t.Function.Parameters
+			if len(schema) == 0 {
+				schema = json.RawMessage(`{"type": "object", "properties": {}}`)
+			}
+			tools[i] = map[string]any{
+				"name":         t.Function.Name,
+				"input_schema": schema,
+			}
+			if t.Function.Description != "" {
+				tools[i]["description"] = t.Function.Description
+			}
+		}
+		m["tools"] = tools
+	}
+	if req.ToolChoice != nil {
+		m["tool_choice"] = anthropicToolChoice(req.ToolChoice)
+	}
+
+	maxTokens := anthropicMaxTokens
+	if req.MaxTokens > 0 {
+		maxTokens = req.MaxTokens
+	} else if req.MaxCompletionTokens > 0 {
+		maxTokens = req.MaxCompletionTokens
+	}
+	m["max_tokens"] = maxTokens
+	if req.Stream {
+		m["stream"] = true
+	}
+	if req.Temperature != nil {
+		m["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		m["top_p"] = *req.TopP
+	}
+	if len(req.Stop) > 0 {
+		m["stop_sequences"] = req.Stop
+	}
+
+	// Extra fields can override the translated ones, as for OpenAI
+	for k, v := range req.Extra {
+		m[k] = v
+	}
+
+	return json.Marshal(m)
+}
+
+// messageText returns the text of a message, joining its text parts if its
+// content is given as parts.
+func messageText(msg Message) string {
+	if len(msg.ContentParts) == 0 {
+		return msg.Content
+	}
+	var texts []string
+	for _, p := range msg.ContentParts {
+		if p.Type == "text" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// anthropicBlocks returns the Messages API role and content blocks of a
+// chat message.
+func anthropicBlocks(msg Message) (string, []map[string]any) {
+	switch msg.Role {
+	case "tool":
+		// Results are given as blocks rather than text, so that images
+		// returned by a tool are kept
+		return "user", []map[string]any{{
+			"type":        "tool_result",
+			"tool_use_id": msg.ToolCallID,
+			"content":     anthropicContentBlocks(msg),
+		}}
+
+	case "assistant":
+		var blocks []map[string]any
+		if msg.ReasoningContent != "" {
+			// Thinking is sent back without the signature Anthropic
+			// requires, which responses are not kept with
+			blocks = append(blocks, map[string]any{"type": "thinking", "thinking": msg.ReasoningContent})
+		}
+		if msg.Content != "" {
+			blocks = append(blocks, map[string]any{"type": "text", "text": msg.Content})
+		}
+		for _, tc := range msg.ToolCalls {
+			input := json.RawMessage(tc.Function.Arguments)
+			if !json.Valid(input) {
+				input = json.RawMessage(`{}`)
+			}
+			blocks = append(blocks, map[string]any{
+				"type":  "tool_use",
+				"id":    tc.ID,
+				"name":  tc.Function.Name,
+				"input": input,
+			})
+		}
+		if len(blocks) == 0 {
+			blocks = append(blocks, map[string]any{"type": "text", "text": ""})
+		}
+		return "assistant", blocks
+	}
+
+	return msg.Role, anthropicContentBlocks(msg)
+}
+
+// anthropicContentBlocks returns the text and image blocks of a message's
+// content.
+func anthropicContentBlocks(msg Message) []map[string]any {
+	if len(msg.ContentParts) == 0 {
+		return []map[string]any{{"type": "text", "text": msg.Content}}
+	}
+	blocks := []map[string]any{}
+	for _, p := range msg.ContentParts {
+		switch {
+		case p.Type == "text":
+			blocks = append(blocks, map[string]any{"type": "text", "text": p.Text})
+		case p.Type == "image_url" && p.ImageURL != nil:
+			blocks = append(blocks, map[string]any{"type": "image", "source": anthropicImageSource(p.ImageURL.URL)})
+		}
+	}
+	return blocks
+}
+
+// anthropicImageSource returns the source of an image block for an image
+// URL, decoding data URLs into base64 sources.
+func anthropicImageSource(url string) map[string]any {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if mediaType, data, ok := strings.Cut(rest, ";base64,"); ok {
+			return map[string]any{"type": "base64", "media_type": mediaType, "data": data}
+		}
+	}
+	return map[string]any{"type": "url", "url": url}
+}
+
+// anthropicToolChoice translates an OpenAI tool_choice. Unknown forms are
+// sent as they are.
+func anthropicToolChoice(choice any) any {
+	switch c := choice.(type) {
+	case string:
+		switch c {
+		case "auto", "none":
+			return map[string]any{"type": c}
+		case "required":
+			return map[string]any{"type": "any"}
+		}
+	case map[string]any:
+		if fn, ok := c["function"].(map[string]any); ok {
+			return map[string]any{"type": "tool", "name": fn["name"]}
+		}
+	}
+	return choice
+}
+
+// anthropicBlock is a content block of a Messages API response, or the
+// block starting a content block in a stream.
+type anthropicBlock struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text"`
+	Thinking string          `json:"thinking"`
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	Input    json.RawMessage `json:"input"`
+}
+
+// anthropicUsage is the token usage of a Messages API response.
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// promptTokens returns the input tokens, including those written to and
+// read from the prompt cache, which the API counts apart.
+func (u *anthropicUsage) promptTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// anthropicResponse is a Messages API response.
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      *anthropicUsage  `json:"usage"`
+}
+
+// anthropicFinishReason translates a stop reason to its OpenAI finish
+// reason. Unknown reasons are kept.
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence", "pause_turn":
+		return "stop"
+	case "max_tokens":
+		return "length"
+	case "tool_use":
+		return "tool_calls"
+	case "refusal":
+		return "content_filter"
+	}
+	return stopReason
+}
+
+// anthropicToolInput returns the arguments of a tool_use block's input.
+func anthropicToolInput(input json.RawMessage) string {
+	if len(input) == 0 || string(input) == "null" {
+		return "{}"
+	}
+	return string(input)
+}
+
+func (Anthropic) DecodeChat(body []byte) (*ChatCompletionResponse, error) {
+	var resp anthropicResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	msg := ResponseMessage{Role: "assistant"}
+	var content, reasoning strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+		case "tool_use":
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: ToolCallFunction{
+					Name:      block.Name,
+					Arguments: anthropicToolInput(block.Input),
+				},
+			})
+		}
+	}
+	msg.Content = content.String()
+	msg.ReasoningContent = reasoning.String()
+
+	result := &ChatCompletionResponse{
+		ID:     resp.ID,
+		Object: "chat.completion",
+		Model:  resp.Model,
+		Choices: []Choice{{
+			Message:            msg,
+			FinishReason:       anthropicFinishReason(resp.StopReason),
+			NativeFinishReason: resp.StopReason,
+		}},
+	}
+	if u := resp.Usage; u != nil {
+		result.Usage = &Usage{
+			PromptTokens:     u.promptTokens(),
+			CompletionTokens: u.OutputTokens,
+			TotalTokens:      u.promptTokens() + u.OutputTokens,
+		}
+	}
+	return result, nil
+}
+
+// anthropicEvent is an event of a Messages API stream.
+type anthropicEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		ID    string          `json:"id"`
+		Model string          `json:"model"`
+		Usage *anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Index        int            `json:"index"`
+	ContentBlock anthropicBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage"`
+}
+
+// ParseStream translates the events of a Messages API stream into chat
+// completion chunks, one per event carrying data, and accumulates them.
+// The stream is done at message_stop. Error events are recorded as
+// violations, as in OpenAI streams.
+func (Anthropic) ParseStream(r io.Reader, start time.Time) (*StreamResult, error) {
+	b := newStreamBuilder()
+	result := b.result
+
+	var id, model string
+	var usage Usage
+	// Tool call index of each tool_use content block, by block index
+	toolIndex := make(map[int]int)
+
+	err := readSSE(r, func(event string, data []byte, lineNo int) bool {
+		var ev anthropicEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationNonJSON,
+				Line:    lineNo,
+				Data:    string(data),
+				Message: "data is not a JSON event: " + err.Error(),
+			})
+			return true
+		}
+		typ := ev.Type
+		if typ == "" {
+			typ = event
+		}
+
+		var choice ChunkChoice
+		chunk := ChatCompletionChunk{Object: "chat.completion.chunk"}
+		switch typ {
+		case "message_start":
+			id, model = ev.Message.ID, ev.Message.Model
+			if u := ev.Message.Usage; u != nil {
+				usage.PromptTokens = u.promptTokens()
+				usage.CompletionTokens = u.OutputTokens
+			}
+			choice.Delta.Role = "assistant"
+
+		case "content_block_start":
+			switch block := ev.ContentBlock; block.Type {
+			case "text":
+				choice.Delta.Content = block.Text
+			case "thinking":
+				choice.Delta.ReasoningContent = block.Thinking
+			case "tool_use":
+				index := len(toolIndex)
+				toolIndex[ev.Index] = index
+				tc := ToolCallDelta{Index: index, ID: block.ID, Type: "function"}
+				tc.Function.Name = block.Name
+				// The input is usually {} here and streamed in deltas
+				if args := anthropicToolInput(block.Input); args != "{}" {
+					tc.Function.Arguments = args
+				}
+				choice.Delta.ToolCalls = []ToolCallDelta{tc}
+			default:
+				return true
+			}
+
+		case "content_block_delta":
+			switch ev.Delta.Type {
+			case "text_delta":
+				choice.Delta.Content = ev.Delta.Text
+			case "thinking_delta":
+				choice.Delta.ReasoningContent = ev.Delta.Thinking
+			case "input_json_delta":
+				index, ok := toolIndex[ev.Index]
+				if !ok {
+					return true
+				}
+				tc := ToolCallDelta{Index: index}
+				tc.Function.Arguments = ev.Delta.PartialJSON
+				choice.Delta.ToolCalls = []ToolCallDelta{tc}
+			default:
+				// Signatures and citations have no OpenAI equivalent
+				return true
+			}
+
+		case "message_delta":
+			if reason := ev.Delta.StopReason; reason != "" {
+				finish := anthropicFinishReason(reason)
+				choice.FinishReason = &finish
+				choice.NativeFinishReason = &reason
+			}
+			if u := ev.Usage; u != nil {
+				if u.promptTokens() > 0 {
+					usage.PromptTokens = u.promptTokens()
+				}
+				usage.CompletionTokens = u.OutputTokens
+			}
+			final := usage
+			final.TotalTokens = final.PromptTokens + final.CompletionTokens
+			chunk.Usage = &final
+
+		case "message_stop":
+			result.Done = true
+			return false
+
+		case "error":
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationErrorEvent,
+				Line:    lineNo,
+				Data:    string(data),
+				Message: "error event: " + streamErrorMessage(data),
+			})
+			return true
+
+		default:
+			// ping and content_block_stop carry nothing
+			return true
+		}
+
+		chunk.ID, chunk.Model = id, model
+		chunk.Choices = []ChunkChoice{choice}
+		b.add(chunk, time.Since(start))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.build(), nil
+}
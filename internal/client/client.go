@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,28 +24,170 @@ type Config struct {
 	ResponseHeaderTimeout time.Duration
 	// Extra contains additional fields to include in all request payloads.
 	Extra map[string]any
+	// RetryPolicy controls retries of transient request failures.
+	RetryPolicy RetryPolicy
+	// Embedding enables semantic similarity checks. Nil disables them.
+	Embedding *EmbeddingConfig
+	// UserAgent is sent as the User-Agent header. If empty, Go's default
+	// is sent.
+	UserAgent string
+	// Temperature, if set, is sent with every completion request that
+	// sets no temperature of its own.
+	Temperature *float64
+	// Provider translates chat requests and responses to and from the
+	// server's API. Nil uses OpenAI.
+	Provider Provider
+	// Dial opens the connections of requests, e.g. one from Dialer to
+	// connect to servers whose names DNS does not resolve. Nil uses the
+	// default dialer.
+	Dial DialFunc
+	// Headers are sent with every request to the server, after and so
+	// overriding the client's own, e.g. tenant ids or routing headers a
+	// gateway requires, or an Authorization of another scheme.
+	Headers http.Header
 }
 
-// Client is an OpenAI-compatible API client.
+// StatusError is returned when the server responds with a status other
+// than 200 OK.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// API is the error object of an OpenAI-style body, or nil if the body
+	// is not one. errors.As finds it through the StatusError.
+	API *APIError
+}
+
+// newStatusError returns the error for a response with the given status
+// and body, parsing the body's error object if it has one.
+func newStatusError(status int, body []byte) *StatusError {
+	apiErr, _ := ParseAPIError(body)
+	return &StatusError{StatusCode: status, Body: string(body), API: apiErr}
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Body)
+}
+
+// Unwrap returns the error object of the body, if it has one.
+func (e *StatusError) Unwrap() error {
+	if e.API == nil {
+		return nil
+	}
+	return e.API
+}
+
+// APIError is the error object of an OpenAI-style error body,
+// {"error": {"message": ..., "type": ..., "param": ..., "code": ...}}.
+type APIError struct {
+	Message string
+	// Type is the error's category, e.g. "invalid_request_error".
+	Type  string
+	Param string
+	// Code is the error's code, e.g. "model_not_found". Servers that send
+	// a number, such as llama.cpp's HTTP status, have it formatted as one.
+	Code string
+}
+
+func (e *APIError) Error() string {
+	if e.Type == "" {
+		return e.Message
+	}
+	return e.Type + ": " + e.Message
+}
+
+// ParseAPIError parses an OpenAI-style error body. The error describes
+// what keeps the body from being one.
+func ParseAPIError(body []byte) (*APIError, error) {
+	var resp struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, errors.New("the body is not JSON")
+	}
+	if len(resp.Error) == 0 || string(resp.Error) == "null" {
+		return nil, errors.New("the body has no error field")
+	}
+
+	var obj struct {
+		Message any             `json:"message"`
+		Type    any             `json:"type"`
+		Param   any             `json:"param"`
+		Code    json.RawMessage `json:"code"`
+	}
+	if err := json.Unmarshal(resp.Error, &obj); err != nil {
+		return nil, errors.New("the error field is not an object")
+	}
+	msg, ok := obj.Message.(string)
+	if !ok || msg == "" {
+		return nil, errors.New("the error object has no message")
+	}
+
+	apiErr := &APIError{Message: msg}
+	apiErr.Type, _ = obj.Type.(string)
+	apiErr.Param, _ = obj.Param.(string)
+	var code any
+	if json.Unmarshal(obj.Code, &code) == nil {
+		switch v := code.(type) {
+		case string:
+			apiErr.Code = v
+		case float64:
+			apiErr.Code = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return apiErr, nil
+}
+
+// Client is an OpenAI-compatible API client. Chat requests can be sent to
+// servers of other APIs through a Provider.
 type Client struct {
-	baseURL    string
-	apiKey     string
-	model      string
-	extra      map[string]any
-	httpClient *http.Client
-	logger     evallog.RequestLogger
+	baseURL     string
+	apiKey      string
+	model       string
+	extra       map[string]any
+	retry       RetryPolicy
+	embedding   *EmbeddingConfig
+	userAgent   string
+	headers     http.Header
+	temperature *float64
+	provider    Provider
+	httpClient  *http.Client
+	logger      evallog.RequestLogger
+	stats       *StatsRecorder
+	rewrite     func(*ChatCompletionRequest)
+	observer    ChatObserver
+}
+
+// ChatObserver is told of each successful chat completion as the client
+// decoded it, in the OpenAI shape whatever API the provider speaks.
+type ChatObserver interface {
+	// ObserveChat is called with a request and its blocking response.
+	ObserveChat(req ChatCompletionRequest, resp *ChatCompletionResponse)
+	// ObserveChatStream is called with a request and its accumulated
+	// stream.
+	ObserveChatStream(req ChatCompletionRequest, result *StreamResult)
 }
 
 // New creates a new Client.
 func New(cfg Config) *Client {
+	provider := cfg.Provider
+	if provider == nil {
+		provider = OpenAI{}
+	}
 	return &Client{
-		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
-		apiKey:  cfg.APIKey,
-		model:   cfg.Model,
-		extra:   cfg.Extra,
+		baseURL:     strings.TrimSuffix(cfg.BaseURL, "/"),
+		apiKey:      cfg.APIKey,
+		model:       cfg.Model,
+		extra:       cfg.Extra,
+		retry:       cfg.RetryPolicy,
+		embedding:   cfg.Embedding,
+		userAgent:   cfg.UserAgent,
+		headers:     cfg.Headers,
+		temperature: cfg.Temperature,
+		provider:    provider,
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 			Transport: &http.Transport{
+				DialContext:           cfg.Dial,
 				ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
 			},
 		},
@@ -53,30 +197,70 @@ func New(cfg Config) *Client {
 // WithLogger returns a new Client that uses the given logger.
 // This creates a shallow copy that shares the underlying http.Client.
 func (c *Client) WithLogger(logger evallog.RequestLogger) *Client {
-	return &Client{
-		baseURL:    c.baseURL,
-		apiKey:     c.apiKey,
-		model:      c.model,
-		extra:      c.extra,
-		httpClient: c.httpClient,
-		logger:     logger,
-	}
+	cp := *c
+	cp.logger = logger
+	return &cp
 }
 
-// applyExtra merges the client's extra fields into the request.
+// WithUserAgent returns a new Client that sends the given User-Agent.
+// This creates a shallow copy that shares the underlying http.Client.
+func (c *Client) WithUserAgent(userAgent string) *Client {
+	cp := *c
+	cp.userAgent = userAgent
+	return &cp
+}
+
+// WithStats returns a new Client that records request metrics into the given recorder.
+// This creates a shallow copy that shares the underlying http.Client.
+func (c *Client) WithStats(stats *StatsRecorder) *Client {
+	cp := *c
+	cp.stats = stats
+	return &cp
+}
+
+// WithRewrite returns a new Client that passes every chat completion
+// request to rewrite, complete with model and extra fields, before sending
+// it. This creates a shallow copy that shares the underlying http.Client.
+func (c *Client) WithRewrite(rewrite func(*ChatCompletionRequest)) *Client {
+	cp := *c
+	cp.rewrite = rewrite
+	return &cp
+}
+
+// WithObserver returns a new Client that tells observer of each successful
+// chat completion. This creates a shallow copy that shares the underlying
+// http.Client.
+func (c *Client) WithObserver(observer ChatObserver) *Client {
+	cp := *c
+	cp.observer = observer
+	return &cp
+}
+
+// applyExtra merges the client's extra fields into the request, and its
+// temperature if the request sets none.
 func (c *Client) applyExtra(req *ChatCompletionRequest) {
+	req.Extra = c.mergeExtra(req.Extra)
+	if req.Temperature == nil {
+		req.Temperature = c.temperature
+	}
+}
+
+// mergeExtra returns extra with the client's extra fields added. Fields
+// already present in extra take precedence.
+func (c *Client) mergeExtra(extra map[string]any) map[string]any {
 	if len(c.extra) == 0 {
-		return
+		return extra
 	}
-	if req.Extra == nil {
-		req.Extra = make(map[string]any)
+	if extra == nil {
+		extra = make(map[string]any)
 	}
 	for k, v := range c.extra {
 		// Don't override if the request already has this key
-		if _, exists := req.Extra[k]; !exists {
-			req.Extra[k] = v
+		if _, exists := extra[k]; !exists {
+			extra[k] = v
 		}
 	}
+	return extra
 }
 
 // Model returns the configured model name.
@@ -84,25 +268,51 @@ func (c *Client) Model() string {
 	return c.model
 }
 
+// UserAgent returns the User-Agent sent with requests, empty for Go's
+// default.
+func (c *Client) UserAgent() string {
+	return c.userAgent
+}
+
+// Provider returns the API the client's chat requests are sent in.
+func (c *Client) Provider() Provider {
+	return c.provider
+}
+
+// Logger returns the client's request logger, or nil.
+func (c *Client) Logger() evallog.RequestLogger {
+	return c.logger
+}
+
 // ChatCompletion performs a non-streaming chat completion.
 func (c *Client) ChatCompletion(ctx context.Context, req ChatCompletionRequest) (*ChatCompletionResponse, error) {
 	req.Model = c.model
 	req.Stream = false
 	c.applyExtra(&req)
+	if c.rewrite != nil {
+		c.rewrite(&req)
+	}
 
-	reqBody, err := json.Marshal(req)
+	reqBody, err := c.provider.EncodeChat(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+c.provider.ChatPath(req), bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	c.setHeaders(httpReq)
 
-	resp, err := c.httpClient.Do(httpReq)
+	if c.stats != nil {
+		c.stats.recordRequest()
+		start := time.Now()
+		defer func() { c.stats.recordRequestTime(time.Since(start)) }()
+	}
+
+	sent := time.Now()
+	resp, err := c.do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -113,33 +323,92 @@ func (c *Client) ChatCompletion(ctx context.Context, req ChatCompletionRequest)
 		return nil, fmt.Errorf("read response: %w", err)
 	}
 
+	headers := captureHeaders(resp.Header)
+
 	// Log request/response
 	if c.logger != nil {
-		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody)
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody, sent)
+		c.logger.LogResponseHeaders(headers)
 		c.logger.LogResponse(resp.StatusCode, respBody)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+		return nil, newStatusError(resp.StatusCode, respBody)
 	}
 
-	var result ChatCompletionResponse
-	if err := json.Unmarshal(respBody, &result); err != nil {
+	result, err := c.provider.DecodeChat(respBody)
+	if err != nil {
 		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
+	result.Headers = headers
 
-	return &result, nil
+	if c.stats != nil {
+		c.stats.recordUsage(result.Usage)
+		c.stats.recordTimings(result.Timings)
+	}
+	if c.observer != nil {
+		c.observer.ObserveChat(req, result)
+	}
+
+	return result, nil
 }
 
 // StreamResult holds the result of a streaming completion.
 type StreamResult struct {
-	// Accumulated content from all chunks
+	// Accumulated content of the first choice. With n > 1, see Choices for
+	// the others.
 	Content          string
 	ReasoningContent string
 	ToolCalls        []ToolCall
 	Usage            *Usage
+	// Timings is llama.cpp's performance report from the final chunk.
+	Timings *Timings
+	// Logprobs accumulates the token logprobs of all chunks, if requested
+	Logprobs []TokenLogprob
+	// Choices holds the accumulated result of each choice, ordered by index.
+	Choices []StreamChoice
+	// TTFT is the time from sending the request to receiving the first
+	// chunk carrying content, reasoning, or tool call data.
+	TTFT time.Duration
+	// ITL is the mean inter-token latency: the average delay between
+	// consecutive chunks carrying content, reasoning, or tool call data.
+	// Zero if fewer than two such chunks were received.
+	ITL time.Duration
 	// Raw chunks for inspection
 	Chunks []ChatCompletionChunk
+	// ChunkTimes holds the receive time of each chunk in Chunks, relative
+	// to sending the request.
+	ChunkTimes []time.Duration
+	// Headers holds selected response headers, keyed by lowercase name.
+	Headers map[string]string
+	// ContentType is the Content-Type response header.
+	ContentType string
+	// Raw holds the response body bytes as received, up to and including
+	// the [DONE] terminator and whatever arrived with it. It is also what
+	// is logged of the stream.
+	Raw []byte
+	// Violations lists the lines that were skipped since they were not
+	// chunks, such as error events and non-JSON data.
+	Violations []StreamViolation
+	// Done is true if the stream ended with data: [DONE], with
+	// message_stop from an Anthropic server, with a line with done set
+	// from an Ollama server, or after a finish reason from a Gemini
+	// server.
+	Done bool
+	// Trailer holds the HTTP trailer of the response. It is only read if
+	// the stream ended without [DONE], when the body is read to its end.
+	Trailer http.Header
+}
+
+// StreamChoice holds the accumulated deltas of one choice in a stream.
+type StreamChoice struct {
+	Index            int
+	Content          string
+	ReasoningContent string
+	ToolCalls        []ToolCall
+	Logprobs         []TokenLogprob
+	// FinishReason is empty if no chunk carried one for this choice.
+	FinishReason string
 }
 
 // ChatCompletionStream performs a streaming chat completion.
@@ -150,28 +419,42 @@ func (c *Client) ChatCompletionStream(ctx context.Context, req ChatCompletionReq
 		req.StreamOptions = &StreamOptions{IncludeUsage: true}
 	}
 	c.applyExtra(&req)
+	if c.rewrite != nil {
+		c.rewrite(&req)
+	}
 
-	reqBody, err := json.Marshal(req)
+	reqBody, err := c.provider.EncodeChat(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+c.provider.ChatPath(req), bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	c.setHeaders(httpReq)
 
-	resp, err := c.httpClient.Do(httpReq)
+	if c.stats != nil {
+		c.stats.recordRequest()
+	}
+
+	start := time.Now()
+	if c.stats != nil {
+		defer func() { c.stats.recordRequestTime(time.Since(start)) }()
+	}
+	resp, err := c.do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	headers := captureHeaders(resp.Header)
+
 	// Log request
 	if c.logger != nil {
-		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody)
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody, start)
+		c.logger.LogResponseHeaders(headers)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -179,17 +462,37 @@ func (c *Client) ChatCompletionStream(ctx context.Context, req ChatCompletionReq
 		if c.logger != nil {
 			c.logger.LogResponse(resp.StatusCode, body)
 		}
-		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		return nil, newStatusError(resp.StatusCode, body)
 	}
 
-	result, rawChunks, err := parseSSEStream(resp.Body)
+	var raw bytes.Buffer
+	result, err := c.provider.ParseStream(io.TeeReader(resp.Body, &raw), start)
 	if err != nil {
 		return nil, err
 	}
+	result.Headers = headers
+	result.ContentType = resp.Header.Get("Content-Type")
+	result.Raw = raw.Bytes()
+	if !result.Done {
+		result.Trailer = resp.Trailer
+	}
+
+	if c.stats != nil {
+		c.stats.recordUsage(result.Usage)
+		c.stats.recordTimings(result.Timings)
+		c.stats.recordViolations(result.Violations)
+		if result.TTFT > 0 {
+			c.stats.recordTTFT(result.TTFT)
+		}
+		if result.ITL > 0 {
+			c.stats.recordITL(result.ITL)
+		}
+	}
 
 	// Log streamed response
 	if c.logger != nil {
-		c.logger.LogStreamResponse(resp.StatusCode, rawChunks)
+		c.logger.LogStreamResponse(resp.StatusCode, result.Raw)
+		c.logger.LogStreamTiming(result.TTFT, result.ITL, len(result.Chunks))
 
 		// Write JSONL for replay
 		if len(result.Chunks) > 0 {
@@ -202,31 +505,52 @@ func (c *Client) ChatCompletionStream(ctx context.Context, req ChatCompletionReq
 			c.logger.LogStreamChunks(jsonlBuf.Bytes())
 		}
 	}
+	if c.observer != nil {
+		c.observer.ObserveChatStream(req, result)
+	}
 
 	return result, nil
 }
 
 func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.provider.SetAuth(req.Header, c.apiKey)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
+	setCustomHeaders(req.Header, c.headers)
 }
 
 // ApplyTemplate calls the /apply-template endpoint to render messages into a prompt.
 // This is specific to llama.cpp servers.
 // Note: This endpoint is at the root, not under /v1.
 func (c *Client) ApplyTemplate(ctx context.Context, messages []Message) (string, error) {
-	reqData := ApplyTemplateRequest{
+	reqBody, err := json.Marshal(ApplyTemplateRequest{
 		Model:    c.model,
 		Messages: messages,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
 	}
+	return c.applyTemplate(ctx, reqBody)
+}
 
-	reqBody, err := json.Marshal(reqData)
+// ApplyTemplateRaw renders messages given as a JSON array, for messages
+// that Message cannot express, such as one with an empty
+// reasoning_content.
+func (c *Client) ApplyTemplateRaw(ctx context.Context, messages json.RawMessage) (string, error) {
+	reqBody, err := json.Marshal(struct {
+		Model    string          `json:"model"`
+		Messages json.RawMessage `json:"messages"`
+	}{c.model, messages})
 	if err != nil {
 		return "", fmt.Errorf("marshal request: %w", err)
 	}
+	return c.applyTemplate(ctx, reqBody)
+}
 
+// applyTemplate sends an /apply-template request and returns the prompt.
+func (c *Client) applyTemplate(ctx context.Context, reqBody []byte) (string, error) {
 	// Strip /v1 suffix if present - apply-template is at the root
 	baseURL := strings.TrimSuffix(c.baseURL, "/v1")
 
@@ -237,7 +561,14 @@ func (c *Client) ApplyTemplate(ctx context.Context, messages []Message) (string,
 
 	c.setHeaders(httpReq)
 
-	resp, err := c.httpClient.Do(httpReq)
+	if c.stats != nil {
+		c.stats.recordRequest()
+		start := time.Now()
+		defer func() { c.stats.recordTemplateTime(time.Since(start)) }()
+	}
+
+	sent := time.Now()
+	resp, err := c.do(httpReq)
 	if err != nil {
 		return "", fmt.Errorf("do request: %w", err)
 	}
@@ -250,12 +581,12 @@ func (c *Client) ApplyTemplate(ctx context.Context, messages []Message) (string,
 
 	// Log request/response
 	if c.logger != nil {
-		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody)
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody, sent)
 		c.logger.LogResponse(resp.StatusCode, respBody)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(respBody))
+		return "", newStatusError(resp.StatusCode, respBody)
 	}
 
 	var result ApplyTemplateResponse
@@ -265,3 +596,58 @@ func (c *Client) ApplyTemplate(ctx context.Context, messages []Message) (string,
 
 	return result.Prompt, nil
 }
+
+// Tokenize calls the /tokenize endpoint to convert text into token ids,
+// without special tokens. This is specific to llama.cpp servers.
+// Note: This endpoint is at the root, not under /v1.
+func (c *Client) Tokenize(ctx context.Context, content string) ([]int, error) {
+	reqBody, err := json.Marshal(TokenizeRequest{Content: content})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	// Strip /v1 suffix if present - tokenize is at the root
+	baseURL := strings.TrimSuffix(c.baseURL, "/v1")
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/tokenize", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	if c.stats != nil {
+		c.stats.recordRequest()
+		start := time.Now()
+		defer func() { c.stats.recordRequestTime(time.Since(start)) }()
+	}
+
+	sent := time.Now()
+	resp, err := c.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	// Log request/response
+	if c.logger != nil {
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody, sent)
+		c.logger.LogResponse(resp.StatusCode, respBody)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp.StatusCode, respBody)
+	}
+
+	var result TokenizeResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return result.Tokens, nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// streamLogger records the logged stream body.
+type streamLogger struct {
+	raw []byte
+}
+
+func (l *streamLogger) LogRequest(method, url string, body []byte, sent time.Time) {}
+func (l *streamLogger) LogResponseHeaders(headers map[string]string)               {}
+func (l *streamLogger) LogResponse(status int, body []byte)                        {}
+func (l *streamLogger) LogStreamResponse(status int, rawChunks []byte)             { l.raw = rawChunks }
+func (l *streamLogger) LogStreamTiming(ttft, itl time.Duration, chunks int)        {}
+func (l *streamLogger) LogStreamChunks(jsonl []byte)                               {}
+
+func TestChatCompletionStreamLogsRaw(t *testing.T) {
+	// CRLF line endings are kept as received, for the wire format checks
+	body := bytes.ReplaceAll(contentStream(3), []byte("\n"), []byte("\r\n"))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	logger := &streamLogger{}
+	c := New(Config{BaseURL: srv.URL, Model: "m", Provider: OpenAI{}}).WithLogger(logger)
+	result, err := c.ChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream: %v", err)
+	}
+	if !bytes.Equal(result.Raw, body) {
+		t.Errorf("Raw = %q, want the body as sent", result.Raw)
+	}
+	if !bytes.Equal(logger.raw, result.Raw) {
+		t.Errorf("logged stream = %q, want Raw", logger.raw)
+	}
+	if want := "token 0 token 1 token 2 "; result.Content != want {
+		t.Errorf("content = %q, want %q", result.Content, want)
+	}
+}
@@ -0,0 +1,326 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CompletionRequest represents a request to the legacy text completions
+// endpoint.
+type CompletionRequest struct {
+	Model         string         `json:"model"`
+	Prompt        string         `json:"prompt"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Echo          bool           `json:"echo,omitempty"`
+	// Logprobs is the number of most likely alternatives to return per
+	// token. Nil omits logprobs; 0 returns only the sampled tokens.
+	Logprobs    *int     `json:"logprobs,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+
+	// Extra contains additional fields to include in the request JSON.
+	// These are flattened into the root of the request object.
+	Extra map[string]any `json:"-"`
+}
+
+// MarshalJSON implements custom JSON marshaling to flatten Extra fields.
+func (r CompletionRequest) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any)
+
+	m["model"] = r.Model
+	m["prompt"] = r.Prompt
+
+	if r.Stream {
+		m["stream"] = r.Stream
+	}
+	if r.StreamOptions != nil {
+		m["stream_options"] = r.StreamOptions
+	}
+	if r.MaxTokens > 0 {
+		m["max_tokens"] = r.MaxTokens
+	}
+	if r.Echo {
+		m["echo"] = r.Echo
+	}
+	if r.Logprobs != nil {
+		m["logprobs"] = *r.Logprobs
+	}
+	if len(r.Stop) > 0 {
+		m["stop"] = r.Stop
+	}
+	if r.Temperature != nil {
+		m["temperature"] = *r.Temperature
+	}
+
+	// Merge extra fields (they can override standard fields if needed)
+	for k, v := range r.Extra {
+		m[k] = v
+	}
+
+	return json.Marshal(m)
+}
+
+// CompletionResponse represents a text completion response. Streamed chunks
+// share the same shape.
+type CompletionResponse struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   *Usage             `json:"usage,omitempty"`
+
+	// Headers holds selected response headers, keyed by lowercase name.
+	Headers map[string]string `json:"-"`
+}
+
+// CompletionChoice represents a text completion choice.
+type CompletionChoice struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+	// FinishReason is empty in streamed chunks before the last.
+	FinishReason string              `json:"finish_reason"`
+	Logprobs     *CompletionLogprobs `json:"logprobs,omitempty"`
+}
+
+// CompletionLogprobs holds the log probabilities of a text completion in the
+// legacy column-oriented format: one entry per token in each slice.
+type CompletionLogprobs struct {
+	Tokens []string `json:"tokens"`
+	// TokenLogprobs is nil for a token without a logprob, such as the first
+	// echoed prompt token.
+	TokenLogprobs []*float64           `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+	TextOffset    []int                `json:"text_offset"`
+}
+
+// CompletionStreamResult holds the result of a streaming text completion.
+type CompletionStreamResult struct {
+	// Accumulated text of the first choice
+	Text string
+	// FinishReason is empty if no chunk carried one.
+	FinishReason string
+	// Logprobs accumulates the logprobs of all chunks, if requested
+	Logprobs *CompletionLogprobs
+	Usage    *Usage
+	// TTFT is the time from sending the request to receiving the first
+	// chunk carrying text.
+	TTFT time.Duration
+	// Raw chunks for inspection
+	Chunks []CompletionResponse
+	// Headers holds selected response headers, keyed by lowercase name.
+	Headers map[string]string
+}
+
+// Completion performs a non-streaming text completion.
+func (c *Client) Completion(ctx context.Context, req CompletionRequest) (*CompletionResponse, error) {
+	req.Model = c.model
+	req.Stream = false
+	req.Extra = c.mergeExtra(req.Extra)
+	if req.Temperature == nil {
+		req.Temperature = c.temperature
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/completions", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	if c.stats != nil {
+		c.stats.recordRequest()
+		start := time.Now()
+		defer func() { c.stats.recordRequestTime(time.Since(start)) }()
+	}
+
+	sent := time.Now()
+	resp, err := c.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	headers := captureHeaders(resp.Header)
+
+	// Log request/response
+	if c.logger != nil {
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody, sent)
+		c.logger.LogResponseHeaders(headers)
+		c.logger.LogResponse(resp.StatusCode, respBody)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp.StatusCode, respBody)
+	}
+
+	var result CompletionResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	result.Headers = headers
+
+	if c.stats != nil {
+		c.stats.recordUsage(result.Usage)
+	}
+
+	return &result, nil
+}
+
+// CompletionStream performs a streaming text completion.
+func (c *Client) CompletionStream(ctx context.Context, req CompletionRequest) (*CompletionStreamResult, error) {
+	req.Model = c.model
+	req.Stream = true
+	if req.StreamOptions == nil {
+		req.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	req.Extra = c.mergeExtra(req.Extra)
+	if req.Temperature == nil {
+		req.Temperature = c.temperature
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/completions", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	if c.stats != nil {
+		c.stats.recordRequest()
+	}
+
+	start := time.Now()
+	if c.stats != nil {
+		defer func() { c.stats.recordRequestTime(time.Since(start)) }()
+	}
+	resp, err := c.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	headers := captureHeaders(resp.Header)
+
+	// Log request
+	if c.logger != nil {
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody, start)
+		c.logger.LogResponseHeaders(headers)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if c.logger != nil {
+			c.logger.LogResponse(resp.StatusCode, body)
+		}
+		return nil, newStatusError(resp.StatusCode, body)
+	}
+
+	result, rawChunks, err := parseCompletionStream(resp.Body, start)
+	if c.logger != nil {
+		c.logger.LogStreamResponse(resp.StatusCode, rawChunks)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Headers = headers
+
+	if c.stats != nil {
+		c.stats.recordUsage(result.Usage)
+		if result.TTFT > 0 {
+			c.stats.recordTTFT(result.TTFT)
+		}
+	}
+
+	return result, nil
+}
+
+// parseCompletionStream parses a text completion SSE stream and accumulates
+// the first choice. Returns the accumulated result and raw chunk data for
+// logging.
+func parseCompletionStream(r io.Reader, start time.Time) (*CompletionStreamResult, []byte, error) {
+	result := &CompletionStreamResult{}
+
+	var text strings.Builder
+	var rawChunks bytes.Buffer
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxSSELine)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		rawChunks.Write(line)
+		rawChunks.WriteByte('\n')
+
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			continue
+		}
+		data = bytes.TrimPrefix(data, []byte(" "))
+		if string(data) == "[DONE]" {
+			break
+		}
+
+		var chunk CompletionResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return nil, rawChunks.Bytes(), fmt.Errorf("unmarshal chunk: %w", err)
+		}
+		result.Chunks = append(result.Chunks, chunk)
+
+		if chunk.Usage != nil {
+			result.Usage = chunk.Usage
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			if choice.Text != "" && result.TTFT == 0 {
+				result.TTFT = time.Since(start)
+			}
+			text.WriteString(choice.Text)
+			if choice.FinishReason != "" {
+				result.FinishReason = choice.FinishReason
+			}
+			if lp := choice.Logprobs; lp != nil {
+				if result.Logprobs == nil {
+					result.Logprobs = &CompletionLogprobs{}
+				}
+				result.Logprobs.Tokens = append(result.Logprobs.Tokens, lp.Tokens...)
+				result.Logprobs.TokenLogprobs = append(result.Logprobs.TokenLogprobs, lp.TokenLogprobs...)
+				result.Logprobs.TopLogprobs = append(result.Logprobs.TopLogprobs, lp.TopLogprobs...)
+				result.Logprobs.TextOffset = append(result.Logprobs.TextOffset, lp.TextOffset...)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, rawChunks.Bytes(), fmt.Errorf("scan stream: %w", err)
+	}
+
+	result.Text = text.String()
+	return result, rawChunks.Bytes(), nil
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EmbeddingConfig configures the endpoint used to embed text for semantic
+// similarity checks. An empty BaseURL or Model falls back to the client's
+// own, so a zero EmbeddingConfig embeds with the server under test. APIKey
+// is only used with a separate BaseURL.
+type EmbeddingConfig struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+}
+
+// EmbeddingRequest represents an embeddings request.
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse represents an embeddings response.
+type EmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// CanEmbed reports whether an embedding endpoint is configured.
+func (c *Client) CanEmbed() bool {
+	return c.embedding != nil
+}
+
+// Embeddings embeds each input and returns the vectors in input order.
+// Embedding requests are assertion overhead, so they are logged but not
+// counted in request stats.
+func (c *Client) Embeddings(ctx context.Context, input []string) ([][]float64, error) {
+	if c.embedding == nil {
+		return nil, fmt.Errorf("no embedding endpoint configured")
+	}
+
+	baseURL, apiKey, model := c.baseURL, c.apiKey, c.model
+	if c.embedding.BaseURL != "" {
+		// Don't send the server's API key to a separate endpoint
+		baseURL = strings.TrimSuffix(c.embedding.BaseURL, "/")
+		apiKey = c.embedding.APIKey
+	}
+	if c.embedding.Model != "" {
+		model = c.embedding.Model
+	}
+
+	reqBody, err := json.Marshal(EmbeddingRequest{Model: model, Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/embeddings", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.embedding.BaseURL == "" {
+		setCustomHeaders(httpReq.Header, c.headers)
+	}
+
+	sent := time.Now()
+	resp, err := c.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	// Log request/response
+	if c.logger != nil {
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), reqBody, sent)
+		c.logger.LogResponse(resp.StatusCode, respBody)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp.StatusCode, respBody)
+	}
+
+	var result EmbeddingResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	vectors := make([][]float64, len(input))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(input) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no embedding returned for input %d", i)
+		}
+	}
+
+	return vectors, nil
+}
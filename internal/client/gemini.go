@@ -0,0 +1,490 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Gemini is Google's generateContent API, as served by Gemini-compatible
+// gateways under a base URL such as
+// https://generativelanguage.googleapis.com/v1beta. The model is named in
+// the path, and streams use streamGenerateContent with alt=sse. System
+// messages become the system instruction, tool calls and results become
+// functionCall and functionResponse parts, and structured output becomes
+// a response JSON schema. Reasoning is not sent back, since the API keeps
+// thoughts only as opaque signatures. Fields the API has no equivalent
+// for, such as logprobs and logit_bias, are dropped; extra fields are sent
+// as they are, except that a generationConfig extra is merged into the
+// translated one, e.g. to enable thinkingConfig.
+type Gemini struct{}
+
+func (Gemini) Name() string {
+	return ProviderGemini
+}
+
+func (Gemini) ChatPath(req ChatCompletionRequest) string {
+	// Models may be named with their resource prefix, as in
+	// tunedModels/..., or without, as in gemini-2.5-flash
+	model := req.Model
+	if !strings.Contains(model, "/") {
+		model = "models/" + model
+	}
+	if req.Stream {
+		return "/" + model + ":streamGenerateContent?alt=sse"
+	}
+	return "/" + model + ":generateContent"
+}
+
+func (Gemini) SetAuth(h http.Header, apiKey string) {
+	if apiKey != "" {
+		h.Set("x-goog-api-key", apiKey)
+	}
+}
+
+// geminiContent is a content, one turn of a conversation, of a
+// generateContent request.
+type geminiContent struct {
+	Role  string           `json:"role"`
+	Parts []map[string]any `json:"parts"`
+}
+
+func (Gemini) EncodeChat(req ChatCompletionRequest) ([]byte, error) {
+	m := map[string]any{}
+
+	// Function responses must name their function, which tool messages
+	// only give by the id of the call
+	callNames := make(map[string]string)
+	for _, msg := range req.Messages {
+		for _, tc := range msg.ToolCalls {
+			callNames[tc.ID] = tc.Function.Name
+		}
+	}
+
+	var system []string
+	var contents []geminiContent
+	for _, msg := range req.Messages {
+		if msg.Role == "system" || msg.Role == "developer" {
+			system = append(system, messageText(msg))
+			continue
+		}
+		role, parts := geminiParts(msg, callNames)
+
+		// Consecutive contents of one role, such as the results of
+		// parallel tool calls, are merged, since roles must alternate
+		if n := len(contents); n > 0 && contents[n-1].Role == role {
+			contents[n-1].Parts = append(contents[n-1].Parts, parts...)
+		} else {
+			contents = append(contents, geminiContent{Role: role, Parts: parts})
+		}
+	}
+	// Nil messages are omitted, for requests testing a missing field
+	if req.Messages != nil {
+		if contents == nil {
+			contents = []geminiContent{}
+		}
+		m["contents"] = contents
+	}
+	if len(system) > 0 {
+		m["systemInstruction"] = map[string]any{
+			"parts": []map[string]any{{"text": strings.Join(system, "\n\n")}},
+		}
+	}
+
+	if len(req.Tools) > 0 {
+		decls := make([]map[string]any, len(req.Tools))
+		for i, t := range req.Tools {
+			decls[i] = map[string]any{"name": t.Function.Name}
+			if t.Function.Description != "" {
+				decls[i]["description"] = t.Function.Description
+			}
+			if len(t.Function.Parameters) > 0 {
+				decls[i]["parametersJsonSchema"] = t.Function.Parameters
+			}
+		}
+		m["tools"] = []map[string]any{{"functionDeclarations": decls}}
+	}
+	if req.ToolChoice != nil {
+		m["toolConfig"] = map[string]any{"functionCallingConfig": geminiToolChoice(req.ToolChoice)}
+	}
+
+	config := map[string]any{}
+	if req.MaxTokens > 0 {
+		config["maxOutputTokens"] = req.MaxTokens
+	} else if req.MaxCompletionTokens > 0 {
+		config["maxOutputTokens"] = req.MaxCompletionTokens
+	}
+	if req.Temperature != nil {
+		config["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		config["topP"] = *req.TopP
+	}
+	if req.PresencePenalty != nil {
+		config["presencePenalty"] = *req.PresencePenalty
+	}
+	if req.FrequencyPenalty != nil {
+		config["frequencyPenalty"] = *req.FrequencyPenalty
+	}
+	if req.Seed != nil {
+		config["seed"] = *req.Seed
+	}
+	if len(req.Stop) > 0 {
+		config["stopSequences"] = req.Stop
+	}
+	if req.N > 1 {
+		config["candidateCount"] = req.N
+	}
+	if rf := req.ResponseFormat; rf != nil {
+		switch rf.Type {
+		case "json_object":
+			config["responseMimeType"] = "application/json"
+		case "json_schema":
+			config["responseMimeType"] = "application/json"
+			if rf.JSONSchema != nil && len(rf.JSONSchema.Schema) > 0 {
+				config["responseJsonSchema"] = rf.JSONSchema.Schema
+			}
+		}
+	}
+
+	// Extra fields can override the translated ones, as for OpenAI, but a
+	// generationConfig is merged, so that it does not drop the sampling
+	// parameters
+	for k, v := range req.Extra {
+		if extra, ok := v.(map[string]any); ok && k == "generationConfig" {
+			for ck, cv := range extra {
+				config[ck] = cv
+			}
+			continue
+		}
+		m[k] = v
+	}
+	if _, ok := m["generationConfig"]; !ok && len(config) > 0 {
+		m["generationConfig"] = config
+	}
+
+	return json.Marshal(m)
+}
+
+// geminiParts returns the generateContent role and parts of a chat
+// message. callNames maps tool call ids to function names.
+func geminiParts(msg Message, callNames map[string]string) (string, []map[string]any) {
+	switch msg.Role {
+	case "tool":
+		// The response must be an object, so other results are wrapped
+		var response any = map[string]any{"result": messageText(msg)}
+		var obj map[string]any
+		if json.Unmarshal([]byte(messageText(msg)), &obj) == nil && obj != nil {
+			response = obj
+		}
+		fr := map[string]any{"name": callNames[msg.ToolCallID], "response": response}
+		if msg.ToolCallID != "" {
+			fr["id"] = msg.ToolCallID
+		}
+		return "user", []map[string]any{{"functionResponse": fr}}
+
+	case "assistant":
+		var parts []map[string]any
+		if msg.Content != "" {
+			parts = append(parts, map[string]any{"text": msg.Content})
+		}
+		for _, tc := range msg.ToolCalls {
+			args := json.RawMessage(tc.Function.Arguments)
+			if !json.Valid(args) {
+				args = json.RawMessage(`{}`)
+			}
+			fc := map[string]any{"name": tc.Function.Name, "args": args}
+			if tc.ID != "" {
+				fc["id"] = tc.ID
+			}
+			parts = append(parts, map[string]any{"functionCall": fc})
+		}
+		if len(parts) == 0 {
+			parts = append(parts, map[string]any{"text": ""})
+		}
+		return "model", parts
+	}
+
+	if len(msg.ContentParts) == 0 {
+		return "user", []map[string]any{{"text": msg.Content}}
+	}
+	var parts []map[string]any
+	for _, p := range msg.ContentParts {
+		switch {
+		case p.Type == "text":
+			parts = append(parts, map[string]any{"text": p.Text})
+		case p.Type == "image_url" && p.ImageURL != nil:
+			parts = append(parts, geminiImagePart(p.ImageURL.URL))
+		}
+	}
+	return "user", parts
+}
+
+// geminiImagePart returns the part of an image URL, decoding data URLs
+// into inline data.
+func geminiImagePart(url string) map[string]any {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if mediaType, data, ok := strings.Cut(rest, ";base64,"); ok {
+			return map[string]any{"inlineData": map[string]any{"mimeType": mediaType, "data": data}}
+		}
+	}
+	return map[string]any{"fileData": map[string]any{"fileUri": url}}
+}
+
+// geminiToolChoice translates an OpenAI tool_choice into a function
+// calling config. Unknown forms are sent as they are.
+func geminiToolChoice(choice any) any {
+	switch c := choice.(type) {
+	case string:
+		switch c {
+		case "auto":
+			return map[string]any{"mode": "AUTO"}
+		case "none":
+			return map[string]any{"mode": "NONE"}
+		case "required":
+			return map[string]any{"mode": "ANY"}
+		}
+	case map[string]any:
+		if fn, ok := c["function"].(map[string]any); ok {
+			return map[string]any{"mode": "ANY", "allowedFunctionNames": []any{fn["name"]}}
+		}
+	}
+	return choice
+}
+
+// geminiPart is a part of a generateContent response.
+type geminiPart struct {
+	Text         string `json:"text"`
+	Thought      bool   `json:"thought"`
+	FunctionCall *struct {
+		ID   string          `json:"id"`
+		Name string          `json:"name"`
+		Args json.RawMessage `json:"args"`
+	} `json:"functionCall"`
+}
+
+// geminiCandidate is a candidate of a generateContent response, the
+// equivalent of a choice.
+type geminiCandidate struct {
+	Content struct {
+		Parts []geminiPart `json:"parts"`
+	} `json:"content"`
+	FinishReason string `json:"finishReason"`
+	Index        int    `json:"index"`
+}
+
+// geminiUsage is the token usage of a generateContent response.
+type geminiUsage struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// usage returns the OpenAI usage, counting thoughts as completion tokens,
+// as OpenAI counts reasoning tokens.
+func (u *geminiUsage) usage() *Usage {
+	completion := u.CandidatesTokenCount + u.ThoughtsTokenCount
+	total := u.TotalTokenCount
+	if total == 0 {
+		total = u.PromptTokenCount + completion
+	}
+	return &Usage{PromptTokens: u.PromptTokenCount, CompletionTokens: completion, TotalTokens: total}
+}
+
+// geminiResponse is a generateContent response, or one chunk of a stream.
+type geminiResponse struct {
+	ResponseID     string            `json:"responseId"`
+	ModelVersion   string            `json:"modelVersion"`
+	Candidates     []geminiCandidate `json:"candidates"`
+	UsageMetadata  *geminiUsage      `json:"usageMetadata"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+// blocked returns the reason the prompt was blocked, if no candidate was
+// generated since it was.
+func (r *geminiResponse) blocked() string {
+	if len(r.Candidates) > 0 || r.PromptFeedback == nil {
+		return ""
+	}
+	return r.PromptFeedback.BlockReason
+}
+
+// geminiFinishReason translates a finish reason to its OpenAI finish
+// reason. A candidate that stopped after calling functions finished with
+// tool_calls. Unknown reasons, such as MALFORMED_FUNCTION_CALL, are kept.
+func geminiFinishReason(reason string, calledFunctions bool) string {
+	switch reason {
+	case "STOP":
+		if calledFunctions {
+			return "tool_calls"
+		}
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "content_filter"
+	}
+	return reason
+}
+
+// geminiArgs returns the arguments of a function call.
+func geminiArgs(args json.RawMessage) string {
+	if len(args) == 0 || string(args) == "null" {
+		return "{}"
+	}
+	return string(args)
+}
+
+func (Gemini) DecodeChat(body []byte) (*ChatCompletionResponse, error) {
+	var resp geminiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &ChatCompletionResponse{
+		ID:     resp.ResponseID,
+		Object: "chat.completion",
+		Model:  resp.ModelVersion,
+	}
+	for _, cand := range resp.Candidates {
+		msg := ResponseMessage{Role: "assistant"}
+		var content, reasoning strings.Builder
+		for _, part := range cand.Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+					ID:   callID(part.FunctionCall.ID),
+					Type: "function",
+					Function: ToolCallFunction{
+						Name:      part.FunctionCall.Name,
+						Arguments: geminiArgs(part.FunctionCall.Args),
+					},
+				})
+			case part.Thought:
+				reasoning.WriteString(part.Text)
+			default:
+				content.WriteString(part.Text)
+			}
+		}
+		msg.Content = content.String()
+		msg.ReasoningContent = reasoning.String()
+		result.Choices = append(result.Choices, Choice{
+			Index:              cand.Index,
+			Message:            msg,
+			FinishReason:       geminiFinishReason(cand.FinishReason, len(msg.ToolCalls) > 0),
+			NativeFinishReason: cand.FinishReason,
+		})
+	}
+	if reason := resp.blocked(); reason != "" {
+		result.Choices = []Choice{{
+			Message:            ResponseMessage{Role: "assistant"},
+			FinishReason:       "content_filter",
+			NativeFinishReason: reason,
+		}}
+	}
+	if u := resp.UsageMetadata; u != nil {
+		result.Usage = u.usage()
+	}
+	return result, nil
+}
+
+// ParseStream translates the chunks of a streamGenerateContent stream
+// into chat completion chunks and accumulates them. The API sends no
+// terminator, so the stream is done if it ends after a finish reason.
+// Error objects are recorded as violations, as in OpenAI streams.
+func (Gemini) ParseStream(r io.Reader, start time.Time) (*StreamResult, error) {
+	b := newStreamBuilder()
+	result := b.result
+
+	// Tool calls so far and whether the role was sent, by candidate index
+	toolCalls := make(map[int]int)
+	started := make(map[int]bool)
+	finished := false
+	var usage *Usage
+
+	err := readSSE(r, func(event string, data []byte, lineNo int) bool {
+		if msg, ok := streamErrorPayload(data); ok {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationErrorPayload,
+				Line:    lineNo,
+				Data:    string(data),
+				Message: "error in stream: " + msg,
+			})
+			return true
+		}
+		var resp geminiResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationNonJSON,
+				Line:    lineNo,
+				Data:    string(data),
+				Message: "data is not a JSON response: " + err.Error(),
+			})
+			return true
+		}
+
+		chunk := ChatCompletionChunk{
+			ID:     resp.ResponseID,
+			Object: "chat.completion.chunk",
+			Model:  resp.ModelVersion,
+		}
+		for _, cand := range resp.Candidates {
+			choice := ChunkChoice{Index: cand.Index}
+			if !started[cand.Index] {
+				started[cand.Index] = true
+				choice.Delta.Role = "assistant"
+			}
+			for _, part := range cand.Content.Parts {
+				switch {
+				case part.FunctionCall != nil:
+					// Function calls arrive whole rather than in deltas
+					tc := ToolCallDelta{Index: toolCalls[cand.Index], ID: callID(part.FunctionCall.ID), Type: "function"}
+					tc.Function.Name = part.FunctionCall.Name
+					tc.Function.Arguments = geminiArgs(part.FunctionCall.Args)
+					choice.Delta.ToolCalls = append(choice.Delta.ToolCalls, tc)
+					toolCalls[cand.Index]++
+				case part.Thought:
+					choice.Delta.ReasoningContent += part.Text
+				default:
+					choice.Delta.Content += part.Text
+				}
+			}
+			if reason := cand.FinishReason; reason != "" {
+				finish := geminiFinishReason(reason, toolCalls[cand.Index] > 0)
+				choice.FinishReason = &finish
+				choice.NativeFinishReason = &reason
+				finished = true
+			}
+			chunk.Choices = append(chunk.Choices, choice)
+		}
+		if reason := resp.blocked(); reason != "" {
+			finish := "content_filter"
+			chunk.Choices = []ChunkChoice{{FinishReason: &finish, NativeFinishReason: &reason}}
+			finished = true
+		}
+		// Usage is sent with every chunk, counting the tokens so far; as
+		// in OpenAI streams, only the final chunks report it
+		if u := resp.UsageMetadata; u != nil {
+			usage = u.usage()
+			if finished {
+				chunk.Usage = usage
+			}
+		}
+
+		b.add(chunk, time.Since(start))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Done = finished
+	if result.Usage == nil {
+		result.Usage = usage
+	}
+	return b.build(), nil
+}
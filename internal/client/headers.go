@@ -0,0 +1,100 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// capturedHeaders are the response headers exposed on results, for
+// correlating requests with server logs and for evals to assert on.
+var capturedHeaders = []string{
+	"x-request-id",
+	"request-id",
+	"server-timing",
+	"openai-processing-ms",
+	"retry-after",
+	// Cache status, as reported by HTTP caches and LLM gateways
+	"age",
+	"x-cache",
+	"x-cache-status",
+	"cf-cache-status",
+	"x-litellm-cache-hit",
+	"x-portkey-cache-status",
+}
+
+// capturedHeaderPrefixes are header name prefixes captured in full, such as
+// the rate limit family.
+var capturedHeaderPrefixes = []string{
+	"x-ratelimit-",
+	"ratelimit",
+}
+
+// captureHeaders returns the captured headers present in h, keyed by
+// lowercase name. Repeated headers are joined with ", ". Returns nil if none
+// are present.
+func captureHeaders(h http.Header) map[string]string {
+	var captured map[string]string
+	for name, values := range h {
+		lower := strings.ToLower(name)
+		if !isCapturedHeader(lower) {
+			continue
+		}
+		if captured == nil {
+			captured = make(map[string]string)
+		}
+		captured[lower] = strings.Join(values, ", ")
+	}
+	return captured
+}
+
+func isCapturedHeader(name string) bool {
+	for _, h := range capturedHeaders {
+		if name == h {
+			return true
+		}
+	}
+	for _, p := range capturedHeaderPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestID returns the server's request id from captured headers, or "".
+func RequestID(headers map[string]string) string {
+	if id := headers["x-request-id"]; id != "" {
+		return id
+	}
+	return headers["request-id"]
+}
+
+// ParseHeader parses a request header in curl's "Name: value" form. The
+// value may be empty.
+func ParseHeader(s string) (name, value string, err error) {
+	name, value, ok := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("%q is not Name: value", s)
+	}
+	if strings.ContainsAny(name, " \t\r\n") {
+		return "", "", fmt.Errorf("%q: invalid header name %q", s, name)
+	}
+	value = strings.TrimSpace(value)
+	if strings.ContainsAny(value, "\r\n") {
+		return "", "", fmt.Errorf("%q: header value contains a line break", s)
+	}
+	return name, value, nil
+}
+
+// setCustomHeaders sets custom headers on a request, replacing the values
+// of headers already set.
+func setCustomHeaders(h, custom http.Header) {
+	for name, values := range custom {
+		h.Del(name)
+		for _, v := range values {
+			h.Add(name, v)
+		}
+	}
+}
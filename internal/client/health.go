@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Health requests the server's /health endpoint and returns its status
+// code. llama.cpp, vLLM, and TGI serve it at the root, not under /v1;
+// other servers may answer 404.
+func (c *Client) Health(ctx context.Context) (int, error) {
+	// Strip /v1 suffix if present - health is at the root
+	baseURL := strings.TrimSuffix(c.baseURL, "/v1")
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/health", nil)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	// Not retried, so that a 503 from an overloaded server is seen
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so that the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
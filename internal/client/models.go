@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ModelList represents a response from the /models endpoint.
+type ModelList struct {
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+
+	// Headers holds selected response headers, keyed by lowercase name.
+	Headers map[string]string `json:"-"`
+}
+
+// Model represents one entry of a model list.
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// ListModels lists the models served at the /models endpoint.
+func (c *Client) ListModels(ctx context.Context) (*ModelList, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	if c.stats != nil {
+		c.stats.recordRequest()
+		start := time.Now()
+		defer func() { c.stats.recordRequestTime(time.Since(start)) }()
+	}
+
+	sent := time.Now()
+	resp, err := c.do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	headers := captureHeaders(resp.Header)
+
+	// Log request/response
+	if c.logger != nil {
+		c.logger.LogRequest(httpReq.Method, httpReq.URL.String(), nil, sent)
+		c.logger.LogResponseHeaders(headers)
+		c.logger.LogResponse(resp.StatusCode, respBody)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp.StatusCode, respBody)
+	}
+
+	var result ModelList
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	result.Headers = headers
+
+	return &result, nil
+}
+
+// IDs returns the ids of the listed models, in order.
+func (l *ModelList) IDs() []string {
+	ids := make([]string, len(l.Data))
+	for i, m := range l.Data {
+		ids[i] = m.ID
+	}
+	return ids
+}
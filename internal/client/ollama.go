@@ -0,0 +1,377 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Ollama is Ollama's native chat API (/api/chat), under a base URL without
+// /v1 such as http://localhost:11434. Reasoning is sent and received as
+// the thinking field, tool results name their function with tool_name,
+// images are sent as base64 data, and sampling parameters go in options.
+// Streams are NDJSON rather than SSE. reasoning_effort becomes the think
+// option; extra fields are sent as they are, e.g. think to enable or
+// disable thinking, except that an options extra is merged into the
+// translated one. tool_choice, logprobs, and other fields the API has no
+// equivalent for are dropped.
+type Ollama struct{}
+
+func (Ollama) Name() string {
+	return ProviderOllama
+}
+
+func (Ollama) ChatPath(ChatCompletionRequest) string {
+	return "/api/chat"
+}
+
+func (Ollama) SetAuth(h http.Header, apiKey string) {
+	if apiKey != "" {
+		h.Set("Authorization", "Bearer "+apiKey)
+	}
+}
+
+func (Ollama) EncodeChat(req ChatCompletionRequest) ([]byte, error) {
+	// The API streams unless told not to
+	m := map[string]any{"model": req.Model, "stream": req.Stream}
+
+	// Tool results name their function, which tool messages only give by
+	// the id of the call
+	callNames := make(map[string]string)
+	for _, msg := range req.Messages {
+		for _, tc := range msg.ToolCalls {
+			callNames[tc.ID] = tc.Function.Name
+		}
+	}
+	// Nil messages are omitted, for requests testing a missing field
+	if req.Messages != nil {
+		messages := make([]map[string]any, len(req.Messages))
+		for i, msg := range req.Messages {
+			messages[i] = ollamaMessage(msg, callNames)
+		}
+		m["messages"] = messages
+	}
+
+	if len(req.Tools) > 0 {
+		m["tools"] = req.Tools
+	}
+	if rf := req.ResponseFormat; rf != nil {
+		switch rf.Type {
+		case "json_object":
+			m["format"] = "json"
+		case "json_schema":
+			if rf.JSONSchema != nil && len(rf.JSONSchema.Schema) > 0 {
+				m["format"] = rf.JSONSchema.Schema
+			} else {
+				m["format"] = "json"
+			}
+		}
+	}
+
+	options := map[string]any{}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	} else if req.MaxCompletionTokens > 0 {
+		options["num_predict"] = req.MaxCompletionTokens
+	}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		options["top_p"] = *req.TopP
+	}
+	if req.PresencePenalty != nil {
+		options["presence_penalty"] = *req.PresencePenalty
+	}
+	if req.FrequencyPenalty != nil {
+		options["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if req.Seed != nil {
+		options["seed"] = *req.Seed
+	}
+	if len(req.Stop) > 0 {
+		options["stop"] = req.Stop
+	}
+
+	// Extra fields can override the translated ones, as for OpenAI, but
+	// options are merged, so that they do not drop the sampling parameters
+	for k, v := range req.Extra {
+		if extra, ok := v.(map[string]any); ok && k == "options" {
+			for key, value := range extra {
+				options[key] = value
+			}
+			continue
+		}
+		if k == "reasoning_effort" {
+			if _, set := req.Extra["think"]; !set {
+				m["think"] = ollamaThink(v)
+			}
+			continue
+		}
+		m[k] = v
+	}
+	if _, ok := m["options"]; !ok && len(options) > 0 {
+		m["options"] = options
+	}
+
+	return json.Marshal(m)
+}
+
+// ollamaThink translates a reasoning_effort into the think option: false
+// for "none", and the level otherwise.
+func ollamaThink(effort any) any {
+	if effort == "none" {
+		return false
+	}
+	return effort
+}
+
+// ollamaMessage returns the /api/chat form of a chat message. callNames
+// maps tool call ids to function names.
+func ollamaMessage(msg Message, callNames map[string]string) map[string]any {
+	m := map[string]any{"role": msg.Role, "content": messageText(msg)}
+	if msg.ReasoningContent != "" {
+		m["thinking"] = msg.ReasoningContent
+	}
+	if msg.Role == "tool" {
+		if name := callNames[msg.ToolCallID]; name != "" {
+			m["tool_name"] = name
+		}
+		if msg.ToolCallID != "" {
+			m["tool_call_id"] = msg.ToolCallID
+		}
+	}
+
+	var calls []map[string]any
+	for _, tc := range msg.ToolCalls {
+		args := json.RawMessage(tc.Function.Arguments)
+		if !json.Valid(args) {
+			args = json.RawMessage(`{}`)
+		}
+		call := map[string]any{"function": map[string]any{"name": tc.Function.Name, "arguments": args}}
+		if tc.ID != "" {
+			call["id"] = tc.ID
+		}
+		calls = append(calls, call)
+	}
+	if len(calls) > 0 {
+		m["tool_calls"] = calls
+	}
+
+	// Images are only accepted as base64 data, not fetched from URLs
+	var images []string
+	for _, p := range msg.ContentParts {
+		if p.Type != "image_url" || p.ImageURL == nil {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(p.ImageURL.URL, "data:"); ok {
+			if _, data, ok := strings.Cut(rest, ";base64,"); ok {
+				images = append(images, data)
+			}
+		}
+	}
+	if len(images) > 0 {
+		m["images"] = images
+	}
+	return m
+}
+
+// ollamaToolCall is a tool call of an /api/chat response.
+type ollamaToolCall struct {
+	ID       string `json:"id"`
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// ollamaResponse is an /api/chat response, or one line of a stream.
+type ollamaResponse struct {
+	Model   string `json:"model"`
+	Message struct {
+		Content   string           `json:"content"`
+		Thinking  string           `json:"thinking"`
+		ToolCalls []ollamaToolCall `json:"tool_calls"`
+	} `json:"message"`
+	Done               bool   `json:"done"`
+	DoneReason         string `json:"done_reason"`
+	PromptEvalCount    int    `json:"prompt_eval_count"`
+	PromptEvalDuration int64  `json:"prompt_eval_duration"`
+	EvalCount          int    `json:"eval_count"`
+	EvalDuration       int64  `json:"eval_duration"`
+}
+
+// toolCalls translates the response's tool calls, generating ids for
+// those without, since older versions of Ollama give none.
+func (r *ollamaResponse) toolCalls() []ToolCall {
+	var calls []ToolCall
+	for _, tc := range r.Message.ToolCalls {
+		args := string(tc.Function.Arguments)
+		if len(tc.Function.Arguments) == 0 || args == "null" {
+			args = "{}"
+		}
+		calls = append(calls, ToolCall{
+			ID:   callID(tc.ID),
+			Type: "function",
+			Function: ToolCallFunction{
+				Name:      tc.Function.Name,
+				Arguments: args,
+			},
+		})
+	}
+	return calls
+}
+
+// usage returns the token usage of a final response.
+func (r *ollamaResponse) usage() *Usage {
+	return &Usage{
+		PromptTokens:     r.PromptEvalCount,
+		CompletionTokens: r.EvalCount,
+		TotalTokens:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+// timings returns the throughput Ollama measured, which it reports like
+// llama.cpp, or nil if it reported none.
+func (r *ollamaResponse) timings() *Timings {
+	if r.PromptEvalDuration == 0 && r.EvalDuration == 0 {
+		return nil
+	}
+	t := &Timings{
+		PromptN:     r.PromptEvalCount,
+		PromptMS:    float64(r.PromptEvalDuration) / 1e6,
+		PredictedN:  r.EvalCount,
+		PredictedMS: float64(r.EvalDuration) / 1e6,
+	}
+	if t.PromptN > 0 && t.PromptMS > 0 {
+		t.PromptPerTokenMS = t.PromptMS / float64(t.PromptN)
+		t.PromptPerSecond = float64(t.PromptN) / t.PromptMS * 1000
+	}
+	if t.PredictedN > 0 && t.PredictedMS > 0 {
+		t.PredictedPerTokenMS = t.PredictedMS / float64(t.PredictedN)
+		t.PredictedPerSecond = float64(t.PredictedN) / t.PredictedMS * 1000
+	}
+	return t
+}
+
+// ollamaFinishReason translates a done reason to its OpenAI finish
+// reason. A response that called tools finished with tool_calls. Unknown
+// reasons, such as load and unload, are kept.
+func ollamaFinishReason(reason string, calledTools bool) string {
+	switch reason {
+	case "stop", "":
+		if calledTools {
+			return "tool_calls"
+		}
+		return "stop"
+	}
+	return reason
+}
+
+func (Ollama) DecodeChat(body []byte) (*ChatCompletionResponse, error) {
+	var resp ollamaResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	msg := ResponseMessage{
+		Role:             "assistant",
+		Content:          resp.Message.Content,
+		ReasoningContent: resp.Message.Thinking,
+		ToolCalls:        resp.toolCalls(),
+	}
+	return &ChatCompletionResponse{
+		Object: "chat.completion",
+		Model:  resp.Model,
+		Choices: []Choice{{
+			Message:            msg,
+			FinishReason:       ollamaFinishReason(resp.DoneReason, len(msg.ToolCalls) > 0),
+			NativeFinishReason: resp.DoneReason,
+		}},
+		Usage:   resp.usage(),
+		Timings: resp.timings(),
+	}, nil
+}
+
+// ParseStream translates the lines of an NDJSON stream into chat
+// completion chunks and accumulates them. The stream is done at the line
+// with done set. Error objects are recorded as violations, as in OpenAI
+// streams.
+func (Ollama) ParseStream(r io.Reader, start time.Time) (*StreamResult, error) {
+	b := newStreamBuilder()
+	result := b.result
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxSSELine)
+	toolCalls := 0
+	lineNo := 0
+	started := false
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		lineNo++
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if msg, ok := streamErrorPayload(line); ok {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationErrorPayload,
+				Line:    lineNo,
+				Data:    string(line),
+				Message: "error in stream: " + msg,
+			})
+			continue
+		}
+		var resp ollamaResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationNonJSON,
+				Line:    lineNo,
+				Data:    string(line),
+				Message: "line is not a JSON response: " + err.Error(),
+			})
+			continue
+		}
+
+		var choice ChunkChoice
+		if !started {
+			started = true
+			choice.Delta.Role = "assistant"
+		}
+		choice.Delta.Content = resp.Message.Content
+		choice.Delta.ReasoningContent = resp.Message.Thinking
+		// Tool calls arrive whole rather than in deltas
+		for _, tc := range resp.toolCalls() {
+			d := ToolCallDelta{Index: toolCalls, ID: tc.ID, Type: "function"}
+			d.Function.Name = tc.Function.Name
+			d.Function.Arguments = tc.Function.Arguments
+			choice.Delta.ToolCalls = append(choice.Delta.ToolCalls, d)
+			toolCalls++
+		}
+
+		chunk := ChatCompletionChunk{Object: "chat.completion.chunk", Model: resp.Model}
+		if resp.Done {
+			finish := ollamaFinishReason(resp.DoneReason, toolCalls > 0)
+			choice.FinishReason = &finish
+			choice.NativeFinishReason = &resp.DoneReason
+			chunk.Usage = resp.usage()
+			chunk.Timings = resp.timings()
+		}
+		chunk.Choices = []ChunkChoice{choice}
+		b.add(chunk, time.Since(start))
+
+		if resp.Done {
+			result.Done = true
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan stream: %w", err)
+	}
+	return b.build(), nil
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Provider names.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderGemini    = "gemini"
+	ProviderOllama    = "ollama"
+)
+
+// Providers returns the names of all providers.
+func Providers() []string {
+	return []string{ProviderOpenAI, ProviderAnthropic, ProviderGemini, ProviderOllama}
+}
+
+// Provider is the chat API a server speaks. Evals build OpenAI-style
+// chat completion requests and check OpenAI-style responses; a provider
+// translates both to and from its wire format, so that the same evals
+// validate servers of any provider.
+type Provider interface {
+	// Name returns the provider's name, as given to --provider.
+	Name() string
+	// ChatPath returns the path of the chat endpoint for a request under
+	// the base URL.
+	ChatPath(req ChatCompletionRequest) string
+	// SetAuth sets the headers authenticating a request with apiKey, and
+	// any the API requires of every request.
+	SetAuth(h http.Header, apiKey string)
+	// EncodeChat returns the body of a chat request.
+	EncodeChat(req ChatCompletionRequest) ([]byte, error)
+	// DecodeChat decodes the body of a chat response.
+	DecodeChat(body []byte) (*ChatCompletionResponse, error)
+	// ParseStream parses a streamed chat response, as parseSSEStream does.
+	ParseStream(r io.Reader, start time.Time) (*StreamResult, error)
+}
+
+// NewProvider returns the provider with the given name.
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case ProviderOpenAI:
+		return OpenAI{}, nil
+	case ProviderAnthropic:
+		return Anthropic{}, nil
+	case ProviderGemini:
+		return Gemini{}, nil
+	case ProviderOllama:
+		return Ollama{}, nil
+	}
+	return nil, fmt.Errorf("unknown provider %q (valid: %s)", name, strings.Join(Providers(), ", "))
+}
+
+// OpenAI is the OpenAI chat completions API, which requests and responses
+// are sent in as they are.
+type OpenAI struct{}
+
+func (OpenAI) Name() string {
+	return ProviderOpenAI
+}
+
+func (OpenAI) ChatPath(ChatCompletionRequest) string {
+	return "/chat/completions"
+}
+
+func (OpenAI) SetAuth(h http.Header, apiKey string) {
+	if apiKey != "" {
+		h.Set("Authorization", "Bearer "+apiKey)
+	}
+}
+
+func (OpenAI) EncodeChat(req ChatCompletionRequest) ([]byte, error) {
+	return json.Marshal(req)
+}
+
+func (OpenAI) DecodeChat(body []byte) (*ChatCompletionResponse, error) {
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (OpenAI) ParseStream(r io.Reader, start time.Time) (*StreamResult, error) {
+	return parseSSEStream(r, start)
+}
+
+// callID returns the id of a tool call translated from another API,
+// generating one if the server gave none, as some API versions do not.
+func callID(id string) string {
+	if id != "" {
+		return id
+	}
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "call_" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// encodeChat encodes req with p and decodes the body as generic JSON.
+func encodeChat(t *testing.T, p Provider, req ChatCompletionRequest) map[string]any {
+	t.Helper()
+	data, err := p.EncodeChat(req)
+	if err != nil {
+		t.Fatalf("EncodeChat: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	return m
+}
+
+func TestEncodeChatStop(t *testing.T) {
+	req := ChatCompletionRequest{
+		Model:    "m",
+		Messages: []Message{{Role: "user", Content: "Count to ten."}},
+		Stop:     []string{"7", "\n\n"},
+	}
+	want := []any{"7", "\n\n"}
+
+	tests := []struct {
+		provider Provider
+		stop     func(m map[string]any) any
+	}{
+		{OpenAI{}, func(m map[string]any) any { return m["stop"] }},
+		{Anthropic{}, func(m map[string]any) any { return m["stop_sequences"] }},
+		{Gemini{}, func(m map[string]any) any {
+			config, _ := m["generationConfig"].(map[string]any)
+			return config["stopSequences"]
+		}},
+		{Ollama{}, func(m map[string]any) any {
+			options, _ := m["options"].(map[string]any)
+			return options["stop"]
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider.Name(), func(t *testing.T) {
+			m := encodeChat(t, tt.provider, req)
+			if got := tt.stop(m); !reflect.DeepEqual(got, want) {
+				t.Errorf("stop sequences = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAnthropicToolResultBlocks(t *testing.T) {
+	const image = "data:image/png;base64,iVBORw0KGgo="
+	req := ChatCompletionRequest{
+		Model: "m",
+		Messages: []Message{
+			{Role: "user", Content: "Take a screenshot."},
+			{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: ToolCallFunction{Name: "screenshot", Arguments: "{}"}}}},
+			{Role: "tool", ToolCallID: "call_1", ContentParts: []ContentPart{
+				{Type: "text", Text: "Captured the screen."},
+				{Type: "image_url", ImageURL: &ImageURL{URL: image}},
+			}},
+			{Role: "tool", ToolCallID: "call_2", Content: "plain result"},
+		},
+	}
+	m := encodeChat(t, Anthropic{}, req)
+
+	messages, _ := m["messages"].([]any)
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want 3: %v", len(messages), messages)
+	}
+	// The two results are merged into one user message
+	results, _ := messages[2].(map[string]any)["content"].([]any)
+	if len(results) != 2 {
+		t.Fatalf("got %d tool results, want 2: %v", len(results), results)
+	}
+
+	want := []any{
+		map[string]any{
+			"type":        "tool_result",
+			"tool_use_id": "call_1",
+			"content": []any{
+				map[string]any{"type": "text", "text": "Captured the screen."},
+				map[string]any{"type": "image", "source": map[string]any{
+					"type": "base64", "media_type": "image/png", "data": "iVBORw0KGgo=",
+				}},
+			},
+		},
+		map[string]any{
+			"type":        "tool_result",
+			"tool_use_id": "call_2",
+			"content":     []any{map[string]any{"type": "text", "text": "plain result"}},
+		},
+	}
+	if !reflect.DeepEqual(results, want) {
+		got, _ := json.MarshalIndent(results, "", "  ")
+		t.Errorf("tool results:\n%s", got)
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DialFunc opens a network connection, as net.Dialer.DialContext does.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Resolve overrides the addresses a host and port resolve to, as curl's
+// --resolve option does.
+type Resolve struct {
+	// Host is the host name, or "*" for any host.
+	Host string
+	Port string
+	// Addrs are the addresses connected to instead, tried in order.
+	Addrs []string
+}
+
+// ParseResolve parses an override in curl's host:port:addr[,addr]...
+// form. IPv6 addresses may be given in brackets.
+func ParseResolve(s string) (Resolve, error) {
+	host, rest, ok := cutHost(s)
+	if !ok {
+		return Resolve{}, fmt.Errorf("%q is not host:port:addr", s)
+	}
+	port, addrs, ok := strings.Cut(rest, ":")
+	if !ok || host == "" || addrs == "" {
+		return Resolve{}, fmt.Errorf("%q is not host:port:addr", s)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return Resolve{}, fmt.Errorf("%q: invalid port %q", s, port)
+	}
+
+	r := Resolve{Host: strings.ToLower(host), Port: port}
+	for _, addr := range strings.Split(addrs, ",") {
+		addr = strings.TrimSpace(addr)
+		if strings.HasPrefix(addr, "[") && strings.HasSuffix(addr, "]") {
+			addr = addr[1 : len(addr)-1]
+		}
+		if addr == "" {
+			return Resolve{}, fmt.Errorf("%q: empty address", s)
+		}
+		if strings.Contains(addr, ":") && net.ParseIP(addr) == nil {
+			return Resolve{}, fmt.Errorf("%q: invalid address %q", s, addr)
+		}
+		r.Addrs = append(r.Addrs, addr)
+	}
+	return r, nil
+}
+
+// cutHost splits the host, which may be a bracketed IPv6 address, from
+// the rest of a host:port:addr override.
+func cutHost(s string) (host, rest string, ok bool) {
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]:")
+		if end < 0 {
+			return "", "", false
+		}
+		return s[1:end], s[end+2:], true
+	}
+	return strings.Cut(s, ":")
+}
+
+// matches reports whether the override applies to connections to host
+// and port.
+func (r Resolve) matches(host, port string) bool {
+	return r.Port == port && (r.Host == "*" || strings.EqualFold(r.Host, host))
+}
+
+// Dialer returns a DialFunc connecting to the overridden addresses of
+// hosts and ports, and to other addresses as dialer does. The first
+// override matching a connection applies. TLS still verifies and sends
+// the requested host name, so HTTPS works as with curl.
+func Dialer(resolves []Resolve, dialer *net.Dialer) DialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		for _, r := range resolves {
+			if !r.matches(host, port) {
+				continue
+			}
+			var errs []error
+			for _, a := range r.Addrs {
+				conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(a, port))
+				if err == nil {
+					return conn, nil
+				}
+				errs = append(errs, err)
+			}
+			return nil, fmt.Errorf("dial %s resolved to %s: %w", addr, strings.Join(r.Addrs, ","), errors.Join(errs...))
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures retries of requests that fail for transient reasons.
+// Only the initial request is retried; a streaming response that fails
+// mid-stream is not.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values <= 1 disable retries.
+	MaxAttempts int
+	// Backoff is the delay before the first retry. It doubles on each
+	// subsequent retry, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// RetryOnStatus lists HTTP status codes that trigger a retry.
+	RetryOnStatus []int
+}
+
+// DefaultRetryStatuses are the status codes retried by DefaultRetryPolicy.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy returns a policy allowing the given number of retries
+// with exponential backoff starting at one second.
+func DefaultRetryPolicy(retries int) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:   retries + 1,
+		Backoff:       time.Second,
+		MaxBackoff:    30 * time.Second,
+		RetryOnStatus: DefaultRetryStatuses,
+	}
+}
+
+// retryStatus reports whether the policy retries the given status code.
+func (p RetryPolicy) retryStatus(status int) bool {
+	for _, s := range p.RetryOnStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the backoff before the given retry (1-based).
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// isRetryableError reports whether a transport error is likely transient.
+func isRetryableError(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// do sends the request, retrying transient failures per the client's retry policy.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	return c.retry.Do(c.httpClient, req)
+}
+
+// Do sends the request with hc, retrying transient failures per the
+// policy. Requests with a body must set GetBody to be retried, as
+// http.NewRequest does for in-memory bodies.
+func (p RetryPolicy) Do(hc *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := hc.Do(req)
+		if attempt >= p.MaxAttempts {
+			return resp, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if !isRetryableError(err) {
+				return nil, err
+			}
+			wait = p.delay(attempt)
+		case p.retryStatus(resp.StatusCode):
+			wait = p.delay(attempt)
+			if ra := retryAfter(resp, time.Now()); ra > 0 && (p.MaxBackoff == 0 || ra <= p.MaxBackoff) {
+				wait = ra
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as
+// an HTTP date, into the wait from now. It returns 0 for a missing or
+// invalid header, or a date that has passed.
+func retryAfter(resp *http.Response, now time.Time) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// sleepContext sleeps for d or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{"first", RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 1, 100 * time.Millisecond},
+		{"doubles", RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 2, 200 * time.Millisecond},
+		{"doubles again", RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 4, 800 * time.Millisecond},
+		{"capped", RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 5, time.Second},
+		{"stays capped", RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}, 60, time.Second},
+		{"backoff above cap", RetryPolicy{Backoff: 5 * time.Second, MaxBackoff: time.Second}, 1, time.Second},
+		{"no cap", RetryPolicy{Backoff: time.Second}, 6, 32 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.delay(tt.retry); got != tt.want {
+				t.Errorf("delay(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"missing", "", 0},
+		{"seconds", "5", 5 * time.Second},
+		{"zero", "0", 0},
+		{"negative", "-3", 0},
+		{"http date", now.Add(7 * time.Second).Format(http.TimeFormat), 7 * time.Second},
+		{"past http date", now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"invalid", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			if got := retryAfter(resp, now); got != tt.want {
+				t.Errorf("retryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+// failingServer answers the first failures requests with status and a
+// Retry-After of retryAfter, if set, and later ones with 200. It counts
+// the requests and checks that each carries the same body.
+func failingServer(t *testing.T, status, failures int, retryAfter string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d sent body %q", attempts.Load()+1, body)
+		}
+		if int(attempts.Add(1)) > failures {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func newRetryRequest(t *testing.T, ctx context.Context, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestRetryPolicyDoStatus(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:   3,
+		Backoff:       time.Millisecond,
+		MaxBackoff:    5 * time.Millisecond,
+		RetryOnStatus: DefaultRetryStatuses,
+	}
+	tests := []struct {
+		status       int
+		wantAttempts int
+	}{
+		{http.StatusTooManyRequests, 3},
+		{http.StatusInternalServerError, 3},
+		{http.StatusBadGateway, 3},
+		{http.StatusServiceUnavailable, 3},
+		{http.StatusGatewayTimeout, 3},
+		{http.StatusBadRequest, 1},
+		{http.StatusUnauthorized, 1},
+		{http.StatusNotFound, 1},
+		{http.StatusUnprocessableEntity, 1},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.status), func(t *testing.T) {
+			srv, attempts := failingServer(t, tt.status, 10, "")
+			resp, err := policy.Do(srv.Client(), newRetryRequest(t, context.Background(), srv.URL))
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := int(attempts.Load()); got != tt.wantAttempts {
+				t.Errorf("made %d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+
+	t.Run("recovers", func(t *testing.T) {
+		srv, attempts := failingServer(t, http.StatusServiceUnavailable, 2, "")
+		resp, err := policy.Do(srv.Client(), newRetryRequest(t, context.Background(), srv.URL))
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || attempts.Load() != 3 {
+			t.Errorf("got status %d after %d attempts, want 200 after 3", resp.StatusCode, attempts.Load())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, attempts := failingServer(t, http.StatusServiceUnavailable, 10, "")
+		resp, err := RetryPolicy{}.Do(srv.Client(), newRetryRequest(t, context.Background(), srv.URL))
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		resp.Body.Close()
+		if attempts.Load() != 1 {
+			t.Errorf("made %d attempts with retries disabled, want 1", attempts.Load())
+		}
+	})
+}
+
+func TestRetryPolicyDoRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter func() string
+		maxBackoff time.Duration
+		minWait    time.Duration
+		maxWait    time.Duration
+	}{
+		{
+			name:       "seconds",
+			retryAfter: func() string { return "1" },
+			maxBackoff: 5 * time.Second,
+			minWait:    time.Second,
+			maxWait:    3 * time.Second,
+		},
+		{
+			// HTTP dates have a resolution of a second, so this waits
+			// between one and two
+			name:       "http date",
+			retryAfter: func() string { return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat) },
+			maxBackoff: 5 * time.Second,
+			minWait:    time.Second,
+			maxWait:    3 * time.Second,
+		},
+		{
+			name:       "above the cap",
+			retryAfter: func() string { return "3600" },
+			maxBackoff: 10 * time.Millisecond,
+			minWait:    time.Millisecond,
+			maxWait:    time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, attempts := failingServer(t, http.StatusTooManyRequests, 1, tt.retryAfter())
+			policy := RetryPolicy{
+				MaxAttempts:   2,
+				Backoff:       time.Millisecond,
+				MaxBackoff:    tt.maxBackoff,
+				RetryOnStatus: DefaultRetryStatuses,
+			}
+			start := time.Now()
+			resp, err := policy.Do(srv.Client(), newRetryRequest(t, context.Background(), srv.URL))
+			elapsed := time.Since(start)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+				t.Fatalf("got status %d after %d attempts, want 200 after 2", resp.StatusCode, attempts.Load())
+			}
+			if elapsed < tt.minWait || elapsed > tt.maxWait {
+				t.Errorf("retried after %v, want between %v and %v", elapsed, tt.minWait, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoCanceledDuringBackoff(t *testing.T) {
+	srv, attempts := failingServer(t, http.StatusServiceUnavailable, 10, "")
+	policy := RetryPolicy{
+		MaxAttempts:   3,
+		Backoff:       time.Hour,
+		RetryOnStatus: DefaultRetryStatuses,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	resp, err := policy.Do(srv.Client(), newRetryRequest(t, ctx, srv.URL))
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want the context's", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %v, not when the context was done", elapsed)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("made %d attempts, want 1", attempts.Load())
+	}
+}
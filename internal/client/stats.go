@@ -0,0 +1,135 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// Stats holds request metrics accumulated across all requests made by a Client.
+type Stats struct {
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	// TTFT is the time to first token of the first streaming request.
+	TTFT time.Duration
+	// ITL is the mean inter-token latency across streaming requests.
+	ITL time.Duration
+	// RequestTime is the total time spent in chat completion requests,
+	// including reading and parsing the response.
+	RequestTime time.Duration
+	// TemplateTime is the total time spent in /apply-template requests.
+	TemplateTime time.Duration
+	// Timings totals the llama.cpp timings reported by the server, with
+	// rates computed over all requests. Nil if none were reported.
+	Timings *Timings
+}
+
+// StatsRecorder accumulates Stats across requests. It is safe for concurrent use.
+type StatsRecorder struct {
+	mu    sync.Mutex
+	stats Stats
+
+	itlTotal time.Duration
+	itlCount int
+
+	violations []StreamViolation
+}
+
+// Stats returns a snapshot of the accumulated metrics.
+func (r *StatsRecorder) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats
+	if r.itlCount > 0 {
+		s.ITL = r.itlTotal / time.Duration(r.itlCount)
+	}
+	if s.Timings != nil {
+		t := *s.Timings
+		if t.PromptN > 0 && t.PromptMS > 0 {
+			t.PromptPerTokenMS = t.PromptMS / float64(t.PromptN)
+			t.PromptPerSecond = 1000 * float64(t.PromptN) / t.PromptMS
+		}
+		if t.PredictedN > 0 && t.PredictedMS > 0 {
+			t.PredictedPerTokenMS = t.PredictedMS / float64(t.PredictedN)
+			t.PredictedPerSecond = 1000 * float64(t.PredictedN) / t.PredictedMS
+		}
+		s.Timings = &t
+	}
+	return s
+}
+
+// Violations returns the stream violations recorded across requests, in
+// the order they were received.
+func (r *StatsRecorder) Violations() []StreamViolation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]StreamViolation(nil), r.violations...)
+}
+
+func (r *StatsRecorder) recordRequest() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Requests++
+}
+
+func (r *StatsRecorder) recordUsage(usage *Usage) {
+	if usage == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.PromptTokens += usage.PromptTokens
+	r.stats.CompletionTokens += usage.CompletionTokens
+}
+
+func (r *StatsRecorder) recordTimings(t *Timings) {
+	if t == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stats.Timings == nil {
+		r.stats.Timings = &Timings{}
+	}
+	r.stats.Timings.CacheN += t.CacheN
+	r.stats.Timings.PromptN += t.PromptN
+	r.stats.Timings.PromptMS += t.PromptMS
+	r.stats.Timings.PredictedN += t.PredictedN
+	r.stats.Timings.PredictedMS += t.PredictedMS
+}
+
+func (r *StatsRecorder) recordRequestTime(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.RequestTime += d
+}
+
+func (r *StatsRecorder) recordTemplateTime(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.TemplateTime += d
+}
+
+func (r *StatsRecorder) recordITL(itl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.itlTotal += itl
+	r.itlCount++
+}
+
+func (r *StatsRecorder) recordTTFT(ttft time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stats.TTFT == 0 {
+		r.stats.TTFT = ttft
+	}
+}
+
+func (r *StatsRecorder) recordViolations(violations []StreamViolation) {
+	if len(violations) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.violations = append(r.violations, violations...)
+}
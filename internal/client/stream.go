@@ -6,76 +6,311 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"time"
 )
 
+// maxSSELine bounds the length of one line of an SSE stream. Chunks are
+// usually small, but a server may send a whole tool call or a long
+// logprobs list in one.
+const maxSSELine = 16 << 20
+
+// Kinds of stream violation.
+const (
+	// ViolationNonJSON is a data line that is neither JSON nor [DONE].
+	ViolationNonJSON = "non_json"
+	// ViolationErrorEvent is an event named "error", which OpenAI streams
+	// never send.
+	ViolationErrorEvent = "error_event"
+	// ViolationErrorPayload is a data line carrying an error object in
+	// place of a chunk.
+	ViolationErrorPayload = "error_payload"
+)
+
+// StreamViolation is a line of a stream that the parser skipped rather
+// than abort on, since it is not a chat completion chunk.
+type StreamViolation struct {
+	Kind string
+	// Line is the 1-based line number in the stream body.
+	Line int
+	// Data is the event's data as received.
+	Data string
+	// Message describes the violation, with the server's error message
+	// for errors.
+	Message string
+}
+
 // parseSSEStream parses an SSE stream and accumulates the result.
-// Returns the accumulated result and raw chunk data for logging.
-func parseSSEStream(r io.Reader) (*StreamResult, []byte, error) {
-	result := &StreamResult{}
-	toolCallBuilders := make(map[int]*toolCallBuilder)
+// The start time is used to compute chunk receive times, time to first
+// token, and inter-token latency. Lines that are not chunks, such as
+// error events, are recorded as violations rather than ending the parse.
+func parseSSEStream(r io.Reader, start time.Time) (*StreamResult, error) {
+	b := newStreamBuilder()
+	result := b.result
+
+	err := readSSE(r, func(event string, data []byte, lineNo int) bool {
+		if string(data) == "[DONE]" {
+			result.Done = true
+			return false
+		}
+
+		if event == "error" {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationErrorEvent,
+				Line:    lineNo,
+				Data:    string(data),
+				Message: "error event: " + streamErrorMessage(data),
+			})
+			return true
+		}
+		if msg, ok := streamErrorPayload(data); ok {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationErrorPayload,
+				Line:    lineNo,
+				Data:    string(data),
+				Message: "error in stream: " + msg,
+			})
+			return true
+		}
+
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			result.Violations = append(result.Violations, StreamViolation{
+				Kind:    ViolationNonJSON,
+				Line:    lineNo,
+				Data:    string(data),
+				Message: "data is not a JSON chunk: " + err.Error(),
+			})
+			return true
+		}
+
+		b.add(chunk, time.Since(start))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.build(), nil
+}
+
+// readSSE reads an SSE stream, calling fn with the data of each data
+// line, the name of its event from its "event:" field, and its 1-based
+// line number, until fn returns false or the stream ends. The data is only
+// valid until fn returns.
+func readSSE(r io.Reader, fn func(event string, data []byte, lineNo int) bool) error {
+	var event string
+	lineNo := 0
 
-	var rawChunks bytes.Buffer
 	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxSSELine)
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		rawChunks.WriteString(line)
-		rawChunks.WriteString("\n")
+		// The line is only valid until the next Scan, so it is decoded in
+		// place rather than converted to a string
+		line := scanner.Bytes()
+		lineNo++
 
-		if !strings.HasPrefix(line, "data: ") {
+		// A blank line ends the event
+		if len(line) == 0 {
+			event = ""
+			continue
+		}
+		if name, ok := bytes.CutPrefix(line, []byte("event:")); ok {
+			event = string(bytes.TrimSpace(name))
 			continue
 		}
 
-		data := strings.TrimPrefix(line, "data: ")
-		if data == "[DONE]" {
+		// As the SSE spec allows, the space after the colon is optional
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			continue
+		}
+		data = bytes.TrimPrefix(data, []byte(" "))
+		// An empty data field, as some servers send to keep the
+		// connection alive, carries no chunk
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		if !fn(event, data, lineNo) {
 			break
 		}
+	}
 
-		var chunk ChatCompletionChunk
-		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
-			return nil, rawChunks.Bytes(), fmt.Errorf("unmarshal chunk: %w", err)
-		}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan stream: %w", err)
+	}
+	return nil
+}
+
+// streamBuilder accumulates the chunks of a stream into its result.
+type streamBuilder struct {
+	result  *StreamResult
+	choices map[int]*choiceBuilder
+	order   []int
+	// Receive times of chunks carrying generated data
+	tokenTimes []time.Duration
+}
+
+func newStreamBuilder() *streamBuilder {
+	return &streamBuilder{
+		result:  &StreamResult{},
+		choices: make(map[int]*choiceBuilder),
+	}
+}
+
+// add accumulates a chunk received the given time after the request was
+// sent.
+func (b *streamBuilder) add(chunk ChatCompletionChunk, received time.Duration) {
+	result := b.result
+	result.Chunks = append(result.Chunks, chunk)
+	result.ChunkTimes = append(result.ChunkTimes, received)
 
-		result.Chunks = append(result.Chunks, chunk)
+	// Accumulate usage if present
+	if chunk.Usage != nil {
+		result.Usage = chunk.Usage
+	}
+	if chunk.Timings != nil {
+		result.Timings = chunk.Timings
+	}
+
+	// Process choices
+	hasToken := false
+	for _, choice := range chunk.Choices {
+		delta := choice.Delta
 
-		// Accumulate usage if present
-		if chunk.Usage != nil {
-			result.Usage = chunk.Usage
+		if delta.Content != "" || delta.ReasoningContent != "" || len(delta.ToolCalls) > 0 {
+			hasToken = true
 		}
 
-		// Process choices
-		for _, choice := range chunk.Choices {
-			delta := choice.Delta
-
-			// Accumulate content
-			result.Content += delta.Content
-			result.ReasoningContent += delta.ReasoningContent
-
-			// Accumulate tool calls
-			for _, tc := range delta.ToolCalls {
-				builder, ok := toolCallBuilders[tc.Index]
-				if !ok {
-					builder = &toolCallBuilder{}
-					toolCallBuilders[tc.Index] = builder
-				}
-				builder.Accumulate(tc)
+		// Accumulate deltas per choice, since with n > 1 choices interleave
+		cb, ok := b.choices[choice.Index]
+		if !ok {
+			cb = &choiceBuilder{
+				choice:    StreamChoice{Index: choice.Index},
+				toolCalls: make(map[int]*toolCallBuilder),
 			}
+			b.choices[choice.Index] = cb
+			b.order = append(b.order, choice.Index)
 		}
+		cb.Accumulate(choice)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, rawChunks.Bytes(), fmt.Errorf("scan stream: %w", err)
+	if hasToken {
+		if result.TTFT == 0 {
+			result.TTFT = received
+		}
+		b.tokenTimes = append(b.tokenTimes, received)
+	}
+}
+
+// build finalizes the result.
+func (b *streamBuilder) build() *StreamResult {
+	result := b.result
+
+	// Mean gap between consecutive token-bearing chunks
+	if n := len(b.tokenTimes); n > 1 {
+		result.ITL = (b.tokenTimes[n-1] - b.tokenTimes[0]) / time.Duration(n-1)
 	}
 
-	// Build final tool calls
-	for i := 0; i < len(toolCallBuilders); i++ {
-		if builder, ok := toolCallBuilders[i]; ok {
-			result.ToolCalls = append(result.ToolCalls, builder.Build())
+	sort.Ints(b.order)
+	for _, index := range b.order {
+		result.Choices = append(result.Choices, b.choices[index].Build())
+	}
+
+	// The top-level fields hold the first choice
+	if len(result.Choices) > 0 {
+		first := result.Choices[0]
+		result.Content = first.Content
+		result.ReasoningContent = first.ReasoningContent
+		result.ToolCalls = first.ToolCalls
+		result.Logprobs = first.Logprobs
+	}
+
+	return result
+}
+
+// streamErrorPayload returns the message of an error object sent in place
+// of a chunk, as {"error": {...}} or {"error": "..."}, and whether data is
+// one.
+func streamErrorPayload(data []byte) (string, bool) {
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil || len(payload.Error) == 0 || string(payload.Error) == "null" {
+		return "", false
+	}
+	return streamErrorMessage(data), true
+}
+
+// streamErrorMessage extracts the message of an error sent in a stream,
+// falling back to the data itself.
+func streamErrorMessage(data []byte) string {
+	var payload struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return string(data)
+	}
+	var nested struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(payload.Error, &nested); err == nil && nested.Message != "" {
+		return nested.Message
+	}
+	var plain string
+	if err := json.Unmarshal(payload.Error, &plain); err == nil && plain != "" {
+		return plain
+	}
+	if payload.Message != "" {
+		return payload.Message
+	}
+	return string(data)
+}
+
+// choiceBuilder accumulates the deltas of one choice. Text is collected in
+// builders, since concatenating each delta to a string copies everything
+// received so far and long streams have thousands of deltas.
+type choiceBuilder struct {
+	choice           StreamChoice
+	content          strings.Builder
+	reasoningContent strings.Builder
+	toolCalls        map[int]*toolCallBuilder
+}
+
+func (b *choiceBuilder) Accumulate(choice ChunkChoice) {
+	delta := choice.Delta
+	b.content.WriteString(delta.Content)
+	b.reasoningContent.WriteString(delta.ReasoningContent)
+	if choice.Logprobs != nil {
+		b.choice.Logprobs = append(b.choice.Logprobs, choice.Logprobs.Content...)
+	}
+	if choice.FinishReason != nil && *choice.FinishReason != "" {
+		b.choice.FinishReason = *choice.FinishReason
+	}
+
+	for _, tc := range delta.ToolCalls {
+		builder, ok := b.toolCalls[tc.Index]
+		if !ok {
+			builder = &toolCallBuilder{}
+			b.toolCalls[tc.Index] = builder
 		}
+		builder.Accumulate(tc)
 	}
+}
 
-	return result, rawChunks.Bytes(), nil
+// Build finalizes the choice, assembling its tool calls in index order.
+func (b *choiceBuilder) Build() StreamChoice {
+	b.choice.Content = b.content.String()
+	b.choice.ReasoningContent = b.reasoningContent.String()
+	b.choice.ToolCalls = nil
+	for i := 0; i < len(b.toolCalls); i++ {
+		if builder, ok := b.toolCalls[i]; ok {
+			b.choice.ToolCalls = append(b.choice.ToolCalls, builder.Build())
+		}
+	}
+	return b.choice
 }
 
 // toolCallBuilder accumulates tool call deltas.
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// contentStream returns an SSE stream of n content chunks and [DONE].
+func contentStream(n int) []byte {
+	var b bytes.Buffer
+	for i := range n {
+		fmt.Fprintf(&b, "data: {\"id\":\"c\",\"object\":\"chat.completion.chunk\",\"model\":\"m\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"token %d \"}}]}\n\n", i)
+	}
+	b.WriteString("data: [DONE]\n\n")
+	return b.Bytes()
+}
+
+// toolCallStream returns an SSE stream of calls tool calls, each with its
+// arguments split across n chunks, and [DONE].
+func toolCallStream(calls, n int) []byte {
+	var b bytes.Buffer
+	for c := range calls {
+		fmt.Fprintf(&b, "data: {\"id\":\"c\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":%d,\"id\":\"call_%d\",\"type\":\"function\",\"function\":{\"name\":\"get_weather\",\"arguments\":\"\"}}]}}]}\n\n", c, c)
+		fmt.Fprintf(&b, "data: {\"id\":\"c\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":%d,\"function\":{\"arguments\":\"{\\\"location\\\": \\\"\"}}]}}]}\n\n", c)
+		for range n {
+			fmt.Fprintf(&b, "data: {\"id\":\"c\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":%d,\"function\":{\"arguments\":\"Paris \"}}]}}]}\n\n", c)
+		}
+		fmt.Fprintf(&b, "data: {\"id\":\"c\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":%d,\"function\":{\"arguments\":\"\\\"}\"}}]}}]}\n\n", c)
+	}
+	b.WriteString("data: {\"id\":\"c\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\n")
+	b.WriteString("data: [DONE]\n\n")
+	return b.Bytes()
+}
+
+func TestParseSSEStreamToolCalls(t *testing.T) {
+	result, err := parseSSEStream(bytes.NewReader(toolCallStream(2, 3)), time.Now())
+	if err != nil {
+		t.Fatalf("parseSSEStream: %v", err)
+	}
+	if len(result.ToolCalls) != 2 {
+		t.Fatalf("got %d tool calls, want 2", len(result.ToolCalls))
+	}
+	want := `{"location": "Paris Paris Paris "}`
+	for i, tc := range result.ToolCalls {
+		if tc.ID != fmt.Sprintf("call_%d", i) || tc.Function.Name != "get_weather" || tc.Function.Arguments != want {
+			t.Errorf("tool call %d = %s %s(%s), want call_%d get_weather(%s)", i, tc.ID, tc.Function.Name, tc.Function.Arguments, i, want)
+		}
+	}
+}
+
+func BenchmarkParseSSEStreamContent(b *testing.B) {
+	body := contentStream(500)
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		if _, err := parseSSEStream(bytes.NewReader(body), time.Now()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseSSEStreamToolCalls(b *testing.B) {
+	body := toolCallStream(4, 200)
+	b.SetBytes(int64(len(body)))
+	for b.Loop() {
+		if _, err := parseSSEStream(bytes.NewReader(body), time.Now()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReadSSEDataSpace(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"with space", "data: {\"a\":1}\n\ndata: [DONE]\n\n", []string{`{"a":1}`, "[DONE]"}},
+		{"without space", "data:{\"a\":1}\n\ndata:[DONE]\n\n", []string{`{"a":1}`, "[DONE]"}},
+		{"only one space trimmed", "data:  x\n\n", []string{" x"}},
+		{"empty data skipped", "data:\n\ndata: \n\ndata: x\n\n", []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			if err := readSSE(strings.NewReader(tt.body), func(_ string, data []byte, _ int) bool {
+				got = append(got, string(data))
+				return true
+			}); err != nil {
+				t.Fatalf("readSSE: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("data = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSSEStreamWithoutSpace(t *testing.T) {
+	body := strings.ReplaceAll(string(contentStream(3)), "data: ", "data:")
+	result, err := parseSSEStream(strings.NewReader(body), time.Now())
+	if err != nil {
+		t.Fatalf("parseSSEStream: %v", err)
+	}
+	if !result.Done {
+		t.Error("stream not marked done")
+	}
+	if want := "token 0 token 1 token 2 "; result.Content != want {
+		t.Errorf("content = %q, want %q", result.Content, want)
+	}
+	if len(result.Violations) > 0 {
+		t.Errorf("violations: %v", result.Violations)
+	}
+}
+
+func TestParseSSEStreamViolations(t *testing.T) {
+	chunk := `data: {"id":"c","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n"
+	tests := []struct {
+		name    string
+		body    string
+		kind    string
+		line    int
+		message string
+	}{
+		{
+			name:    "error event",
+			body:    chunk + "event: error\ndata: {\"error\":{\"message\":\"overloaded\"}}\n\n" + chunk,
+			kind:    ViolationErrorEvent,
+			line:    4,
+			message: "error event: overloaded",
+		},
+		{
+			name:    "error payload",
+			body:    chunk + "data: {\"error\":{\"message\":\"context length exceeded\"}}\n\n" + chunk,
+			kind:    ViolationErrorPayload,
+			line:    3,
+			message: "error in stream: context length exceeded",
+		},
+		{
+			name:    "non-JSON data",
+			body:    chunk + "data: keep-alive\n\n" + chunk,
+			kind:    ViolationNonJSON,
+			line:    3,
+			message: "data is not a JSON chunk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseSSEStream(strings.NewReader(tt.body+"data: [DONE]\n\n"), time.Now())
+			if err != nil {
+				t.Fatalf("parseSSEStream: %v", err)
+			}
+			if len(result.Violations) != 1 {
+				t.Fatalf("got %d violations, want 1: %v", len(result.Violations), result.Violations)
+			}
+			v := result.Violations[0]
+			if v.Kind != tt.kind || v.Line != tt.line || !strings.HasPrefix(v.Message, tt.message) {
+				t.Errorf("violation = %s at line %d: %q, want %s at line %d: %q", v.Kind, v.Line, v.Message, tt.kind, tt.line, tt.message)
+			}
+			// The stream goes on past the violation
+			if !result.Done || result.Content != "HiHi" {
+				t.Errorf("done = %v, content = %q, want the stream parsed to [DONE]", result.Done, result.Content)
+			}
+		})
+	}
+}
+
+func TestParseSSEStreamKeepAlive(t *testing.T) {
+	body := "data:\n\n" + string(contentStream(2))
+	body = strings.Replace(body, "data: [DONE]", "data: \n\ndata: [DONE]", 1)
+	result, err := parseSSEStream(strings.NewReader(body), time.Now())
+	if err != nil {
+		t.Fatalf("parseSSEStream: %v", err)
+	}
+	if len(result.Violations) > 0 {
+		t.Errorf("empty data fields reported as violations: %v", result.Violations)
+	}
+	if want := "token 0 token 1 "; result.Content != want {
+		t.Errorf("content = %q, want %q", result.Content, want)
+	}
+}
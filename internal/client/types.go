@@ -4,15 +4,35 @@ import "encoding/json"
 
 // ChatCompletionRequest represents a chat completion request.
 type ChatCompletionRequest struct {
-	Model             string          `json:"model"`
-	Messages          []Message       `json:"messages"`
-	Tools             []Tool          `json:"tools,omitempty"`
-	ToolChoice        any             `json:"tool_choice,omitempty"`
-	ParallelToolCalls bool            `json:"parallel_tool_calls,omitempty"`
-	ResponseFormat    *ResponseFormat `json:"response_format,omitempty"`
-	Stream            bool            `json:"stream,omitempty"`
-	StreamOptions     *StreamOptions  `json:"stream_options,omitempty"`
-	MaxTokens         int             `json:"max_tokens,omitempty"`
+	Model               string          `json:"model"`
+	Messages            []Message       `json:"messages"`
+	Tools               []Tool          `json:"tools,omitempty"`
+	ToolChoice          any             `json:"tool_choice,omitempty"`
+	ParallelToolCalls   bool            `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+	Stream              bool            `json:"stream,omitempty"`
+	StreamOptions       *StreamOptions  `json:"stream_options,omitempty"`
+	MaxTokens           int             `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Logprobs            bool            `json:"logprobs,omitempty"`
+	TopLogprobs         int             `json:"top_logprobs,omitempty"`
+	N                   int             `json:"n,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"`
+	Seed                *int            `json:"seed,omitempty"`
+	// Stop lists sequences at which generation stops, excluded from the
+	// response.
+	Stop []string `json:"stop,omitempty"`
+	// LogitBias maps token ids, as decimal strings, to a bias from -100
+	// (ban) to 100 (force) added to their logits.
+	LogitBias map[string]float64 `json:"logit_bias,omitempty"`
+	// ContinueFinalMessage asks vLLM to continue a trailing assistant
+	// message (prefill) instead of starting a new turn. It requires
+	// AddGenerationPrompt to be false.
+	ContinueFinalMessage bool  `json:"continue_final_message,omitempty"`
+	AddGenerationPrompt  *bool `json:"add_generation_prompt,omitempty"`
 
 	// Extra contains additional fields to include in the request JSON.
 	// These are flattened into the root of the request object.
@@ -25,7 +45,10 @@ func (r ChatCompletionRequest) MarshalJSON() ([]byte, error) {
 	m := make(map[string]any)
 
 	m["model"] = r.Model
-	m["messages"] = r.Messages
+	// Nil messages are omitted, for requests testing a missing field
+	if r.Messages != nil {
+		m["messages"] = r.Messages
+	}
 
 	if len(r.Tools) > 0 {
 		m["tools"] = r.Tools
@@ -48,6 +71,45 @@ func (r ChatCompletionRequest) MarshalJSON() ([]byte, error) {
 	if r.MaxTokens > 0 {
 		m["max_tokens"] = r.MaxTokens
 	}
+	if r.MaxCompletionTokens > 0 {
+		m["max_completion_tokens"] = r.MaxCompletionTokens
+	}
+	if r.Logprobs {
+		m["logprobs"] = r.Logprobs
+	}
+	if r.TopLogprobs > 0 {
+		m["top_logprobs"] = r.TopLogprobs
+	}
+	if r.N > 0 {
+		m["n"] = r.N
+	}
+	if r.Temperature != nil {
+		m["temperature"] = *r.Temperature
+	}
+	if r.TopP != nil {
+		m["top_p"] = *r.TopP
+	}
+	if r.PresencePenalty != nil {
+		m["presence_penalty"] = *r.PresencePenalty
+	}
+	if r.FrequencyPenalty != nil {
+		m["frequency_penalty"] = *r.FrequencyPenalty
+	}
+	if r.Seed != nil {
+		m["seed"] = *r.Seed
+	}
+	if len(r.Stop) > 0 {
+		m["stop"] = r.Stop
+	}
+	if len(r.LogitBias) > 0 {
+		m["logit_bias"] = r.LogitBias
+	}
+	if r.ContinueFinalMessage {
+		m["continue_final_message"] = r.ContinueFinalMessage
+	}
+	if r.AddGenerationPrompt != nil {
+		m["add_generation_prompt"] = *r.AddGenerationPrompt
+	}
 
 	// Merge extra fields (they can override standard fields if needed)
 	for k, v := range r.Extra {
@@ -64,6 +126,56 @@ type Message struct {
 	ReasoningContent string     `json:"reasoning_content,omitempty"`
 	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID       string     `json:"tool_call_id,omitempty"`
+
+	// ContentParts, if set, is sent as the content instead of Content, for
+	// content such as images that is not text.
+	ContentParts []ContentPart `json:"-"`
+}
+
+// ContentPart is one part of message content given as an array.
+type ContentPart struct {
+	Type     string    `json:"type"` // "text" or "image_url"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL is the image of an image_url content part: a URL, or a data URL
+// holding the image itself.
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// message is Message without its JSON methods.
+type message Message
+
+// MarshalJSON encodes ContentParts, if set, as the content.
+func (m Message) MarshalJSON() ([]byte, error) {
+	if len(m.ContentParts) == 0 {
+		return json.Marshal(message(m))
+	}
+	return json.Marshal(struct {
+		message
+		Content []ContentPart `json:"content"`
+	}{message(m), m.ContentParts})
+}
+
+// UnmarshalJSON decodes content given as an array into ContentParts.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var v struct {
+		message
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*m = Message(v.message)
+	if len(v.Content) > 0 && v.Content[0] == '[' {
+		return json.Unmarshal(v.Content, &m.ContentParts)
+	}
+	if len(v.Content) > 0 && string(v.Content) != "null" {
+		return json.Unmarshal(v.Content, &m.Content)
+	}
+	return nil
 }
 
 // Tool represents a function tool definition.
@@ -103,7 +215,8 @@ type JSONSchema struct {
 	Name        string          `json:"name"`
 	Description string          `json:"description,omitempty"`
 	Schema      json.RawMessage `json:"schema"`
-	Strict      bool            `json:"strict,omitempty"`
+	// Strict is sent if set, so that false can be sent explicitly.
+	Strict *bool `json:"strict,omitempty"`
 }
 
 // StreamOptions configures streaming behavior.
@@ -119,6 +232,13 @@ type ChatCompletionResponse struct {
 	Model   string   `json:"model"`
 	Choices []Choice `json:"choices"`
 	Usage   *Usage   `json:"usage,omitempty"`
+	// Timings is llama.cpp's non-standard performance report.
+	Timings *Timings `json:"timings,omitempty"`
+	// Provider is the upstream provider an OpenRouter request was routed to.
+	Provider string `json:"provider,omitempty"`
+
+	// Headers holds selected response headers, keyed by lowercase name.
+	Headers map[string]string `json:"-"`
 }
 
 // Choice represents a completion choice.
@@ -126,6 +246,31 @@ type Choice struct {
 	Index        int             `json:"index"`
 	Message      ResponseMessage `json:"message"`
 	FinishReason string          `json:"finish_reason"`
+	Logprobs     *Logprobs       `json:"logprobs,omitempty"`
+	// NativeFinishReason is the provider's own finish reason, which
+	// OpenRouter reports alongside the normalized one.
+	NativeFinishReason string `json:"native_finish_reason,omitempty"`
+}
+
+// Logprobs holds the log probabilities of generated tokens.
+type Logprobs struct {
+	Content []TokenLogprob `json:"content"`
+}
+
+// TokenLogprob is the log probability of one generated token, with the most
+// likely alternatives when top_logprobs was requested.
+type TokenLogprob struct {
+	Token       string       `json:"token"`
+	Logprob     float64      `json:"logprob"`
+	Bytes       []int        `json:"bytes,omitempty"`
+	TopLogprobs []TopLogprob `json:"top_logprobs"`
+}
+
+// TopLogprob is a candidate token and its log probability.
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
 }
 
 // ResponseMessage represents the message in a response.
@@ -143,6 +288,21 @@ type Usage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
+// Timings is the performance report llama.cpp adds to responses, and to
+// the final chunk of a stream.
+type Timings struct {
+	// CacheN is the number of prompt tokens reused from the cache.
+	CacheN              int     `json:"cache_n,omitempty"`
+	PromptN             int     `json:"prompt_n"`
+	PromptMS            float64 `json:"prompt_ms"`
+	PromptPerTokenMS    float64 `json:"prompt_per_token_ms"`
+	PromptPerSecond     float64 `json:"prompt_per_second"`
+	PredictedN          int     `json:"predicted_n"`
+	PredictedMS         float64 `json:"predicted_ms"`
+	PredictedPerTokenMS float64 `json:"predicted_per_token_ms"`
+	PredictedPerSecond  float64 `json:"predicted_per_second"`
+}
+
 // ChatCompletionChunk represents a streaming response chunk.
 type ChatCompletionChunk struct {
 	ID      string        `json:"id"`
@@ -151,6 +311,9 @@ type ChatCompletionChunk struct {
 	Model   string        `json:"model"`
 	Choices []ChunkChoice `json:"choices"`
 	Usage   *Usage        `json:"usage,omitempty"`
+	Timings *Timings      `json:"timings,omitempty"`
+	// Provider is the upstream provider an OpenRouter request was routed to.
+	Provider string `json:"provider,omitempty"`
 }
 
 // ChunkChoice represents a choice in a streaming chunk.
@@ -158,6 +321,10 @@ type ChunkChoice struct {
 	Index        int        `json:"index"`
 	Delta        ChunkDelta `json:"delta"`
 	FinishReason *string    `json:"finish_reason"`
+	Logprobs     *Logprobs  `json:"logprobs,omitempty"`
+	// NativeFinishReason is the provider's own finish reason, which
+	// OpenRouter reports alongside the normalized one.
+	NativeFinishReason *string `json:"native_finish_reason,omitempty"`
 }
 
 // ChunkDelta represents the delta content in a streaming chunk.
@@ -192,3 +359,13 @@ type ApplyTemplateRequest struct {
 type ApplyTemplateResponse struct {
 	Prompt string `json:"prompt"`
 }
+
+// TokenizeRequest represents a request to the /tokenize endpoint.
+type TokenizeRequest struct {
+	Content string `json:"content"`
+}
+
+// TokenizeResponse represents a response from the /tokenize endpoint.
+type TokenizeResponse struct {
+	Tokens []int `json:"tokens"`
+}
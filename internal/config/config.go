@@ -0,0 +1,97 @@
+// Package config loads the optional llm-serve-test configuration file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config is the contents of the configuration file.
+type Config struct {
+	// Schema is the optional JSON Schema reference used by editors.
+	Schema string `json:"$schema,omitempty" description:"JSON Schema reference for editor support"`
+	// Suites defines named eval suites. A suite with the same name as a
+	// built-in suite replaces it.
+	Suites map[string]Suite `json:"suites,omitempty" description:"Named eval suites; a suite named like a built-in (smoke, full, nightly) replaces it"`
+	// Redact lists regular expressions whose matches are masked in logs
+	// and reports, in addition to API keys.
+	Redact []string `json:"redact,omitempty" description:"Regular expressions whose matches are masked as *** in logs and reports"`
+	// Disable skips categories or evals that do not apply to a deployment,
+	// with a reason shown in output and reports.
+	Disable Disable `json:"disable,omitempty" description:"Categories and evals to skip, with the reason shown as SKIPPED(reason)"`
+	// Templates declares, per model family, the markers its chat template
+	// must wrap reasoning and tool calls in, for template_conformance.
+	Templates map[string]TemplateMarkers `json:"templates,omitempty" description:"Expected chat template markers per model family; a family applies to models whose name contains it, unless --template-family names one"`
+	// OutputScan declares custom regex packs and the moderation endpoint
+	// for the output scanners chosen with --scan-output.
+	OutputScan OutputScan `json:"output_scan,omitempty" description:"Custom regex packs and the moderation endpoint for --scan-output"`
+	// SLOs declares latency objectives; evals exceeding them pass with SLO
+	// violations, which fail the run.
+	SLOs SLOs `json:"slos,omitempty" description:"Latency objectives per eval class, category, or eval; evals exceeding them pass with SLO violations, and the run exits 1"`
+}
+
+// DefaultPath returns the configuration file path used when --config is
+// not given.
+func DefaultPath() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(base, "llm-serve-test", "config.json"), nil
+}
+
+// Load reads the configuration file at path. If path is empty, the default
+// path is used, and a missing file yields an empty configuration.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return &Config{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	// Reject unknown fields so that typos fail up front
+	var cfg Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Duration is a time.Duration that is encoded in JSON as a string such as
+// "30s" or "5m".
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,31 @@
+package config
+
+import "github.com/aldehir/llm-serving-tests/internal/eval"
+
+// Disable turns off whole categories or single evals, each with the
+// reason shown when it is skipped.
+type Disable struct {
+	Categories map[string]string `json:"categories,omitempty" description:"Categories to skip, mapped to the reason shown, e.g. {\"Vision\": \"vision not deployed here\"}"`
+	Evals      map[string]string `json:"evals,omitempty" description:"Evals to skip, mapped to the reason shown"`
+}
+
+// Disabled returns the categories and evals to skip.
+func (c *Config) Disabled() eval.Disabled {
+	return eval.Disabled{
+		Categories: c.Disable.Categories,
+		Evals:      c.Disable.Evals,
+	}
+}
+
+// categoryNames returns the categories of all registered evals.
+func categoryNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, e := range eval.AllEvals() {
+		if !seen[e.Category()] {
+			seen[e.Category()] = true
+			names = append(names, e.Category())
+		}
+	}
+	return names
+}
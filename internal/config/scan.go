@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
+)
+
+// OutputScan declares custom regex packs and the moderation endpoint for
+// the output scanners chosen with --scan-output.
+type OutputScan struct {
+	Packs      map[string]map[string]string `json:"packs,omitempty" description:"Custom regex packs, mapping a pack name to the kinds of text it flags and their regular expressions"`
+	Moderation Moderation                   `json:"moderation,omitempty" description:"OpenAI-compatible moderation endpoint for the moderation pack"`
+}
+
+// Moderation configures the moderation endpoint scanner.
+type Moderation struct {
+	URL       string `json:"url,omitempty" description:"Moderation endpoint URL, e.g. https://api.openai.com/v1/moderations"`
+	Model     string `json:"model,omitempty" description:"Moderation model (default: the endpoint's)"`
+	APIKeyEnv string `json:"api_key_env,omitempty" description:"Environment variable holding the endpoint's API key"`
+}
+
+// ModerationAPIKey returns the moderation endpoint's API key, read from
+// the environment variable the config file names.
+func (c *Config) ModerationAPIKey() string {
+	if c.OutputScan.Moderation.APIKeyEnv == "" {
+		return ""
+	}
+	return os.Getenv(c.OutputScan.Moderation.APIKeyEnv)
+}
+
+// OutputScanners returns the named output scanners: built-in packs, packs
+// declared in the config file, or the moderation endpoint.
+func (c *Config) OutputScanners(names []string) ([]hygiene.Scanner, error) {
+	var scanners []hygiene.Scanner
+	for _, name := range names {
+		switch {
+		case name == hygiene.ModerationPack:
+			m := c.OutputScan.Moderation
+			if m.URL == "" {
+				return nil, fmt.Errorf("pack %q needs output_scan.moderation.url in the config file", name)
+			}
+			scanners = append(scanners, hygiene.NewModerationScanner(m.URL, c.ModerationAPIKey(), m.Model))
+		case c.OutputScan.Packs[name] != nil:
+			s, err := hygiene.NewRegexScanner(name, c.OutputScan.Packs[name])
+			if err != nil {
+				return nil, err
+			}
+			scanners = append(scanners, s)
+		case slices.Contains(hygiene.Packs(), name):
+			s, err := hygiene.NewPack(name)
+			if err != nil {
+				return nil, err
+			}
+			scanners = append(scanners, s)
+		default:
+			return nil, fmt.Errorf("unknown pack %q (valid: %s)", name, strings.Join(c.scanPackNames(), ", "))
+		}
+	}
+	return scanners, nil
+}
+
+// scanPackNames returns the names of the built-in packs, the moderation
+// pack, and the packs declared in the config file.
+func (c *Config) scanPackNames() []string {
+	names := append(hygiene.Packs(), hygiene.ModerationPack)
+	names = append(names, sortedKeys(c.OutputScan.Packs)...)
+	return names
+}
@@ -0,0 +1,118 @@
+package config
+
+//go:generate go run ../../cmd/llm-serve-test config-schema -o ../../schema/config.schema.json
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// schemaID is the URL the published schema is served from.
+const schemaID = "https://raw.githubusercontent.com/aldehir/llm-serving-tests/main/schema/config.schema.json"
+
+// enumSources provide the allowed values for fields tagged with enum:"<name>".
+var enumSources = map[string]func() []string{
+	"modes":   eval.AllModes,
+	"classes": eval.AllClasses,
+	"flavors": eval.AllFlavors,
+	"evals":   evalNames,
+	"tags":    eval.AllTags,
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// Schema returns a JSON Schema for the configuration file, generated from
+// the Config type. Field descriptions come from `description` struct tags.
+func Schema() map[string]any {
+	s := typeSchema(reflect.TypeOf(Config{}))
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	s["$id"] = schemaID
+	s["title"] = "llm-serve-test configuration"
+	return s
+}
+
+// typeSchema returns the schema for a Go type.
+func typeSchema(t reflect.Type) map[string]any {
+	if t == durationType {
+		return map[string]any{
+			"type":    "string",
+			"pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]any)
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" || name == "" {
+				continue
+			}
+			props[name] = fieldSchema(f)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Slice:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	panic("config schema: unsupported type " + t.String())
+}
+
+// fieldSchema returns the schema for a struct field, applying its
+// description, enum, minimum, and maximum tags.
+func fieldSchema(f reflect.StructField) map[string]any {
+	s := typeSchema(f.Type)
+	if d := f.Tag.Get("description"); d != "" {
+		s["description"] = d
+	}
+	if src := f.Tag.Get("enum"); src != "" {
+		// Enums on slices constrain the items
+		target := s
+		if items, ok := s["items"].(map[string]any); ok {
+			target = items
+		}
+		target["enum"] = enumSources[src]()
+	}
+	for _, key := range []string{"minimum", "maximum"} {
+		if v := f.Tag.Get(key); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				panic("config schema: invalid " + key + " tag on " + f.Name)
+			}
+			s[key] = n
+		}
+	}
+	return s
+}
+
+// evalNames returns the names of all registered evals.
+func evalNames() []string {
+	var names []string
+	for _, e := range eval.AllEvals() {
+		names = append(names, e.Name())
+	}
+	return names
+}
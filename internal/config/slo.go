@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// SLOs declares latency objectives per eval class, category, or eval. An
+// eval's own entry overrides its category's, which overrides its class's,
+// limit by limit.
+type SLOs struct {
+	Classes    map[string]SLO `json:"classes,omitempty" description:"Latency objectives per eval class, e.g. {\"interleaved\": {\"total\": \"120s\"}}"`
+	Categories map[string]SLO `json:"categories,omitempty" description:"Latency objectives per category, e.g. {\"Basic\": {\"ttft\": \"2s\"}}"`
+	Evals      map[string]SLO `json:"evals,omitempty" description:"Latency objectives per eval"`
+}
+
+// SLO is a latency objective. Unset limits are not checked.
+type SLO struct {
+	TTFT  Duration `json:"ttft,omitempty" description:"Longest time to first token, checked in streaming mode, e.g. \"2s\""`
+	Total Duration `json:"total,omitempty" description:"Longest duration of one run of the eval, e.g. \"120s\""`
+}
+
+// Objectives returns the latency objectives evals are checked against.
+func (c *Config) Objectives() eval.SLOs {
+	convert := func(m map[string]SLO) map[string]eval.SLO {
+		if m == nil {
+			return nil
+		}
+		out := make(map[string]eval.SLO, len(m))
+		for name, slo := range m {
+			out[name] = eval.SLO{TTFT: time.Duration(slo.TTFT), Total: time.Duration(slo.Total)}
+		}
+		return out
+	}
+	return eval.SLOs{
+		Classes:    convert(c.SLOs.Classes),
+		Categories: convert(c.SLOs.Categories),
+		Evals:      convert(c.SLOs.Evals),
+	}
+}
+
+// validateSLOs checks that each objective names a known class, category,
+// or eval and sets a positive limit.
+func (c *Config) validateSLOs() []error {
+	var errs []error
+	check := func(kind, noun string, known []string, m map[string]SLO) {
+		for _, name := range sortedKeys(m) {
+			prefix := fmt.Sprintf("slos.%s.%s", kind, name)
+			if !slices.Contains(known, name) {
+				errs = append(errs, fmt.Errorf("slos.%s: unknown %s %q", kind, noun, name))
+			}
+			slo := m[name]
+			if slo.TTFT == 0 && slo.Total == 0 {
+				errs = append(errs, fmt.Errorf("%s: sets no limit", prefix))
+			}
+			if slo.TTFT < 0 {
+				errs = append(errs, fmt.Errorf("%s.ttft: must be positive", prefix))
+			}
+			if slo.Total < 0 {
+				errs = append(errs, fmt.Errorf("%s.total: must be positive", prefix))
+			}
+		}
+	}
+	check("classes", "class", eval.AllClasses(), c.SLOs.Classes)
+	check("categories", "category", categoryNames(), c.SLOs.Categories)
+	check("evals", "eval", evalNames(), c.SLOs.Evals)
+	return errs
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Suite is a named eval subset with run parameters. Zero-valued fields
+// leave the corresponding flag default unchanged, and flags given
+// explicitly on the command line override the suite.
+type Suite struct {
+	Description string `json:"description,omitempty" description:"Human-readable summary of the suite"`
+	// Evals lists eval names to run. Empty runs every eval that matches
+	// the other filters.
+	Evals []string `json:"evals,omitempty" enum:"evals" description:"Evals to run; empty runs all evals matching the other settings"`
+	// All includes evals that are disabled by default.
+	All bool `json:"all,omitempty" description:"Include evals that are disabled by default"`
+	// Vision includes evals that send images.
+	Vision bool   `json:"vision,omitempty" description:"Include evals that send images"`
+	Class  string `json:"class,omitempty" enum:"classes" description:"Run only evals of this class"`
+	Mode   string `json:"mode,omitempty" enum:"modes" description:"Request mode"`
+	// Flavor adds evals of a server's extensions.
+	Flavor string `json:"flavor,omitempty" enum:"flavors" description:"Server flavor, adding evals of its extensions"`
+	// Tags and SkipTags select eval runs by tag, as --tag and --skip-tag.
+	Tags     []string `json:"tags,omitempty" enum:"tags" description:"Run only evals with all of these tags"`
+	SkipTags []string `json:"skip_tags,omitempty" enum:"tags" description:"Skip evals with any of these tags"`
+	// Timeout is the per-request timeout.
+	Timeout       Duration `json:"timeout,omitempty" description:"Request timeout, e.g. \"30s\""`
+	Repeat        int      `json:"repeat,omitempty" minimum:"0" description:"Run each eval this many times"`
+	PassThreshold float64  `json:"pass_threshold,omitempty" minimum:"0" maximum:"1" description:"Fraction of repeated runs that must pass"`
+}
+
+// builtinSuites are the suites available without a configuration file.
+var builtinSuites = map[string]Suite{
+	"smoke": {
+		Description: "Quick check of basic completions and tool calling",
+		Evals:       []string{"chat_completion", "single_tool_call"},
+		Timeout:     Duration(10 * time.Second),
+	},
+	"full": {
+		Description: "All evals that are enabled by default",
+	},
+	"nightly": {
+		Description:   "Every eval, including disabled-by-default stress tests, repeated to catch flakiness",
+		All:           true,
+		Repeat:        3,
+		PassThreshold: 2.0 / 3.0,
+	},
+}
+
+// Suite returns the named suite, preferring suites from the configuration
+// file over built-in suites.
+func (c *Config) Suite(name string) (Suite, error) {
+	if s, ok := c.Suites[name]; ok {
+		return s, nil
+	}
+	if s, ok := builtinSuites[name]; ok {
+		return s, nil
+	}
+	return Suite{}, fmt.Errorf("unknown suite %q (available: %s)", name, strings.Join(c.SuiteNames(), ", "))
+}
+
+// SuiteNames returns the names of all available suites, sorted.
+func (c *Config) SuiteNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range []map[string]Suite{builtinSuites, c.Suites} {
+		for name := range m {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// TemplateMarkers declares the markers a model family's chat template must
+// wrap parts of a conversation in. Undeclared markers are not checked.
+type TemplateMarkers struct {
+	Reasoning Markers `json:"reasoning,omitempty" description:"Markers that must wrap reasoning_content, e.g. <think> and </think>"`
+	ToolCall  Markers `json:"tool_call,omitempty" description:"Markers that must wrap each tool call, e.g. <tool_call> and </tool_call>"`
+	// InterleavedReasoning lets reasoning_stream_order accept reasoning
+	// that resumes after a tool call.
+	InterleavedReasoning bool `json:"interleaved_reasoning,omitempty" description:"The family's models may resume reasoning after a tool call within one streamed response"`
+}
+
+// Markers is a pair of opening and closing markers.
+type Markers struct {
+	Open  string `json:"open,omitempty" description:"Opening marker"`
+	Close string `json:"close,omitempty" description:"Closing marker"`
+}
+
+// TemplateMarkers returns the template markers declared for a model
+// family. If family is empty, it is the longest declared family name
+// contained in the model name, ignoring case. It returns nil if no family
+// applies, and an error if the named family is not declared.
+func (c *Config) TemplateMarkers(model, family string) (*eval.TemplateMarkers, error) {
+	if family == "" {
+		family = matchFamily(c.Templates, model)
+		if family == "" {
+			return nil, nil
+		}
+	}
+
+	t, ok := c.Templates[family]
+	if !ok {
+		names := make([]string, 0, len(c.Templates))
+		for name := range c.Templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown template family %q: the config file declares no templates", family)
+		}
+		return nil, fmt.Errorf("unknown template family %q (declared: %s)", family, strings.Join(names, ", "))
+	}
+
+	return &eval.TemplateMarkers{
+		Family:         family,
+		ReasoningOpen:  t.Reasoning.Open,
+		ReasoningClose: t.Reasoning.Close,
+		ToolCallOpen:   t.ToolCall.Open,
+		ToolCallClose:  t.ToolCall.Close,
+
+		InterleavedReasoning: t.InterleavedReasoning,
+	}, nil
+}
+
+// matchFamily returns the longest family name contained in the model name,
+// ignoring case, or "" if there is none.
+func matchFamily(templates map[string]TemplateMarkers, model string) string {
+	model = strings.ToLower(model)
+	var best string
+	for name := range templates {
+		if !strings.Contains(model, strings.ToLower(name)) {
+			continue
+		}
+		// Break ties by name so that the choice does not depend on map order
+		if len(name) > len(best) || (len(name) == len(best) && name < best) {
+			best = name
+		}
+	}
+	return best
+}
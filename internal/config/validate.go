@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
+)
+
+// Validate checks the configuration for values that would fail or be
+// ignored at run time, returning one error per problem.
+func (c *Config) Validate() []error {
+	var errs []error
+	known := evalNames()
+
+	names := make([]string, 0, len(c.Suites))
+	for name := range c.Suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := c.Suites[name]
+		prefix := fmt.Sprintf("suites.%s", name)
+
+		for _, e := range s.Evals {
+			if !slices.Contains(known, e) {
+				errs = append(errs, fmt.Errorf("%s.evals: unknown eval %q", prefix, e))
+			}
+		}
+		if s.Class != "" && !slices.Contains(eval.AllClasses(), s.Class) {
+			errs = append(errs, fmt.Errorf("%s.class: invalid class %q", prefix, s.Class))
+		}
+		if s.Mode != "" && !slices.Contains(eval.AllModes(), s.Mode) {
+			errs = append(errs, fmt.Errorf("%s.mode: invalid mode %q", prefix, s.Mode))
+		}
+		if s.Flavor != "" && !slices.Contains(eval.AllFlavors(), s.Flavor) {
+			errs = append(errs, fmt.Errorf("%s.flavor: invalid flavor %q", prefix, s.Flavor))
+		}
+		for _, t := range s.Tags {
+			if !slices.Contains(eval.AllTags(), t) {
+				errs = append(errs, fmt.Errorf("%s.tags: unknown tag %q", prefix, t))
+			}
+		}
+		for _, t := range s.SkipTags {
+			if !slices.Contains(eval.AllTags(), t) {
+				errs = append(errs, fmt.Errorf("%s.skip_tags: unknown tag %q", prefix, t))
+			}
+		}
+		if s.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("%s.timeout: must not be negative", prefix))
+		}
+		if s.Repeat < 0 {
+			errs = append(errs, fmt.Errorf("%s.repeat: must not be negative", prefix))
+		}
+		if s.PassThreshold < 0 || s.PassThreshold > 1 {
+			errs = append(errs, fmt.Errorf("%s.pass_threshold: must be between 0 and 1", prefix))
+		}
+	}
+
+	for i, p := range c.Redact {
+		if _, err := regexp.Compile(p); err != nil {
+			errs = append(errs, fmt.Errorf("redact[%d]: invalid pattern %q: %v", i, p, err))
+		}
+	}
+
+	reserved := append(hygiene.Packs(), hygiene.ModerationPack)
+	for _, name := range sortedKeys(c.OutputScan.Packs) {
+		prefix := fmt.Sprintf("output_scan.packs.%s", name)
+		if slices.Contains(reserved, name) {
+			errs = append(errs, fmt.Errorf("%s: name taken by a built-in pack", prefix))
+		}
+		if len(c.OutputScan.Packs[name]) == 0 {
+			errs = append(errs, fmt.Errorf("%s: declares no patterns", prefix))
+		}
+		for _, kind := range sortedKeys(c.OutputScan.Packs[name]) {
+			if p := c.OutputScan.Packs[name][kind]; p == "" {
+				errs = append(errs, fmt.Errorf("%s.%s: pattern must not be empty", prefix, kind))
+			} else if _, err := regexp.Compile(p); err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s: invalid pattern %q: %v", prefix, kind, p, err))
+			}
+		}
+	}
+	if m := c.OutputScan.Moderation; m.URL == "" && (m.Model != "" || m.APIKeyEnv != "") {
+		errs = append(errs, fmt.Errorf("output_scan.moderation: url is required"))
+	}
+
+	errs = append(errs, c.validateSLOs()...)
+
+	categories := categoryNames()
+	for _, name := range sortedKeys(c.Disable.Categories) {
+		if !slices.Contains(categories, name) {
+			errs = append(errs, fmt.Errorf("disable.categories: unknown category %q", name))
+		}
+		if c.Disable.Categories[name] == "" {
+			errs = append(errs, fmt.Errorf("disable.categories.%s: reason must not be empty", name))
+		}
+	}
+	for _, name := range sortedKeys(c.Disable.Evals) {
+		if !slices.Contains(known, name) {
+			errs = append(errs, fmt.Errorf("disable.evals: unknown eval %q", name))
+		}
+		if c.Disable.Evals[name] == "" {
+			errs = append(errs, fmt.Errorf("disable.evals.%s: reason must not be empty", name))
+		}
+	}
+
+	families := make([]string, 0, len(c.Templates))
+	for name := range c.Templates {
+		families = append(families, name)
+	}
+	sort.Strings(families)
+
+	for _, name := range families {
+		t := c.Templates[name]
+		prefix := fmt.Sprintf("templates.%s", name)
+
+		if t.Reasoning == (Markers{}) && t.ToolCall == (Markers{}) && !t.InterleavedReasoning {
+			errs = append(errs, fmt.Errorf("%s: declares no markers", prefix))
+		}
+		if (t.Reasoning.Open == "") != (t.Reasoning.Close == "") {
+			errs = append(errs, fmt.Errorf("%s.reasoning: open and close must be given together", prefix))
+		}
+		if (t.ToolCall.Open == "") != (t.ToolCall.Close == "") {
+			errs = append(errs, fmt.Errorf("%s.tool_call: open and close must be given together", prefix))
+		}
+	}
+
+	return errs
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,273 @@
+// Package drift measures the numerical drift between two servers from the
+// log probabilities they assign to the tokens of the same greedy
+// generations.
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// seed is the seed of every request, so that servers sampling despite
+// temperature 0 still sample alike.
+const seed = 42
+
+// conversations is the fixed conversation set sent to both servers. They
+// mix short factual answers, where the model is confident, with open-ended
+// prose and multi-turn context, where drift shows first.
+var conversations = [][]client.Message{
+	{
+		{Role: "user", Content: "What is the capital of Australia? Answer in one sentence."},
+	},
+	{
+		{Role: "user", Content: "Write a four-line poem about the sea."},
+	},
+	{
+		{Role: "user", Content: "Explain how a hash map works, including collision handling and resizing."},
+	},
+	{
+		{Role: "system", Content: "You are a concise assistant. Answer in at most three sentences."},
+		{Role: "user", Content: "Why is the sky blue?"},
+	},
+	{
+		{Role: "user", Content: "List the first ten prime numbers, separated by commas."},
+	},
+	{
+		{Role: "user", Content: "My name is Priya and I keep bees."},
+		{Role: "assistant", Content: "Nice to meet you, Priya! Beekeeping is a rewarding hobby. How many hives do you have?"},
+		{Role: "user", Content: "Three. What should I check when I inspect them in spring?"},
+	},
+	{
+		{Role: "user", Content: "Translate to French: The library opens at nine and closes at six."},
+	},
+	{
+		{Role: "user", Content: "Write a Python function that returns the n-th Fibonacci number."},
+	},
+}
+
+// Config configures a drift measurement.
+type Config struct {
+	// MaxTokens caps the completion length of each request.
+	MaxTokens int
+	// TopLogprobs is the number of most likely tokens requested at each
+	// position, compared between the servers.
+	TopLogprobs int
+}
+
+// Conversation is the comparison of the two servers' generations for one
+// conversation.
+type Conversation struct {
+	// Prompt is the conversation's last message.
+	Prompt string
+	// Tokens is the number of positions compared: the tokens the
+	// generations share, and the position they diverge at, if the second
+	// server ranked the first server's token there.
+	Tokens int
+	// DivergedAt is the index of the first token at which the generations
+	// differ, or -1 if they are identical.
+	DivergedAt int
+	// Deltas are the absolute differences between the servers' log
+	// probabilities of each compared token.
+	Deltas []float64
+	// TopOverlap is the mean fraction of the top tokens at each compared
+	// position that both servers ranked.
+	TopOverlap float64
+	Err        error
+}
+
+// Stats summarizes a distribution of logprob differences.
+type Stats struct {
+	Mean float64
+	P50  float64
+	P95  float64
+	P99  float64
+	Max  float64
+}
+
+// Summary aggregates the comparisons of a drift measurement.
+type Summary struct {
+	Conversations []Conversation
+	Errors        int
+	// FirstError is the first request error, for diagnosing failed runs.
+	FirstError error
+	// Identical is the number of conversations whose generations were
+	// identical.
+	Identical int
+	// Tokens is the number of positions compared across conversations.
+	Tokens int
+	Deltas Stats
+	// TopOverlap is the mean overlap of the top tokens across compared
+	// positions.
+	TopOverlap float64
+}
+
+// Run sends each conversation to both servers, seeded and greedy with
+// logprobs, and compares the log probabilities of their generations token
+// by token. Generations are compared up to the first token they differ at,
+// since the contexts of later tokens differ.
+func Run(ctx context.Context, a, b *client.Client, cfg Config) Summary {
+	var results []Conversation
+	for _, messages := range conversations {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, compare(ctx, a, b, messages, cfg))
+	}
+	return summarize(results)
+}
+
+// compare sends one conversation to both servers and compares their
+// generations.
+func compare(ctx context.Context, a, b *client.Client, messages []client.Message, cfg Config) Conversation {
+	conv := Conversation{Prompt: messages[len(messages)-1].Content, DivergedAt: -1}
+
+	tokensA, err := generate(ctx, a, messages, cfg)
+	if err != nil {
+		conv.Err = fmt.Errorf("server: %w", err)
+		return conv
+	}
+	tokensB, err := generate(ctx, b, messages, cfg)
+	if err != nil {
+		conv.Err = fmt.Errorf("comparison server: %w", err)
+		return conv
+	}
+
+	var overlap float64
+	n := min(len(tokensA), len(tokensB))
+	for i := range n {
+		ta, tb := tokensA[i], tokensB[i]
+		if ta.Token != tb.Token {
+			conv.DivergedAt = i
+			// The context is still shared, so the second server's
+			// probability of the first server's token is comparable
+			if lp, ok := topLogprob(tb, ta.Token); ok {
+				conv.Deltas = append(conv.Deltas, math.Abs(ta.Logprob-lp))
+				overlap += topOverlap(ta, tb)
+			}
+			break
+		}
+		conv.Deltas = append(conv.Deltas, math.Abs(ta.Logprob-tb.Logprob))
+		overlap += topOverlap(ta, tb)
+	}
+	if conv.DivergedAt < 0 && len(tokensA) != len(tokensB) {
+		conv.DivergedAt = n
+	}
+	conv.Tokens = len(conv.Deltas)
+	if conv.Tokens > 0 {
+		conv.TopOverlap = overlap / float64(conv.Tokens)
+	}
+	return conv
+}
+
+// generate sends a conversation and returns the logprobs of the generated
+// tokens.
+func generate(ctx context.Context, c *client.Client, messages []client.Message, cfg Config) ([]client.TokenLogprob, error) {
+	temperature := 0.0
+	s := seed
+	resp, err := c.ChatCompletion(ctx, client.ChatCompletionRequest{
+		Messages:    messages,
+		Temperature: &temperature,
+		Seed:        &s,
+		MaxTokens:   cfg.MaxTokens,
+		Logprobs:    true,
+		TopLogprobs: cfg.TopLogprobs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("no choices in response")
+	}
+	lp := resp.Choices[0].Logprobs
+	if lp == nil || len(lp.Content) == 0 {
+		return nil, errors.New("no logprobs in response")
+	}
+	return lp.Content, nil
+}
+
+// topLogprob returns the log probability a position's top tokens give
+// token.
+func topLogprob(t client.TokenLogprob, token string) (float64, bool) {
+	for _, top := range t.TopLogprobs {
+		if top.Token == token {
+			return top.Logprob, true
+		}
+	}
+	return 0, false
+}
+
+// topOverlap returns the fraction of the top tokens of a position that
+// both servers ranked, or 1 if neither returned top tokens.
+func topOverlap(a, b client.TokenLogprob) float64 {
+	n := max(len(a.TopLogprobs), len(b.TopLogprobs))
+	if n == 0 {
+		return 1
+	}
+	shared := 0
+	for _, top := range a.TopLogprobs {
+		if _, ok := topLogprob(b, top.Token); ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(n)
+}
+
+// summarize aggregates conversation comparisons into a Summary.
+func summarize(results []Conversation) Summary {
+	s := Summary{Conversations: results}
+
+	var deltas []float64
+	var overlap float64
+	for _, r := range results {
+		if r.Err != nil {
+			if s.FirstError == nil {
+				s.FirstError = r.Err
+			}
+			s.Errors++
+			continue
+		}
+		if r.DivergedAt < 0 {
+			s.Identical++
+		}
+		s.Tokens += r.Tokens
+		deltas = append(deltas, r.Deltas...)
+		overlap += r.TopOverlap * float64(r.Tokens)
+	}
+	s.Deltas = stats(deltas)
+	if s.Tokens > 0 {
+		s.TopOverlap = overlap / float64(s.Tokens)
+	}
+	return s
+}
+
+// stats computes the mean, nearest-rank percentiles, and maximum of xs.
+func stats(xs []float64) Stats {
+	if len(xs) == 0 {
+		return Stats{}
+	}
+	sorted := append([]float64(nil), xs...)
+	sort.Float64s(sorted)
+
+	var total float64
+	for _, x := range sorted {
+		total += x
+	}
+
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(i, 0)]
+	}
+
+	return Stats{
+		Mean: total / float64(len(sorted)),
+		P50:  rank(0.50),
+		P95:  rank(0.95),
+		P99:  rank(0.99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
@@ -0,0 +1,159 @@
+package eval
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const accuracyCategory = "Accuracy"
+
+// accuracyQuestion is a math word problem with a numeric ground-truth answer.
+type accuracyQuestion struct {
+	id       string
+	question string
+	answer   float64
+}
+
+// accuracyQuestions is the fixed question set of the accuracy benchmark, in
+// the style of GSM8K: short word problems needing a few reasoning steps.
+var accuracyQuestions = []accuracyQuestion{
+	{"muffins", "A baker makes 24 muffins in each batch. She bakes 7 batches and sells 150 muffins. How many muffins are left?", 18},
+	{"marbles", "Tom has 3 times as many marbles as Jerry. Together they have 64 marbles. How many marbles does Tom have?", 48},
+	{"train_distance", "A train travels at 80 km per hour for 2.5 hours. How many kilometers does it travel?", 200},
+	{"discount_tax", "A shirt costs $40. It is discounted by 25%, then a 10% sales tax is added to the discounted price. What is the final price in dollars?", 33},
+	{"ball_percentage", "A bag holds 5 red, 7 blue, and 8 green balls. What percentage of the balls are blue?", 35},
+	{"workers", "6 workers can build a wall in 10 days. How many days would it take 15 workers working at the same rate?", 4},
+	{"sum_1_to_50", "What is the sum of all integers from 1 to 50?", 1275},
+	{"rectangle_area", "A rectangle has a perimeter of 46 cm and a length of 15 cm. What is its area in square centimeters?", 120},
+	{"reading", "Sara reads 12 pages on Monday, and each day after that she reads 3 more pages than the day before. How many pages has she read in total from Monday through Friday?", 90},
+	{"heads_legs", "A farm has only chickens and cows. There are 30 heads and 74 legs in total. How many cows are there?", 7},
+	{"power_remainder", "What is the remainder when 2^10 is divided by 7?", 2},
+	{"ages", "Alice is 4 years older than Bob. In 6 years, the sum of their ages will be 50. How old is Bob now?", 17},
+	{"pencils", "A store sells pencils at 3 for $1.20. How much do 25 pencils cost in dollars?", 10},
+	{"minutes", "How many minutes are there in 3.5 days?", 5040},
+	{"average", "The average of five numbers is 18. Four of the numbers are 12, 20, 15, and 25. What is the fifth number?", 18},
+	{"leaky_tank", "An empty 500 liter tank is filled at 12 liters per minute while it leaks 2 liters per minute. How many minutes until it is full?", 50},
+	{"eggs", "A dozen eggs costs $3.60. How much do 30 eggs cost in dollars?", 9},
+	{"primes", "How many prime numbers are there between 1 and 30?", 10},
+	{"fuel_cost", "A car travels 15 km per liter of fuel, and fuel costs $1.80 per liter. How much does the fuel for a 450 km trip cost in dollars?", 54},
+	{"population", "A town of 12,000 people grows by 5% in the first year and by 10% in the second year. What is the population after two years?", 13860},
+}
+
+// accuracyInstruction asks for the answer in a form extractNumericAnswer
+// reliably finds.
+const accuracyInstruction = "\n\nSolve the problem. End your response with the final answer on its own line, in the form \"Answer: <number>\"."
+
+// AccuracyEvals returns one eval per accuracy benchmark question. They are
+// run by the accuracy subcommand rather than as part of the test suite.
+func AccuracyEvals() []Eval {
+	evals := make([]Eval, len(accuracyQuestions))
+	for i, q := range accuracyQuestions {
+		evals[i] = &accuracyEval{q: q}
+	}
+	return evals
+}
+
+// accuracyEval asks one benchmark question and checks the final answer.
+type accuracyEval struct {
+	q         accuracyQuestion
+	streaming bool
+}
+
+func (e *accuracyEval) Name() string {
+	return "accuracy_" + e.q.id
+}
+
+func (e *accuracyEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *accuracyEval) Streaming() bool             { return e.streaming }
+
+func (e *accuracyEval) Category() string {
+	return accuracyCategory
+}
+
+func (e *accuracyEval) Class() string {
+	return ClassStandard
+}
+
+func (e *accuracyEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: e.q.question + accuracyInstruction},
+		},
+	}
+
+	var content string
+
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		content = result.Content
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		if len(resp.Choices) == 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeNoChoices,
+				Message:  "no choices in response",
+			}
+		}
+		content = resp.Choices[0].Message.Content
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "content is empty",
+		}
+	}
+
+	if failed := checkNumericAnswer(e, content, e.q.answer); failed != nil {
+		return *failed
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// Accuracy returns the number of correct samples and the total number of
+// samples across results. Repeated evals count each iteration as a sample.
+func Accuracy(results []Result) (correct, total int) {
+	for _, r := range results {
+		if len(r.Iterations) > 0 {
+			correct += PassedIterations(r.Iterations)
+			total += len(r.Iterations)
+			continue
+		}
+		if r.Passed {
+			correct++
+		}
+		total++
+	}
+	return correct, total
+}
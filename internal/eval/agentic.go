@@ -3,9 +3,12 @@ package eval
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/textmetrics"
 )
 
 const agenticCategory = "Agentic"
@@ -19,6 +22,8 @@ func agenticEvals() []Eval {
 		&agenticLongResponseEval{streaming: true},
 		&agenticTemplateRenderingEval{},
 		&agenticIncidentInvestigationEval{streaming: true},
+		&agenticForcedToolChoiceEval{streaming: true},
+		&agenticToolImageEval{streaming: true},
 	}
 }
 
@@ -61,6 +66,10 @@ func (e *agenticToolCallEval) Class() string {
 	return ClassInterleaved
 }
 
+func (e *agenticToolCallEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn}
+}
+
 func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 	// Turn 1: User asks question requiring tool use
 	req1 := client.ChatCompletionRequest{
@@ -81,6 +90,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 1 request failed: " + err.Error(),
 			}
 		}
@@ -93,6 +103,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 1 request failed: " + err.Error(),
 			}
 		}
@@ -101,6 +112,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "turn 1: no choices in response",
 			}
 		}
@@ -114,6 +126,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallMissing,
 			Message:  "turn 1: expected tool call, got none",
 		}
 	}
@@ -124,6 +137,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallWrongName,
 			Message:  "turn 1: expected tool 'get_weather', got '" + tc.Function.Name + "'",
 		}
 	}
@@ -156,6 +170,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 2 request failed: " + err.Error(),
 			}
 		}
@@ -167,6 +182,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 2 request failed: " + err.Error(),
 			}
 		}
@@ -175,6 +191,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "turn 2: no choices in response",
 			}
 		}
@@ -187,6 +204,7 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeContentEmpty,
 			Message:  "turn 2: expected content in response, got empty",
 		}
 	}
@@ -198,6 +216,13 @@ func (e *agenticToolCallEval) Run(ctx context.Context, c *client.Client) Result
 	}
 }
 
+// renderedTemplate attaches a prompt rendered by /apply-template to a
+// failed result. The report omits /apply-template exchanges from the
+// conversation, so the artifact is where the rendering can be inspected.
+func renderedTemplate(prompt string) []Artifact {
+	return []Artifact{{Name: "rendered-template.txt", Data: []byte(prompt)}}
+}
+
 // agenticReasoningInTemplateEval verifies reasoning appears in the template
 // when messages end with a tool result after an assistant message.
 type agenticReasoningInTemplateEval struct {
@@ -219,6 +244,10 @@ func (e *agenticReasoningInTemplateEval) Class() string {
 	return ClassInterleaved
 }
 
+func (e *agenticReasoningInTemplateEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn, TagTemplate}
+}
+
 func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Client) Result {
 	// First, get reasoning content from the model
 	req1 := client.ChatCompletionRequest{
@@ -239,6 +268,7 @@ func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Clie
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "initial request failed: " + err.Error(),
 			}
 		}
@@ -251,6 +281,7 @@ func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Clie
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "initial request failed: " + err.Error(),
 			}
 		}
@@ -259,6 +290,7 @@ func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Clie
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -272,6 +304,7 @@ func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Clie
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeReasoningEmpty,
 			Message:  "model did not return reasoning_content, cannot test template",
 		}
 	}
@@ -281,6 +314,7 @@ func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Clie
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallMissing,
 			Message:  "model did not return tool calls, cannot test template",
 		}
 	}
@@ -309,6 +343,7 @@ func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Clie
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeTemplateFailed,
 			Message:  "/apply-template failed: " + err.Error(),
 		}
 	}
@@ -316,10 +351,12 @@ func (e *agenticReasoningInTemplateEval) Run(ctx context.Context, c *client.Clie
 	// Verify reasoning content appears in the prompt
 	if !strings.Contains(prompt, reasoningContent) {
 		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "reasoning_content not found in rendered template",
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      CodeTemplateReasoningMissing,
+			Message:   "reasoning_content not found in rendered template",
+			Artifacts: renderedTemplate(prompt),
 		}
 	}
 
@@ -351,6 +388,10 @@ func (e *agenticReasoningNotInUserTemplateEval) Class() string {
 	return ClassInterleaved
 }
 
+func (e *agenticReasoningNotInUserTemplateEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn, TagTemplate}
+}
+
 func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *client.Client) Result {
 	// First, get reasoning content from the model
 	req1 := client.ChatCompletionRequest{
@@ -371,6 +412,7 @@ func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *clie
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "initial request failed: " + err.Error(),
 			}
 		}
@@ -383,6 +425,7 @@ func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *clie
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "initial request failed: " + err.Error(),
 			}
 		}
@@ -391,6 +434,7 @@ func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *clie
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -404,6 +448,7 @@ func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *clie
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeReasoningEmpty,
 			Message:  "model did not return reasoning_content, cannot test template",
 		}
 	}
@@ -413,6 +458,7 @@ func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *clie
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallMissing,
 			Message:  "model did not return tool calls, cannot test template",
 		}
 	}
@@ -442,6 +488,7 @@ func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *clie
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeTemplateFailed,
 			Message:  "/apply-template failed: " + err.Error(),
 		}
 	}
@@ -449,10 +496,12 @@ func (e *agenticReasoningNotInUserTemplateEval) Run(ctx context.Context, c *clie
 	// Verify reasoning content does NOT appear in the prompt
 	if strings.Contains(prompt, reasoningContent) {
 		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "reasoning_content found in template when it should not be (ends with user message)",
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      CodeTemplateReasoningLeaked,
+			Message:   "reasoning_content found in template when it should not be (ends with user message)",
+			Artifacts: renderedTemplate(prompt),
 		}
 	}
 
@@ -552,6 +601,10 @@ func (e *agenticLongResponseEval) Class() string {
 	return ClassStandard
 }
 
+func (e *agenticLongResponseEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn, TagSlow}
+}
+
 func (e *agenticLongResponseEval) IsDefaultDisabled() bool {
 	return true
 }
@@ -592,6 +645,7 @@ Do not just summarize - provide a complete educational explanation.`
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 1 request failed: " + err.Error(),
 			}
 		}
@@ -604,6 +658,7 @@ Do not just summarize - provide a complete educational explanation.`
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 1 request failed: " + err.Error(),
 			}
 		}
@@ -612,6 +667,7 @@ Do not just summarize - provide a complete educational explanation.`
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "turn 1: no choices in response",
 			}
 		}
@@ -625,6 +681,7 @@ Do not just summarize - provide a complete educational explanation.`
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallMissing,
 			Message:  "turn 1: expected tool call, got none",
 		}
 	}
@@ -635,6 +692,7 @@ Do not just summarize - provide a complete educational explanation.`
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallWrongName,
 			Message:  "turn 1: expected tool 'fetch_documentation', got '" + tc.Function.Name + "'",
 		}
 	}
@@ -668,6 +726,7 @@ Do not just summarize - provide a complete educational explanation.`
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 2 request failed: " + err.Error(),
 			}
 		}
@@ -680,6 +739,7 @@ Do not just summarize - provide a complete educational explanation.`
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "turn 2 request failed: " + err.Error(),
 			}
 		}
@@ -688,6 +748,7 @@ Do not just summarize - provide a complete educational explanation.`
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "turn 2: no choices in response",
 			}
 		}
@@ -701,6 +762,7 @@ Do not just summarize - provide a complete educational explanation.`
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallUnexpected,
 			Message:  "turn 2: expected no tool calls, got " + toolCalls2[0].Function.Name,
 		}
 	}
@@ -711,36 +773,84 @@ Do not just summarize - provide a complete educational explanation.`
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeContentTooShort,
 			Message:  "turn 2: response too short (expected 2500+ chars for comprehensive tutorial)",
 		}
 	}
 
-	// Verify content covers key topics from the documentation
-	requiredTopics := []string{
-		"reference",
-		"mark",
-		"generation",
+	// Verify content covers key topics from the documentation. Coverage is
+	// the fraction of a topic's key terms that appear in the response.
+	scores := map[string]float64{
+		"docs_rouge_l_recall": textmetrics.RougeL(content2, detailedDocsResponse).Recall,
+	}
+	var uncovered []string
+	for _, topic := range longResponseTopics {
+		coverage := textmetrics.TokenF1(content2, topic.terms).Recall
+		scores["coverage_"+topic.name] = coverage
+		if coverage < longResponseMinCoverage {
+			uncovered = append(uncovered, fmt.Sprintf("%s (%.2f)", topic.name, coverage))
+		}
 	}
 
-	contentLower := strings.ToLower(content2)
-	for _, topic := range requiredTopics {
-		if !strings.Contains(contentLower, topic) {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "turn 2: response missing expected topic: " + topic,
-			}
+	if len(uncovered) > 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentMissingExpected,
+			Message: fmt.Sprintf("turn 2: response covers too little of topics (min %.2f): %s",
+				longResponseMinCoverage, strings.Join(uncovered, ", ")),
+			Scores: scores,
 		}
 	}
 
+	// With an embedding endpoint configured, also check that the tutorial
+	// is about what was asked for, not just using the right terms
+	if failed := checkSemantic(ctx, c, e, scores, "semantic_similarity", content2, longResponseReference, longResponseMinSimilarity); failed != nil {
+		failed.Message = "turn 2: " + failed.Message
+		return *failed
+	}
+
 	return Result{
 		Name:     e.Name(),
 		Category: e.Category(),
 		Passed:   true,
+		Scores:   scores,
 	}
 }
 
+// longResponseTopics are the documentation topics agenticLongResponseEval
+// expects the tutorial to cover, each with the key terms a faithful
+// explanation would use.
+var longResponseTopics = []struct {
+	name  string
+	terms string
+}{
+	{"reference_counting", "reference counting count references object increments decrements zero freed circular"},
+	{"mark_and_sweep", "mark sweep phase roots reachable objects marked heap unmarked freed"},
+	{"generational", "generational hypothesis objects die young generation old promoted minor major"},
+}
+
+// longResponseMinCoverage is the minimum coverage required for each topic.
+const longResponseMinCoverage = 0.6
+
+// longResponseReference summarizes the tutorial agenticLongResponseEval asks
+// for, as the reference for the semantic similarity check.
+const longResponseReference = `A tutorial on how garbage collection works. Garbage collection automatically
+reclaims memory from objects a program no longer uses, avoiding leaks and use-after-free bugs.
+Reference counting frees objects when their count of references drops to zero but cannot
+collect cycles. Mark and sweep marks objects reachable from the roots and sweeps the rest,
+with mark-compact and copying variants. Generational collectors exploit the observation
+that most objects die young, collecting the young generation often and promoting
+survivors. Concurrent collectors use the tri-color abstraction and write barriers to run
+alongside the program. Java's G1, ZGC, and Shenandoah, Go's concurrent collector, and
+Python's reference counting with cycle detection are examples. Allocation strategies and
+tuning pause times, throughput, and memory footprint complete the picture.`
+
+// longResponseMinSimilarity is the minimum cosine similarity between the
+// tutorial and longResponseReference.
+const longResponseMinSimilarity = 0.7
+
 // agenticTemplateRenderingEval tests that multi-turn tool call conversations
 // with reasoning content render correctly in the chat template without making
 // any LLM calls. This verifies the server's template handling of:
@@ -764,6 +874,10 @@ func (e *agenticTemplateRenderingEval) Class() string {
 	return ClassInterleaved
 }
 
+func (e *agenticTemplateRenderingEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn, TagTemplate}
+}
+
 func (e *agenticTemplateRenderingEval) Run(ctx context.Context, c *client.Client) Result {
 	// Synthetic reasoning content that would come from a reasoning model
 	syntheticReasoning := "The user is asking about the weather in San Francisco. " +
@@ -807,6 +921,7 @@ func (e *agenticTemplateRenderingEval) Run(ctx context.Context, c *client.Client
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeTemplateFailed,
 			Message:  "/apply-template failed: " + err.Error(),
 		}
 	}
@@ -816,50 +931,198 @@ func (e *agenticTemplateRenderingEval) Run(ctx context.Context, c *client.Client
 	// 1. Check that reasoning content appears in the template
 	if !strings.Contains(prompt, syntheticReasoning) {
 		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "reasoning_content not found in rendered template",
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      CodeTemplateReasoningMissing,
+			Message:   "reasoning_content not found in rendered template",
+			Artifacts: renderedTemplate(prompt),
 		}
 	}
 
 	// 2. Check that tool call information appears
 	if !strings.Contains(prompt, "get_weather") {
 		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "tool call function name 'get_weather' not found in rendered template",
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      CodeTemplateToolCallMissing,
+			Message:   "tool call function name 'get_weather' not found in rendered template",
+			Artifacts: renderedTemplate(prompt),
 		}
 	}
 
 	// 3. Check that tool call arguments appear
 	if !strings.Contains(prompt, "San Francisco") {
 		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "tool call arguments not found in rendered template",
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      CodeTemplateToolCallMissing,
+			Message:   "tool call arguments not found in rendered template",
+			Artifacts: renderedTemplate(prompt),
 		}
 	}
 
 	// 4. Check that tool response appears
 	if !strings.Contains(prompt, "partly cloudy") {
+		return Result{
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      CodeTemplateToolResponseMissing,
+			Message:   "tool response content not found in rendered template",
+			Artifacts: renderedTemplate(prompt),
+		}
+	}
+
+	// 5. Check that tool call ID appears (links response to call)
+	if !strings.Contains(prompt, "call_abc123") {
+		return Result{
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      CodeTemplateToolCallMissing,
+			Message:   "tool call ID not found in rendered template",
+			Artifacts: renderedTemplate(prompt),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// preferencesTool returns the user's settings. A question about the weather
+// elsewhere gives a model no reason to call it unless forced.
+var preferencesTool = client.Tool{
+	Type: "function",
+	Function: client.ToolFunction{
+		Name:        "get_user_preferences",
+		Description: "Get the user's saved preferences, such as units of measurement",
+		Parameters:  json.RawMessage(`{"type": "object", "properties": {}}`),
+	},
+}
+
+// agenticForcedToolChoiceEval tests a conversation whose first request
+// forces a call of get_user_preferences with a named tool_choice and whose
+// later requests use "auto". The model must call the forced tool first,
+// then be free to call get_weather and answer. A server that ignores the
+// named choice, or that keeps the first request's forced grammar for the
+// rest of the conversation, fails.
+type agenticForcedToolChoiceEval struct {
+	streaming bool
+}
+
+func (e *agenticForcedToolChoiceEval) Name() string {
+	return "agentic_forced_tool_choice"
+}
+
+func (e *agenticForcedToolChoiceEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *agenticForcedToolChoiceEval) Streaming() bool             { return e.streaming }
+
+func (e *agenticForcedToolChoiceEval) Category() string {
+	return agenticCategory
+}
+
+func (e *agenticForcedToolChoiceEval) Class() string {
+	return ClassStandard
+}
+
+func (e *agenticForcedToolChoiceEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn}
+}
+
+func (e *agenticForcedToolChoiceEval) Run(ctx context.Context, c *client.Client) Result {
+	forced := preferencesTool.Function.Name
+	loop := agenticLoop{
+		tools: []client.Tool{preferencesTool, weatherTool},
+		respond: func(tc client.ToolCall) string {
+			if tc.Function.Name == forced {
+				return `{"temperature_unit": "fahrenheit", "language": "en"}`
+			}
+			return `{"location": "Paris, France", "temperature": 64, "unit": "fahrenheit", "condition": "light rain"}`
+		},
+		toolChoice:    forceFirstTool(forced),
+		maxIterations: 5,
+	}
+
+	messages := []client.Message{
+		{Role: "user", Content: "What's the weather like in Paris right now?"},
+	}
+	turns, failed := loop.run(ctx, c, e, e.streaming, messages, func(i int, turn agenticTurn) *Result {
+		var names []string
+		for _, tc := range turn.toolCalls {
+			names = append(names, tc.Function.Name)
+		}
+		onlyForced := len(names) > 0 && !slices.ContainsFunc(names, func(n string) bool { return n != forced })
+
+		switch {
+		case i == 0 && len(names) == 0:
+			return &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeToolChoiceIgnored,
+				Message:  fmt.Sprintf("iteration 1: tool_choice named %s, but the response called no tool", forced),
+			}
+		case i == 0 && !onlyForced:
+			return &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeToolChoiceIgnored,
+				Message:  fmt.Sprintf("iteration 1: tool_choice named %s, but the response called %s", forced, strings.Join(names, ", ")),
+			}
+		case i == 1 && onlyForced:
+			return &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeToolChoiceStuck,
+				Message:  fmt.Sprintf("iteration 2: tool_choice was \"auto\", but the response called %s again, as if still forced", forced),
+			}
+		}
+		return nil
+	})
+	if failed != nil {
+		return *failed
+	}
+
+	calledWeather := slices.ContainsFunc(turns[1:], func(t agenticTurn) bool {
+		return slices.ContainsFunc(t.toolCalls, func(tc client.ToolCall) bool {
+			return tc.Function.Name == weatherTool.Function.Name
+		})
+	})
+	if !calledWeather {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "tool response content not found in rendered template",
+			Code:     CodeToolCallMissing,
+			Message:  "answered without calling get_weather after the forced call",
 		}
 	}
 
-	// 5. Check that tool call ID appears (links response to call)
-	if !strings.Contains(prompt, "call_abc123") {
+	content := turns[len(turns)-1].content
+	if strings.TrimSpace(content) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "final response is empty",
+		}
+	}
+	if !strings.Contains(content, "64") {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "tool call ID not found in rendered template",
+			Code:     CodeContentMissingExpected,
+			Message:  fmt.Sprintf("final response does not give the temperature from get_weather (64): %s", content),
 		}
 	}
 
@@ -339,6 +339,10 @@ func (e *agenticIncidentInvestigationEval) Class() string {
 	return ClassStandard
 }
 
+func (e *agenticIncidentInvestigationEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn, TagSlow}
+}
+
 func (e *agenticIncidentInvestigationEval) SetStreaming(streaming bool) { e.streaming = streaming }
 func (e *agenticIncidentInvestigationEval) Streaming() bool             { return e.streaming }
 
@@ -369,89 +373,21 @@ func (e *agenticIncidentInvestigationEval) Run(ctx context.Context, c *client.Cl
 		{Role: "user", Content: userPrompt},
 	}
 
-	toolCallRounds := 0
-
-	for i := range maxIterations {
-		req := client.ChatCompletionRequest{
-			Messages:   messages,
-			Tools:      incidentInvestigationTools,
-			ToolChoice: "auto",
-		}
-
-		var content string
-		var reasoningContent string
-		var toolCalls []client.ToolCall
-
-		if e.streaming {
-			result, err := c.ChatCompletionStream(ctx, req)
-			if err != nil {
-				return Result{
-					Name:     e.Name(),
-					Category: e.Category(),
-					Passed:   false,
-					Message:  fmt.Sprintf("iteration %d: request failed: %s", i+1, err.Error()),
-				}
-			}
-			content = result.Content
-			reasoningContent = result.ReasoningContent
-			toolCalls = result.ToolCalls
-		} else {
-			resp, err := c.ChatCompletion(ctx, req)
-			if err != nil {
-				return Result{
-					Name:     e.Name(),
-					Category: e.Category(),
-					Passed:   false,
-					Message:  fmt.Sprintf("iteration %d: request failed: %s", i+1, err.Error()),
-				}
-			}
-			if len(resp.Choices) == 0 {
-				return Result{
-					Name:     e.Name(),
-					Category: e.Category(),
-					Passed:   false,
-					Message:  fmt.Sprintf("iteration %d: no choices in response", i+1),
-				}
-			}
-			content = resp.Choices[0].Message.Content
-			reasoningContent = resp.Choices[0].Message.ReasoningContent
-			toolCalls = resp.Choices[0].Message.ToolCalls
-		}
-
-		// No tool calls means the model is done investigating
-		if len(toolCalls) == 0 {
-			return e.validateFinalResponse(content, toolCallRounds)
-		}
-
-		toolCallRounds++
-
-		// Append assistant message
-		assistantMsg := client.Message{
-			Role:             "assistant",
-			ReasoningContent: reasoningContent,
-			ToolCalls:        toolCalls,
-		}
-		if content != "" {
-			assistantMsg.Content = content
-		}
-		messages = append(messages, assistantMsg)
-
-		// Append tool responses for each tool call
-		for _, tc := range toolCalls {
-			messages = append(messages, client.Message{
-				Role:       "tool",
-				ToolCallID: tc.ID,
-				Content:    lookupIncidentToolResponse(tc.Function.Name),
-			})
+	loop := agenticLoop{
+		tools:         incidentInvestigationTools,
+		respond:       func(tc client.ToolCall) string { return lookupIncidentToolResponse(tc.Function.Name) },
+		maxIterations: maxIterations,
+	}
+	turns, failed := loop.run(ctx, c, e, e.streaming, messages, nil)
+	if failed != nil {
+		if failed.Code == CodeAgenticMaxIterations {
+			failed.Message = fmt.Sprintf("reached max iterations (%d) without completing investigation", maxIterations)
 		}
+		return *failed
 	}
 
-	return Result{
-		Name:     e.Name(),
-		Category: e.Category(),
-		Passed:   false,
-		Message:  fmt.Sprintf("reached max iterations (%d) without completing investigation", maxIterations),
-	}
+	// Every turn but the final answer was a round of tool calls
+	return e.validateFinalResponse(turns[len(turns)-1].content, len(turns)-1)
 }
 
 func (e *agenticIncidentInvestigationEval) validateFinalResponse(content string, toolCallRounds int) Result {
@@ -460,6 +396,7 @@ func (e *agenticIncidentInvestigationEval) validateFinalResponse(content string,
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeAgenticTooFewRounds,
 			Message:  fmt.Sprintf("model only used %d tool call round(s), expected at least %d", toolCallRounds, minToolCallRoundsIncident),
 		}
 	}
@@ -469,6 +406,7 @@ func (e *agenticIncidentInvestigationEval) validateFinalResponse(content string,
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeContentEmpty,
 			Message:  "final response is empty",
 		}
 	}
@@ -478,6 +416,7 @@ func (e *agenticIncidentInvestigationEval) validateFinalResponse(content string,
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeContentTooShort,
 			Message:  fmt.Sprintf("final response too short (%d chars, expected at least 200)", len(content)),
 		}
 	}
@@ -497,6 +436,7 @@ func (e *agenticIncidentInvestigationEval) validateFinalResponse(content string,
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeContentMissingExpected,
 			Message:  fmt.Sprintf("final response only mentions %d/5 expected keywords (payment, deploy, feature, error, checkout); expected at least 3", matched),
 		}
 	}
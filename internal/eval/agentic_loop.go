@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// agenticLoop drives a tool-calling conversation to its final answer: each
+// iteration sends the conversation, and the tool calls of the response are
+// answered and sent back, until a response has none.
+type agenticLoop struct {
+	tools []client.Tool
+	// respond returns the result of a tool call.
+	respond func(tc client.ToolCall) string
+	// toolChoice returns the tool_choice of an iteration, counted from 0.
+	// If nil, every iteration uses "auto".
+	toolChoice    func(iteration int) any
+	maxIterations int
+}
+
+// agenticTurn is one response of an agentic loop.
+type agenticTurn struct {
+	toolChoice       any
+	content          string
+	reasoningContent string
+	toolCalls        []client.ToolCall
+}
+
+// namedToolChoice returns the tool_choice forcing a call of the named
+// function.
+func namedToolChoice(name string) map[string]any {
+	return map[string]any{
+		"type":     "function",
+		"function": map[string]any{"name": name},
+	}
+}
+
+// forceFirstTool returns a toolChoice forcing the named tool on the first
+// iteration and leaving the choice to the model afterwards.
+func forceFirstTool(name string) func(int) any {
+	return func(iteration int) any {
+		if iteration == 0 {
+			return namedToolChoice(name)
+		}
+		return "auto"
+	}
+}
+
+// run continues the conversation in messages until a response has no tool
+// calls. It returns every turn, the last holding the final answer, or a
+// failed result if a request fails or maxIterations pass without a final
+// answer. check, if set, inspects each turn before its tool calls are
+// answered, and stops the loop by returning a failed result.
+func (l agenticLoop) run(ctx context.Context, c *client.Client, e Eval, streaming bool, messages []client.Message, check func(iteration int, turn agenticTurn) *Result) ([]agenticTurn, *Result) {
+	var turns []agenticTurn
+	for i := range l.maxIterations {
+		var toolChoice any = "auto"
+		if l.toolChoice != nil {
+			toolChoice = l.toolChoice(i)
+		}
+		req := client.ChatCompletionRequest{
+			Messages:   messages,
+			Tools:      l.tools,
+			ToolChoice: toolChoice,
+		}
+
+		turn := agenticTurn{toolChoice: toolChoice}
+		if streaming {
+			result, err := c.ChatCompletionStream(ctx, req)
+			if err != nil {
+				return turns, &Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeRequestFailed,
+					Message:  fmt.Sprintf("iteration %d: request failed: %s", i+1, err.Error()),
+				}
+			}
+			turn.content = result.Content
+			turn.reasoningContent = result.ReasoningContent
+			turn.toolCalls = result.ToolCalls
+		} else {
+			resp, err := c.ChatCompletion(ctx, req)
+			if err != nil {
+				return turns, &Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeRequestFailed,
+					Message:  fmt.Sprintf("iteration %d: request failed: %s", i+1, err.Error()),
+				}
+			}
+			if len(resp.Choices) == 0 {
+				return turns, &Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeNoChoices,
+					Message:  fmt.Sprintf("iteration %d: no choices in response", i+1),
+				}
+			}
+			turn.content = resp.Choices[0].Message.Content
+			turn.reasoningContent = resp.Choices[0].Message.ReasoningContent
+			turn.toolCalls = resp.Choices[0].Message.ToolCalls
+		}
+		turns = append(turns, turn)
+
+		if check != nil {
+			if failed := check(i, turn); failed != nil {
+				return turns, failed
+			}
+		}
+
+		// No tool calls means the model has answered
+		if len(turn.toolCalls) == 0 {
+			return turns, nil
+		}
+
+		assistantMsg := client.Message{
+			Role:             "assistant",
+			ReasoningContent: turn.reasoningContent,
+			ToolCalls:        turn.toolCalls,
+		}
+		if turn.content != "" {
+			assistantMsg.Content = turn.content
+		}
+		messages = append(messages, assistantMsg)
+
+		for _, tc := range turn.toolCalls {
+			messages = append(messages, client.Message{
+				Role:       "tool",
+				ToolCallID: tc.ID,
+				Content:    l.respond(tc),
+			})
+		}
+	}
+
+	return turns, &Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   false,
+		Code:     CodeAgenticMaxIterations,
+		Message:  fmt.Sprintf("reached max iterations (%d) without a final answer", l.maxIterations),
+	}
+}
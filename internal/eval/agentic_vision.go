@@ -0,0 +1,197 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// screenshotTool captures the user's screen, returning it as an image.
+var screenshotTool = client.Tool{
+	Type: "function",
+	Function: client.ToolFunction{
+		Name:        "take_screenshot",
+		Description: "Capture a screenshot of the user's screen",
+		Parameters:  json.RawMessage(`{"type": "object", "properties": {}}`),
+	},
+}
+
+// screenshotURL returns a data URL of the PNG image returned by
+// take_screenshot: a red circle centered on a white background.
+var screenshotURL = sync.OnceValue(func() string {
+	return shapeImageURL(imageRed, insideCircle)
+})
+
+// describesScreenshot reports whether a response names the color and shape
+// of the screenshot's icon.
+func describesScreenshot(content string) bool {
+	lower := strings.ToLower(content)
+	if !strings.Contains(lower, "red") {
+		return false
+	}
+	for _, shape := range []string{"circle", "circular", "round", "disk", "disc", "dot", "ball", "sphere"} {
+		if strings.Contains(lower, shape) {
+			return true
+		}
+	}
+	return false
+}
+
+// agenticToolImageEval tests a conversation in which a tool returns an
+// image, as agents taking screenshots do: the tool message's content is an
+// array holding an image_url part. Many chat templates render only text
+// content, so the server rejects the request or the model never sees the
+// image. If the model does not describe the image, the same image is sent
+// in a user message, to tell a tool result the template mishandles from a
+// model that does not accept images at all.
+type agenticToolImageEval struct {
+	streaming bool
+}
+
+func (e *agenticToolImageEval) Name() string {
+	return "agentic_tool_image"
+}
+
+func (e *agenticToolImageEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *agenticToolImageEval) Streaming() bool             { return e.streaming }
+
+func (e *agenticToolImageEval) Category() string {
+	return agenticCategory
+}
+
+func (e *agenticToolImageEval) Class() string {
+	return ClassStandard
+}
+
+func (e *agenticToolImageEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn}
+}
+
+func (e *agenticToolImageEval) RequiresVision() bool {
+	return true
+}
+
+func (e *agenticToolImageEval) Run(ctx context.Context, c *client.Client) Result {
+	image := client.ContentPart{Type: "image_url", ImageURL: &client.ImageURL{URL: screenshotURL()}}
+
+	content, toolCalls, err := e.send(ctx, c, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Take a screenshot of my screen and tell me the color and shape of the icon on it."},
+			{
+				Role: "assistant",
+				ToolCalls: []client.ToolCall{{
+					ID:       "call_screenshot_1",
+					Type:     "function",
+					Function: client.ToolCallFunction{Name: screenshotTool.Function.Name, Arguments: "{}"},
+				}},
+			},
+			{
+				Role:       "tool",
+				ToolCallID: "call_screenshot_1",
+				ContentParts: []client.ContentPart{
+					{Type: "text", Text: "Screenshot of the current screen:"},
+					image,
+				},
+			},
+		},
+		Tools: []client.Tool{screenshotTool},
+	})
+
+	// Only a 4xx response rejects the conversation; other errors are
+	// failures of the server
+	var statusErr *client.StatusError
+	rejected := errors.As(err, &statusErr) &&
+		statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError
+	if err != nil && !rejected {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	if err == nil && describesScreenshot(content) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+		}
+	}
+
+	// Send the image in a user message, which vision models must accept
+	control, _, controlErr := e.send(ctx, c, client.ChatCompletionRequest{
+		Messages: []client.Message{{
+			Role: "user",
+			ContentParts: []client.ContentPart{
+				{Type: "text", Text: "Here is a screenshot of my screen. What are the color and shape of the icon on it?"},
+				image,
+			},
+		}},
+	})
+	if controlErr != nil || !describesScreenshot(control) {
+		outcome := fmt.Sprintf("answered %q", control)
+		if controlErr != nil {
+			outcome = "failed: " + controlErr.Error()
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeVisionUnsupported,
+			Message:  "the image was not described in a user message either (" + outcome + "); check that the model and server accept images",
+		}
+	}
+
+	if rejected {
+		msg := errorMessage(statusErr.Body)
+		if msg == "" {
+			msg = statusErr.Body
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeToolImageRejected,
+			Message:  fmt.Sprintf("image in a tool result rejected with status %d: %s (described in a user message)", statusErr.StatusCode, msg),
+		}
+	}
+
+	outcome := fmt.Sprintf("answered %q", content)
+	if len(toolCalls) > 0 {
+		outcome = "called " + toolCalls[0].Function.Name + " again"
+	}
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   false,
+		Code:     CodeToolImageIgnored,
+		Message:  "image in a tool result not described: the model " + outcome + ", but described it in a user message",
+	}
+}
+
+// send sends a request, returning the response's content and tool calls.
+func (e *agenticToolImageEval) send(ctx context.Context, c *client.Client, req client.ChatCompletionRequest) (string, []client.ToolCall, error) {
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return "", nil, err
+		}
+		return result.Content, result.ToolCalls, nil
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return "", nil, errors.New("no choices in response")
+	}
+	return resp.Choices[0].Message.Content, resp.Choices[0].Message.ToolCalls, nil
+}
@@ -0,0 +1,78 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numberPattern matches an integer or decimal. Commas are only taken as
+// thousands separators in groups of three digits, so that "1,2" is two
+// numbers rather than 12.
+const numberPattern = `-?(?:\d{1,3}(?:,\d{3})+(?:\.\d+)?\b|\d+(?:\.\d+)?)`
+
+var (
+	// boxedAnswerRe matches a LaTeX \boxed{...} final answer.
+	boxedAnswerRe = regexp.MustCompile(`\\boxed\{([^{}]*)\}`)
+	// statedAnswerRe matches phrasings like "the answer is 405" or
+	// "Answer: 405", allowing markdown emphasis and "=" before the number.
+	statedAnswerRe = regexp.MustCompile(`(?i)answer(?:\s+is)?\s*[:=]?\s*[*_$]*\s*(` + numberPattern + `)`)
+	numberRe       = regexp.MustCompile(numberPattern)
+)
+
+// extractNumericAnswer returns the final numeric answer in content. It
+// prefers an explicit \boxed{} answer, then a stated "answer is" answer, and
+// otherwise takes the last number in the text, since models usually state
+// the result after any working.
+func extractNumericAnswer(content string) (float64, bool) {
+	if m := boxedAnswerRe.FindAllStringSubmatch(content, -1); m != nil {
+		if n := numberRe.FindString(m[len(m)-1][1]); n != "" {
+			return parseNumber(n)
+		}
+	}
+	if m := statedAnswerRe.FindAllStringSubmatch(content, -1); m != nil {
+		return parseNumber(m[len(m)-1][1])
+	}
+	if m := numberRe.FindAllString(content, -1); m != nil {
+		return parseNumber(m[len(m)-1])
+	}
+	return 0, false
+}
+
+// parseNumber parses a number matched by numberPattern, ignoring thousands
+// separators.
+func parseNumber(s string) (float64, bool) {
+	s = strings.ReplaceAll(s, ",", "")
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// checkNumericAnswer verifies that the final numeric answer in content equals
+// expected. It returns a failing Result, or nil if the answer is correct.
+func checkNumericAnswer(e Eval, content string, expected float64) *Result {
+	got, ok := extractNumericAnswer(content)
+	if !ok {
+		return &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeAnswerMissing,
+			Message:  fmt.Sprintf("no numeric answer found in content (expected %g)", expected),
+		}
+	}
+	if math.Abs(got-expected) > 1e-9 {
+		return &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeAnswerWrong,
+			Message:  fmt.Sprintf("expected answer %g, got %g", expected, got),
+		}
+	}
+	return nil
+}
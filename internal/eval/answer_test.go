@@ -0,0 +1,43 @@
+package eval
+
+import "testing"
+
+func TestExtractNumericAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    float64
+		ok      bool
+	}{
+		{"boxed", `So the product is \boxed{437}.`, 437, true},
+		{"boxed over later numbers", `\boxed{42}, found in 3 steps`, 42, true},
+		{"last boxed", `First \boxed{1}, corrected to \boxed{2}`, 2, true},
+		{"boxed expression", `\boxed{x = 12}`, 12, true},
+		{"answer is", "The answer is 405. I checked it twice, 2 times.", 405, true},
+		{"answer colon with emphasis", "Answer: **-12**", -12, true},
+		{"answer equals", "answer = 3.5", 3.5, true},
+		{"last stated answer", "The answer is 10. Wait, the answer is 11.", 11, true},
+		{"last number", "23 * 19 = 437", 437, true},
+		{"negative", "The result is -7", -7, true},
+		{"decimal", "It costs 3.75 dollars", 3.75, true},
+		{"negative decimal", "The total is -0.5", -0.5, true},
+		{"thousands", "The population is 1,234,567", 1234567, true},
+		{"stated thousands", "The answer is 12,345.", 12345, true},
+		{"thousands with decimal", "about 1,234.5 km", 1234.5, true},
+		{"boxed thousands", `\boxed{10,000}`, 10000, true},
+		{"comma list", "1,2", 2, true},
+		{"comma list of three", "the values 1,2,3", 3, true},
+		{"group of four digits", "12,3456", 3456, true},
+		{"trailing comma", "We get 42, as expected", 42, true},
+		{"no number", "I cannot answer that.", 0, false},
+		{"empty", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := extractNumericAnswer(tt.content)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("extractNumericAnswer(%q) = %g, %v, want %g, %v", tt.content, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
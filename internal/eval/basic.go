@@ -2,6 +2,7 @@ package eval
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/aldehir/llm-serving-tests/internal/client"
@@ -13,6 +14,9 @@ const basicCategory = "Basic"
 func basicEvals() []Eval {
 	return []Eval{
 		&chatCompletionEval{},
+		&arithmeticAnswerEval{},
+		&multipleChoicesEval{},
+		&multiTurnCoherenceEval{},
 	}
 }
 
@@ -36,6 +40,10 @@ func (e *chatCompletionEval) Class() string {
 	return ClassStandard
 }
 
+func (e *chatCompletionEval) IsFundamental() bool {
+	return true
+}
+
 func (e *chatCompletionEval) Run(ctx context.Context, c *client.Client) Result {
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
@@ -52,6 +60,7 @@ func (e *chatCompletionEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -63,6 +72,7 @@ func (e *chatCompletionEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -71,6 +81,7 @@ func (e *chatCompletionEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -82,6 +93,7 @@ func (e *chatCompletionEval) Run(ctx context.Context, c *client.Client) Result {
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeContentEmpty,
 			Message:  "content is empty",
 		}
 	}
@@ -92,3 +104,297 @@ func (e *chatCompletionEval) Run(ctx context.Context, c *client.Client) Result {
 		Passed:   true,
 	}
 }
+
+// arithmeticAnswerEval verifies that the model answers a simple arithmetic
+// question correctly, catching servers that return fluent but wrong output
+// (e.g. broken sampling or a corrupted model).
+type arithmeticAnswerEval struct {
+	streaming bool
+}
+
+func (e *arithmeticAnswerEval) Name() string {
+	return "arithmetic_answer"
+}
+
+func (e *arithmeticAnswerEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *arithmeticAnswerEval) Streaming() bool             { return e.streaming }
+
+func (e *arithmeticAnswerEval) Category() string {
+	return basicCategory
+}
+
+func (e *arithmeticAnswerEval) Class() string {
+	return ClassStandard
+}
+
+func (e *arithmeticAnswerEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "What is 23 * 19? Reply with just the number."},
+		},
+	}
+
+	var content string
+
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		content = result.Content
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		if len(resp.Choices) == 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeNoChoices,
+				Message:  "no choices in response",
+			}
+		}
+		content = resp.Choices[0].Message.Content
+	}
+
+	if failed := checkNumericAnswer(e, content, 437); failed != nil {
+		return *failed
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// multipleChoicesEval verifies that n > 1 returns that many choices with
+// distinct indices, each with its own content and finish_reason. In streaming
+// mode this checks that deltas interleaved across choices are attributed to
+// the right index.
+type multipleChoicesEval struct {
+	streaming bool
+}
+
+// multipleChoicesN is the number of choices requested by multipleChoicesEval.
+const multipleChoicesN = 3
+
+func (e *multipleChoicesEval) Name() string {
+	return "multiple_choices"
+}
+
+func (e *multipleChoicesEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *multipleChoicesEval) Streaming() bool             { return e.streaming }
+
+func (e *multipleChoicesEval) Category() string {
+	return basicCategory
+}
+
+func (e *multipleChoicesEval) Class() string {
+	return ClassStandard
+}
+
+// IsDefaultDisabled returns true because many servers do not support n > 1.
+func (e *multipleChoicesEval) IsDefaultDisabled() bool {
+	return true
+}
+
+func (e *multipleChoicesEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Write a one-sentence greeting."},
+		},
+		N: multipleChoicesN,
+	}
+
+	// choice is the per-index outcome common to both modes
+	type choice struct {
+		index        int
+		content      string
+		finishReason string
+	}
+	var choices []choice
+
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		for _, ch := range result.Choices {
+			choices = append(choices, choice{ch.Index, ch.Content, ch.FinishReason})
+		}
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		for _, ch := range resp.Choices {
+			choices = append(choices, choice{ch.Index, ch.Message.Content, ch.FinishReason})
+		}
+	}
+
+	if len(choices) != multipleChoicesN {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeChoicesCount,
+			Message:  fmt.Sprintf("expected %d choices, got %d", multipleChoicesN, len(choices)),
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, ch := range choices {
+		if ch.index < 0 || ch.index >= multipleChoicesN || seen[ch.index] {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeChoicesIndex,
+				Message:  fmt.Sprintf("choice index %d is duplicated or out of range [0, %d)", ch.index, multipleChoicesN),
+			}
+		}
+		seen[ch.index] = true
+
+		if strings.TrimSpace(ch.content) == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeContentEmpty,
+				Message:  fmt.Sprintf("choice %d has empty content", ch.index),
+			}
+		}
+		if ch.finishReason == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeFinishReasonMissing,
+				Message:  fmt.Sprintf("choice %d has no finish_reason", ch.index),
+			}
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// multiTurnCoherenceEval runs a three-turn conversation that gives a fact,
+// asks something unrelated, and then asks for the fact, sending the model's
+// own replies back as history. A server that mangles history when
+// re-rendering the template loses the fact.
+type multiTurnCoherenceEval struct {
+	streaming bool
+}
+
+// multiTurnFact is the fact given in the first turn, compared ignoring
+// everything but its digits.
+const multiTurnFact = "31-07-42"
+
+func (e *multiTurnCoherenceEval) Name() string {
+	return "multi_turn_coherence"
+}
+
+func (e *multiTurnCoherenceEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *multiTurnCoherenceEval) Streaming() bool             { return e.streaming }
+
+func (e *multiTurnCoherenceEval) Category() string {
+	return basicCategory
+}
+
+func (e *multiTurnCoherenceEval) Class() string {
+	return ClassStandard
+}
+
+func (e *multiTurnCoherenceEval) Tags() []string {
+	return []string{TagMultiTurn}
+}
+
+func (e *multiTurnCoherenceEval) Run(ctx context.Context, c *client.Client) Result {
+	turns := []string{
+		"Please remember this: my locker combination is " + multiTurnFact + ". Reply with just \"Noted.\"",
+		"What is the capital of France? Reply with just the city name.",
+		"What is my locker combination? Reply with just the combination.",
+	}
+
+	var messages []client.Message
+	var content string
+	for i, turn := range turns {
+		messages = append(messages, client.Message{Role: "user", Content: turn})
+
+		var failed *Result
+		content, failed = completionContent(ctx, c, e, e.streaming, client.ChatCompletionRequest{Messages: messages})
+		if failed != nil {
+			failed.Message = fmt.Sprintf("turn %d: %s", i+1, failed.Message)
+			return *failed
+		}
+		if strings.TrimSpace(content) == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeContentEmpty,
+				Message:  fmt.Sprintf("turn %d: content is empty", i+1),
+			}
+		}
+
+		messages = append(messages, client.Message{Role: "assistant", Content: content})
+	}
+
+	if !strings.Contains(digitsOnly(content), digitsOnly(multiTurnFact)) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeMultiTurnContextLost,
+			Message:  fmt.Sprintf("final answer %q does not give the combination %s from the first turn", content, multiTurnFact),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// digitsOnly returns the digits of s.
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,257 @@
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
+	evallog "github.com/aldehir/llm-serving-tests/internal/log"
+)
+
+// responseCapture records what a server answered during one eval: the
+// shape of each successful response, the tool calls and content of each
+// chat completion, and each prompt rendered by /apply-template. It is used
+// as a request logger, for the shapes and prompts on the wire, and as a
+// chat observer, for chat completions as the client decoded them, so that
+// it captures them whatever API the server speaks.
+type responseCapture struct {
+	mu        sync.Mutex
+	url       string     // URL of the last request
+	shapes    [][]string // shape of each successful response
+	toolCalls [][]string // tool calls of each successful chat completion
+	contents  []string   // content of each successful chat completion
+	prompts   []string   // each prompt rendered by /apply-template
+	// outputs holds all text generated by each successful chat completion,
+	// for output scanners
+	outputs []hygiene.Output
+}
+
+func (rc *responseCapture) LogRequest(method, url string, body []byte, sent time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.url = url
+}
+
+func (rc *responseCapture) LogResponseHeaders(headers map[string]string)        {}
+func (rc *responseCapture) LogStreamTiming(ttft, itl time.Duration, chunks int) {}
+func (rc *responseCapture) LogStreamChunks(jsonl []byte)                        {}
+
+func (rc *responseCapture) LogResponse(status int, body []byte) {
+	if status != http.StatusOK {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return
+	}
+	shape := make(map[string]bool)
+	addShape(shape, "", value)
+	rc.shapes = append(rc.shapes, slices.Sorted(maps.Keys(shape)))
+
+	if strings.HasSuffix(rc.url, "/apply-template") {
+		var resp client.ApplyTemplateResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return
+		}
+		rc.prompts = append(rc.prompts, resp.Prompt)
+	}
+}
+
+// LogStreamResponse records the shape of a stream: that of all its events,
+// given as SSE data lines or, as in Ollama's streams, NDJSON lines.
+func (rc *responseCapture) LogStreamResponse(status int, rawChunks []byte) {
+	if status != http.StatusOK {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	shape := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(rawChunks))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if data, ok := strings.CutPrefix(line, "data:"); ok {
+			line = strings.TrimSpace(data)
+		} else if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(line), &value); err != nil {
+			continue
+		}
+		addShape(shape, "", value)
+	}
+	rc.shapes = append(rc.shapes, slices.Sorted(maps.Keys(shape)))
+}
+
+func (rc *responseCapture) ObserveChat(req client.ChatCompletionRequest, resp *client.ChatCompletionResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	var calls []string
+	var content, reasoning string
+	if len(resp.Choices) > 0 {
+		for _, tc := range resp.Choices[0].Message.ToolCalls {
+			calls = append(calls, formatToolCall(tc.Function.Name, tc.Function.Arguments))
+		}
+		content = resp.Choices[0].Message.Content
+		reasoning = resp.Choices[0].Message.ReasoningContent
+	}
+	rc.addChat(req, reasoning, content, calls)
+}
+
+func (rc *responseCapture) ObserveChatStream(req client.ChatCompletionRequest, result *client.StreamResult) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	var calls []string
+	for _, tc := range result.ToolCalls {
+		calls = append(calls, formatToolCall(tc.Function.Name, tc.Function.Arguments))
+	}
+	rc.addChat(req, result.ReasoningContent, result.Content, calls)
+}
+
+// addChat records the tool calls, content, and generated text of a chat
+// completion, with the system prompts of its request for output scanners.
+func (rc *responseCapture) addChat(req client.ChatCompletionRequest, reasoning, content string, calls []string) {
+	var system []string
+	for _, m := range req.Messages {
+		if m.Role == "system" || m.Role == "developer" {
+			text := []string{m.Content}
+			for _, p := range m.ContentParts {
+				text = append(text, p.Text)
+			}
+			system = append(system, strings.Join(text, "\n"))
+		}
+	}
+	rc.toolCalls = append(rc.toolCalls, calls)
+	rc.contents = append(rc.contents, content)
+	parts := append([]string{reasoning, content}, calls...)
+	rc.outputs = append(rc.outputs, hygiene.Output{Text: strings.Join(parts, "\n"), System: system})
+}
+
+// addExchange records a recorded exchange as if it had just been sent.
+// Successful chat completions are decoded with provider, the API the
+// exchange was recorded in.
+func (rc *responseCapture) addExchange(provider client.Provider, ex evallog.Exchange) {
+	rc.LogRequest(ex.Method, ex.URL, ex.RequestBody, ex.Sent)
+	if ex.StreamRaw != "" {
+		rc.LogStreamResponse(ex.Status, []byte(ex.StreamRaw))
+	} else {
+		rc.LogResponse(ex.Status, ex.ResponseBody)
+	}
+	if ex.Status != http.StatusOK || strings.HasSuffix(ex.URL, "/apply-template") {
+		return
+	}
+
+	// The request is not decoded, so its system prompts are unknown
+	if ex.StreamRaw != "" {
+		if result, err := provider.ParseStream(strings.NewReader(ex.StreamRaw), ex.Sent); err == nil {
+			rc.ObserveChatStream(client.ChatCompletionRequest{}, result)
+		}
+	} else if resp, err := provider.DecodeChat(ex.ResponseBody); err == nil {
+		rc.ObserveChat(client.ChatCompletionRequest{}, resp)
+	}
+}
+
+// addShape adds the shape of a JSON value to shape as one "path: type"
+// entry per field, such as "choices[].message.content: string". Array
+// elements share the path of their array, so the shape does not depend on
+// how many elements there are.
+func addShape(shape map[string]bool, path string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		shape[shapePath(path)+": object"] = true
+		for key, field := range v {
+			if path == "" {
+				addShape(shape, key, field)
+			} else {
+				addShape(shape, path+"."+key, field)
+			}
+		}
+	case []any:
+		shape[shapePath(path)+": array"] = true
+		for _, elem := range v {
+			addShape(shape, path+"[]", elem)
+		}
+	case string:
+		shape[shapePath(path)+": string"] = true
+	case float64:
+		shape[shapePath(path)+": number"] = true
+	case bool:
+		shape[shapePath(path)+": boolean"] = true
+	case nil:
+		shape[shapePath(path)+": null"] = true
+	}
+}
+
+// shapePath names the root of a response "$".
+func shapePath(path string) string {
+	if path == "" {
+		return "$"
+	}
+	return path
+}
+
+// formatToolCall renders a tool call for comparison, with its arguments
+// re-encoded so that key order and whitespace do not count as differences.
+func formatToolCall(name, arguments string) string {
+	var args any
+	if err := json.Unmarshal([]byte(arguments), &args); err == nil {
+		if normalized, err := json.Marshal(args); err == nil {
+			arguments = string(normalized)
+		}
+	}
+	return name + arguments
+}
+
+// teeLogger passes each logged event to every logger.
+type teeLogger []evallog.RequestLogger
+
+func (t teeLogger) LogRequest(method, url string, body []byte, sent time.Time) {
+	for _, l := range t {
+		l.LogRequest(method, url, body, sent)
+	}
+}
+
+func (t teeLogger) LogResponseHeaders(headers map[string]string) {
+	for _, l := range t {
+		l.LogResponseHeaders(headers)
+	}
+}
+
+func (t teeLogger) LogResponse(status int, body []byte) {
+	for _, l := range t {
+		l.LogResponse(status, body)
+	}
+}
+
+func (t teeLogger) LogStreamResponse(status int, rawChunks []byte) {
+	for _, l := range t {
+		l.LogStreamResponse(status, rawChunks)
+	}
+}
+
+func (t teeLogger) LogStreamTiming(ttft, itl time.Duration, chunks int) {
+	for _, l := range t {
+		l.LogStreamTiming(ttft, itl, chunks)
+	}
+}
+
+func (t teeLogger) LogStreamChunks(jsonl []byte) {
+	for _, l := range t {
+		l.LogStreamChunks(jsonl)
+	}
+}
@@ -0,0 +1,89 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// ollamaServer answers /api/chat with a tool call, as one response or as
+// an NDJSON stream.
+func ollamaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Stream bool `json:"stream"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		call := `{"function":{"name":"get_weather","arguments":{"location":"Paris"}}}`
+		if !req.Stream {
+			fmt.Fprintf(w, `{"model":"m","message":{"role":"assistant","content":"Checking.","tool_calls":[%s]},"done":true,"done_reason":"stop"}`, call)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprintln(w, `{"model":"m","message":{"role":"assistant","content":"Checking."},"done":false}`)
+		fmt.Fprintf(w, `{"model":"m","message":{"role":"assistant","content":"","tool_calls":[%s]},"done":false}`+"\n", call)
+		fmt.Fprintln(w, `{"model":"m","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}`)
+	}))
+}
+
+func TestResponseCaptureNonOpenAIProvider(t *testing.T) {
+	srv := ollamaServer(t)
+	defer srv.Close()
+
+	capture := &responseCapture{}
+	c := client.New(client.Config{BaseURL: srv.URL, Model: "m", Provider: client.Ollama{}}).
+		WithLogger(capture).
+		WithObserver(capture)
+
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "What is the weather in Paris?"},
+		},
+	}
+	if _, err := c.ChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("ChatCompletion: %v", err)
+	}
+	if _, err := c.ChatCompletionStream(context.Background(), req); err != nil {
+		t.Fatalf("ChatCompletionStream: %v", err)
+	}
+
+	wantCalls := []string{`get_weather{"location":"Paris"}`}
+	if len(capture.toolCalls) != 2 {
+		t.Fatalf("captured %d chat completions, want 2", len(capture.toolCalls))
+	}
+	for i, calls := range capture.toolCalls {
+		if !slices.Equal(calls, wantCalls) {
+			t.Errorf("tool calls of response %d = %v, want %v", i+1, calls, wantCalls)
+		}
+		if capture.contents[i] != "Checking." {
+			t.Errorf("content of response %d = %q, want %q", i+1, capture.contents[i], "Checking.")
+		}
+		if got := capture.outputs[i].System; !slices.Equal(got, []string{"Be brief."}) {
+			t.Errorf("system prompts of response %d = %v, want [Be brief.]", i+1, got)
+		}
+	}
+
+	if len(capture.shapes) != 2 {
+		t.Fatalf("captured %d shapes, want 2", len(capture.shapes))
+	}
+	for i, shape := range capture.shapes {
+		if !slices.Contains(shape, "message.tool_calls: array") {
+			t.Errorf("shape of response %d lacks message.tool_calls: %v", i+1, shape)
+		}
+	}
+}
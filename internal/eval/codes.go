@@ -0,0 +1,283 @@
+package eval
+
+// Failure codes identify the kind of failure behind a Result. Unlike
+// messages, codes are stable across releases so that results from different
+// runs and models can be aggregated by failure type.
+const (
+	// CodeRequestFailed means an HTTP request failed or returned a non-200 status.
+	CodeRequestFailed = "REQUEST_FAILED"
+	// CodeNoChoices means a response contained no choices.
+	CodeNoChoices = "NO_CHOICES"
+
+	// CodeChoicesCount means the number of choices did not match the requested n.
+	CodeChoicesCount = "CHOICES_COUNT"
+	// CodeChoicesIndex means choice indices were duplicated or out of range.
+	CodeChoicesIndex = "CHOICES_INDEX"
+
+	// CodeContentEmpty means the response content was empty.
+	CodeContentEmpty = "CONTENT_EMPTY"
+	// CodeContentTooShort means the response content was shorter than required.
+	CodeContentTooShort = "CONTENT_TOO_SHORT"
+	// CodeContentMissingExpected means the response content lacked expected terms.
+	CodeContentMissingExpected = "CONTENT_MISSING_EXPECTED"
+	// CodeNeedleNotFound means a passphrase hidden in long context was not retrieved.
+	CodeNeedleNotFound = "NEEDLE_NOT_FOUND"
+	// CodeLongContextRejected means the server rejected a long prompt.
+	CodeLongContextRejected = "LONG_CONTEXT_REJECTED"
+	// CodeLongContextTruncated means usage reported fewer prompt tokens than
+	// a long prompt tokenizes to.
+	CodeLongContextTruncated = "LONG_CONTEXT_TRUNCATED"
+	// CodeStreamCrossTalk means one of several concurrent streams carried
+	// content or chunk ids belonging to another.
+	CodeStreamCrossTalk = "STREAM_CROSS_TALK"
+	// CodeConcurrentServerError means a request failed with a 5xx status
+	// while the server was handling several at once.
+	CodeConcurrentServerError = "CONCURRENT_SERVER_ERROR"
+	// CodeConcurrentTimeout means a request timed out while the server was
+	// handling several at once.
+	CodeConcurrentTimeout = "CONCURRENT_TIMEOUT"
+	// CodeErrorNotRejected means the server accepted a malformed request.
+	CodeErrorNotRejected = "ERROR_NOT_REJECTED"
+	// CodeErrorServerError means the server answered a malformed request
+	// with a 5xx status instead of a 4xx.
+	CodeErrorServerError = "ERROR_STATUS_5XX"
+	// CodeErrorBodyInvalid means the server rejected a malformed request
+	// without an OpenAI-style error object.
+	CodeErrorBodyInvalid = "ERROR_BODY_INVALID"
+	// CodeErrorTimeout means the server did not answer a malformed request
+	// in time.
+	CodeErrorTimeout = "ERROR_TIMEOUT"
+	// CodeNondeterministic means identical seeded requests produced different content.
+	CodeNondeterministic = "NONDETERMINISTIC"
+	// CodeAnswerMissing means no final answer could be extracted from the content.
+	CodeAnswerMissing = "ANSWER_MISSING"
+	// CodeAnswerWrong means the extracted final answer was incorrect.
+	CodeAnswerWrong = "ANSWER_WRONG"
+	// CodeContentNotSimilar means the response content was semantically too
+	// far from the reference text.
+	CodeContentNotSimilar = "CONTENT_NOT_SIMILAR"
+	// CodeMultiTurnContextLost means a fact from an earlier turn was
+	// missing from the answer to a later one.
+	CodeMultiTurnContextLost = "MULTI_TURN_CONTEXT_LOST"
+
+	// CodeReasoningEmpty means reasoning_content was empty.
+	CodeReasoningEmpty = "REASONING_EMPTY"
+	// CodeReasoningLeaked means reasoning appeared in the content field.
+	CodeReasoningLeaked = "REASONING_LEAKED"
+	// CodeReasoningOutOfOrder means a stream sent reasoning after content,
+	// or after a tool call for a family without interleaved reasoning.
+	CodeReasoningOutOfOrder = "REASONING_OUT_OF_ORDER"
+
+	// CodeToolCallMissing means no tool call was returned when one was expected.
+	CodeToolCallMissing = "TOOLCALL_MISSING"
+	// CodeToolCallUnexpected means a tool call was returned when none was expected.
+	CodeToolCallUnexpected = "TOOLCALL_UNEXPECTED"
+	// CodeToolCallCount means the number of tool calls was wrong.
+	CodeToolCallCount = "TOOLCALL_COUNT"
+	// CodeToolCallWrongName means a tool call named the wrong function.
+	CodeToolCallWrongName = "TOOLCALL_WRONG_NAME"
+	// CodeToolCallArgsInvalid means tool call arguments were not valid JSON.
+	CodeToolCallArgsInvalid = "TOOLCALL_ARGS_INVALID"
+	// CodeToolCallArgsMissing means tool call arguments lacked a required field.
+	CodeToolCallArgsMissing = "TOOLCALL_ARGS_MISSING"
+	// CodeToolCallArgsType means a tool call argument had the wrong type.
+	CodeToolCallArgsType = "TOOLCALL_ARGS_TYPE"
+	// CodeToolCallArgsValue means a tool call argument had an unexpected value.
+	CodeToolCallArgsValue = "TOOLCALL_ARGS_VALUE"
+	// CodeToolCallArgsExtraProp means tool call arguments had a property
+	// their schema does not allow.
+	CodeToolCallArgsExtraProp = "TOOLCALL_ARGS_EXTRA_PROP"
+	// CodeToolCallIDMissing means a tool call had no ID.
+	CodeToolCallIDMissing = "TOOLCALL_ID_MISSING"
+	// CodeToolCallIDDuplicate means tool calls of one response shared an ID.
+	CodeToolCallIDDuplicate = "TOOLCALL_ID_DUPLICATE"
+	// CodeToolCallIDUnstable means a streamed tool call's ID changed between chunks.
+	CodeToolCallIDUnstable = "TOOLCALL_ID_UNSTABLE"
+	// CodeToolCallIDRejected means a turn answering tool calls by their IDs was rejected.
+	CodeToolCallIDRejected = "TOOLCALL_ID_REJECTED"
+	// CodeToolCallDeltaIndex means streamed tool call indices were not
+	// contiguous from 0.
+	CodeToolCallDeltaIndex = "TOOLCALL_DELTA_INDEX"
+	// CodeToolCallDeltaHeader means the first streamed delta of a tool call
+	// lacked its id, type, or function name.
+	CodeToolCallDeltaHeader = "TOOLCALL_DELTA_HEADER"
+	// CodeToolCallDeltaRepeated means a later streamed delta of a tool call
+	// repeated its id, type, or function name.
+	CodeToolCallDeltaRepeated = "TOOLCALL_DELTA_REPEATED"
+	// CodeToolChoiceIgnored means a response did not call the function
+	// that tool_choice named.
+	CodeToolChoiceIgnored = "TOOLCHOICE_IGNORED"
+	// CodeToolChoiceStuck means a request with tool_choice "auto" was still
+	// forced to call the function a previous request of the conversation
+	// named.
+	CodeToolChoiceStuck = "TOOLCHOICE_STUCK"
+	// CodeToolSelectionUnreliable means too few sampled responses chose the
+	// correct tool among distractors.
+	CodeToolSelectionUnreliable = "TOOL_SELECTION_UNRELIABLE"
+
+	// CodeSchemaInvalidJSON means structured output was not valid JSON.
+	CodeSchemaInvalidJSON = "SCHEMA_INVALID_JSON"
+	// CodeSchemaMissingField means structured output lacked a required field.
+	CodeSchemaMissingField = "SCHEMA_MISSING_FIELD"
+	// CodeSchemaWrongType means a structured output field had the wrong type.
+	CodeSchemaWrongType = "SCHEMA_WRONG_TYPE"
+	// CodeSchemaExtraProp means structured output had a disallowed property.
+	CodeSchemaExtraProp = "SCHEMA_EXTRA_PROP"
+	// CodeSchemaValueInvalid means a structured output value was outside an
+	// enum or an array had too few or too many items.
+	CodeSchemaValueInvalid = "SCHEMA_VALUE_INVALID"
+	// CodeSchemaNoncompliant means some of many schema-constrained responses
+	// did not match the schema.
+	CodeSchemaNoncompliant = "SCHEMA_NONCOMPLIANT"
+	// CodeSchemaStateLeaked means structured output followed the schema of
+	// an earlier request instead of its own.
+	CodeSchemaStateLeaked = "SCHEMA_STATE_LEAKED"
+	// CodeGrammarNotCached means requests repeating a json_schema kept paying
+	// the latency of compiling it, on a server flavor that caches grammars.
+	CodeGrammarNotCached = "GRAMMAR_NOT_CACHED"
+
+	// CodeEchoMissing means a text completion with echo: true did not start
+	// with the prompt.
+	CodeEchoMissing = "ECHO_MISSING"
+	// CodeStopSequenceIgnored means generated text ran past a stop sequence.
+	CodeStopSequenceIgnored = "STOP_SEQUENCE_IGNORED"
+
+	// CodeModelsInvalid means the /models response was malformed.
+	CodeModelsInvalid = "MODELS_INVALID"
+	// CodeModelNotListed means the model under test was absent from /models.
+	CodeModelNotListed = "MODEL_NOT_LISTED"
+	// CodeModelsUnstable means repeated /models requests listed different ids.
+	CodeModelsUnstable = "MODELS_UNSTABLE"
+
+	// CodeFinishReasonMissing means no finish_reason was returned.
+	CodeFinishReasonMissing = "FINISH_REASON_MISSING"
+	// CodeFinishReasonWrong means finish_reason had an unexpected value.
+	CodeFinishReasonWrong = "FINISH_REASON_WRONG"
+	// CodeFinishReasonEarly means a stream sent finish_reason more than once
+	// or before its final generated data.
+	CodeFinishReasonEarly = "FINISH_REASON_EARLY"
+	// CodeMaxTokensExceeded means usage reported more completion tokens than
+	// max_tokens allowed.
+	CodeMaxTokensExceeded = "MAX_TOKENS_EXCEEDED"
+	// CodeStreamNotTerminated means a stream truncated by max_tokens did not
+	// end.
+	CodeStreamNotTerminated = "STREAM_NOT_TERMINATED"
+	// CodeMaxCompletionTokensRejected means a request setting
+	// max_completion_tokens was rejected.
+	CodeMaxCompletionTokensRejected = "MAX_COMPLETION_TOKENS_REJECTED"
+	// CodeMaxCompletionTokensMismatch means max_completion_tokens truncated
+	// differently from max_tokens of the same value.
+	CodeMaxCompletionTokensMismatch = "MAX_COMPLETION_TOKENS_MISMATCH"
+
+	// CodeSSEContentType means a stream lacked Content-Type text/event-stream.
+	CodeSSEContentType = "SSE_CONTENT_TYPE"
+	// CodeSSEMalformed means a stream's bytes were not well-formed SSE events.
+	CodeSSEMalformed = "SSE_MALFORMED"
+	// CodeSSEDoneMissing means a stream did not end with data: [DONE].
+	CodeSSEDoneMissing = "SSE_DONE_MISSING"
+	// CodeStreamErrorUnsignaled means a stream that failed mid-generation
+	// ended without a finish_reason, error event, or HTTP trailer saying so.
+	CodeStreamErrorUnsignaled = "STREAM_ERROR_UNSIGNALED"
+
+	// CodeUsageMissing means usage was absent or had zero token counts.
+	CodeUsageMissing = "USAGE_MISSING"
+	// CodeUsageNotFinal means streamed usage did not arrive in the final chunk.
+	CodeUsageNotFinal = "USAGE_NOT_FINAL"
+	// CodeUsageUnexpected means a stream carried usage that was not requested.
+	CodeUsageUnexpected = "USAGE_UNEXPECTED"
+	// CodeUsageMismatch means total_tokens did not equal prompt plus completion tokens.
+	CodeUsageMismatch = "USAGE_MISMATCH"
+	// CodeUsageTokenCount means completion_tokens disagreed with the number
+	// of streamed chunks carrying text or with the token count of the text.
+	CodeUsageTokenCount = "USAGE_TOKEN_COUNT_MISMATCH"
+
+	// CodeLogprobsMissing means logprobs were requested but not returned.
+	CodeLogprobsMissing = "LOGPROBS_MISSING"
+	// CodeLogprobsCount means the number of token logprobs did not match the
+	// completion token count.
+	CodeLogprobsCount = "LOGPROBS_COUNT"
+	// CodeLogprobsTopCount means a token had the wrong number of top_logprobs.
+	CodeLogprobsTopCount = "LOGPROBS_TOP_COUNT"
+	// CodeLogprobsInvalid means a logprob was not a valid log probability.
+	CodeLogprobsInvalid = "LOGPROBS_INVALID"
+
+	// CodeLogitBiasRejected means a request setting logit_bias was rejected.
+	CodeLogitBiasRejected = "LOGIT_BIAS_REJECTED"
+	// CodeLogitBiasIgnored means banning a token with logit_bias did not
+	// change greedy output that started with it.
+	CodeLogitBiasIgnored = "LOGIT_BIAS_IGNORED"
+
+	// CodeTimingsMissing means llama.cpp reported no timings object.
+	CodeTimingsMissing = "TIMINGS_MISSING"
+	// CodeTimingsInconsistent means llama.cpp timings disagreed with usage
+	// or with themselves.
+	CodeTimingsInconsistent = "TIMINGS_INCONSISTENT"
+
+	// CodeExtensionError means a server extension field caused a server
+	// error, or a rejection without an error message.
+	CodeExtensionError = "EXTENSION_ERROR"
+	// CodeExtensionInvalid means output violated the constraint requested by
+	// a server extension field.
+	CodeExtensionInvalid = "EXTENSION_OUTPUT_INVALID"
+
+	// CodeTemplateFailed means the /apply-template request failed.
+	CodeTemplateFailed = "TEMPLATE_FAILED"
+	// CodeTemplateReasoningMissing means reasoning was absent from a rendered template.
+	CodeTemplateReasoningMissing = "TEMPLATE_REASONING_MISSING"
+	// CodeTemplateReasoningLeaked means reasoning was rendered where it should be dropped.
+	CodeTemplateReasoningLeaked = "TEMPLATE_REASONING_LEAKED"
+	// CodeTemplateToolCallMissing means tool call details were absent from a rendered template.
+	CodeTemplateToolCallMissing = "TEMPLATE_TOOLCALL_MISSING"
+	// CodeTemplateToolResponseMissing means a tool response was absent from a rendered template.
+	CodeTemplateToolResponseMissing = "TEMPLATE_TOOL_RESPONSE_MISSING"
+	// CodeTemplateServerError means an unusual conversation shape caused a
+	// server error, or a rejection without an error message.
+	CodeTemplateServerError = "TEMPLATE_SERVER_ERROR"
+	// CodeTemplateMessageDropped means the model answered without
+	// information given only in a message the template should have rendered.
+	CodeTemplateMessageDropped = "TEMPLATE_MESSAGE_DROPPED"
+	// CodeTemplateMarkerMissing means part of a rendered template was not
+	// wrapped in the markers declared for the model's family.
+	CodeTemplateMarkerMissing = "TEMPLATE_MARKER_MISSING"
+	// CodePrefillRestarted means the response to a trailing assistant
+	// message repeated it instead of continuing it.
+	CodePrefillRestarted = "PREFILL_RESTARTED"
+	// CodePrefillNotContinued means the response to a trailing assistant
+	// message did not pick up where it left off.
+	CodePrefillNotContinued = "PREFILL_NOT_CONTINUED"
+
+	// CodeRegressionTemplate means a rendered template differed from the
+	// regression pack.
+	CodeRegressionTemplate = "REGRESSION_TEMPLATE_CHANGED"
+	// CodeRegressionShape means a response's fields or their types differed
+	// from the regression pack.
+	CodeRegressionShape = "REGRESSION_SHAPE_CHANGED"
+
+	// CodeUserAgentDependent means the server answered a request differently
+	// depending on its User-Agent.
+	CodeUserAgentDependent = "USER_AGENT_DEPENDENT"
+	// CodeResponseCached means a repeated request was answered from a cache
+	// rather than generated anew.
+	CodeResponseCached = "RESPONSE_CACHED"
+
+	// CodeAgenticMaxIterations means an agentic loop never produced a final answer.
+	CodeAgenticMaxIterations = "AGENTIC_MAX_ITERATIONS"
+	// CodeAgenticTooFewRounds means an agentic loop finished with too few tool rounds.
+	CodeAgenticTooFewRounds = "AGENTIC_TOO_FEW_ROUNDS"
+
+	// CodeToolImageRejected means a request with an image in a tool result
+	// was rejected, though the model accepts images in user messages.
+	CodeToolImageRejected = "TOOL_IMAGE_REJECTED"
+	// CodeToolImageIgnored means the response did not describe an image in a
+	// tool result, though the model describes it in a user message.
+	CodeToolImageIgnored = "TOOL_IMAGE_IGNORED"
+	// CodeVisionUnsupported means the model did not describe an image in a
+	// user message, so it or the server does not accept images.
+	CodeVisionUnsupported = "VISION_UNSUPPORTED"
+	// CodeVisionRejected means a request with an image in a user message was
+	// rejected.
+	CodeVisionRejected = "VISION_REJECTED"
+	// CodeVisionImageMixup means the model described another image of a
+	// message than the one asked about.
+	CodeVisionImageMixup = "VISION_IMAGE_MIXUP"
+)
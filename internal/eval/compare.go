@@ -0,0 +1,120 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Comparison is the outcome of an eval on the comparison server, with the
+// ways it diverged from the server under test.
+type Comparison struct {
+	Passed  bool
+	Code    string
+	Message string
+	// Divergences describes each difference from the server under test;
+	// empty if both behaved alike.
+	Divergences []string `json:",omitempty"`
+	// Diffs holds the rendered templates that diverged.
+	Diffs []Diff `json:",omitempty"`
+}
+
+// startComparison runs an eval against the comparison server in the
+// background, on a copy of the eval so it does not disturb the run under
+// test. The returned function waits for it and compares its outcome with
+// the result and capture of the server under test.
+func (r *Runner) startComparison(e Eval, streaming bool) func(Result, *responseCapture) *Comparison {
+	e = isolatedEval(e, streaming)
+	other := &responseCapture{}
+
+	var result Result
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		result = r.runIterations(e, r.config.Compare.WithLogger(other).WithObserver(other), nil)
+	}()
+
+	return func(primary Result, capture *responseCapture) *Comparison {
+		<-done
+		cmp := &Comparison{
+			Passed:  result.Passed,
+			Code:    result.Code,
+			Message: result.Message,
+		}
+		cmp.Divergences, cmp.Diffs = divergences(primary, capture, result, other)
+		return cmp
+	}
+}
+
+// divergences describes how the comparison server's run of an eval
+// differed from the run under test: in outcome, in the number of chat
+// completions and the tool calls of each, and in prompts rendered by /apply-template, with a diff
+// of the first diverging prompt.
+func divergences(primary Result, pc *responseCapture, other Result, oc *responseCapture) ([]string, []Diff) {
+	var out []string
+	var diffs []Diff
+
+	switch {
+	case primary.Passed && !other.Passed:
+		out = append(out, fmt.Sprintf("outcome: passed, but failed on comparison server (%s): %s", other.Code, other.Message))
+	case !primary.Passed && other.Passed:
+		out = append(out, fmt.Sprintf("outcome: failed (%s), but passed on comparison server", primary.Code))
+	case !primary.Passed && primary.Code != other.Code:
+		out = append(out, fmt.Sprintf("outcome: failed with %s, but with %s on comparison server: %s", primary.Code, other.Code, other.Message))
+	}
+
+	// A side that captured fewer chat completions, or none, made requests
+	// the other did not, so its missing responses diverge too
+	if len(pc.toolCalls) != len(oc.toolCalls) {
+		out = append(out, fmt.Sprintf("chat completions: %d captured, but %d on comparison server",
+			len(pc.toolCalls), len(oc.toolCalls)))
+	}
+	for i := range min(len(pc.toolCalls), len(oc.toolCalls)) {
+		a, b := pc.toolCalls[i], oc.toolCalls[i]
+		if strings.Join(a, "\n") != strings.Join(b, "\n") {
+			out = append(out, fmt.Sprintf("tool calls of response %d: %s, but %s on comparison server",
+				i+1, describeToolCalls(a), describeToolCalls(b)))
+			break
+		}
+	}
+
+	for i := range min(len(pc.prompts), len(oc.prompts)) {
+		a, b := pc.prompts[i], oc.prompts[i]
+		if a != b {
+			at := commonPrefixLen(a, b)
+			out = append(out, fmt.Sprintf("rendered template %d: differs at byte %d: %q, but %q on comparison server",
+				i+1, at, excerptAt(a, at), excerptAt(b, at)))
+			diffs = append(diffs, Diff{
+				ALabel: fmt.Sprintf("template %d", i+1),
+				BLabel: "comparison server",
+				A:      a,
+				B:      b,
+			})
+			break
+		}
+	}
+
+	return out, diffs
+}
+
+// describeToolCalls lists tool calls for a divergence message.
+func describeToolCalls(calls []string) string {
+	if len(calls) == 0 {
+		return "none"
+	}
+	return "[" + strings.Join(calls, ", ") + "]"
+}
+
+// commonPrefixLen returns the length in bytes of the longest common prefix
+// of a and b.
+func commonPrefixLen(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// excerptAt returns up to 40 bytes of s starting at offset i.
+func excerptAt(s string, i int) string {
+	return s[i:min(i+40, len(s))]
+}
@@ -0,0 +1,48 @@
+package eval
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDivergencesChatCompletionCount(t *testing.T) {
+	calls := [][]string{{`get_weather{"location":"Paris"}`}}
+	tests := []struct {
+		name    string
+		primary [][]string
+		other   [][]string
+		want    []string
+	}{
+		{
+			name:    "identical",
+			primary: calls,
+			other:   calls,
+		},
+		{
+			name:    "empty comparison capture",
+			primary: calls,
+			want:    []string{"chat completions: 1 captured, but 0 on comparison server"},
+		},
+		{
+			name:  "empty primary capture",
+			other: calls,
+			want:  []string{"chat completions: 0 captured, but 1 on comparison server"},
+		},
+		{
+			name:    "fewer on comparison server",
+			primary: [][]string{calls[0], nil},
+			other:   [][]string{calls[0]},
+			want:    []string{"chat completions: 2 captured, but 1 on comparison server"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := &responseCapture{toolCalls: tt.primary}
+			oc := &responseCapture{toolCalls: tt.other}
+			got, _ := divergences(Result{Passed: true}, pc, Result{Passed: true}, oc)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("divergences = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const compatCategory = "Compatibility"
+
+// standardFinishReasons are the finish_reason values of the OpenAI API.
+var standardFinishReasons = []string{"stop", "length", "tool_calls", "content_filter", "function_call"}
+
+// compatProfile describes the known deviations of a server flavor from the
+// OpenAI API. Under the flavor they are reported as notes instead of
+// failing evals.
+type compatProfile struct {
+	// server is the display name used in notes.
+	server string
+	// finishReasons maps non-standard finish_reason values to the OpenAI
+	// values they stand for.
+	finishReasons map[string][]string
+}
+
+// compatProfiles holds the profiles of flavors that tolerate deviations.
+var compatProfiles = map[string]compatProfile{
+	FlavorTGI: {
+		server: "TGI",
+		// TGI also ends tool calls with eos_token
+		finishReasons: map[string][]string{
+			"eos_token":     {"stop", "tool_calls"},
+			"stop_sequence": {"stop"},
+		},
+	},
+	FlavorOpenRouter: {
+		server: "OpenRouter",
+		// Provider finish reasons that can pass through unnormalized
+		finishReasons: map[string][]string{
+			"end_turn":      {"stop"},
+			"stop_sequence": {"stop"},
+			"STOP":          {"stop"},
+			"max_tokens":    {"length"},
+			"MAX_TOKENS":    {"length"},
+			"tool_use":      {"tool_calls"},
+		},
+	},
+}
+
+// matchFinishReason reports whether reason is the expected finish_reason,
+// either exactly or as an alias known to the flavor's compatibility
+// profile. A match by alias comes with a note describing it.
+func matchFinishReason(flavor, reason, expected string) (ok bool, note string) {
+	if reason == expected {
+		return true, ""
+	}
+	profile, known := compatProfiles[flavor]
+	if !known {
+		return false, ""
+	}
+	if slices.Contains(profile.finishReasons[reason], expected) {
+		return true, fmt.Sprintf("finish_reason %q is %s's name for %q", reason, profile.server, expected)
+	}
+	return false, ""
+}
+
+// compatEvals returns all compatibility evals.
+func compatEvals() []Eval {
+	return []Eval{
+		&compatMetadataEval{flavor: FlavorTGI},
+		&compatMetadataEval{flavor: FlavorOpenRouter},
+		&userAgentEval{},
+		&responseCacheEval{},
+	}
+}
+
+// compatMetadataEval checks a plain completion for the response metadata
+// deviations known to a flavor: non-standard finish_reason strings, extra
+// fields such as provider and native_finish_reason, and unusual object
+// types or ids. Known deviations are reported as notes; only unusable
+// responses fail.
+type compatMetadataEval struct {
+	flavor    string
+	streaming bool
+}
+
+func (e *compatMetadataEval) Name() string {
+	return e.flavor + "_metadata"
+}
+
+func (e *compatMetadataEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *compatMetadataEval) Streaming() bool             { return e.streaming }
+
+func (e *compatMetadataEval) Category() string {
+	return compatCategory
+}
+
+func (e *compatMetadataEval) Class() string {
+	return ClassStandard
+}
+
+func (e *compatMetadataEval) Flavor() string {
+	return e.flavor
+}
+
+// responseMetadata is the metadata of a response, in either mode, checked
+// by compatMetadataEval.
+type responseMetadata struct {
+	id                 string
+	objects            []string // distinct object types seen
+	content            string
+	finishReason       string
+	nativeFinishReason string
+	provider           string
+}
+
+func (e *compatMetadataEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Say hello."},
+		},
+	}
+
+	var meta responseMetadata
+	expectedObject := "chat.completion"
+
+	if e.streaming {
+		expectedObject = "chat.completion.chunk"
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		reason, code, msg := streamFinishReason(result.Chunks)
+		if code != "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     code,
+				Message:  msg,
+			}
+		}
+		meta.finishReason = reason
+		meta.content = result.Content
+		for _, chunk := range result.Chunks {
+			if meta.id == "" {
+				meta.id = chunk.ID
+			}
+			if !slices.Contains(meta.objects, chunk.Object) {
+				meta.objects = append(meta.objects, chunk.Object)
+			}
+			if chunk.Provider != "" {
+				meta.provider = chunk.Provider
+			}
+			for _, choice := range chunk.Choices {
+				if choice.NativeFinishReason != nil && *choice.NativeFinishReason != "" {
+					meta.nativeFinishReason = *choice.NativeFinishReason
+				}
+			}
+		}
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		if len(resp.Choices) == 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeNoChoices,
+				Message:  "no choices in response",
+			}
+		}
+		choice := resp.Choices[0]
+		if choice.FinishReason == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeFinishReasonMissing,
+				Message:  "response has no finish_reason",
+			}
+		}
+		meta = responseMetadata{
+			id:                 resp.ID,
+			objects:            []string{resp.Object},
+			content:            choice.Message.Content,
+			finishReason:       choice.FinishReason,
+			nativeFinishReason: choice.NativeFinishReason,
+			provider:           resp.Provider,
+		}
+	}
+
+	if strings.TrimSpace(meta.content) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "content is empty",
+		}
+	}
+
+	var notes []string
+
+	if !slices.Contains(standardFinishReasons, meta.finishReason) {
+		aliases := compatProfiles[e.flavor].finishReasons[meta.finishReason]
+		if len(aliases) == 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeFinishReasonWrong,
+				Message:  fmt.Sprintf("finish_reason %q is neither standard nor a known %s value", meta.finishReason, compatProfiles[e.flavor].server),
+			}
+		}
+		_, note := matchFinishReason(e.flavor, meta.finishReason, aliases[0])
+		notes = append(notes, note)
+	}
+	if meta.nativeFinishReason != "" {
+		notes = append(notes, fmt.Sprintf("non-standard field native_finish_reason %q", meta.nativeFinishReason))
+	}
+	if meta.provider != "" {
+		notes = append(notes, fmt.Sprintf("non-standard field provider %q", meta.provider))
+	}
+	for _, object := range meta.objects {
+		if object != expectedObject {
+			notes = append(notes, fmt.Sprintf("object is %q, expected %q", object, expectedObject))
+		}
+	}
+	if meta.id == "" {
+		notes = append(notes, "response id is empty")
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Notes:    notes,
+	}
+}
@@ -0,0 +1,355 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const completionsCategory = "Completions"
+
+// completionsTopN is the number of alternatives requested by
+// completion_logprobs.
+const completionsTopN = 2
+
+// completionsPrompt is a plain-text prompt that any model continues
+// sensibly without a chat template.
+const completionsPrompt = "The three primary colors are red, yellow, and"
+
+// completionsEvals returns all legacy text completions evals.
+func completionsEvals() []Eval {
+	return []Eval{
+		&completionTextEval{},
+		&completionEchoEval{},
+		&completionLogprobsEval{},
+		&completionStopEval{},
+	}
+}
+
+// textCompletion is the part of a text completion the completions evals
+// inspect.
+type textCompletion struct {
+	text         string
+	finishReason string
+	logprobs     *client.CompletionLogprobs
+	usage        *client.Usage
+}
+
+// fetchTextCompletion sends req to the text completions endpoint and returns
+// the first choice. On failure it returns a non-nil Result.
+func fetchTextCompletion(ctx context.Context, c *client.Client, e Eval, streaming bool, req client.CompletionRequest) (textCompletion, *Result) {
+	var tc textCompletion
+
+	if streaming {
+		result, err := c.CompletionStream(ctx, req)
+		if err != nil {
+			return tc, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		tc.text = result.Text
+		tc.finishReason = result.FinishReason
+		tc.logprobs = result.Logprobs
+		tc.usage = result.Usage
+		return tc, nil
+	}
+
+	resp, err := c.Completion(ctx, req)
+	if err != nil {
+		return tc, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	if len(resp.Choices) == 0 {
+		return tc, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeNoChoices,
+			Message:  "no choices in response",
+		}
+	}
+	tc.text = resp.Choices[0].Text
+	tc.finishReason = resp.Choices[0].FinishReason
+	tc.logprobs = resp.Choices[0].Logprobs
+	tc.usage = resp.Usage
+	return tc, nil
+}
+
+// completionTextEval verifies that the text completions endpoint continues a
+// prompt and reports a finish_reason.
+type completionTextEval struct {
+	streaming bool
+}
+
+func (e *completionTextEval) Name() string {
+	return "completion_text"
+}
+
+func (e *completionTextEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *completionTextEval) Streaming() bool             { return e.streaming }
+
+func (e *completionTextEval) Category() string {
+	return completionsCategory
+}
+
+func (e *completionTextEval) Class() string {
+	return ClassStandard
+}
+
+func (e *completionTextEval) Run(ctx context.Context, c *client.Client) Result {
+	tc, failed := fetchTextCompletion(ctx, c, e, e.streaming, client.CompletionRequest{
+		Prompt:    completionsPrompt,
+		MaxTokens: 16,
+	})
+	if failed != nil {
+		return *failed
+	}
+
+	if strings.TrimSpace(tc.text) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "text is empty",
+		}
+	}
+
+	if tc.finishReason == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeFinishReasonMissing,
+			Message:  "no finish_reason in response",
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// completionEchoEval verifies that echo: true prepends the prompt to the
+// generated text.
+type completionEchoEval struct {
+	streaming bool
+}
+
+func (e *completionEchoEval) Name() string {
+	return "completion_echo"
+}
+
+func (e *completionEchoEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *completionEchoEval) Streaming() bool             { return e.streaming }
+
+func (e *completionEchoEval) Category() string {
+	return completionsCategory
+}
+
+func (e *completionEchoEval) Class() string {
+	return ClassStandard
+}
+
+func (e *completionEchoEval) Run(ctx context.Context, c *client.Client) Result {
+	tc, failed := fetchTextCompletion(ctx, c, e, e.streaming, client.CompletionRequest{
+		Prompt:    completionsPrompt,
+		MaxTokens: 8,
+		Echo:      true,
+	})
+	if failed != nil {
+		return *failed
+	}
+
+	if !strings.HasPrefix(tc.text, completionsPrompt) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeEchoMissing,
+			Message:  "text does not start with the prompt despite echo: true",
+		}
+	}
+
+	if strings.TrimSpace(strings.TrimPrefix(tc.text, completionsPrompt)) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "no text generated after the echoed prompt",
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// completionLogprobsEval verifies the legacy logprobs format: one token,
+// logprob, and set of alternatives per generated token.
+type completionLogprobsEval struct {
+	streaming bool
+}
+
+func (e *completionLogprobsEval) Name() string {
+	return "completion_logprobs"
+}
+
+func (e *completionLogprobsEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *completionLogprobsEval) Streaming() bool             { return e.streaming }
+
+func (e *completionLogprobsEval) Category() string {
+	return completionsCategory
+}
+
+func (e *completionLogprobsEval) Class() string {
+	return ClassStandard
+}
+
+func (e *completionLogprobsEval) Run(ctx context.Context, c *client.Client) Result {
+	topN := completionsTopN
+	tc, failed := fetchTextCompletion(ctx, c, e, e.streaming, client.CompletionRequest{
+		Prompt:    completionsPrompt,
+		MaxTokens: 8,
+		Logprobs:  &topN,
+	})
+	if failed != nil {
+		return *failed
+	}
+
+	lp := tc.logprobs
+	if lp == nil || len(lp.Tokens) == 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLogprobsMissing,
+			Message:  fmt.Sprintf("response has no logprobs despite logprobs: %d", topN),
+		}
+	}
+
+	if len(lp.TokenLogprobs) != len(lp.Tokens) || len(lp.TopLogprobs) != len(lp.Tokens) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLogprobsCount,
+			Message: fmt.Sprintf("logprobs columns differ in length: %d tokens, %d token_logprobs, %d top_logprobs",
+				len(lp.Tokens), len(lp.TokenLogprobs), len(lp.TopLogprobs)),
+		}
+	}
+
+	if tc.usage != nil && tc.usage.CompletionTokens > 0 && len(lp.Tokens) != tc.usage.CompletionTokens {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLogprobsCount,
+			Message:  fmt.Sprintf("got logprobs for %d tokens, usage reports %d completion tokens", len(lp.Tokens), tc.usage.CompletionTokens),
+		}
+	}
+
+	for i, logprob := range lp.TokenLogprobs {
+		if logprob == nil || *logprob > 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeLogprobsInvalid,
+				Message:  fmt.Sprintf("token %d (%q) has missing or positive logprob", i, lp.Tokens[i]),
+			}
+		}
+		// Some servers also include the sampled token when it falls
+		// outside the top alternatives
+		if n := len(lp.TopLogprobs[i]); n < topN || n > topN+1 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeLogprobsTopCount,
+				Message:  fmt.Sprintf("token %d (%q) has %d top_logprobs, expected %d", i, lp.Tokens[i], n, topN),
+			}
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// completionStopEval verifies that generation halts at a stop sequence,
+// which is excluded from the text, with finish_reason "stop".
+type completionStopEval struct {
+	streaming bool
+}
+
+func (e *completionStopEval) Name() string {
+	return "completion_stop"
+}
+
+func (e *completionStopEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *completionStopEval) Streaming() bool             { return e.streaming }
+
+func (e *completionStopEval) Category() string {
+	return completionsCategory
+}
+
+func (e *completionStopEval) Class() string {
+	return ClassStandard
+}
+
+func (e *completionStopEval) Run(ctx context.Context, c *client.Client) Result {
+	const stop = "7"
+	tc, failed := fetchTextCompletion(ctx, c, e, e.streaming, client.CompletionRequest{
+		Prompt:    "1, 2, 3, 4,",
+		MaxTokens: 64,
+		Stop:      []string{stop},
+	})
+	if failed != nil {
+		return *failed
+	}
+
+	if strings.Contains(tc.text, stop) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeStopSequenceIgnored,
+			Message:  fmt.Sprintf("text contains stop sequence %q: %q", stop, tc.text),
+		}
+	}
+
+	if tc.finishReason != "stop" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeFinishReasonWrong,
+			Message:  fmt.Sprintf("expected finish_reason \"stop\" at stop sequence, got %q", tc.finishReason),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
@@ -0,0 +1,191 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const concurrencyCategory = "Concurrency"
+
+// DefaultStressConcurrency is the number of concurrent streams sent by
+// concurrent_streams when none is configured.
+const DefaultStressConcurrency = 8
+
+// concurrencyEvals returns all concurrency evals.
+func concurrencyEvals() []Eval {
+	return []Eval{
+		&concurrentStreamsEval{},
+	}
+}
+
+// concurrentStreamsEval sends the same streaming request, differing only in
+// a code to repeat, several times at once, and verifies that every stream
+// completes and carries only its own code and chunk id. Batching and
+// scheduler bugs show up as one request's tokens in another's stream.
+type concurrentStreamsEval struct {
+	concurrency int
+}
+
+func (e *concurrentStreamsEval) Name() string {
+	return "concurrent_streams"
+}
+
+func (e *concurrentStreamsEval) Category() string {
+	return concurrencyCategory
+}
+
+func (e *concurrentStreamsEval) Class() string {
+	return ClassStandard
+}
+
+func (e *concurrentStreamsEval) Tags() []string {
+	return []string{TagSlow}
+}
+
+// IsStreamingOnly returns true because cross-talk is checked per stream.
+func (e *concurrentStreamsEval) IsStreamingOnly() bool {
+	return true
+}
+
+// IsDefaultDisabled returns true because the eval loads the server with
+// many requests at once.
+func (e *concurrentStreamsEval) IsDefaultDisabled() bool {
+	return true
+}
+
+func (e *concurrentStreamsEval) configure(cfg RunnerConfig) {
+	e.concurrency = cfg.StressConcurrency
+}
+
+// concurrentStreamCode returns the code stream n is asked to repeat.
+func concurrentStreamCode(n int) string {
+	return fmt.Sprintf("%s-%d", strings.ToUpper(needleWords[n%len(needleWords)]), 4100+n*37)
+}
+
+// concurrentStreamRequest returns the request for stream n.
+func concurrentStreamRequest(n int) client.ChatCompletionRequest {
+	temperature := 0.0
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Repeat this code exactly, then count from one to twenty in words: " + concurrentStreamCode(n)},
+		},
+		Temperature: &temperature,
+		MaxTokens:   128,
+	}
+}
+
+func (e *concurrentStreamsEval) Run(ctx context.Context, c *client.Client) Result {
+	k := e.concurrency
+	if k <= 0 {
+		k = DefaultStressConcurrency
+	}
+
+	// The logger records one exchange at a time, so the streams are not
+	// logged; failing streams' content is attached as artifacts instead
+	streamClient := c.WithLogger(nil)
+	results := make([]*client.StreamResult, k)
+	errs := make([]error, k)
+	var wg sync.WaitGroup
+	for i := range k {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = streamClient.ChatCompletionStream(ctx, concurrentStreamRequest(i))
+		}()
+	}
+	wg.Wait()
+
+	var crossTalk, serverErrors, timeouts, otherErrors []string
+	var artifacts []Artifact
+	for i := range k {
+		if err := errs[i]; err != nil {
+			var statusErr *client.StatusError
+			switch {
+			case errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusInternalServerError:
+				serverErrors = append(serverErrors, fmt.Sprintf("stream %d: status %d", i+1, statusErr.StatusCode))
+			case errors.Is(err, context.DeadlineExceeded):
+				timeouts = append(timeouts, fmt.Sprintf("stream %d", i+1))
+			default:
+				otherErrors = append(otherErrors, fmt.Sprintf("stream %d: %v", i+1, err))
+			}
+			continue
+		}
+
+		var problems []string
+		if ids := chunkIDs(results[i].Chunks); len(ids) > 1 {
+			problems = append(problems, "chunk ids "+strings.Join(ids, ", "))
+		}
+		text := strings.ToUpper(results[i].ReasoningContent + results[i].Content)
+		for j := range k {
+			if j != i && strings.Contains(text, concurrentStreamCode(j)) {
+				problems = append(problems, fmt.Sprintf("code %s of stream %d", concurrentStreamCode(j), j+1))
+			}
+		}
+		if len(problems) > 0 {
+			crossTalk = append(crossTalk, fmt.Sprintf("stream %d has %s", i+1, strings.Join(problems, " and ")))
+			artifacts = append(artifacts, Artifact{
+				Name: fmt.Sprintf("stream-%d.txt", i+1),
+				Data: []byte(results[i].Content),
+			})
+		}
+	}
+
+	completed := k - len(serverErrors) - len(timeouts) - len(otherErrors)
+	scores := map[string]float64{"completed_streams": float64(completed)}
+
+	// The code names the worst kind of failure; the message lists them all
+	var code string
+	switch {
+	case len(crossTalk) > 0:
+		code = CodeStreamCrossTalk
+	case len(serverErrors) > 0:
+		code = CodeConcurrentServerError
+	case len(timeouts) > 0:
+		code = CodeConcurrentTimeout
+	case len(otherErrors) > 0:
+		code = CodeRequestFailed
+	}
+	if code != "" {
+		failures := append(append(crossTalk, serverErrors...), otherErrors...)
+		if len(timeouts) > 0 {
+			failures = append(failures, "timed out: "+strings.Join(timeouts, ", "))
+		}
+		return Result{
+			Name:      e.Name(),
+			Category:  e.Category(),
+			Passed:    false,
+			Code:      code,
+			Message:   fmt.Sprintf("%d/%d concurrent streams completed cleanly; %s", completed-len(crossTalk), k, strings.Join(failures, "; ")),
+			Scores:    scores,
+			Artifacts: artifacts,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("%d concurrent streams completed without cross-talk", k),
+		Scores:   scores,
+	}
+}
+
+// chunkIDs returns the distinct non-empty ids of chunks, in order of first
+// appearance.
+func chunkIDs(chunks []client.ChatCompletionChunk) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, chunk := range chunks {
+		if chunk.ID != "" && !seen[chunk.ID] {
+			seen[chunk.ID] = true
+			ids = append(ids, chunk.ID)
+		}
+	}
+	return ids
+}
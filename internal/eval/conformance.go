@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// TemplateMarkers declares the markers a model family's chat template must
+// wrap parts of a conversation in. Empty markers are not checked.
+type TemplateMarkers struct {
+	// Family is the name the markers were declared under.
+	Family         string
+	ReasoningOpen  string
+	ReasoningClose string
+	ToolCallOpen   string
+	ToolCallClose  string
+	// InterleavedReasoning allows the family's models to resume reasoning
+	// after a tool call within one response, for reasoning_stream_order.
+	InterleavedReasoning bool
+}
+
+// declared reports whether any markers are declared.
+func (m *TemplateMarkers) declared() bool {
+	return m.ReasoningOpen != "" || m.ToolCallOpen != ""
+}
+
+// templateConformanceEval renders a conversation with reasoning and a tool
+// call through /apply-template and checks that each part is wrapped in the
+// markers declared for the model's family. It is disabled unless markers
+// are declared.
+type templateConformanceEval struct {
+	markers *TemplateMarkers
+}
+
+func (e *templateConformanceEval) Name() string {
+	return "template_conformance"
+}
+
+func (e *templateConformanceEval) SetStreaming(streaming bool) {}
+func (e *templateConformanceEval) Streaming() bool             { return false }
+
+func (e *templateConformanceEval) Category() string {
+	return templateCategory
+}
+
+func (e *templateConformanceEval) Class() string {
+	return ClassStandard
+}
+
+func (e *templateConformanceEval) Tags() []string {
+	return []string{TagTemplate, TagTools}
+}
+
+// IsBlockingOnly returns true because /apply-template has no streaming form.
+func (e *templateConformanceEval) IsBlockingOnly() bool {
+	return true
+}
+
+func (e *templateConformanceEval) IsDefaultDisabled() bool {
+	return e.markers == nil || !e.markers.declared()
+}
+
+func (e *templateConformanceEval) configure(cfg RunnerConfig) {
+	e.markers = cfg.Template
+}
+
+func (e *templateConformanceEval) Run(ctx context.Context, c *client.Client) Result {
+	if e.markers == nil || !e.markers.declared() {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+			Notes:    []string{"nothing checked, since no template markers are declared for the model"},
+		}
+	}
+
+	// The reasoning must not name the tool, whose name locates the tool call
+	reasoning := "The user wants the weather in Lisbon, so I will look up the current conditions."
+	messages := []client.Message{
+		{Role: "user", Content: "What's the weather like in Lisbon?"},
+		{
+			Role:             "assistant",
+			ReasoningContent: reasoning,
+			ToolCalls: []client.ToolCall{
+				{
+					ID:   "call_conformance",
+					Type: "function",
+					Function: client.ToolCallFunction{
+						Name:      "get_weather",
+						Arguments: `{"location": "Lisbon, Portugal"}`,
+					},
+				},
+			},
+		},
+		{
+			Role:       "tool",
+			ToolCallID: "call_conformance",
+			Content:    `{"temperature": 21, "conditions": "clear"}`,
+		},
+	}
+
+	prompt, err := c.ApplyTemplate(ctx, messages)
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTemplateFailed,
+			Message:  "/apply-template failed: " + err.Error(),
+		}
+	}
+
+	m := e.markers
+	if m.ReasoningOpen != "" {
+		if !strings.Contains(prompt, reasoning) {
+			return Result{
+				Name:      e.Name(),
+				Category:  e.Category(),
+				Passed:    false,
+				Code:      CodeTemplateReasoningMissing,
+				Message:   "reasoning_content not found in rendered template",
+				Artifacts: renderedTemplate(prompt),
+			}
+		}
+		if !wrappedIn(prompt, reasoning, m.ReasoningOpen, m.ReasoningClose) {
+			return Result{
+				Name:      e.Name(),
+				Category:  e.Category(),
+				Passed:    false,
+				Code:      CodeTemplateMarkerMissing,
+				Message:   fmt.Sprintf("reasoning is not wrapped in %s...%s as declared for %s", m.ReasoningOpen, m.ReasoningClose, m.Family),
+				Artifacts: renderedTemplate(prompt),
+			}
+		}
+	}
+
+	if m.ToolCallOpen != "" {
+		if !strings.Contains(prompt, "get_weather") {
+			return Result{
+				Name:      e.Name(),
+				Category:  e.Category(),
+				Passed:    false,
+				Code:      CodeTemplateToolCallMissing,
+				Message:   "tool call function name 'get_weather' not found in rendered template",
+				Artifacts: renderedTemplate(prompt),
+			}
+		}
+		if !wrappedIn(prompt, "get_weather", m.ToolCallOpen, m.ToolCallClose) {
+			return Result{
+				Name:      e.Name(),
+				Category:  e.Category(),
+				Passed:    false,
+				Code:      CodeTemplateMarkerMissing,
+				Message:   fmt.Sprintf("tool call is not wrapped in %s...%s as declared for %s", m.ToolCallOpen, m.ToolCallClose, m.Family),
+				Artifacts: renderedTemplate(prompt),
+			}
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// wrappedIn returns true if the first occurrence of inner in s lies between
+// an open marker and the close marker that follows it.
+func wrappedIn(s, inner, open, close string) bool {
+	i := strings.Index(s, inner)
+	if i < 0 {
+		return false
+	}
+
+	before := s[:i]
+	o := strings.LastIndex(before, open)
+	if o < 0 || strings.Contains(before[o+len(open):], close) {
+		return false
+	}
+
+	after := s[i+len(inner):]
+	end := strings.Index(after, close)
+	return end >= 0 && !strings.Contains(after[:end], open)
+}
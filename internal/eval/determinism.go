@@ -0,0 +1,271 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/textmetrics"
+)
+
+const determinismCategory = "Determinism"
+
+// determinismMaxDistance is the largest normalized edit distance between two
+// responses that still counts as the same output. It allows for rare
+// floating-point divergence late in a generation.
+const determinismMaxDistance = 0.02
+
+// determinismEvals returns all determinism evals.
+func determinismEvals() []Eval {
+	return []Eval{
+		&seedDeterminismEval{},
+		&batchDeterminismEval{},
+	}
+}
+
+// seedDeterminismEval verifies that two identical requests with temperature 0
+// and the same seed produce the same content.
+type seedDeterminismEval struct {
+	streaming bool
+}
+
+func (e *seedDeterminismEval) Name() string {
+	return "seed_determinism"
+}
+
+func (e *seedDeterminismEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *seedDeterminismEval) Streaming() bool             { return e.streaming }
+
+func (e *seedDeterminismEval) Category() string {
+	return determinismCategory
+}
+
+func (e *seedDeterminismEval) Class() string {
+	return ClassStandard
+}
+
+func (e *seedDeterminismEval) Run(ctx context.Context, c *client.Client) Result {
+	req := determinismRequest()
+
+	var contents [2]string
+	for i := range contents {
+		content, failed := determinismContent(ctx, c, e, e.streaming, req)
+		if failed != nil {
+			failed.Message = fmt.Sprintf("request %d: %s", i+1, failed.Message)
+			return *failed
+		}
+		contents[i] = content
+	}
+
+	return compareDeterministic(e, contents, [2]string{"request 1", "request 2"}, "responses to identical seeded requests differ")
+}
+
+// batchDeterminismLoad is the number of concurrent requests sent to make the
+// server batch the measured request with others.
+const batchDeterminismLoad = 4
+
+// batchDeterminismHeadStart gives the load requests time to be admitted
+// before the measured request is sent.
+const batchDeterminismHeadStart = 250 * time.Millisecond
+
+// batchDeterminismEval verifies that a seeded greedy request returns the same
+// content when sent alone and when the server is busy with other requests.
+// Servers that batch requests may change numerics with batch size, which
+// shows up only under load.
+type batchDeterminismEval struct {
+	streaming bool
+}
+
+func (e *batchDeterminismEval) Name() string {
+	return "batch_determinism"
+}
+
+func (e *batchDeterminismEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *batchDeterminismEval) Streaming() bool             { return e.streaming }
+
+func (e *batchDeterminismEval) Category() string {
+	return determinismCategory
+}
+
+func (e *batchDeterminismEval) Class() string {
+	return ClassStandard
+}
+
+func (e *batchDeterminismEval) Run(ctx context.Context, c *client.Client) Result {
+	req := determinismRequest()
+
+	alone, failed := determinismContent(ctx, c, e, e.streaming, req)
+	if failed != nil {
+		failed.Message = "request alone: " + failed.Message
+		return *failed
+	}
+
+	// Load requests are not logged, so the eval log holds only the two
+	// compared requests. They are cancelled once the measured request is done.
+	loadCtx, cancel := context.WithCancel(ctx)
+	loadClient := c.WithLogger(nil)
+	loadErrs := make([]error, batchDeterminismLoad)
+	var wg sync.WaitGroup
+	for i := range batchDeterminismLoad {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, loadErrs[i] = loadClient.ChatCompletion(loadCtx, batchDeterminismLoadRequest(i))
+		}()
+	}
+
+	select {
+	case <-time.After(batchDeterminismHeadStart):
+	case <-ctx.Done():
+	}
+
+	loaded, failed := determinismContent(ctx, c, e, e.streaming, req)
+	cancel()
+	wg.Wait()
+	if failed != nil {
+		failed.Message = "request under load: " + failed.Message
+		return *failed
+	}
+	for i, err := range loadErrs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  fmt.Sprintf("load request %d failed: %v", i+1, err),
+			}
+		}
+	}
+
+	return compareDeterministic(e, [2]string{alone, loaded}, [2]string{"alone", "under load"}, "response under concurrent load differs from response alone")
+}
+
+// batchDeterminismLoadRequest returns the nth load request. Each asks for a
+// long, distinct generation so that it is still running while the measured
+// request is processed.
+func batchDeterminismLoadRequest(n int) client.ChatCompletionRequest {
+	topics := []string{"a lighthouse keeper", "a desert caravan", "a deep-sea diver", "a mountain village"}
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Write a long, detailed story about " + topics[n%len(topics)] + "."},
+		},
+		MaxTokens: 512,
+	}
+}
+
+// determinismRequest returns the seeded greedy request compared by the
+// determinism evals. The prompt invites open-ended output so that divergence
+// shows quickly.
+func determinismRequest() client.ChatCompletionRequest {
+	temperature := 0.0
+	seed := 42
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Write a four-line poem about the sea."},
+		},
+		Temperature: &temperature,
+		Seed:        &seed,
+		MaxTokens:   128,
+	}
+}
+
+// determinismContent is completionContent that also fails on empty content,
+// since two empty responses would trivially match.
+func determinismContent(ctx context.Context, c *client.Client, e Eval, streaming bool, req client.ChatCompletionRequest) (string, *Result) {
+	content, failed := completionContent(ctx, c, e, streaming, req)
+	if failed != nil {
+		return "", failed
+	}
+	if strings.TrimSpace(content) == "" {
+		return "", &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "content is empty",
+		}
+	}
+	return content, nil
+}
+
+// compareDeterministic passes if the two contents are within
+// determinismMaxDistance of each other, failing with the given message
+// otherwise. Differing contents are kept as a diff, named by labels.
+func compareDeterministic(e Eval, contents, labels [2]string, differ string) Result {
+	if contents[0] == contents[1] {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+			Scores:   map[string]float64{"edit_distance": 0},
+		}
+	}
+
+	distance := textmetrics.NormalizedEditDistance(contents[0], contents[1])
+	scores := map[string]float64{"edit_distance": distance}
+	diffs := []Diff{{ALabel: labels[0], BLabel: labels[1], A: contents[0], B: contents[1]}}
+	if distance > determinismMaxDistance {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeNondeterministic,
+			Message:  fmt.Sprintf("%s (normalized edit distance %.3f > %.2f)", differ, distance, determinismMaxDistance),
+			Scores:   scores,
+			Diffs:    diffs,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("responses nearly identical (normalized edit distance %.3f)", distance),
+		Scores:   scores,
+		Diffs:    diffs,
+	}
+}
+
+// completionContent sends req in the given mode and returns the content of
+// the first choice. On failure it returns a non-nil Result.
+func completionContent(ctx context.Context, c *client.Client, e Eval, streaming bool, req client.ChatCompletionRequest) (string, *Result) {
+	if streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return "", &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		return result.Content, nil
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return "", &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	if len(resp.Choices) == 0 {
+		return "", &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeNoChoices,
+			Message:  "no choices in response",
+		}
+	}
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,197 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const errorHandlingCategory = "Error Handling"
+
+// errorHandlingTimeout is how long a server gets to reject a malformed
+// request before it counts as hanging.
+const errorHandlingTimeout = 30 * time.Second
+
+// errorHandlingModel is a model id no server serves.
+const errorHandlingModel = "llm-serve-test-no-such-model"
+
+// errorHandlingEvals returns all error handling evals.
+func errorHandlingEvals() []Eval {
+	hello := []client.Message{{Role: "user", Content: "Say hello."}}
+	return []Eval{
+		&errorHandlingEval{
+			name:    "error_unknown_model",
+			problem: "an unknown model",
+			request: client.ChatCompletionRequest{
+				Messages: hello,
+				Extra:    map[string]any{"model": errorHandlingModel},
+			},
+			// llama.cpp serves one model whatever the request names
+			lenient: FlavorLlamaCpp,
+		},
+		&errorHandlingEval{
+			name:    "error_missing_messages",
+			problem: "no messages field",
+		},
+		&errorHandlingEval{
+			name:    "error_invalid_role",
+			problem: `a message with role "wizard"`,
+			request: client.ChatCompletionRequest{
+				Messages: []client.Message{{Role: "wizard", Content: "Say hello."}},
+			},
+		},
+		&errorHandlingEval{
+			name:    "error_bad_tool_schema",
+			problem: "a tool whose parameters schema is invalid",
+			request: client.ChatCompletionRequest{
+				Messages: hello,
+				Tools: []client.Tool{
+					{
+						Type: "function",
+						Function: client.ToolFunction{
+							Name:        "get_weather",
+							Description: "Get the current weather for a location",
+							Parameters:  json.RawMessage(`{"type": "object", "properties": ["location"], "required": "location"}`),
+						},
+					},
+				},
+			},
+		},
+		&errorHandlingEval{
+			name:    "error_negative_max_tokens",
+			problem: "max_tokens -5",
+			request: client.ChatCompletionRequest{
+				Messages: hello,
+				Extra:    map[string]any{"max_tokens": -5},
+			},
+		},
+	}
+}
+
+// errorHandlingEval sends a malformed request and verifies that the server
+// rejects it promptly with a 4xx status and an OpenAI-style error object,
+// rather than accepting it, failing with a 5xx, or hanging.
+type errorHandlingEval struct {
+	name      string
+	problem   string
+	request   client.ChatCompletionRequest
+	streaming bool
+	flavor    string
+	// lenient is the flavor under which accepting the request is noted
+	// rather than failed.
+	lenient string
+}
+
+func (e *errorHandlingEval) Name() string {
+	return e.name
+}
+
+func (e *errorHandlingEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *errorHandlingEval) Streaming() bool             { return e.streaming }
+
+func (e *errorHandlingEval) Category() string {
+	return errorHandlingCategory
+}
+
+func (e *errorHandlingEval) Class() string {
+	return ClassStandard
+}
+
+func (e *errorHandlingEval) configure(cfg RunnerConfig) {
+	e.flavor = cfg.Flavor
+}
+
+func (e *errorHandlingEval) Run(ctx context.Context, c *client.Client) Result {
+	reqCtx, cancel := context.WithTimeout(ctx, errorHandlingTimeout)
+	defer cancel()
+
+	// The request is copied so that the client's extra fields are not
+	// merged into the shared map
+	req := e.request
+	req.Extra = make(map[string]any, len(e.request.Extra))
+	for k, v := range e.request.Extra {
+		req.Extra[k] = v
+	}
+
+	var err error
+	if e.streaming {
+		_, err = c.ChatCompletionStream(reqCtx, req)
+	} else {
+		_, err = c.ChatCompletion(reqCtx, req)
+	}
+
+	var statusErr *client.StatusError
+	switch {
+	case err == nil:
+		if e.lenient != "" && e.flavor == e.lenient {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   true,
+				Notes:    []string{fmt.Sprintf("request with %s accepted, as %s does", e.problem, e.flavor)},
+			}
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeErrorNotRejected,
+			Message:  fmt.Sprintf("request with %s was accepted", e.problem),
+		}
+	case ctx.Err() == nil && errors.Is(reqCtx.Err(), context.DeadlineExceeded):
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeErrorTimeout,
+			Message:  fmt.Sprintf("no response to a request with %s within %s", e.problem, errorHandlingTimeout),
+		}
+	case !errors.As(err, &statusErr):
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	case statusErr.StatusCode >= http.StatusInternalServerError:
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeErrorServerError,
+			Message:  fmt.Sprintf("request with %s failed with status %d instead of a 4xx: %s", e.problem, statusErr.StatusCode, statusErr.Body),
+		}
+	case statusErr.StatusCode < http.StatusBadRequest:
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeErrorNotRejected,
+			Message:  fmt.Sprintf("request with %s got status %d instead of a 4xx", e.problem, statusErr.StatusCode),
+		}
+	}
+
+	apiErr, err := client.ParseAPIError([]byte(statusErr.Body))
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeErrorBodyInvalid,
+			Message:  fmt.Sprintf("request with %s rejected with status %d, but %v: %q", e.problem, statusErr.StatusCode, err, statusErr.Body),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("rejected with status %d: %v", statusErr.StatusCode, apiErr),
+	}
+}
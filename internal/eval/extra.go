@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// extraCheckTimeout bounds the request made to check --extra fields.
+const extraCheckTimeout = 30 * time.Second
+
+// CheckExtraFields sends a minimal completion carrying the client's extra
+// request fields, named by fields, and returns an error with the server's
+// message if the server rejects it with a 4xx status. Every eval would
+// otherwise fail the same way. Other failures are left to the evals to
+// report.
+func CheckExtraFields(c *client.Client, fields []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), extraCheckTimeout)
+	defer cancel()
+
+	_, err := c.ChatCompletion(ctx, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Reply with the word OK."},
+		},
+		MaxTokens: 1,
+	})
+
+	var statusErr *client.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode < http.StatusBadRequest || statusErr.StatusCode >= http.StatusInternalServerError {
+		return nil
+	}
+	msg := errorMessage(statusErr.Body)
+	if msg == "" {
+		msg = statusErr.Body
+	}
+	return fmt.Errorf("server rejected a request with --extra %s (status %d): %s", strings.Join(fields, ", "), statusErr.StatusCode, msg)
+}
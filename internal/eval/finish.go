@@ -0,0 +1,520 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const finishReasonCategory = "Finish Reason"
+
+// finishReasonEvals returns all finish_reason evals.
+func finishReasonEvals() []Eval {
+	return []Eval{
+		&finishReasonStopEval{},
+		&finishReasonLengthEval{},
+		&finishReasonToolCallsEval{},
+		&maxTokensTruncationEval{},
+		&maxCompletionTokensEval{},
+	}
+}
+
+// finishReasonStopEval verifies that a normal completion finishes with "stop".
+type finishReasonStopEval struct {
+	streaming bool
+	flavor    string
+}
+
+func (e *finishReasonStopEval) Name() string {
+	return "finish_reason_stop"
+}
+
+func (e *finishReasonStopEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *finishReasonStopEval) Streaming() bool             { return e.streaming }
+
+func (e *finishReasonStopEval) configure(cfg RunnerConfig) { e.flavor = cfg.Flavor }
+
+func (e *finishReasonStopEval) Category() string {
+	return finishReasonCategory
+}
+
+func (e *finishReasonStopEval) Class() string {
+	return ClassStandard
+}
+
+func (e *finishReasonStopEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Say hello."},
+		},
+	}
+	return runFinishReasonEval(ctx, c, e, e.streaming, e.flavor, req, "stop")
+}
+
+// finishReasonLengthEval verifies that a completion truncated by max_tokens
+// finishes with "length".
+type finishReasonLengthEval struct {
+	streaming bool
+	flavor    string
+}
+
+func (e *finishReasonLengthEval) Name() string {
+	return "finish_reason_length"
+}
+
+func (e *finishReasonLengthEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *finishReasonLengthEval) Streaming() bool             { return e.streaming }
+
+func (e *finishReasonLengthEval) configure(cfg RunnerConfig) { e.flavor = cfg.Flavor }
+
+func (e *finishReasonLengthEval) Category() string {
+	return finishReasonCategory
+}
+
+func (e *finishReasonLengthEval) Class() string {
+	return ClassStandard
+}
+
+func (e *finishReasonLengthEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Write a detailed, multi-paragraph essay about the history of computing."},
+		},
+		MaxTokens: 8,
+	}
+	return runFinishReasonEval(ctx, c, e, e.streaming, e.flavor, req, "length")
+}
+
+// finishReasonToolCallsEval verifies that a completion ending in a tool call
+// finishes with "tool_calls".
+type finishReasonToolCallsEval struct {
+	streaming bool
+	flavor    string
+}
+
+func (e *finishReasonToolCallsEval) Name() string {
+	return "finish_reason_tool_calls"
+}
+
+func (e *finishReasonToolCallsEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *finishReasonToolCallsEval) Streaming() bool             { return e.streaming }
+
+func (e *finishReasonToolCallsEval) configure(cfg RunnerConfig) { e.flavor = cfg.Flavor }
+
+func (e *finishReasonToolCallsEval) Category() string {
+	return finishReasonCategory
+}
+
+func (e *finishReasonToolCallsEval) Class() string {
+	return ClassStandard
+}
+
+func (e *finishReasonToolCallsEval) Tags() []string {
+	return []string{TagTools}
+}
+
+func (e *finishReasonToolCallsEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "What's the weather in San Francisco?"},
+		},
+		Tools: []client.Tool{
+			{
+				Type: "function",
+				Function: client.ToolFunction{
+					Name:        "get_weather",
+					Description: "Get the current weather for a location",
+					Parameters: json.RawMessage(`{
+						"type": "object",
+						"properties": {
+							"location": {
+								"type": "string",
+								"description": "The city and state, e.g. San Francisco, CA"
+							}
+						},
+						"required": ["location"]
+					}`),
+				},
+			},
+		},
+		ToolChoice: "auto",
+	}
+	return runFinishReasonEval(ctx, c, e, e.streaming, e.flavor, req, "tool_calls")
+}
+
+// truncationMaxTokens is the limit of the truncation evals, low enough to
+// cut off any answer to their prompt.
+const truncationMaxTokens = 5
+
+// truncationStreamTimeout bounds a stream truncated at truncationMaxTokens,
+// which should end almost at once. A stream still open by then is taken to
+// hang rather than be slow.
+const truncationStreamTimeout = 20 * time.Second
+
+// maxTokensTruncationEval verifies that a completion cut off by a very small
+// max_tokens is truncated as the limit promises: finish_reason is "length",
+// usage reports no more completion tokens than the limit, and a stream ends
+// rather than hanging once the limit is reached.
+type maxTokensTruncationEval struct {
+	streaming bool
+	flavor    string
+}
+
+func (e *maxTokensTruncationEval) Name() string {
+	return "max_tokens_truncation"
+}
+
+func (e *maxTokensTruncationEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *maxTokensTruncationEval) Streaming() bool             { return e.streaming }
+
+func (e *maxTokensTruncationEval) configure(cfg RunnerConfig) { e.flavor = cfg.Flavor }
+
+func (e *maxTokensTruncationEval) Category() string {
+	return finishReasonCategory
+}
+
+func (e *maxTokensTruncationEval) Class() string {
+	return ClassStandard
+}
+
+func (e *maxTokensTruncationEval) Run(ctx context.Context, c *client.Client) Result {
+	req := truncationRequest()
+	req.MaxTokens = truncationMaxTokens
+
+	t, err := sendTruncated(ctx, c, e.streaming, req)
+	if err != nil {
+		return truncationError(e, "max_tokens", err)
+	}
+	return t.check(e, e.flavor, "max_tokens")
+}
+
+// errStreamNotTerminated is returned by sendTruncated for a stream that did
+// not end within truncationStreamTimeout.
+var errStreamNotTerminated = errors.New("stream did not end")
+
+// truncation is the outcome of a request limited to truncationMaxTokens.
+type truncation struct {
+	reason string
+	usage  *client.Usage
+	// code and message describe a stream whose finish_reason was malformed.
+	code    string
+	message string
+}
+
+// truncationRequest returns the request of the truncation evals, without
+// its limit. Any answer to it is longer than truncationMaxTokens.
+func truncationRequest() client.ChatCompletionRequest {
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "List the planets of the solar system, describing each in a sentence."},
+		},
+	}
+}
+
+// sendTruncated sends req, returning its finish_reason and usage. A stream
+// is given truncationStreamTimeout to end.
+func sendTruncated(ctx context.Context, c *client.Client, streaming bool, req client.ChatCompletionRequest) (truncation, error) {
+	if streaming {
+		req.StreamOptions = &client.StreamOptions{IncludeUsage: true}
+		streamCtx, cancel := context.WithTimeout(ctx, truncationStreamTimeout)
+		defer cancel()
+		result, err := c.ChatCompletionStream(streamCtx, req)
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(streamCtx.Err(), context.DeadlineExceeded) {
+				return truncation{}, errStreamNotTerminated
+			}
+			return truncation{}, err
+		}
+		reason, code, msg := streamFinishReason(result.Chunks)
+		return truncation{reason: reason, usage: result.Usage, code: code, message: msg}, nil
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return truncation{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return truncation{code: CodeNoChoices, message: "no choices in response"}, nil
+	}
+	return truncation{reason: resp.Choices[0].FinishReason, usage: resp.Usage}, nil
+}
+
+// truncationError returns the failed result of a truncation eval whose
+// request, limited by field, returned err.
+func truncationError(e Eval, field string, err error) Result {
+	if errors.Is(err, errStreamNotTerminated) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeStreamNotTerminated,
+			Message:  fmt.Sprintf("stream with %s %d did not end within %s", field, truncationMaxTokens, truncationStreamTimeout),
+		}
+	}
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   false,
+		Code:     CodeRequestFailed,
+		Message:  "request failed: " + err.Error(),
+	}
+}
+
+// check verifies that a response limited by field to truncationMaxTokens
+// finished with "length", within the limit.
+func (t truncation) check(e Eval, flavor, field string) Result {
+	if t.code != "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     t.code,
+			Message:  t.message,
+		}
+	}
+
+	ok, note := matchFinishReason(flavor, t.reason, "length")
+	if !ok {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeFinishReasonWrong,
+			Message:  fmt.Sprintf("expected finish_reason \"length\" with %s %d, got %q", field, truncationMaxTokens, t.reason),
+		}
+	}
+
+	var notes []string
+	if note != "" {
+		notes = append(notes, note)
+	}
+	// Missing usage fails the usage evals; here it only leaves the token
+	// count unchecked
+	if t.usage == nil {
+		notes = append(notes, "no usage reported, so completion_tokens was not checked against "+field)
+	} else if t.usage.CompletionTokens > truncationMaxTokens {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeMaxTokensExceeded,
+			Message:  fmt.Sprintf("usage reports %d completion tokens, more than %s %d", t.usage.CompletionTokens, field, truncationMaxTokens),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Notes:    notes,
+	}
+}
+
+// maxCompletionTokensEval verifies that the server honors
+// max_completion_tokens, which OpenAI introduced to replace the deprecated
+// max_tokens, by truncating as maxTokensTruncationEval checks. Where the
+// server also accepts max_tokens, the two limits must truncate alike.
+type maxCompletionTokensEval struct {
+	streaming bool
+	flavor    string
+}
+
+func (e *maxCompletionTokensEval) Name() string {
+	return "max_completion_tokens"
+}
+
+func (e *maxCompletionTokensEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *maxCompletionTokensEval) Streaming() bool             { return e.streaming }
+
+func (e *maxCompletionTokensEval) configure(cfg RunnerConfig) { e.flavor = cfg.Flavor }
+
+func (e *maxCompletionTokensEval) Category() string {
+	return finishReasonCategory
+}
+
+func (e *maxCompletionTokensEval) Class() string {
+	return ClassStandard
+}
+
+func (e *maxCompletionTokensEval) Run(ctx context.Context, c *client.Client) Result {
+	req := truncationRequest()
+	req.MaxCompletionTokens = truncationMaxTokens
+
+	t, err := sendTruncated(ctx, c, e.streaming, req)
+	if err != nil {
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError {
+			msg := errorMessage(statusErr.Body)
+			if msg == "" {
+				msg = statusErr.Body
+			}
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeMaxCompletionTokensRejected,
+				Message:  fmt.Sprintf("request with max_completion_tokens rejected with status %d: %s", statusErr.StatusCode, msg),
+			}
+		}
+		return truncationError(e, "max_completion_tokens", err)
+	}
+	result := t.check(e, e.flavor, "max_completion_tokens")
+	if !result.Passed {
+		return result
+	}
+
+	// The same request limited by max_tokens, which servers following
+	// OpenAI in deprecating it may reject
+	legacy := truncationRequest()
+	legacy.MaxTokens = truncationMaxTokens
+	lt, err := sendTruncated(ctx, c, e.streaming, legacy)
+	if err != nil || lt.code != "" {
+		outcome := lt.message
+		if err != nil {
+			outcome = err.Error()
+		}
+		result.Notes = append(result.Notes, "not compared with max_tokens, whose request failed: "+outcome)
+		return result
+	}
+
+	if lt.reason != t.reason {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeMaxCompletionTokensMismatch,
+			Message:  fmt.Sprintf("finish_reason was %q with max_completion_tokens %d, but %q with max_tokens %d", t.reason, truncationMaxTokens, lt.reason, truncationMaxTokens),
+		}
+	}
+	if t.usage != nil && lt.usage != nil && lt.usage.CompletionTokens != t.usage.CompletionTokens {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeMaxCompletionTokensMismatch,
+			Message:  fmt.Sprintf("usage reports %d completion tokens with max_completion_tokens %d, but %d with max_tokens %d", t.usage.CompletionTokens, truncationMaxTokens, lt.usage.CompletionTokens, truncationMaxTokens),
+		}
+	}
+	return result
+}
+
+// runFinishReasonEval sends req and checks that the response finishes with
+// the expected finish_reason. In streaming mode it also checks that
+// finish_reason is sent exactly once, on the final chunk carrying generated
+// data. Aliases of the expected value known to the flavor's compatibility
+// profile pass with a note.
+func runFinishReasonEval(ctx context.Context, c *client.Client, e Eval, streaming bool, flavor string, req client.ChatCompletionRequest, expected string) Result {
+	var reason string
+
+	if streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		var code, msg string
+		reason, code, msg = streamFinishReason(result.Chunks)
+		if code != "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     code,
+				Message:  msg,
+			}
+		}
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		if len(resp.Choices) == 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeNoChoices,
+				Message:  "no choices in response",
+			}
+		}
+		reason = resp.Choices[0].FinishReason
+		if reason == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeFinishReasonMissing,
+				Message:  "response has no finish_reason",
+			}
+		}
+	}
+
+	ok, note := matchFinishReason(flavor, reason, expected)
+	if !ok {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeFinishReasonWrong,
+			Message:  fmt.Sprintf("expected finish_reason %q, got %q", expected, reason),
+		}
+	}
+
+	var notes []string
+	if note != "" {
+		notes = append(notes, note)
+	}
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Notes:    notes,
+	}
+}
+
+// streamFinishReason returns the finish_reason of a stream, or a failure
+// code and message if finish_reason is missing, sent more than once, or
+// followed by more generated data.
+func streamFinishReason(chunks []client.ChatCompletionChunk) (reason, code, message string) {
+	finishChunk := -1
+	for i, chunk := range chunks {
+		for _, choice := range chunk.Choices {
+			delta := choice.Delta
+			hasData := delta.Content != "" || delta.ReasoningContent != "" || len(delta.ToolCalls) > 0
+
+			if finishChunk >= 0 && hasData {
+				return "", CodeFinishReasonEarly, fmt.Sprintf("chunk %d carries generated data after finish_reason was sent in chunk %d", i, finishChunk)
+			}
+
+			if choice.FinishReason == nil || *choice.FinishReason == "" {
+				continue
+			}
+			if finishChunk >= 0 {
+				return "", CodeFinishReasonEarly, fmt.Sprintf("finish_reason sent in both chunk %d and chunk %d", finishChunk, i)
+			}
+			finishChunk = i
+			reason = *choice.FinishReason
+		}
+	}
+
+	if finishChunk < 0 {
+		return "", CodeFinishReasonMissing, "no chunk carried a finish_reason"
+	}
+	return reason, "", ""
+}
@@ -0,0 +1,178 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const (
+	// grammarCachedRuns is the number of requests timed after the first
+	// with the same schema.
+	grammarCachedRuns = 3
+	// grammarCompileMin is the smallest first-request overhead treated as
+	// a measurable compile cost; below it, caching cannot be judged.
+	grammarCompileMin = 50 * time.Millisecond
+	// grammarCachedMaxFraction is the largest fraction of the first
+	// request's overhead that cached requests may still pay.
+	grammarCachedMaxFraction = 0.5
+)
+
+// grammarCacheFlavors are the server flavors that cache compiled grammars,
+// and so are held to it.
+var grammarCacheFlavors = []string{FlavorVLLM}
+
+// jsonSchemaCompileTimeEval measures the cost of compiling a json_schema
+// into a grammar: the extra latency of the first request with a new
+// schema, and of later requests with the same schema, over an
+// unconstrained request. Servers of flavors that cache compiled grammars
+// fail if later requests keep paying the compile cost; others only report
+// the measurements.
+type jsonSchemaCompileTimeEval struct {
+	flavor string
+}
+
+func (e *jsonSchemaCompileTimeEval) Name() string {
+	return "json_schema_compile_time"
+}
+
+func (e *jsonSchemaCompileTimeEval) Category() string {
+	return schemaCategory
+}
+
+func (e *jsonSchemaCompileTimeEval) Class() string {
+	return ClassPerformance
+}
+
+// IsBlockingOnly returns true because whole-request latency is measured.
+func (e *jsonSchemaCompileTimeEval) IsBlockingOnly() bool {
+	return true
+}
+
+func (e *jsonSchemaCompileTimeEval) configure(cfg RunnerConfig) { e.flavor = cfg.Flavor }
+
+func (e *jsonSchemaCompileTimeEval) Run(ctx context.Context, c *client.Client) Result {
+	// A schema no earlier request used, so the first request compiles it
+	nonce := fmt.Sprintf("ref_%x", time.Now().UnixNano())
+	schema := json.RawMessage(fmt.Sprintf(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"},
+			"occupation": {"type": "string"},
+			"skills": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 5},
+			"address": {
+				"type": "object",
+				"properties": {
+					"street": {"type": "string"},
+					"city": {"type": "string"},
+					"country": {"type": "string"}
+				},
+				"required": ["street", "city", "country"],
+				"additionalProperties": false
+			},
+			%q: {"type": "string"}
+		},
+		"required": ["name", "age", "occupation", "skills", "address", %q],
+		"additionalProperties": false
+	}`, nonce, nonce))
+
+	// One output token keeps generation time out of the measurement
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Generate a fictional person."},
+		},
+		MaxTokens: 1,
+	}
+
+	timed := func(req client.ChatCompletionRequest) (time.Duration, error) {
+		start := time.Now()
+		_, err := c.ChatCompletion(ctx, req)
+		return time.Since(start), err
+	}
+
+	// Unconstrained baseline, after a warm-up that fills any prefix cache
+	var plain time.Duration
+	for range 2 {
+		d, err := timed(req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		plain = d
+	}
+
+	req.ResponseFormat = jsonSchemaFormat("person", schema)
+	first, err := timed(req)
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	cachedRuns := make([]time.Duration, grammarCachedRuns)
+	for i := range cachedRuns {
+		cachedRuns[i], err = timed(req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+	}
+	slices.Sort(cachedRuns)
+	cached := cachedRuns[len(cachedRuns)/2]
+
+	firstOverhead := first - plain
+	cachedOverhead := cached - plain
+	scores := map[string]float64{
+		"plain_ms":           msFloat(plain),
+		"first_ms":           msFloat(first),
+		"cached_ms":          msFloat(cached),
+		"first_overhead_ms":  msFloat(firstOverhead),
+		"cached_overhead_ms": msFloat(cachedOverhead),
+	}
+	summary := fmt.Sprintf("schema overhead over unconstrained: first request %s, cached %s",
+		firstOverhead.Round(time.Millisecond), cachedOverhead.Round(time.Millisecond))
+
+	if slices.Contains(grammarCacheFlavors, e.flavor) && firstOverhead >= grammarCompileMin &&
+		float64(cachedOverhead) > grammarCachedMaxFraction*float64(firstOverhead) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeGrammarNotCached,
+			Message:  summary + "; repeated requests still pay the compile cost",
+			Scores:   scores,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  summary,
+		Scores:   scores,
+	}
+}
+
+// msFloat returns a duration in fractional milliseconds.
+func msFloat(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
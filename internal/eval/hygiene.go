@@ -0,0 +1,48 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
+)
+
+// scanOutputs runs the output scanners over the outputs captured for the
+// named eval, keeping what they flag. A scanner that fails is reported once
+// per eval and skipped.
+func (r *Runner) scanOutputs(name string, capture *responseCapture) {
+	capture.mu.Lock()
+	outputs := capture.outputs
+	capture.mu.Unlock()
+
+	var findings []hygiene.Finding
+	for _, s := range r.config.OutputScanners {
+		for _, out := range outputs {
+			found, err := s.Scan(context.Background(), out)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: output scanner %s failed on %s: %v\n", s.Name(), name, err)
+				break
+			}
+			for _, f := range found {
+				f.Eval = name
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	r.findingsMu.Lock()
+	defer r.findingsMu.Unlock()
+	r.findings = append(r.findings, findings...)
+}
+
+// OutputFindings returns what the output scanners flagged during Run,
+// ordered by eval.
+func (r *Runner) OutputFindings() []hygiene.Finding {
+	r.findingsMu.Lock()
+	defer r.findingsMu.Unlock()
+	findings := append([]hygiene.Finding(nil), r.findings...)
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Eval < findings[j].Eval })
+	return findings
+}
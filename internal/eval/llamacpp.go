@@ -0,0 +1,171 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const llamaCppCategory = "llama.cpp"
+
+// timingsRateTolerance is the largest relative difference allowed between a
+// reported tokens-per-second rate and the rate computed from its token
+// count and duration.
+const timingsRateTolerance = 0.01
+
+// llamaCppEvals returns all llama.cpp extension evals.
+func llamaCppEvals() []Eval {
+	return []Eval{
+		&llamaCppTimingsEval{},
+	}
+}
+
+// llamaCppTimingsEval verifies that llama.cpp reports a timings object whose
+// token counts match usage and whose rates match its counts and durations.
+type llamaCppTimingsEval struct {
+	streaming bool
+}
+
+func (e *llamaCppTimingsEval) Name() string {
+	return "llamacpp_timings"
+}
+
+func (e *llamaCppTimingsEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *llamaCppTimingsEval) Streaming() bool             { return e.streaming }
+
+func (e *llamaCppTimingsEval) Category() string {
+	return llamaCppCategory
+}
+
+func (e *llamaCppTimingsEval) Class() string {
+	return ClassStandard
+}
+
+func (e *llamaCppTimingsEval) Flavor() string {
+	return FlavorLlamaCpp
+}
+
+func (e *llamaCppTimingsEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Count from 1 to 10, separated by spaces."},
+		},
+		MaxTokens: 64,
+	}
+
+	var timings *client.Timings
+	var usage *client.Usage
+
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		timings = result.Timings
+		usage = result.Usage
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		timings = resp.Timings
+		usage = resp.Usage
+	}
+
+	if timings == nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTimingsMissing,
+			Message:  "response has no timings object",
+		}
+	}
+
+	if timings.PredictedN <= 0 || timings.PredictedMS <= 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTimingsInconsistent,
+			Message:  fmt.Sprintf("timings report %d predicted tokens in %gms", timings.PredictedN, timings.PredictedMS),
+		}
+	}
+
+	if usage != nil {
+		if timings.PredictedN != usage.CompletionTokens {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeTimingsInconsistent,
+				Message:  fmt.Sprintf("timings predicted_n %d != usage completion_tokens %d", timings.PredictedN, usage.CompletionTokens),
+			}
+		}
+		// Cached prompt tokens are counted in usage but not processed
+		if timings.PromptN > usage.PromptTokens {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeTimingsInconsistent,
+				Message:  fmt.Sprintf("timings prompt_n %d > usage prompt_tokens %d", timings.PromptN, usage.PromptTokens),
+			}
+		}
+	}
+
+	if msg := checkTimingsRate("predicted", timings.PredictedN, timings.PredictedMS, timings.PredictedPerSecond); msg != "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTimingsInconsistent,
+			Message:  msg,
+		}
+	}
+	if msg := checkTimingsRate("prompt", timings.PromptN, timings.PromptMS, timings.PromptPerSecond); msg != "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTimingsInconsistent,
+			Message:  msg,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message: fmt.Sprintf("prompt %.1f tok/s, generation %.1f tok/s",
+			timings.PromptPerSecond, timings.PredictedPerSecond),
+	}
+}
+
+// checkTimingsRate returns a description of the problem if the reported
+// per-second rate of a timings phase disagrees with its count and duration,
+// or "" if it agrees. Phases with no tokens or duration are not checked.
+func checkTimingsRate(phase string, n int, ms, perSecond float64) string {
+	if n <= 0 || ms <= 0 {
+		return ""
+	}
+	expected := 1000 * float64(n) / ms
+	if math.Abs(perSecond-expected) > timingsRateTolerance*expected {
+		return fmt.Sprintf("timings %s_per_second %.2f does not match %d tokens in %.2fms (%.2f)", phase, perSecond, n, ms, expected)
+	}
+	return ""
+}
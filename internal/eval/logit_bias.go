@@ -0,0 +1,166 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const samplingCategory = "Sampling"
+
+// logitBiasBan is the bias that bans a token.
+const logitBiasBan = -100
+
+// samplingEvals returns all sampling parameter evals.
+func samplingEvals() []Eval {
+	return []Eval{
+		&logitBiasEval{},
+	}
+}
+
+// logitBiasEval verifies that the server accepts logit_bias and, where the
+// server can tokenize text, that banning the first token of a greedy
+// response changes it.
+type logitBiasEval struct {
+	streaming bool
+}
+
+func (e *logitBiasEval) Name() string {
+	return "logit_bias"
+}
+
+func (e *logitBiasEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *logitBiasEval) Streaming() bool             { return e.streaming }
+
+func (e *logitBiasEval) Category() string {
+	return samplingCategory
+}
+
+func (e *logitBiasEval) Class() string {
+	return ClassStandard
+}
+
+// logitBiasRequest returns a greedy request with the given logit_bias.
+func logitBiasRequest(bias map[string]float64) client.ChatCompletionRequest {
+	temperature := 0.0
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Reply with exactly one word: Hello"},
+		},
+		Temperature: &temperature,
+		MaxTokens:   16,
+		LogitBias:   bias,
+	}
+}
+
+func (e *logitBiasEval) Run(ctx context.Context, c *client.Client) Result {
+	// A zero bias on token 0, which exists in every vocabulary, leaves the
+	// output unchanged, so the response doubles as the baseline
+	baseline, failed := e.send(ctx, c, logitBiasRequest(map[string]float64{"0": 0}))
+	if failed != nil {
+		return *failed
+	}
+	if strings.TrimSpace(baseline) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "content is empty",
+		}
+	}
+
+	tokens, err := c.Tokenize(ctx, baseline)
+	if err != nil || len(tokens) == 0 {
+		reason := "no tokens"
+		if err != nil {
+			reason = err.Error()
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+			Notes:    []string{"ban not checked, since /tokenize failed: " + reason},
+		}
+	}
+
+	first := strconv.Itoa(tokens[0])
+	banned, failed := e.send(ctx, c, logitBiasRequest(map[string]float64{first: logitBiasBan}))
+	if failed != nil {
+		return *failed
+	}
+	if banned == baseline {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLogitBiasIgnored,
+			Message:  fmt.Sprintf("response %q is unchanged with its first token %s banned", baseline, first),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Diffs:    []Diff{{ALabel: "unbiased", BLabel: "token " + first + " banned", A: baseline, B: banned}},
+	}
+}
+
+// send sends req in the eval's mode and returns the response content. On
+// failure it returns a non-nil Result.
+func (e *logitBiasEval) send(ctx context.Context, c *client.Client, req client.ChatCompletionRequest) (string, *Result) {
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return "", e.requestError(err)
+		}
+		return result.Content, nil
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return "", e.requestError(err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeNoChoices,
+			Message:  "no choices in response",
+		}
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// requestError maps a failed request to a result, telling a rejection of
+// logit_bias apart from other failures.
+func (e *logitBiasEval) requestError(err error) *Result {
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError {
+		msg := errorMessage(statusErr.Body)
+		if msg == "" {
+			msg = statusErr.Body
+		}
+		return &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLogitBiasRejected,
+			Message:  fmt.Sprintf("request with logit_bias rejected with status %d: %s", statusErr.StatusCode, msg),
+		}
+	}
+	return &Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   false,
+		Code:     CodeRequestFailed,
+		Message:  "request failed: " + err.Error(),
+	}
+}
@@ -0,0 +1,282 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const logprobsCategory = "Logprobs"
+
+// logprobsTopN is the number of top_logprobs requested by logprobs_top.
+const logprobsTopN = 3
+
+// logprobsEvals returns all logprobs evals.
+func logprobsEvals() []Eval {
+	return []Eval{
+		&logprobsTokensEval{},
+		&logprobsTopEval{},
+		&logprobsStreamDeltasEval{},
+	}
+}
+
+// logprobsRequest asks for a short completion with logprobs, and topN
+// alternatives per token if topN is non-zero.
+func logprobsRequest(topN int) client.ChatCompletionRequest {
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Count from 1 to 10, separated by spaces."},
+		},
+		Logprobs:    true,
+		TopLogprobs: topN,
+	}
+}
+
+// logprobsResponse is the part of a response the logprobs evals inspect.
+type logprobsResponse struct {
+	logprobs  []client.TokenLogprob
+	usage     *client.Usage
+	reasoning bool
+}
+
+// fetchLogprobs sends req and returns the token logprobs of the response.
+// On failure, including a response without logprobs, it returns a non-nil
+// Result.
+func fetchLogprobs(ctx context.Context, c *client.Client, e Eval, streaming bool, req client.ChatCompletionRequest) (logprobsResponse, *Result) {
+	var resp logprobsResponse
+
+	if streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return resp, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		resp.logprobs = result.Logprobs
+		resp.usage = result.Usage
+		resp.reasoning = result.ReasoningContent != ""
+	} else {
+		r, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return resp, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		if len(r.Choices) == 0 {
+			return resp, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeNoChoices,
+				Message:  "no choices in response",
+			}
+		}
+		if lp := r.Choices[0].Logprobs; lp != nil {
+			resp.logprobs = lp.Content
+		}
+		resp.usage = r.Usage
+		resp.reasoning = r.Choices[0].Message.ReasoningContent != ""
+	}
+
+	if len(resp.logprobs) == 0 {
+		return resp, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLogprobsMissing,
+			Message:  "response has no logprobs despite logprobs: true",
+		}
+	}
+	return resp, nil
+}
+
+// logprobsTokensEval verifies that logprobs are returned for every generated
+// token and are valid log probabilities.
+type logprobsTokensEval struct {
+	streaming bool
+}
+
+func (e *logprobsTokensEval) Name() string {
+	return "logprobs_tokens"
+}
+
+func (e *logprobsTokensEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *logprobsTokensEval) Streaming() bool             { return e.streaming }
+
+func (e *logprobsTokensEval) Category() string {
+	return logprobsCategory
+}
+
+func (e *logprobsTokensEval) Class() string {
+	return ClassStandard
+}
+
+func (e *logprobsTokensEval) Run(ctx context.Context, c *client.Client) Result {
+	resp, failed := fetchLogprobs(ctx, c, e, e.streaming, logprobsRequest(0))
+	if failed != nil {
+		return *failed
+	}
+
+	for i, lp := range resp.logprobs {
+		if lp.Logprob > 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeLogprobsInvalid,
+				Message:  fmt.Sprintf("token %d (%q) has positive logprob %g", i, lp.Token, lp.Logprob),
+			}
+		}
+	}
+
+	// Without usage there is nothing to count against
+	if resp.usage == nil || resp.usage.CompletionTokens == 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+		}
+	}
+
+	n, want := len(resp.logprobs), resp.usage.CompletionTokens
+	// Servers may omit logprobs for reasoning tokens, which still count
+	// toward completion_tokens
+	if n > want || (n < want && !resp.reasoning) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLogprobsCount,
+			Message:  fmt.Sprintf("got logprobs for %d tokens, usage reports %d completion tokens", n, want),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// logprobsTopEval verifies that each token carries the requested number of
+// top_logprobs alternatives.
+type logprobsTopEval struct {
+	streaming bool
+}
+
+func (e *logprobsTopEval) Name() string {
+	return "logprobs_top"
+}
+
+func (e *logprobsTopEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *logprobsTopEval) Streaming() bool             { return e.streaming }
+
+func (e *logprobsTopEval) Category() string {
+	return logprobsCategory
+}
+
+func (e *logprobsTopEval) Class() string {
+	return ClassStandard
+}
+
+func (e *logprobsTopEval) Run(ctx context.Context, c *client.Client) Result {
+	resp, failed := fetchLogprobs(ctx, c, e, e.streaming, logprobsRequest(logprobsTopN))
+	if failed != nil {
+		return *failed
+	}
+
+	for i, lp := range resp.logprobs {
+		if len(lp.TopLogprobs) != logprobsTopN {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeLogprobsTopCount,
+				Message:  fmt.Sprintf("token %d (%q) has %d top_logprobs, expected %d", i, lp.Token, len(lp.TopLogprobs), logprobsTopN),
+			}
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// logprobsStreamDeltasEval verifies that every streaming chunk carrying
+// content also carries the logprobs of its tokens.
+type logprobsStreamDeltasEval struct{}
+
+func (e *logprobsStreamDeltasEval) Name() string {
+	return "logprobs_stream_deltas"
+}
+
+func (e *logprobsStreamDeltasEval) Category() string {
+	return logprobsCategory
+}
+
+func (e *logprobsStreamDeltasEval) Class() string {
+	return ClassStandard
+}
+
+func (e *logprobsStreamDeltasEval) IsStreamingOnly() bool {
+	return true
+}
+
+func (e *logprobsStreamDeltasEval) Run(ctx context.Context, c *client.Client) Result {
+	result, err := c.ChatCompletionStream(ctx, logprobsRequest(0))
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	contentChunks := 0
+	for i, chunk := range result.Chunks {
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			contentChunks++
+			if choice.Logprobs == nil || len(choice.Logprobs.Content) == 0 {
+				return Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeLogprobsMissing,
+					Message:  fmt.Sprintf("chunk %d carries content %q without logprobs", i, choice.Delta.Content),
+				}
+			}
+		}
+	}
+
+	if contentChunks == 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "stream carried no content",
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
@@ -0,0 +1,218 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const modelsCategory = "Models"
+
+// modelsEvals returns all /models endpoint evals.
+func modelsEvals() []Eval {
+	return []Eval{
+		&modelsListEval{},
+		&modelsContainsTargetEval{},
+		&modelsStableEval{},
+	}
+}
+
+// fetchModels lists the served models. On failure it returns a non-nil
+// Result.
+func fetchModels(ctx context.Context, c *client.Client, e Eval) (*client.ModelList, *Result) {
+	list, err := c.ListModels(ctx)
+	if err != nil {
+		return nil, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	return list, nil
+}
+
+// modelsListEval verifies the structure of the /models response: an
+// object "list" holding model objects with ids.
+type modelsListEval struct{}
+
+func (e *modelsListEval) Name() string {
+	return "models_list"
+}
+
+func (e *modelsListEval) Category() string {
+	return modelsCategory
+}
+
+func (e *modelsListEval) Class() string {
+	return ClassStandard
+}
+
+// IsBlockingOnly returns true because /models has no streaming form.
+func (e *modelsListEval) IsBlockingOnly() bool {
+	return true
+}
+
+func (e *modelsListEval) Run(ctx context.Context, c *client.Client) Result {
+	list, failed := fetchModels(ctx, c, e)
+	if failed != nil {
+		return *failed
+	}
+
+	if list.Object != "list" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeModelsInvalid,
+			Message:  fmt.Sprintf("expected object \"list\", got %q", list.Object),
+		}
+	}
+
+	if len(list.Data) == 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeModelsInvalid,
+			Message:  "model list is empty",
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, m := range list.Data {
+		if m.ID == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeModelsInvalid,
+				Message:  fmt.Sprintf("model %d has no id", i),
+			}
+		}
+		if m.Object != "model" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeModelsInvalid,
+				Message:  fmt.Sprintf("model %q has object %q, expected \"model\"", m.ID, m.Object),
+			}
+		}
+		if seen[m.ID] {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeModelsInvalid,
+				Message:  fmt.Sprintf("model %q is listed more than once", m.ID),
+			}
+		}
+		seen[m.ID] = true
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("%d models listed", len(list.Data)),
+	}
+}
+
+// modelsContainsTargetEval verifies that the model under test is listed.
+type modelsContainsTargetEval struct{}
+
+func (e *modelsContainsTargetEval) Name() string {
+	return "models_contains_target"
+}
+
+func (e *modelsContainsTargetEval) Category() string {
+	return modelsCategory
+}
+
+func (e *modelsContainsTargetEval) Class() string {
+	return ClassStandard
+}
+
+// IsBlockingOnly returns true because /models has no streaming form.
+func (e *modelsContainsTargetEval) IsBlockingOnly() bool {
+	return true
+}
+
+func (e *modelsContainsTargetEval) Run(ctx context.Context, c *client.Client) Result {
+	list, failed := fetchModels(ctx, c, e)
+	if failed != nil {
+		return *failed
+	}
+
+	ids := list.IDs()
+	if !slices.Contains(ids, c.Model()) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeModelNotListed,
+			Message:  fmt.Sprintf("model %q not in /models (listed: %s)", c.Model(), strings.Join(ids, ", ")),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// modelsStableEval verifies that repeated /models requests list the same
+// model ids, so clients can cache them.
+type modelsStableEval struct{}
+
+func (e *modelsStableEval) Name() string {
+	return "models_stable"
+}
+
+func (e *modelsStableEval) Category() string {
+	return modelsCategory
+}
+
+func (e *modelsStableEval) Class() string {
+	return ClassStandard
+}
+
+// IsBlockingOnly returns true because /models has no streaming form.
+func (e *modelsStableEval) IsBlockingOnly() bool {
+	return true
+}
+
+func (e *modelsStableEval) Run(ctx context.Context, c *client.Client) Result {
+	var ids [2][]string
+	for i := range ids {
+		list, failed := fetchModels(ctx, c, e)
+		if failed != nil {
+			failed.Message = fmt.Sprintf("request %d: %s", i+1, failed.Message)
+			return *failed
+		}
+		ids[i] = list.IDs()
+		slices.Sort(ids[i])
+	}
+
+	if !slices.Equal(ids[0], ids[1]) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeModelsUnstable,
+			Message:  fmt.Sprintf("model ids changed between requests: [%s] then [%s]", strings.Join(ids[0], ", "), strings.Join(ids[1], ", ")),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
@@ -0,0 +1,345 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const longContextCategory = "Long Context"
+
+// NeedleConfig configures the needle-in-a-haystack eval grid.
+type NeedleConfig struct {
+	// Lengths are the target prompt lengths, in tokens.
+	Lengths []int
+	// Depths are the needle positions, as a percentage of the way through
+	// the filler text (0 = start, 100 = end).
+	Depths []int
+	// ContextSize is the prompt length of long_context, in thousands of
+	// tokens.
+	ContextSize int
+}
+
+// DefaultNeedleConfig is the grid used when none is configured.
+var DefaultNeedleConfig = NeedleConfig{
+	Lengths:     []int{1000, 4000, 16000},
+	Depths:      []int{0, 25, 50, 75, 100},
+	ContextSize: 32,
+}
+
+// longContextDepth is where long_context hides its needle. Near the start,
+// it is lost if the server drops the oldest part of an overlong prompt.
+const longContextDepth = 10
+
+// longContextEvals returns all long-context evals.
+func longContextEvals() []Eval {
+	return []Eval{
+		&needleHaystackEval{},
+		&longContextEval{},
+	}
+}
+
+// needleFiller is cycled to build the haystack. The sentences are mundane
+// and unrelated so the needle stands out only by its content.
+var needleFiller = []string{
+	"The morning market opened early, and the vendors arranged crates of apples, pears, and plums along the stone walkway.",
+	"Rainfall in the valley was heavier than usual that season, which kept the river high well into the summer months.",
+	"The library extended its weekend hours after students asked for more quiet space to study before examinations.",
+	"A small bakery on the corner started selling rye bread on Tuesdays, and it usually sold out before noon.",
+	"The city council debated whether to repave the old bridge or replace it entirely with a wider modern span.",
+	"Migrating geese passed over the lake in long lines, calling to each other as the evenings grew colder.",
+	"The museum's new exhibit featured maps drawn by sailors who charted the coastline three centuries ago.",
+	"Engineers tested the new elevator for several weeks before the building was finally opened to tenants.",
+	"The orchestra rehearsed the symphony's final movement until the conductor was satisfied with the tempo.",
+	"Farmers in the region rotated their crops between wheat, barley, and clover to keep the soil productive.",
+	"The lighthouse keeper logged the weather every six hours, noting wind speed, visibility, and the state of the sea.",
+	"A local school planted a vegetable garden, and the students took turns watering it during the holidays.",
+}
+
+// needleWords are combined to make a unique passphrase for each grid cell.
+var needleWords = []string{"violet", "harbor", "granite", "falcon", "ember", "willow", "cobalt", "lantern"}
+
+// needleCharsPerToken estimates prompt length for typical English text.
+const needleCharsPerToken = 4
+
+// needleHaystackEval hides a passphrase at several depths within filler text
+// of several lengths and verifies that the model retrieves it, recording a
+// depth by length grid of results.
+type needleHaystackEval struct {
+	streaming bool
+	config    NeedleConfig
+}
+
+func (e *needleHaystackEval) Name() string {
+	return "needle_in_haystack"
+}
+
+func (e *needleHaystackEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *needleHaystackEval) Streaming() bool             { return e.streaming }
+
+func (e *needleHaystackEval) Category() string {
+	return longContextCategory
+}
+
+func (e *needleHaystackEval) Class() string {
+	return ClassStandard
+}
+
+func (e *needleHaystackEval) Tags() []string {
+	return []string{TagSlow}
+}
+
+// IsDefaultDisabled returns true because the eval sends many long prompts.
+func (e *needleHaystackEval) IsDefaultDisabled() bool {
+	return true
+}
+
+func (e *needleHaystackEval) configure(cfg RunnerConfig) {
+	e.config = cfg.Needle
+}
+
+func (e *needleHaystackEval) Run(ctx context.Context, c *client.Client) Result {
+	cfg := e.config
+	if len(cfg.Lengths) == 0 {
+		cfg.Lengths = DefaultNeedleConfig.Lengths
+	}
+	if len(cfg.Depths) == 0 {
+		cfg.Depths = DefaultNeedleConfig.Depths
+	}
+
+	grid := &Grid{
+		RowLabel: "Depth",
+		ColLabel: "Context length (tokens)",
+	}
+	for _, d := range cfg.Depths {
+		grid.Rows = append(grid.Rows, fmt.Sprintf("%d%%", d))
+	}
+	for _, l := range cfg.Lengths {
+		grid.Cols = append(grid.Cols, fmt.Sprintf("%d", l))
+	}
+
+	var failures []string
+	requestErrors := 0
+	found := 0
+	for i, depth := range cfg.Depths {
+		row := make([]bool, len(cfg.Lengths))
+		for j, length := range cfg.Lengths {
+			passphrase := needlePassphrase(i*len(cfg.Lengths) + j)
+			content, failed := completionContent(ctx, c, e, e.streaming, needleRequest(length, depth, passphrase))
+			switch {
+			case failed != nil:
+				requestErrors++
+				failures = append(failures, fmt.Sprintf("%d tokens at %d%%: %s", length, depth, failed.Message))
+			case !strings.Contains(strings.ToLower(content), passphrase):
+				failures = append(failures, fmt.Sprintf("%d tokens at %d%%", length, depth))
+			default:
+				row[j] = true
+				found++
+			}
+		}
+		grid.Cells = append(grid.Cells, row)
+	}
+
+	total := len(cfg.Depths) * len(cfg.Lengths)
+	scores := map[string]float64{"needle_recall": float64(found) / float64(total)}
+
+	if len(failures) > 0 {
+		code := CodeNeedleNotFound
+		if requestErrors == len(failures) {
+			code = CodeRequestFailed
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     code,
+			Message:  fmt.Sprintf("retrieved %d/%d needles; missed: %s", found, total, strings.Join(failures, "; ")),
+			Scores:   scores,
+			Grid:     grid,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("retrieved %d/%d needles", found, total),
+		Scores:   scores,
+		Grid:     grid,
+	}
+}
+
+// needlePassphrase returns a distinct passphrase for grid cell n.
+func needlePassphrase(n int) string {
+	w := len(needleWords)
+	return fmt.Sprintf("%s-%s-%d", needleWords[n%w], needleWords[(n/w+n+1)%w], 10+n)
+}
+
+// needleRequest builds a prompt of roughly length tokens with the passphrase
+// placed depth percent of the way through the filler.
+func needleRequest(length, depth int, passphrase string) client.ChatCompletionRequest {
+	needle := "The secret passphrase is " + passphrase + ". Remember it."
+
+	var sentences []string
+	size := 0
+	for i := 0; size < length*needleCharsPerToken; i++ {
+		s := needleFiller[i%len(needleFiller)]
+		sentences = append(sentences, s)
+		size += len(s) + 1
+	}
+
+	at := len(sentences) * depth / 100
+	sentences = append(sentences[:at], append([]string{needle}, sentences[at:]...)...)
+
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: strings.Join(sentences, " ") +
+				"\n\nWhat is the secret passphrase mentioned in the text above? Reply with just the passphrase."},
+		},
+	}
+}
+
+// longContextEval sends a single prompt of --context-size thousand tokens
+// and verifies that the server accepts it whole and the model retrieves the
+// needle hidden near its start.
+type longContextEval struct {
+	streaming bool
+	size      int
+}
+
+func (e *longContextEval) Name() string {
+	return "long_context"
+}
+
+func (e *longContextEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *longContextEval) Streaming() bool             { return e.streaming }
+
+func (e *longContextEval) Category() string {
+	return longContextCategory
+}
+
+func (e *longContextEval) Class() string {
+	return ClassStandard
+}
+
+func (e *longContextEval) Tags() []string {
+	return []string{TagSlow}
+}
+
+// IsDefaultDisabled returns true because the prompt may exceed the server's
+// context window.
+func (e *longContextEval) IsDefaultDisabled() bool {
+	return true
+}
+
+func (e *longContextEval) configure(cfg RunnerConfig) {
+	e.size = cfg.Needle.ContextSize
+}
+
+func (e *longContextEval) Run(ctx context.Context, c *client.Client) Result {
+	size := e.size
+	if size <= 0 {
+		size = DefaultNeedleConfig.ContextSize
+	}
+	length := size * 1000
+	passphrase := needlePassphrase(size)
+	req := needleRequest(length, longContextDepth, passphrase)
+
+	content, usage, err := e.send(ctx, c, req)
+	if err != nil {
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError {
+			msg := errorMessage(statusErr.Body)
+			if msg == "" {
+				msg = statusErr.Body
+			}
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeLongContextRejected,
+				Message:  fmt.Sprintf("prompt of about %d tokens rejected with status %d: %s", length, statusErr.StatusCode, msg),
+			}
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	var notes []string
+	scores := map[string]float64{}
+	if usage != nil {
+		scores["prompt_tokens"] = float64(usage.PromptTokens)
+	}
+
+	// The prompt's token count is exact where the server can tokenize it;
+	// the chat template only adds to it
+	tokens, tokErr := c.Tokenize(ctx, req.Messages[0].Content)
+	switch {
+	case tokErr != nil:
+		notes = append(notes, "truncation not checked, since /tokenize failed: "+tokErr.Error())
+	case usage == nil:
+		notes = append(notes, "truncation not checked, since the response has no usage")
+	case usage.PromptTokens < len(tokens):
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeLongContextTruncated,
+			Message:  fmt.Sprintf("usage reports %d prompt tokens for a prompt that tokenizes to %d; the prompt was truncated", usage.PromptTokens, len(tokens)),
+			Notes:    notes,
+			Scores:   scores,
+		}
+	}
+
+	if !strings.Contains(strings.ToLower(content), passphrase) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeNeedleNotFound,
+			Message:  fmt.Sprintf("passphrase %q hidden at %d%% of about %d tokens not retrieved", passphrase, longContextDepth, length),
+			Notes:    notes,
+			Scores:   scores,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("retrieved the passphrase from about %d tokens", length),
+		Notes:    notes,
+		Scores:   scores,
+	}
+}
+
+// send sends req in the eval's mode and returns the response content and
+// usage.
+func (e *longContextEval) send(ctx context.Context, c *client.Client, req client.ChatCompletionRequest) (string, *client.Usage, error) {
+	if e.streaming {
+		req.StreamOptions = &client.StreamOptions{IncludeUsage: true}
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return "", nil, err
+		}
+		return result.Content, result.Usage, nil
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return "", resp.Usage, errors.New("no choices in response")
+	}
+	return resp.Choices[0].Message.Content, resp.Usage, nil
+}
@@ -2,6 +2,8 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/aldehir/llm-serving-tests/internal/client"
@@ -14,6 +16,7 @@ func reasoningEvals() []Eval {
 	return []Eval{
 		&reasoningPresentEval{},
 		&reasoningNotLeakedEval{},
+		&reasoningStreamOrderEval{},
 	}
 }
 
@@ -55,6 +58,7 @@ func (e *reasoningPresentEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -67,6 +71,7 @@ func (e *reasoningPresentEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -75,6 +80,7 @@ func (e *reasoningPresentEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -88,6 +94,7 @@ func (e *reasoningPresentEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeReasoningEmpty,
 			Message:  "reasoning_content is empty",
 		}
 	}
@@ -98,10 +105,16 @@ func (e *reasoningPresentEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeContentEmpty,
 			Message:  "content is empty (expected final answer)",
 		}
 	}
 
+	// Reasoning should lead to the right answer
+	if failed := checkNumericAnswer(e, content, 405); failed != nil {
+		return *failed
+	}
+
 	return Result{
 		Name:     e.Name(),
 		Category: e.Category(),
@@ -147,6 +160,7 @@ func (e *reasoningNotLeakedEval) Run(ctx context.Context, c *client.Client) Resu
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -159,6 +173,7 @@ func (e *reasoningNotLeakedEval) Run(ctx context.Context, c *client.Client) Resu
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -167,6 +182,7 @@ func (e *reasoningNotLeakedEval) Run(ctx context.Context, c *client.Client) Resu
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -180,6 +196,7 @@ func (e *reasoningNotLeakedEval) Run(ctx context.Context, c *client.Client) Resu
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeReasoningEmpty,
 			Message:  "reasoning_content is empty, cannot verify leak prevention",
 		}
 	}
@@ -204,6 +221,7 @@ func (e *reasoningNotLeakedEval) Run(ctx context.Context, c *client.Client) Resu
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeReasoningLeaked,
 				Message:  "content appears to contain reasoning (found: " + indicator + ")",
 			}
 		}
@@ -215,3 +233,160 @@ func (e *reasoningNotLeakedEval) Run(ctx context.Context, c *client.Client) Resu
 		Passed:   true,
 	}
 }
+
+// reasoningStreamOrderEval verifies that a stream sends all reasoning before
+// the content and tool calls that follow it. UI clients render the thinking
+// section first and break if reasoning resumes later. Families declared
+// with interleaved_reasoning may resume reasoning after a tool call, but
+// never after content.
+type reasoningStreamOrderEval struct {
+	interleaved bool
+}
+
+func (e *reasoningStreamOrderEval) Name() string {
+	return "reasoning_stream_order"
+}
+
+func (e *reasoningStreamOrderEval) Category() string {
+	return reasoningCategory
+}
+
+func (e *reasoningStreamOrderEval) Class() string {
+	return ClassReasoning
+}
+
+func (e *reasoningStreamOrderEval) Tags() []string {
+	return []string{TagTools}
+}
+
+// IsStreamingOnly returns true because only streams have an order.
+func (e *reasoningStreamOrderEval) IsStreamingOnly() bool {
+	return true
+}
+
+func (e *reasoningStreamOrderEval) configure(cfg RunnerConfig) {
+	e.interleaved = cfg.Template != nil && cfg.Template.InterleavedReasoning
+}
+
+// streamSegment is a run of consecutive deltas of one kind.
+type streamSegment struct {
+	kind   string
+	chunks int
+}
+
+// streamSegments returns the runs of reasoning, content, and tool call
+// deltas in chunks. A chunk with several kinds counts as reasoning, then
+// content, then tool calls.
+func streamSegments(chunks []client.ChatCompletionChunk) []streamSegment {
+	var segments []streamSegment
+	add := func(kind string) {
+		if n := len(segments); n > 0 && segments[n-1].kind == kind {
+			segments[n-1].chunks++
+			return
+		}
+		segments = append(segments, streamSegment{kind: kind, chunks: 1})
+	}
+	for _, chunk := range chunks {
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		d := chunk.Choices[0].Delta
+		if d.ReasoningContent != "" {
+			add("reasoning")
+		}
+		if d.Content != "" {
+			add("content")
+		}
+		if len(d.ToolCalls) > 0 {
+			add("tool_calls")
+		}
+	}
+	return segments
+}
+
+// streamPattern formats segments, e.g. "reasoning×12 → content×30".
+func streamPattern(segments []streamSegment) string {
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		parts[i] = fmt.Sprintf("%s×%d", s.kind, s.chunks)
+	}
+	return strings.Join(parts, " → ")
+}
+
+func (e *reasoningStreamOrderEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "What is 15 * 27? Think step by step and give the answer, then get the weather in Paris and in Tokyo."},
+		},
+		Tools: []client.Tool{
+			{
+				Type: "function",
+				Function: client.ToolFunction{
+					Name:        "get_weather",
+					Description: "Get the current weather for a location",
+					Parameters: json.RawMessage(`{
+						"type": "object",
+						"properties": {
+							"location": {
+								"type": "string",
+								"description": "The city, e.g. Paris"
+							}
+						},
+						"required": ["location"]
+					}`),
+				},
+			},
+		},
+	}
+
+	result, err := c.ChatCompletionStream(ctx, req)
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	segments := streamSegments(result.Chunks)
+	pattern := streamPattern(segments)
+	notes := []string{"observed pattern: " + pattern}
+	if strings.TrimSpace(result.ReasoningContent) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeReasoningEmpty,
+			Message:  "reasoning_content is empty, cannot verify stream order",
+			Notes:    notes,
+		}
+	}
+
+	// Reasoning may follow only reasoning, or a tool call if interleaved
+	for i := 1; i < len(segments); i++ {
+		if segments[i].kind != "reasoning" {
+			continue
+		}
+		prev := segments[i-1].kind
+		if prev == "tool_calls" && e.interleaved {
+			continue
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeReasoningOutOfOrder,
+			Message:  fmt.Sprintf("reasoning resumed after %s: %s", prev, pattern),
+			Notes:    notes,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Notes:    notes,
+	}
+}
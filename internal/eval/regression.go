@@ -0,0 +1,217 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	evallog "github.com/aldehir/llm-serving-tests/internal/log"
+)
+
+// RegressionPackVersion is the format version of regression packs written
+// by this build. Packs of other versions must be regenerated.
+const RegressionPackVersion = 1
+
+// regressionListMax bounds the shape entries listed in a failure message.
+const regressionListMax = 5
+
+// RegressionPack is a snapshot of a fully passing run: the prompts each
+// eval had rendered by /apply-template and the shapes of the responses it
+// received. Later runs are checked against it to catch template and
+// response format changes that evals do not fail on.
+type RegressionPack struct {
+	Version int
+	Model   string
+	Created time.Time
+	// Evals holds the snapshot of each eval, keyed by name with mode
+	// suffix, e.g. "chat_completion (blocking)".
+	Evals map[string]RegressionSnapshot
+}
+
+// RegressionSnapshot is what one eval saw in the run a pack was made from.
+type RegressionSnapshot struct {
+	// Templates holds each prompt rendered by /apply-template, in order.
+	Templates []string `json:",omitempty"`
+	// Shapes holds the shape of each successful response, in order, as
+	// sorted "path: type" entries.
+	Shapes [][]string `json:",omitempty"`
+}
+
+// BuildRegressionPack makes a regression pack from the logs of a run in
+// dir. Every eval of the run must have passed, and logged its exchanges in
+// turns.jsonl. Repeated evals contribute their first run.
+func BuildRegressionPack(dir, model string) (*RegressionPack, error) {
+	evals, err := evallog.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(evals) == 0 {
+		return nil, fmt.Errorf("no eval logs found in %s", dir)
+	}
+
+	var failed []string
+	for _, ev := range evals {
+		if !ev.Passed {
+			failed = append(failed, ev.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("run has %d failed evals (%s); a regression pack must be made from a fully passing run",
+			len(failed), strings.Join(failed, ", "))
+	}
+
+	pack := &RegressionPack{
+		Version: RegressionPackVersion,
+		Model:   model,
+		Created: time.Now().UTC(),
+		Evals:   make(map[string]RegressionSnapshot, len(evals)),
+	}
+	for _, ev := range evals {
+		exchanges, err := evallog.LoadExchanges(dir, ev.Name)
+		if err != nil {
+			return nil, err
+		}
+		if exchanges == nil {
+			return nil, fmt.Errorf("%s has no recorded exchanges; rerun with a build that writes turns.jsonl", ev.Name)
+		}
+
+		// Replay the exchanges through the capture used by live runs
+		capture := &responseCapture{}
+		for _, ex := range exchanges {
+			if ex.Iteration > 1 {
+				continue
+			}
+			capture.LogRequest(ex.Method, ex.URL, ex.RequestBody, ex.Sent)
+			if ex.StreamRaw != "" {
+				capture.LogStreamResponse(ex.Status, []byte(ex.StreamRaw))
+			} else {
+				capture.LogResponse(ex.Status, ex.ResponseBody)
+			}
+		}
+		pack.Evals[ev.Name] = RegressionSnapshot{
+			Templates: capture.prompts,
+			Shapes:    capture.shapes,
+		}
+	}
+	return pack, nil
+}
+
+// Write saves the pack as indented JSON.
+func (p *RegressionPack) Write(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal regression pack: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write regression pack: %w", err)
+	}
+	return nil
+}
+
+// LoadRegressionPack reads a regression pack written by Write.
+func LoadRegressionPack(path string) (*RegressionPack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read regression pack: %w", err)
+	}
+	var pack RegressionPack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("parse regression pack %s: %w", path, err)
+	}
+	if pack.Version != RegressionPackVersion {
+		return nil, fmt.Errorf("regression pack %s has version %d, but this build reads version %d; regenerate it",
+			path, pack.Version, RegressionPackVersion)
+	}
+	return &pack, nil
+}
+
+// check fails a passing result whose rendered templates or response shapes
+// differ from the pack's snapshot of the eval. Evals absent from the pack
+// are not checked.
+func (p *RegressionPack) check(result Result, capture *responseCapture) Result {
+	snap, ok := p.Evals[result.Name]
+	if !ok || !result.Passed {
+		return result
+	}
+
+	for i, want := range snap.Templates {
+		if i >= len(capture.prompts) {
+			result.Passed = false
+			result.Code = CodeRegressionTemplate
+			result.Message = fmt.Sprintf("rendered %d of the pack's %d templates", len(capture.prompts), len(snap.Templates))
+			return result
+		}
+		if got := capture.prompts[i]; got != want {
+			at := commonPrefixLen(got, want)
+			result.Passed = false
+			result.Code = CodeRegressionTemplate
+			result.Message = fmt.Sprintf("rendered template %d changed at byte %d: %q, was %q",
+				i+1, at, excerptAt(got, at), excerptAt(want, at))
+			result.Diffs = append(result.Diffs, Diff{
+				ALabel: fmt.Sprintf("pack template %d", i+1),
+				BLabel: "current",
+				A:      want,
+				B:      got,
+			})
+			return result
+		}
+	}
+
+	for i, want := range snap.Shapes {
+		if i >= len(capture.shapes) {
+			result.Passed = false
+			result.Code = CodeRegressionShape
+			result.Message = fmt.Sprintf("received %d of the pack's %d responses", len(capture.shapes), len(snap.Shapes))
+			return result
+		}
+		lost, gained := shapeDiff(want, capture.shapes[i])
+		if len(lost) > 0 || len(gained) > 0 {
+			var changes []string
+			if len(lost) > 0 {
+				changes = append(changes, "lost "+shapeList(lost))
+			}
+			if len(gained) > 0 {
+				changes = append(changes, "gained "+shapeList(gained))
+			}
+			result.Passed = false
+			result.Code = CodeRegressionShape
+			result.Message = fmt.Sprintf("shape of response %d changed: %s", i+1, strings.Join(changes, "; "))
+			return result
+		}
+	}
+
+	return result
+}
+
+// shapeDiff returns the shape entries of want missing from got, and those
+// of got missing from want.
+func shapeDiff(want, got []string) (lost, gained []string) {
+	wantSet := make(map[string]bool, len(want))
+	for _, s := range want {
+		wantSet[s] = true
+	}
+	gotSet := make(map[string]bool, len(got))
+	for _, s := range got {
+		gotSet[s] = true
+		if !wantSet[s] {
+			gained = append(gained, s)
+		}
+	}
+	for _, s := range want {
+		if !gotSet[s] {
+			lost = append(lost, s)
+		}
+	}
+	return lost, gained
+}
+
+// shapeList lists shape entries for a failure message, eliding all but the
+// first regressionListMax.
+func shapeList(entries []string) string {
+	if len(entries) > regressionListMax {
+		return fmt.Sprintf("[%s, and %d more]", strings.Join(entries[:regressionListMax], ", "), len(entries)-regressionListMax)
+	}
+	return "[" + strings.Join(entries, ", ") + "]"
+}
@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"fmt"
+	"time"
+)
+
+// Iteration is the outcome of one run of an eval repeated with --repeat.
+type Iteration struct {
+	Passed   bool
+	Code     string
+	Message  string
+	Duration time.Duration
+}
+
+// PassedIterations returns the number of passing iterations.
+func PassedIterations(iterations []Iteration) int {
+	n := 0
+	for _, it := range iterations {
+		if it.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// CombineRuns combines separate runs of one eval, such as the same eval in
+// several merged results files, as if it had been repeated: the result
+// passes only if the fraction of passing runs meets the threshold. Runs
+// that were themselves repeated contribute each of their iterations.
+// Scores and notes come from the last run.
+func CombineRuns(runs []Result, threshold float64) Result {
+	var iterations []Iteration
+	var duration time.Duration
+	for _, r := range runs {
+		duration += r.Duration
+		if len(r.Iterations) > 0 {
+			iterations = append(iterations, r.Iterations...)
+			continue
+		}
+		iterations = append(iterations, Iteration{
+			Passed:   r.Passed,
+			Code:     r.Code,
+			Message:  r.Message,
+			Duration: r.Duration,
+		})
+	}
+
+	last := runs[len(runs)-1]
+	result := aggregateIterations(iterations, threshold)
+	result.Name = last.Name
+	result.Category = last.Category
+	result.Class = last.Class
+	result.Duration = duration
+	result.Scores = last.Scores
+	result.Notes = last.Notes
+	return result
+}
+
+// aggregateIterations combines repeated runs into a single result that passes
+// only if the fraction of passing iterations meets the threshold. A failing
+// result takes its code from the first failed iteration.
+func aggregateIterations(iterations []Iteration, threshold float64) Result {
+	total := len(iterations)
+	passed := PassedIterations(iterations)
+	rate := float64(passed) / float64(total)
+
+	// Tolerate float error so that e.g. 4/5 meets a 0.8 threshold
+	if rate >= threshold-1e-9 {
+		return Result{
+			Passed:     true,
+			Message:    fmt.Sprintf("passed %d/%d iterations", passed, total),
+			Iterations: iterations,
+		}
+	}
+
+	result := Result{
+		Passed:     false,
+		Message:    fmt.Sprintf("passed %d/%d iterations (%.0f%% < %.0f%%)", passed, total, rate*100, threshold*100),
+		Iterations: iterations,
+	}
+	for _, it := range iterations {
+		if !it.Passed {
+			result.Code = it.Code
+			result.Message += ": " + it.Message
+			break
+		}
+	}
+	return result
+}
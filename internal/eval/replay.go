@@ -0,0 +1,220 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	evallog "github.com/aldehir/llm-serving-tests/internal/log"
+)
+
+// ReplayOutcome is how a server answered the recorded requests of one eval,
+// compared with the responses captured in the eval's log.
+type ReplayOutcome struct {
+	// Name is the eval's name with mode suffix.
+	Name string
+	// Requests is the number of requests replayed. Skipped counts recorded
+	// requests to endpoints that are not replayed.
+	Requests int
+	Skipped  int
+	// Divergences describes each difference that changes behavior: in
+	// response status, shape, tool calls, or rendered template.
+	Divergences []string
+	// Diffs holds the content of responses that differed from the capture.
+	// Sampled content rarely repeats, so these are not divergences.
+	Diffs []Diff
+}
+
+// Diverged reports whether the server behaved differently from the capture.
+func (o ReplayOutcome) Diverged() bool {
+	return len(o.Divergences) > 0
+}
+
+// ReplayAgainst re-sends the chat completion and /apply-template requests
+// recorded in the turns.jsonl files of the run in dir to the server of c,
+// comparing each response with the captured one. Evals whose names do not
+// match filter are skipped, as are later runs of repeated evals.
+// The client's model replaces the recorded one.
+func ReplayAgainst(ctx context.Context, dir string, c *client.Client, filter string) ([]ReplayOutcome, error) {
+	evals, err := evallog.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var outcomes []ReplayOutcome
+	for _, ev := range evals {
+		if !NameMatches(ev.Name, filter) {
+			continue
+		}
+		exchanges, err := evallog.LoadExchanges(dir, ev.Name)
+		if err != nil {
+			return nil, err
+		}
+		if exchanges == nil {
+			return nil, fmt.Errorf("%s has no recorded exchanges; rerun with a build that writes turns.jsonl", ev.Name)
+		}
+
+		outcome := ReplayOutcome{Name: ev.Name}
+		for _, ex := range exchanges {
+			if ex.Iteration > 1 {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return outcomes, err
+			}
+			if !replayable(ex.URL) {
+				outcome.Skipped++
+				continue
+			}
+			outcome.Requests++
+			replayExchange(ctx, c, ex, outcome.Requests, &outcome)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, nil
+}
+
+// replayable reports whether requests to url are replayed.
+func replayable(url string) bool {
+	return strings.HasSuffix(url, "/chat/completions") || strings.HasSuffix(url, "/apply-template")
+}
+
+// replayExchange re-sends the nth replayed request of an eval, recording
+// how the response differs from the captured one in outcome.
+func replayExchange(ctx context.Context, c *client.Client, ex evallog.Exchange, n int, outcome *ReplayOutcome) {
+	recorded := &responseCapture{}
+	recorded.addExchange(c.Provider(), ex)
+
+	replayed := &responseCapture{}
+	var logger evallog.RequestLogger = replayed
+	if l := c.Logger(); l != nil {
+		logger = teeLogger{l, replayed}
+	}
+	c = c.WithLogger(logger).WithObserver(replayed)
+
+	status, err := sendRecorded(ctx, c, ex)
+	label := fmt.Sprintf("request %d", n)
+	if status != ex.Status {
+		got := fmt.Sprintf("status %d", status)
+		if status == 0 {
+			got = "failed: " + err.Error()
+		}
+		outcome.Divergences = append(outcome.Divergences, fmt.Sprintf("%s: status %d in capture, but %s", label, ex.Status, got))
+		return
+	}
+	if status != http.StatusOK {
+		return
+	}
+
+	if len(recorded.shapes) > 0 && len(replayed.shapes) > 0 {
+		if lost, gained := shapeDiff(recorded.shapes[0], replayed.shapes[0]); len(lost) > 0 || len(gained) > 0 {
+			var changes []string
+			if len(lost) > 0 {
+				changes = append(changes, "lost "+shapeList(lost))
+			}
+			if len(gained) > 0 {
+				changes = append(changes, "gained "+shapeList(gained))
+			}
+			outcome.Divergences = append(outcome.Divergences, fmt.Sprintf("%s: response shape %s", label, strings.Join(changes, "; ")))
+		}
+	}
+
+	if len(recorded.toolCalls) > 0 && len(replayed.toolCalls) > 0 {
+		a, b := recorded.toolCalls[0], replayed.toolCalls[0]
+		if strings.Join(a, "\n") != strings.Join(b, "\n") {
+			outcome.Divergences = append(outcome.Divergences, fmt.Sprintf("%s: tool calls %s in capture, but %s",
+				label, describeToolCalls(a), describeToolCalls(b)))
+		}
+	}
+
+	if len(recorded.prompts) > 0 && len(replayed.prompts) > 0 {
+		a, b := recorded.prompts[0], replayed.prompts[0]
+		if a != b {
+			at := commonPrefixLen(a, b)
+			outcome.Divergences = append(outcome.Divergences, fmt.Sprintf("%s: rendered template differs at byte %d: %q in capture, but %q",
+				label, at, excerptAt(a, at), excerptAt(b, at)))
+			outcome.Diffs = append(outcome.Diffs, Diff{ALabel: label + " (capture)", BLabel: "replay", A: a, B: b})
+		}
+	}
+
+	if len(recorded.contents) > 0 && len(replayed.contents) > 0 {
+		if a, b := recorded.contents[0], replayed.contents[0]; a != b {
+			outcome.Diffs = append(outcome.Diffs, Diff{ALabel: label + " (capture)", BLabel: "replay", A: a, B: b})
+		}
+	}
+}
+
+// sendRecorded sends the request of a recorded exchange, returning the
+// response status, or 0 with the error if there was no response.
+func sendRecorded(ctx context.Context, c *client.Client, ex evallog.Exchange) (int, error) {
+	var err error
+	if strings.HasSuffix(ex.URL, "/apply-template") {
+		var req client.ApplyTemplateRequest
+		if err := json.Unmarshal(ex.RequestBody, &req); err != nil {
+			return 0, fmt.Errorf("parse recorded request: %w", err)
+		}
+		_, err = c.ApplyTemplate(ctx, req.Messages)
+	} else {
+		req, perr := recordedChatRequest(ex.RequestBody)
+		if perr != nil {
+			return 0, perr
+		}
+		// The client defaults stream_options; send the recorded ones
+		c = c.WithRewrite(func(r *client.ChatCompletionRequest) { r.StreamOptions = req.StreamOptions })
+		if req.Stream {
+			_, err = c.ChatCompletionStream(ctx, req)
+		} else {
+			_, err = c.ChatCompletion(ctx, req)
+		}
+	}
+
+	if err == nil {
+		return http.StatusOK, nil
+	}
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode, err
+	}
+	return 0, err
+}
+
+// recordedChatRequest parses a recorded chat completion request. Fields the
+// request type does not declare, such as server-specific parameters, are
+// kept in Extra, so that the request is re-sent as recorded.
+func recordedChatRequest(body []byte) (client.ChatCompletionRequest, error) {
+	var req client.ChatCompletionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return req, fmt.Errorf("parse recorded request: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return req, fmt.Errorf("parse recorded request: %w", err)
+	}
+
+	typed, err := json.Marshal(req)
+	if err != nil {
+		return req, fmt.Errorf("marshal recorded request: %w", err)
+	}
+	var declared map[string]json.RawMessage
+	if err := json.Unmarshal(typed, &declared); err != nil {
+		return req, fmt.Errorf("marshal recorded request: %w", err)
+	}
+	for key, value := range fields {
+		switch key {
+		case "model", "stream", "stream_options":
+			continue
+		}
+		if _, ok := declared[key]; ok {
+			continue
+		}
+		if req.Extra == nil {
+			req.Extra = make(map[string]any)
+		}
+		req.Extra[key] = value
+	}
+	return req, nil
+}
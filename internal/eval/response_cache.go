@@ -0,0 +1,210 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	evallog "github.com/aldehir/llm-serving-tests/internal/log"
+)
+
+// cacheStatusHeaders are response headers in which caches and gateways
+// report whether a response was served from the cache.
+var cacheStatusHeaders = []string{
+	"x-cache",
+	"x-cache-status",
+	"cf-cache-status",
+	"x-litellm-cache-hit",
+	"x-portkey-cache-status",
+}
+
+// responseCacheEval verifies that the server answers each request anew. A
+// proxy that caches chat completions returns a stored response to a
+// repeated request, so later results would measure the cache rather than
+// the model.
+type responseCacheEval struct {
+	streaming bool
+}
+
+func (e *responseCacheEval) Name() string {
+	return "response_caching"
+}
+
+func (e *responseCacheEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *responseCacheEval) Streaming() bool             { return e.streaming }
+
+func (e *responseCacheEval) Category() string {
+	return compatCategory
+}
+
+func (e *responseCacheEval) Class() string {
+	return ClassStandard
+}
+
+// cachedResponse is one answer to the repeated request.
+type cachedResponse struct {
+	// body is the response body as received, or the raw stream.
+	body    []byte
+	id      string
+	content string
+	headers map[string]string
+}
+
+func (e *responseCacheEval) Run(ctx context.Context, c *client.Client) Result {
+	var responses [2]cachedResponse
+	for i := range responses {
+		r, err := e.send(ctx, c)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  fmt.Sprintf("request %d failed: %v", i+1, err),
+			}
+		}
+		responses[i] = r
+	}
+	first, second := responses[0], responses[1]
+
+	var cached string
+	switch {
+	case bytes.Equal(first.body, second.body):
+		cached = "identical unseeded requests at temperature 1 returned byte-identical responses"
+	case first.id != "" && first.id == second.id:
+		cached = fmt.Sprintf("both responses to identical unseeded requests have id %q", first.id)
+	default:
+		if name, value := cacheHit(second.headers); name != "" {
+			cached = fmt.Sprintf("the repeated request's response has %s: %s", name, value)
+		}
+	}
+	if cached != "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeResponseCached,
+			Message:  cached + ", so chat completions are cached and later results may not reflect the model",
+		}
+	}
+
+	// What the server says of its caching is worth knowing even when it
+	// did not serve the request from a cache
+	var behavior []string
+	if first.content == second.content {
+		behavior = append(behavior, "content was identical, though the responses were not, so likely sampled alike")
+	}
+	if status := cacheStatus(second.headers); status != "" {
+		behavior = append(behavior, "cache headers: "+status)
+	}
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  strings.Join(behavior, "; "),
+	}
+}
+
+// send sends the repeated request, capturing the response as received
+// alongside the eval's log.
+func (e *responseCacheEval) send(ctx context.Context, c *client.Client) (cachedResponse, error) {
+	capture := &bodyCapture{}
+	var logger evallog.RequestLogger = capture
+	if l := c.Logger(); l != nil {
+		logger = teeLogger{l, capture}
+	}
+	c = c.WithLogger(logger)
+
+	// Without a seed, and sampling, a server generating anew is unlikely
+	// to answer twice alike
+	temperature := 1.0
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Invent a name for a fictional city and describe it in one sentence."},
+		},
+		Temperature: &temperature,
+		MaxTokens:   64,
+	}
+
+	var r cachedResponse
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return r, err
+		}
+		r.content = result.Content
+		r.headers = result.Headers
+		if len(result.Chunks) > 0 {
+			r.id = result.Chunks[0].ID
+		}
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return r, err
+		}
+		if len(resp.Choices) > 0 {
+			r.content = resp.Choices[0].Message.Content
+		}
+		r.id = resp.ID
+		r.headers = resp.Headers
+	}
+	r.body = capture.body
+	return r, nil
+}
+
+// cacheHit returns the header reporting that a response was served from a
+// cache, or "" if none does. A positive Age means a cache held the
+// response for that long.
+func cacheHit(headers map[string]string) (name, value string) {
+	for _, h := range cacheStatusHeaders {
+		v := headers[h]
+		if strings.Contains(strings.ToLower(v), "hit") || strings.EqualFold(v, "true") {
+			return h, v
+		}
+	}
+	if age, err := strconv.Atoi(headers["age"]); err == nil && age > 0 {
+		return "age", headers["age"]
+	}
+	return "", ""
+}
+
+// cacheStatus lists the cache headers of a response, or "" if it has none.
+func cacheStatus(headers map[string]string) string {
+	var status []string
+	for _, h := range cacheStatusHeaders {
+		if v, ok := headers[h]; ok {
+			status = append(status, h+": "+v)
+		}
+	}
+	if v, ok := headers["age"]; ok {
+		status = append(status, "age: "+v)
+	}
+	return strings.Join(status, ", ")
+}
+
+// bodyCapture records the body of the last response, or the raw stream, as
+// received. It is used as a request logger.
+type bodyCapture struct {
+	mu   sync.Mutex
+	body []byte
+}
+
+func (bc *bodyCapture) LogResponse(status int, body []byte) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.body = bytes.Clone(body)
+}
+
+func (bc *bodyCapture) LogStreamResponse(status int, rawChunks []byte) {
+	bc.LogResponse(status, rawChunks)
+}
+
+func (bc *bodyCapture) LogRequest(method, url string, body []byte, sent time.Time) {}
+func (bc *bodyCapture) LogResponseHeaders(headers map[string]string)               {}
+func (bc *bodyCapture) LogStreamTiming(ttft, itl time.Duration, chunks int)        {}
+func (bc *bodyCapture) LogStreamChunks(jsonl []byte)                               {}
@@ -3,12 +3,17 @@ package eval
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
 	evallog "github.com/aldehir/llm-serving-tests/internal/log"
+	"github.com/aldehir/llm-serving-tests/internal/textdiff"
 	"github.com/fatih/color"
 )
 
@@ -21,6 +26,9 @@ const (
 	// ClassInterleaved is for evals that require interleaved reasoning
 	// (reasoning_content sent back in multi-turn conversations).
 	ClassInterleaved = "interleaved"
+	// ClassPerformance is for evals that measure server latency rather than
+	// model behavior. It is outside the hierarchy of the other classes.
+	ClassPerformance = "performance"
 )
 
 // StreamMode controls whether evals run in blocking, streaming, or both modes.
@@ -40,9 +48,31 @@ func AllModes() []string {
 	return []string{string(ModeBlocking), string(ModeStreaming), string(ModeBoth)}
 }
 
+// Server flavors select evals for features specific to one server
+// implementation.
+const (
+	// FlavorGeneric runs only evals that apply to any OpenAI-compatible server.
+	FlavorGeneric = "generic"
+	// FlavorLlamaCpp adds evals for llama.cpp server extensions.
+	FlavorLlamaCpp = "llama.cpp"
+	// FlavorVLLM adds evals for vLLM request extensions.
+	FlavorVLLM = "vllm"
+	// FlavorTGI tolerates Text Generation Inference's deviations from the
+	// OpenAI API, reporting them as notes.
+	FlavorTGI = "tgi"
+	// FlavorOpenRouter tolerates OpenRouter's gateway metadata, reporting
+	// it as notes.
+	FlavorOpenRouter = "openrouter"
+)
+
+// AllFlavors returns all valid server flavors.
+func AllFlavors() []string {
+	return []string{FlavorGeneric, FlavorLlamaCpp, FlavorVLLM, FlavorTGI, FlavorOpenRouter}
+}
+
 // AllClasses returns all valid eval classes.
 func AllClasses() []string {
-	return []string{ClassStandard, ClassReasoning, ClassInterleaved}
+	return []string{ClassStandard, ClassReasoning, ClassInterleaved, ClassPerformance}
 }
 
 // ClassMatches returns true if the eval's class is compatible with the requested class.
@@ -50,6 +80,7 @@ func AllClasses() []string {
 // - standard: only standard tests
 // - reasoning: standard + reasoning tests
 // - interleaved: standard + reasoning + interleaved tests
+// - performance: only performance tests
 func ClassMatches(evalClass, requestedClass string) bool {
 	if requestedClass == "" {
 		return true
@@ -94,9 +125,87 @@ type StreamModeEval interface {
 type Result struct {
 	Name     string
 	Category string
+	Class    string
 	Passed   bool
+	// Code is a stable, machine-readable failure code (see codes.go).
+	// Empty for passing results.
+	Code     string
 	Message  string
 	Duration time.Duration
+	// Stats holds request metrics (request count, tokens, TTFT) for the eval.
+	Stats client.Stats
+	// Iterations holds per-run outcomes when the eval was repeated.
+	Iterations []Iteration
+	// Scores holds named measurements recorded by the eval, such as content
+	// coverage, keyed by metric name.
+	Scores map[string]float64 `json:",omitempty"`
+	// Grid holds a two-dimensional breakdown of the eval's checks, if any.
+	Grid *Grid `json:",omitempty"`
+	// Notes lists deviations from the OpenAI API that the server flavor
+	// tolerates, reported instead of failing the eval.
+	Notes []string `json:",omitempty"`
+	// SLOViolations lists how the eval exceeded its latency objectives
+	// (see RunnerConfig.SLOs). They do not fail the eval.
+	SLOViolations []string `json:",omitempty"`
+	// Samples lists the invalid responses of an eval that checks many.
+	Samples []Sample `json:",omitempty"`
+	// Violations lists the lines of the eval's streams that were skipped
+	// since they were not chunks, such as error events and non-JSON data.
+	Violations []client.StreamViolation `json:",omitempty"`
+	// Diffs holds outputs that should have matched but did not.
+	Diffs []Diff `json:",omitempty"`
+	// Artifacts are files that explain the verdict, such as a rendered
+	// template, written beside the eval's log and linked from the report.
+	Artifacts []Artifact `json:"-"`
+	// Repro describes the minimized failing request, with Shrink.
+	Repro *Repro `json:",omitempty"`
+	// Compare holds the outcome on the comparison server, with Compare.
+	Compare *Comparison `json:",omitempty"`
+	// Resumed is true if the result was loaded from a previous run's state.
+	Resumed bool `json:"-"`
+}
+
+// Sample is one invalid response of an eval that checks many, with the
+// prompt that produced it.
+type Sample struct {
+	Prompt  string
+	Code    string
+	Message string
+	Output  string
+}
+
+// Artifact is a file attached to a result as evidence for its verdict.
+type Artifact struct {
+	// Name is the file name, e.g. "rendered-template.txt".
+	Name string
+	Data []byte
+}
+
+// Diff is a pair of outputs that should have matched, such as the responses
+// to two identical seeded requests, shown as a word-level diff.
+type Diff struct {
+	// ALabel and BLabel name the outputs, e.g. "request 1" and "request 2".
+	ALabel string
+	BLabel string
+	A      string
+	B      string
+}
+
+// Grid is a two-dimensional pass/fail breakdown of an eval's checks, such as
+// needle retrieval by depth and context length.
+type Grid struct {
+	RowLabel string
+	ColLabel string
+	Rows     []string
+	Cols     []string
+	// Cells[i][j] is the outcome for Rows[i] and Cols[j].
+	Cells [][]bool
+}
+
+// configurable is implemented by evals that take settings from the runner
+// configuration, such as the needle-in-a-haystack grid.
+type configurable interface {
+	configure(cfg RunnerConfig)
 }
 
 // DefaultDisabled is an optional interface for evals that are disabled by default.
@@ -115,15 +224,171 @@ func IsDefaultDisabled(e Eval) bool {
 	return false
 }
 
+// VisionEval is an optional interface for evals that send images. Evals
+// implementing it with RequiresVision() returning true only run when
+// --vision is given, since text-only models reject or ignore images.
+type VisionEval interface {
+	RequiresVision() bool
+}
+
+// RequiresVision returns true if the eval sends images.
+func RequiresVision(e Eval) bool {
+	if ve, ok := e.(VisionEval); ok {
+		return ve.RequiresVision()
+	}
+	return false
+}
+
+// StreamingOnly is an optional interface for evals that only apply to
+// streaming responses. Evals implementing this interface with
+// IsStreamingOnly() returning true are never run in blocking mode.
+type StreamingOnly interface {
+	IsStreamingOnly() bool
+}
+
+// IsStreamingOnly returns true if the eval only runs in streaming mode.
+// This checks if the eval implements the StreamingOnly interface.
+func IsStreamingOnly(e Eval) bool {
+	if so, ok := e.(StreamingOnly); ok {
+		return so.IsStreamingOnly()
+	}
+	return false
+}
+
+// BlockingOnly is an optional interface for evals whose requests have no
+// streaming form, such as listing models. Evals implementing this interface
+// with IsBlockingOnly() returning true are never run in streaming mode.
+type BlockingOnly interface {
+	IsBlockingOnly() bool
+}
+
+// IsBlockingOnly returns true if the eval only runs in blocking mode.
+// This checks if the eval implements the BlockingOnly interface.
+func IsBlockingOnly(e Eval) bool {
+	if bo, ok := e.(BlockingOnly); ok {
+		return bo.IsBlockingOnly()
+	}
+	return false
+}
+
+// FlavorSpecific is an optional interface for evals of server-specific
+// extensions. They run only when the configured flavor matches.
+type FlavorSpecific interface {
+	Flavor() string
+}
+
+// EvalFlavor returns the server flavor an eval requires, or "" if it
+// applies to any server.
+func EvalFlavor(e Eval) string {
+	if fs, ok := e.(FlavorSpecific); ok {
+		return fs.Flavor()
+	}
+	return ""
+}
+
+// FlavorMatches returns true if the eval runs against the given flavor.
+func FlavorMatches(e Eval, flavor string) bool {
+	f := EvalFlavor(e)
+	return f == "" || f == flavor
+}
+
+// Fundamental is an optional interface for cheap evals that check basic
+// endpoint functionality. Fundamental evals run before all others so that a
+// broken endpoint is detected early.
+type Fundamental interface {
+	IsFundamental() bool
+}
+
+// IsFundamental returns true if the eval is a fundamental eval.
+// This checks if the eval implements the Fundamental interface.
+func IsFundamental(e Eval) bool {
+	if f, ok := e.(Fundamental); ok {
+		return f.IsFundamental()
+	}
+	return false
+}
+
 // RunnerConfig configures the runner.
 type RunnerConfig struct {
 	Verbose bool
-	Filter  string
+	Filter  string // Regular expression matched against eval names
 	Class   string
-	All     bool // Include evals that are disabled by default
+	Flavor  string // Server flavor; flavor-specific evals run only if it matches
+	All     bool   // Include evals that are disabled by default
+	Vision  bool   // Include evals that send images
 	Logger  *evallog.Logger
 	Jobs    int        // Number of parallel test executions (1 = sequential)
 	Mode    StreamMode // Streaming mode: blocking, streaming, or both
+	State   *RunState  // Persists completed results; completed evals are skipped
+	// Tags and SkipTags select the runs of evals, in each mode, that have
+	// every tag of Tags and none of SkipTags (see EvalTags).
+	Tags     []string
+	SkipTags []string
+	// Evals, if set, runs exactly the named evals, ignoring Filter, Class,
+	// Flavor, All, and tags. Disabled-by-default evals run if named.
+	Evals []string
+	// Repeat runs each eval this many times (<= 1 runs once).
+	Repeat int
+	// PassThreshold is the fraction of repeated runs that must pass.
+	PassThreshold float64
+	// FailFastOnBasic aborts the run if any fundamental eval fails.
+	FailFastOnBasic bool
+	// FailFast stops scheduling new evals after the first failure.
+	// In parallel mode, evals already in flight still complete.
+	FailFast bool
+	// Needle configures the needle-in-a-haystack grid.
+	Needle NeedleConfig
+	// StressConcurrency is the number of streams concurrent_streams sends
+	// at once (<= 0 uses DefaultStressConcurrency).
+	StressConcurrency int
+	// Disabled lists the categories and evals the config file disables,
+	// which are reported as skipped instead of run.
+	Disabled Disabled
+	// Template declares the chat template markers of the model's family,
+	// for template_conformance. Nil disables the eval.
+	Template *TemplateMarkers
+	// Shrink minimizes the last chat request of each failed eval into a
+	// repro file in the log directory. It requires Logger.
+	Shrink bool
+	// SLOs declares latency objectives that evals are checked against.
+	SLOs SLOs
+	// OutputScanners scan every chat completion output of the run; what
+	// they flag is reported apart from the results (see OutputFindings).
+	OutputScanners []hygiene.Scanner
+	// Compare, if set, runs each eval against a second server as well and
+	// reports where its outcome, tool calls, or rendered templates diverge.
+	Compare *client.Client
+	// RegressionPack, if set, fails passing evals whose rendered templates
+	// or response shapes differ from the pack.
+	RegressionPack *RegressionPack
+	// Shard, if set, runs only its part of the selected evals.
+	Shard Shard
+	// OnResult, if set, is called with each result as it completes, by one
+	// goroutine at a time.
+	OnResult func(Result)
+}
+
+// Disabled maps category and eval names to the reason they are disabled.
+type Disabled struct {
+	Categories map[string]string
+	Evals      map[string]string
+}
+
+// reason returns why an eval is disabled, preferring the reason given for
+// the eval over that for its category.
+func (d Disabled) reason(e Eval) (string, bool) {
+	if reason, ok := d.Evals[e.Name()]; ok {
+		return reason, true
+	}
+	reason, ok := d.Categories[e.Category()]
+	return reason, ok
+}
+
+// Skip is a selected eval that was not run since it is disabled.
+type Skip struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
 }
 
 // Runner executes evals.
@@ -131,24 +396,87 @@ type Runner struct {
 	client *client.Client
 	config RunnerConfig
 	evals  []Eval
+
+	// skipped lists the selected evals that are disabled
+	skipped []Skip
+
+	// findings holds what the output scanners flagged
+	findingsMu sync.Mutex
+	findings   []hygiene.Finding
+
+	// stopped is set when --fail-fast sees a failure
+	stopped atomic.Bool
 }
 
 // NewRunner creates a new Runner with all registered evals.
 func NewRunner(c *client.Client, cfg RunnerConfig) *Runner {
+	return NewRunnerWithEvals(c, cfg, AllEvals())
+}
+
+// NewRunnerWithEvals creates a new eval runner over the given evals instead
+// of the full test suite.
+func NewRunnerWithEvals(c *client.Client, cfg RunnerConfig, evals []Eval) *Runner {
+	for _, e := range evals {
+		if ce, ok := e.(configurable); ok {
+			ce.configure(cfg)
+		}
+	}
 	return &Runner{
 		client: c,
 		config: cfg,
-		evals:  AllEvals(),
+		evals:  evals,
+	}
+}
+
+// preflightTimeout bounds the /models request made before a run.
+const preflightTimeout = 10 * time.Second
+
+// preflight warns if the server does not list the model under test, which
+// usually means a typo in --model. /models is an OpenAI endpoint, so other
+// APIs are not checked. A server that cannot list its models only gets a
+// warning, since the models evals report that, and the request is left out
+// of the run's stats.
+func (r *Runner) preflight() {
+	if r.client.Provider().Name() != client.ProviderOpenAI {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+
+	list, err := r.client.WithStats(nil).ListModels(ctx)
+	if err != nil {
+		fmt.Printf("%s could not check that model %q is served: %v\n\n",
+			color.YellowString("Warning:"), r.client.Model(), err)
+		return
+	}
+	if ids := list.IDs(); !slices.Contains(ids, r.client.Model()) {
+		fmt.Printf("%s model %q is not listed by /models (served: %s)\n\n",
+			color.YellowString("Warning:"), r.client.Model(), strings.Join(ids, ", "))
 	}
 }
 
 // Run executes all evals and returns results.
 func (r *Runner) Run() []Result {
+	r.preflight()
+
+	selected := make(map[string]bool, len(r.config.Evals))
+	for _, name := range r.config.Evals {
+		selected[name] = true
+	}
+
 	// Filter evals
 	var evals []Eval
 	for _, e := range r.evals {
+		// An explicit selection overrides all other filters
+		if len(selected) > 0 {
+			if selected[e.Name()] {
+				evals = append(evals, e)
+			}
+			continue
+		}
+
 		// Apply name filter
-		if r.config.Filter != "" && !strings.Contains(e.Name(), r.config.Filter) {
+		if !NameMatches(e.Name(), r.config.Filter) {
 			continue
 		}
 
@@ -157,14 +485,156 @@ func (r *Runner) Run() []Result {
 			continue
 		}
 
+		// Skip extensions of other servers
+		if !FlavorMatches(e, r.config.Flavor) {
+			continue
+		}
+
 		// Skip disabled-by-default tests unless --all is set
 		if !r.config.All && IsDefaultDisabled(e) {
 			continue
 		}
 
+		// Skip tests sending images unless --vision is set
+		if !r.config.Vision && RequiresVision(e) {
+			continue
+		}
+
+		// Report disabled tests that would have run as skipped
+		if reason, ok := r.config.Disabled.reason(e); ok {
+			if len(r.modes(e)) > 0 {
+				r.skipped = append(r.skipped, Skip{Name: e.Name(), Category: e.Category(), Reason: reason})
+			}
+			continue
+		}
+
 		evals = append(evals, e)
 	}
+	r.printSkipped()
+
+	// Shard only evals with runs left after tag selection, so that shards
+	// stay even
+	if r.config.Shard.Count > 1 {
+		evals = slices.DeleteFunc(evals, func(e Eval) bool { return len(r.modes(e)) == 0 })
+		evals = r.config.Shard.apply(evals)
+	}
+
+	// Run fundamental evals first, to completion, so the run can be
+	// aborted before the remaining evals are scheduled
+	var fundamental, rest []Eval
+	for _, e := range evals {
+		if IsFundamental(e) {
+			fundamental = append(fundamental, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+
+	results := r.runEvals(fundamental)
+	if !r.stopped.Load() && r.config.FailFastOnBasic && len(rest) > 0 {
+		for _, result := range results {
+			if !result.Passed {
+				fmt.Printf("\n%s fundamental evals failed, skipping %d remaining evals (--fail-fast-on-basic)\n",
+					color.RedString("Aborting:"), len(rest))
+				return results
+			}
+		}
+	}
+
+	if !r.stopped.Load() {
+		results = append(results, r.runEvals(rest)...)
+	}
+
+	if r.stopped.Load() {
+		skipped := -len(results)
+		for _, e := range evals {
+			skipped += len(r.modes(e))
+		}
+		fmt.Printf("\n%s first failure, skipping %d remaining evals (--fail-fast)\n",
+			color.RedString("Stopped:"), skipped)
+	}
+
+	return results
+}
+
+// Skipped returns the selected evals that were not run since they are
+// disabled, in registration order.
+func (r *Runner) Skipped() []Skip {
+	return r.skipped
+}
+
+// printSkipped lists the disabled evals with their reasons.
+func (r *Runner) printSkipped() {
+	if len(r.skipped) == 0 {
+		return
+	}
+	fmt.Println("Skipped")
+	for _, s := range r.skipped {
+		fmt.Printf("  %s %s %s\n", color.YellowString("-"), s.Name, color.YellowString("SKIPPED(%s)", s.Reason))
+	}
+}
+
+// recordFailure stops the run if fail-fast is enabled and the result is a
+// new failure. Failures loaded from a resumed run do not stop it.
+func (r *Runner) recordFailure(result Result) {
+	if r.config.FailFast && !result.Passed && !result.Resumed {
+		r.stopped.Store(true)
+	}
+}
+
+// notify passes a completed result to the OnResult callback, if any.
+func (r *Runner) notify(result Result) {
+	if r.config.OnResult != nil {
+		r.config.OnResult(result)
+	}
+}
+
+// modes returns the streaming settings an eval runs with, per the
+// configured mode and tags.
+func (r *Runner) modes(e Eval) []bool {
+	if len(r.config.Evals) > 0 || (len(r.config.Tags) == 0 && len(r.config.SkipTags) == 0) {
+		return r.configuredModes(e)
+	}
+	var modes []bool
+	for _, streaming := range r.configuredModes(e) {
+		if TagsMatch(runTags(e, streaming), r.config.Tags, r.config.SkipTags) {
+			modes = append(modes, streaming)
+		}
+	}
+	return modes
+}
+
+// configuredModes returns the streaming settings an eval runs with, per
+// the configured mode.
+func (r *Runner) configuredModes(e Eval) []bool {
+	if IsStreamingOnly(e) {
+		if r.config.Mode == ModeBlocking {
+			return nil
+		}
+		return []bool{true}
+	}
+	if IsBlockingOnly(e) {
+		if r.config.Mode == ModeStreaming {
+			return nil
+		}
+		return []bool{false}
+	}
+
+	switch r.config.Mode {
+	case ModeBlocking:
+		return []bool{false}
+	case ModeStreaming:
+		return []bool{true}
+	default:
+		return []bool{false, true}
+	}
+}
 
+// runEvals executes evals sequentially or in parallel per the configured jobs.
+func (r *Runner) runEvals(evals []Eval) []Result {
+	if len(evals) == 0 {
+		return nil
+	}
 	if r.config.Jobs <= 1 {
 		return r.runSequential(evals)
 	}
@@ -177,6 +647,11 @@ func (r *Runner) runSequential(evals []Eval) []Result {
 	currentCategory := ""
 
 	for _, e := range evals {
+		modes := r.modes(e)
+		if len(modes) == 0 {
+			continue
+		}
+
 		// Print category header
 		if e.Category() != currentCategory {
 			currentCategory = e.Category()
@@ -184,8 +659,14 @@ func (r *Runner) runSequential(evals []Eval) []Result {
 		}
 
 		// Run in configured mode(s)
-		for _, result := range r.runEvalInModes(e) {
+		for _, streaming := range modes {
+			if r.stopped.Load() {
+				return results
+			}
+			result := r.runSingleEval(e, streaming)
 			r.printResult(result)
+			r.recordFailure(result)
+			r.notify(result)
 			results = append(results, result)
 		}
 	}
@@ -212,7 +693,12 @@ func (r *Runner) runParallel(evals []Eval) []Result {
 		go func() {
 			defer wg.Done()
 			for job := range jobs {
+				// Drain jobs queued before a fail-fast stop
+				if r.stopped.Load() {
+					continue
+				}
 				result := r.runSingleEval(job.eval, job.streaming)
+				r.recordFailure(result)
 				resultChan <- result
 			}
 		}()
@@ -225,25 +711,20 @@ func (r *Runner) runParallel(evals []Eval) []Result {
 		defer resultWg.Done()
 		for result := range resultChan {
 			r.printResultParallel(result)
+			r.notify(result)
 			results = append(results, result)
 		}
 	}()
 
 	// Send jobs based on mode
-	mode := r.config.Mode
-	if mode == "" {
-		mode = ModeBoth
-	}
-
 	for _, e := range evals {
-		switch mode {
-		case ModeBlocking:
-			jobs <- evalJob{eval: e, streaming: false}
-		case ModeStreaming:
-			jobs <- evalJob{eval: e, streaming: true}
-		case ModeBoth:
-			jobs <- evalJob{eval: e, streaming: false}
-			jobs <- evalJob{eval: e, streaming: true}
+		for _, streaming := range r.modes(e) {
+			if r.stopped.Load() {
+				break
+			}
+			// Both modes of an eval may run at once, so each runs on its
+			// own copy rather than setting the mode of a shared eval
+			jobs <- evalJob{eval: isolatedEval(e, streaming), streaming: streaming}
 		}
 	}
 	close(jobs)
@@ -255,28 +736,6 @@ func (r *Runner) runParallel(evals []Eval) []Result {
 	return results
 }
 
-// runEvalInModes runs an eval in the configured mode(s) and returns results.
-func (r *Runner) runEvalInModes(e Eval) []Result {
-	mode := r.config.Mode
-	if mode == "" {
-		mode = ModeBoth
-	}
-
-	var results []Result
-
-	switch mode {
-	case ModeBlocking:
-		results = append(results, r.runSingleEval(e, false))
-	case ModeStreaming:
-		results = append(results, r.runSingleEval(e, true))
-	case ModeBoth:
-		results = append(results, r.runSingleEval(e, false))
-		results = append(results, r.runSingleEval(e, true))
-	}
-
-	return results
-}
-
 // runSingleEval executes a single eval with logging.
 func (r *Runner) runSingleEval(e Eval, streaming bool) Result {
 	// Set streaming mode if eval supports it
@@ -292,6 +751,14 @@ func (r *Runner) runSingleEval(e Eval, streaming bool) Result {
 		name += " (blocking)"
 	}
 
+	// Skip evals already completed by an interrupted run
+	if r.config.State != nil {
+		if result, ok := r.config.State.Completed(name); ok {
+			result.Resumed = true
+			return result
+		}
+	}
+
 	// Create per-eval logging context and client
 	var evalLog *evallog.EvalLog
 	evalClient := r.client
@@ -300,43 +767,291 @@ func (r *Runner) runSingleEval(e Eval, streaming bool) Result {
 		evalClient = r.client.WithLogger(evalLog)
 	}
 
+	// Capture responses to compare with the comparison server and the
+	// regression pack, and for the output scanners
+	var capture *responseCapture
+	if r.config.Compare != nil || r.config.RegressionPack != nil || len(r.config.OutputScanners) > 0 {
+		capture = &responseCapture{}
+		if evalLog != nil {
+			evalClient = r.client.WithLogger(teeLogger{evalLog, capture})
+		} else {
+			evalClient = r.client.WithLogger(capture)
+		}
+		evalClient = evalClient.WithObserver(capture)
+	}
+	var finishComparison func(Result, *responseCapture) *Comparison
+	if r.config.Compare != nil {
+		finishComparison = r.startComparison(e, streaming)
+	}
+	stats := &client.StatsRecorder{}
+	evalClient = evalClient.WithStats(stats)
+
 	start := time.Now()
-	ctx := context.Background()
-	result := e.Run(ctx, evalClient)
+	result := r.runIterations(e, evalClient, evalLog)
 	result.Duration = time.Since(start)
 	result.Name = name
 	result.Category = e.Category()
+	result.Class = e.Class()
+	result.Stats = stats.Stats()
+	result.Violations = stats.Violations()
+
+	if finishComparison != nil {
+		result.Compare = finishComparison(result, capture)
+	}
+
+	if r.config.Shrink && !result.Passed && r.config.Logger != nil {
+		repro, err := r.shrink(e, name, streaming, result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to shrink %s: %v\n", name, err)
+		}
+		result.Repro = repro
+	}
+
+	// Checked after shrinking, which cannot reproduce regressions
+	if r.config.RegressionPack != nil {
+		result = r.config.RegressionPack.check(result, capture)
+	}
+
+	if len(r.config.OutputScanners) > 0 {
+		r.scanOutputs(name, capture)
+	}
+
+	result.SLOViolations = r.config.SLOs.check(e, result)
 
 	if evalLog != nil {
-		evalLog.LogResult(result.Passed, result.Message)
+		if len(result.Scores) > 0 {
+			evalLog.LogScores(result.Scores)
+		}
+		if g := result.Grid; g != nil {
+			evalLog.LogGrid(g.RowLabel, g.ColLabel, g.Rows, g.Cols, g.Cells)
+		}
+		if len(result.Notes) > 0 {
+			evalLog.LogNotes(result.Notes)
+		}
+		if len(result.SLOViolations) > 0 {
+			evalLog.LogSLOViolations(result.SLOViolations)
+		}
+		if len(result.Samples) > 0 {
+			samples := make([]evallog.Sample, len(result.Samples))
+			for i, s := range result.Samples {
+				samples[i] = evallog.Sample(s)
+			}
+			evalLog.LogSamples(samples)
+		}
+		if len(result.Violations) > 0 {
+			violations := make([]evallog.Violation, len(result.Violations))
+			for i, v := range result.Violations {
+				violations[i] = evallog.Violation(v)
+			}
+			evalLog.LogViolations(violations)
+		}
+		diffs := result.Diffs
+		if cmp := result.Compare; cmp != nil {
+			evalLog.LogComparison(cmp.Passed, cmp.Code, cmp.Divergences)
+			diffs = append(slices.Clone(diffs), cmp.Diffs...)
+		}
+		if len(diffs) > 0 {
+			logged := make([]evallog.Diff, len(diffs))
+			for i, d := range diffs {
+				logged[i] = evallog.Diff(d)
+			}
+			evalLog.LogDiffs(logged)
+		}
+		for _, a := range result.Artifacts {
+			evalLog.LogArtifact(a.Name, a.Data)
+		}
+		if t := result.Stats.Timings; t != nil {
+			evalLog.LogTimings(evallog.ServerTimings{
+				PromptTokens:       t.PromptN,
+				PromptPerSecond:    t.PromptPerSecond,
+				PredictedTokens:    t.PredictedN,
+				PredictedPerSecond: t.PredictedPerSecond,
+			})
+		}
+		evalLog.LogResult(result.Passed, result.Code, result.Message)
 		evalLog.End()
 	}
 
+	if r.config.State != nil {
+		if err := r.config.State.Record(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record run state: %v\n", err)
+		}
+	}
+
 	return result
 }
 
+// runIterations runs an eval the configured number of times. A single run
+// returns the eval's result unchanged; repeated runs are aggregated against
+// the pass threshold.
+func (r *Runner) runIterations(e Eval, c *client.Client, evalLog *evallog.EvalLog) Result {
+	ctx := context.Background()
+	repeat := r.config.Repeat
+	if repeat <= 1 {
+		return e.Run(ctx, c)
+	}
+
+	iterations := make([]Iteration, 0, repeat)
+	var scores map[string]float64
+	var grid *Grid
+	var notes []string
+	var samples []Sample
+	var diffs []Diff
+	var artifacts []Artifact
+	for i := range repeat {
+		if evalLog != nil {
+			evalLog.StartIteration(i+1, repeat)
+		}
+		start := time.Now()
+		res := e.Run(ctx, c)
+		it := Iteration{
+			Passed:   res.Passed,
+			Code:     res.Code,
+			Message:  res.Message,
+			Duration: time.Since(start),
+		}
+		if evalLog != nil {
+			evalLog.LogIteration(it.Passed, it.Code, it.Message)
+		}
+		iterations = append(iterations, it)
+		scores = res.Scores
+		grid = res.Grid
+		notes = res.Notes
+		samples = res.Samples
+		diffs = res.Diffs
+		artifacts = res.Artifacts
+	}
+
+	// Scores come from the last iteration, like the logged conversation
+	result := aggregateIterations(iterations, r.config.PassThreshold)
+	result.Scores = scores
+	result.Grid = grid
+	result.Notes = notes
+	result.Samples = samples
+	result.Diffs = diffs
+	result.Artifacts = artifacts
+	return result
+}
+
+// iterationMarker returns a suffix showing the pass count of repeated evals.
+func iterationMarker(result Result) string {
+	if len(result.Iterations) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%d/%d]", PassedIterations(result.Iterations), len(result.Iterations))
+}
+
+// passMark returns the check mark of a passed result, yellow if the eval
+// exceeded its latency objectives.
+func passMark(result Result) string {
+	if len(result.SLOViolations) > 0 {
+		return color.YellowString("✓")
+	}
+	return color.GreenString("✓")
+}
+
+// resumedMarker returns a suffix marking results loaded from a previous run.
+func resumedMarker(result Result) string {
+	if result.Resumed {
+		return color.HiBlackString(" [resumed]")
+	}
+	return ""
+}
+
 // printResult prints a result in sequential mode (indented under category).
 func (r *Runner) printResult(result Result) {
 	if result.Passed {
-		fmt.Printf("  %s %s (%dms)\n", color.GreenString("✓"), result.Name, result.Duration.Milliseconds())
+		fmt.Printf("  %s %s (%dms)%s%s\n", passMark(result), result.Name, result.Duration.Milliseconds(), iterationMarker(result), resumedMarker(result))
 	} else {
-		fmt.Printf("  %s %s - %s\n", color.RedString("✗"), result.Name, result.Message)
+		fmt.Printf("  %s %s - %s%s\n", color.RedString("✗"), result.Name, result.Message, resumedMarker(result))
 		if r.config.Verbose && r.config.Logger != nil {
 			fmt.Printf("    See log: %s/%s.log\n", r.config.Logger.Dir(), result.Name)
 		}
 	}
+	printRepro(result)
+	printNotes(result)
+	printSLOViolations(result)
+	printViolations(result)
+	printDivergences(result)
+	printDiffs(result)
 }
 
 // printResultParallel prints a result in parallel mode (with category prefix).
 func (r *Runner) printResultParallel(result Result) {
 	if result.Passed {
-		fmt.Printf("%s %s (%dms) [%s]\n", color.GreenString("✓"), result.Name, result.Duration.Milliseconds(), result.Category)
+		fmt.Printf("%s %s (%dms)%s [%s]%s\n", passMark(result), result.Name, result.Duration.Milliseconds(), iterationMarker(result), result.Category, resumedMarker(result))
 	} else {
-		fmt.Printf("%s %s - %s [%s]\n", color.RedString("✗"), result.Name, result.Message, result.Category)
+		fmt.Printf("%s %s - %s [%s]%s\n", color.RedString("✗"), result.Name, result.Message, result.Category, resumedMarker(result))
 		if r.config.Verbose && r.config.Logger != nil {
 			fmt.Printf("    See log: %s/%s.log\n", r.config.Logger.Dir(), result.Name)
 		}
 	}
+	printRepro(result)
+	printNotes(result)
+	printSLOViolations(result)
+	printViolations(result)
+	printDivergences(result)
+	printDiffs(result)
+}
+
+// printViolations prints the stream violations of a result below it.
+func printViolations(result Result) {
+	for _, v := range result.Violations {
+		fmt.Printf("    %s line %d: %s\n", color.YellowString("stream violation:"), v.Line, v.Message)
+	}
+}
+
+// printRepro prints the minimized failing request of a result below it.
+func printRepro(result Result) {
+	if rp := result.Repro; rp != nil {
+		fmt.Printf("    %s %s (%d/%d messages, %d/%d tools, %d runs)\n", color.CyanString("repro:"),
+			rp.Path, rp.Messages, rp.TotalMessages, rp.Tools, rp.TotalTools, rp.Runs)
+	}
+}
+
+// printDivergences prints how the comparison server's outcome of a result
+// diverged, below it.
+func printDivergences(result Result) {
+	if cmp := result.Compare; cmp != nil {
+		for _, d := range cmp.Divergences {
+			fmt.Printf("    %s %s\n", color.MagentaString("diverged:"), d)
+		}
+	}
+}
+
+// diffContext is the number of unchanged words kept on each side of a
+// change in diffs printed to the console.
+const diffContext = 5
+
+// printDiffs prints the diffs of a failed result, and of its outcome on the
+// comparison server, below it.
+func printDiffs(result Result) {
+	diffs := result.Diffs
+	if result.Passed {
+		diffs = nil
+	}
+	if cmp := result.Compare; cmp != nil {
+		diffs = append(slices.Clone(diffs), cmp.Diffs...)
+	}
+	for _, d := range diffs {
+		spans := textdiff.Compact(textdiff.Words(d.A, d.B), diffContext)
+		fmt.Printf("    %s %s\n", color.CyanString("diff (%s → %s):", d.ALabel, d.BLabel), textdiff.Console(spans))
+	}
+}
+
+// printNotes prints the compatibility notes of a result below it.
+func printNotes(result Result) {
+	for _, note := range result.Notes {
+		fmt.Printf("    %s %s\n", color.YellowString("note:"), note)
+	}
+}
+
+// printSLOViolations prints how a result exceeded its latency objectives
+// below it.
+func printSLOViolations(result Result) {
+	for _, v := range result.SLOViolations {
+		fmt.Printf("    %s %s\n", color.YellowString("slo violation:"), v)
+	}
 }
 
 // AllEvals returns all registered evals.
@@ -355,6 +1070,54 @@ func AllEvals() []Eval {
 	// Schema evals
 	evals = append(evals, schemaEvals()...)
 
+	// Models endpoint evals
+	evals = append(evals, modelsEvals()...)
+
+	// Finish reason evals
+	evals = append(evals, finishReasonEvals()...)
+
+	// Long context evals
+	evals = append(evals, longContextEvals()...)
+
+	// Concurrency evals
+	evals = append(evals, concurrencyEvals()...)
+
+	// Error handling evals
+	evals = append(evals, errorHandlingEvals()...)
+
+	// SSE wire format evals
+	evals = append(evals, sseEvals()...)
+
+	// Usage accounting evals
+	evals = append(evals, usageEvals()...)
+
+	// Logprobs evals
+	evals = append(evals, logprobsEvals()...)
+
+	// Sampling parameter evals
+	evals = append(evals, samplingEvals()...)
+
+	// Text completions evals
+	evals = append(evals, completionsEvals()...)
+
+	// Chat template handling evals
+	evals = append(evals, templateEvals()...)
+
+	// llama.cpp extension evals
+	evals = append(evals, llamaCppEvals()...)
+
+	// vLLM extension evals
+	evals = append(evals, vllmEvals()...)
+
+	// Gateway and TGI compatibility evals
+	evals = append(evals, compatEvals()...)
+
+	// Determinism evals
+	evals = append(evals, determinismEvals()...)
+
+	// Vision evals (--vision)
+	evals = append(evals, visionEvals()...)
+
 	// Agentic evals (multi-turn with interleaved reasoning)
 	evals = append(evals, agenticEvals()...)
 
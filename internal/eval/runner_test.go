@@ -0,0 +1,60 @@
+package eval
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// modeEval passes if it runs in the mode its result is named for. Both
+// modes wait for each other before reading the mode, so a mode set on a
+// shared eval by the other job is seen.
+type modeEval struct {
+	streaming bool
+	started   *sync.WaitGroup
+}
+
+func (e *modeEval) Name() string                { return "mode_probe" }
+func (e *modeEval) Category() string            { return compatCategory }
+func (e *modeEval) Class() string               { return ClassStandard }
+func (e *modeEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *modeEval) Streaming() bool             { return e.streaming }
+
+func (e *modeEval) Run(ctx context.Context, c *client.Client) Result {
+	e.started.Done()
+	waited := make(chan struct{})
+	go func() {
+		e.started.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(5 * time.Second):
+	}
+	return Result{Name: e.Name(), Category: e.Category(), Passed: true, Message: strconv.FormatBool(e.streaming)}
+}
+
+func TestRunParallelModesIsolated(t *testing.T) {
+	started := &sync.WaitGroup{}
+	started.Add(2)
+	e := &modeEval{started: started}
+
+	c := client.New(client.Config{BaseURL: "http://127.0.0.1:1", Model: "m", Provider: client.OpenAI{}})
+	r := NewRunnerWithEvals(c, RunnerConfig{Jobs: 2, Mode: ModeBoth}, []Eval{e})
+	results := r.Run()
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for _, res := range results {
+		want := strconv.FormatBool(strings.HasSuffix(res.Name, "(streaming)"))
+		if res.Message != want {
+			t.Errorf("%s ran with streaming %s, want %s", res.Name, res.Message, want)
+		}
+	}
+}
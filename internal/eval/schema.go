@@ -3,19 +3,27 @@ package eval
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"reflect"
+	"slices"
 
 	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/jsonschema"
 )
 
 const schemaCategory = "Structured Output"
 
 // schemaEvals returns all JSON schema-related evals.
 func schemaEvals() []Eval {
-	return []Eval{
+	evals := []Eval{
 		&jsonSchemaEval{},
+		&jsonSchemaMultiTurnEval{},
+		&jsonSchemaFuzzEval{},
+		&jsonSchemaCompileTimeEval{},
+		&strictSchemaEval{strict: true},
+		&strictSchemaEval{strict: false},
 	}
+	return append(evals, schemaMatrixEvals()...)
 }
 
 // jsonSchemaEval verifies that structured output matches the requested schema.
@@ -39,30 +47,11 @@ func (e *jsonSchemaEval) Class() string {
 }
 
 func (e *jsonSchemaEval) Run(ctx context.Context, c *client.Client) Result {
-	// Define a simple schema for a person
-	schema := json.RawMessage(`{
-		"type": "object",
-		"properties": {
-			"name": {"type": "string"},
-			"age": {"type": "integer"},
-			"occupation": {"type": "string"}
-		},
-		"required": ["name", "age", "occupation"],
-		"additionalProperties": false
-	}`)
-
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
 			{Role: "user", Content: "Generate a fictional person with a name, age, and occupation."},
 		},
-		ResponseFormat: &client.ResponseFormat{
-			Type: "json_schema",
-			JSONSchema: &client.JSONSchema{
-				Name:   "person",
-				Schema: schema,
-				Strict: true,
-			},
-		},
+		ResponseFormat: jsonSchemaFormat("person", personSchema),
 	}
 
 	var content string
@@ -74,6 +63,7 @@ func (e *jsonSchemaEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -85,6 +75,7 @@ func (e *jsonSchemaEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -93,6 +84,7 @@ func (e *jsonSchemaEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -106,16 +98,18 @@ func (e *jsonSchemaEval) Run(ctx context.Context, c *client.Client) Result {
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeSchemaInvalidJSON,
 			Message:  "response is not valid JSON: " + err.Error(),
 		}
 	}
 
 	// Validate against schema
-	if err := validatePersonSchema(parsed); err != nil {
+	if err := validateSchema(personValidator, parsed); err != nil {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     err.code,
 			Message:  err.Error(),
 		}
 	}
@@ -127,55 +121,227 @@ func (e *jsonSchemaEval) Run(ctx context.Context, c *client.Client) Result {
 	}
 }
 
-// validatePersonSchema validates the response against the expected person schema.
-// This is a simple validation - for production use, consider a JSON Schema library.
-func validatePersonSchema(data map[string]any) error {
-	requiredFields := []string{"name", "age", "occupation"}
+// jsonSchemaMultiTurnEval verifies that structured output constraints do not
+// leak between requests. Turn 1 requests a person under personSchema; turn
+// 2 feeds that JSON back and requests an update under careerChangeSchema. A
+// server that reuses the grammar state of the first request returns another
+// person.
+type jsonSchemaMultiTurnEval struct {
+	streaming bool
+}
 
-	for _, field := range requiredFields {
-		if _, ok := data[field]; !ok {
-			return fmt.Errorf("missing required field: %s", field)
-		}
+func (e *jsonSchemaMultiTurnEval) Name() string {
+	return "json_schema_multi_turn"
+}
+
+func (e *jsonSchemaMultiTurnEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *jsonSchemaMultiTurnEval) Streaming() bool             { return e.streaming }
+
+func (e *jsonSchemaMultiTurnEval) Category() string {
+	return schemaCategory
+}
+
+func (e *jsonSchemaMultiTurnEval) Class() string {
+	return ClassStandard
+}
+
+func (e *jsonSchemaMultiTurnEval) Tags() []string {
+	return []string{TagMultiTurn}
+}
+
+func (e *jsonSchemaMultiTurnEval) Run(ctx context.Context, c *client.Client) Result {
+	messages := []client.Message{
+		{Role: "user", Content: "Generate a fictional person with a name, age, and occupation."},
 	}
 
-	// Check types
-	if name, ok := data["name"]; ok {
-		if reflect.TypeOf(name).Kind() != reflect.String {
-			return fmt.Errorf("'name' must be a string, got %T", name)
-		}
+	// Turn 1: a person
+	first, failed := completionContent(ctx, c, e, e.streaming, client.ChatCompletionRequest{
+		Messages:       messages,
+		ResponseFormat: jsonSchemaFormat("person", personSchema),
+	})
+	if failed != nil {
+		failed.Message = "turn 1: " + failed.Message
+		return *failed
+	}
+	if failed := e.validate(first, personValidator); failed != nil {
+		failed.Message = "turn 1: " + failed.Message
+		return *failed
 	}
 
-	if age, ok := data["age"]; ok {
-		// JSON numbers are float64 in Go
-		switch v := age.(type) {
-		case float64:
-			if v != float64(int(v)) {
-				return fmt.Errorf("'age' must be an integer, got float")
-			}
-		case int:
-			// OK
-		default:
-			return fmt.Errorf("'age' must be an integer, got %T", age)
-		}
+	// Turn 2: an update of that person under a different schema
+	messages = append(messages,
+		client.Message{Role: "assistant", Content: first},
+		client.Message{Role: "user", Content: "This person has just changed careers. Give their name, their new occupation, and how many years they spent in their previous one."},
+	)
+	second, failed := completionContent(ctx, c, e, e.streaming, client.ChatCompletionRequest{
+		Messages:       messages,
+		ResponseFormat: jsonSchemaFormat("career_change", careerChangeSchema),
+	})
+	if failed != nil {
+		failed.Message = "turn 2: " + failed.Message
+		return *failed
+	}
+	if failed := e.validate(second, careerChangeValidator); failed != nil {
+		failed.Message = "turn 2: " + failed.Message
+		return *failed
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
 	}
+}
 
-	if occupation, ok := data["occupation"]; ok {
-		if reflect.TypeOf(occupation).Kind() != reflect.String {
-			return fmt.Errorf("'occupation' must be a string, got %T", occupation)
+// validate checks content against the requested schema. A property of the
+// turn 1 schema in place of the requested ones means the first request's
+// constraints leaked.
+func (e *jsonSchemaMultiTurnEval) validate(content string, schema *jsonschema.Schema) *Result {
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeSchemaInvalidJSON,
+			Message:  "response is not valid JSON: " + err.Error(),
 		}
 	}
 
-	// Check for additional properties
-	allowedFields := map[string]bool{
-		"name":       true,
-		"age":        true,
-		"occupation": true,
+	err := validateSchema(schema, parsed)
+	if err == nil {
+		return nil
 	}
-	for key := range data {
-		if !allowedFields[key] {
-			return fmt.Errorf("unexpected additional property: %s", key)
+
+	if err.code == CodeSchemaExtraProp || err.code == CodeSchemaMissingField {
+		requested := schema.Properties()
+		for _, name := range personValidator.Properties() {
+			if slices.Contains(requested, name) {
+				continue
+			}
+			if _, ok := parsed[name]; ok {
+				return &Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeSchemaStateLeaked,
+					Message:  fmt.Sprintf("output has property %q of the previous request's schema: %s", name, content),
+				}
+			}
 		}
 	}
 
-	return nil
+	return &Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   false,
+		Code:     err.code,
+		Message:  err.Error(),
+	}
+}
+
+// jsonSchemaFormat returns a strict json_schema response format.
+func jsonSchemaFormat(name string, schema json.RawMessage) *client.ResponseFormat {
+	strict := true
+	return &client.ResponseFormat{
+		Type: "json_schema",
+		JSONSchema: &client.JSONSchema{
+			Name:   name,
+			Schema: schema,
+			Strict: &strict,
+		},
+	}
+}
+
+// personSchema is a simple schema for a person.
+var personSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer"},
+		"occupation": {"type": "string"}
+	},
+	"required": ["name", "age", "occupation"],
+	"additionalProperties": false
+}`)
+
+// careerChangeSchema is the schema of the update requested in the second
+// turn of json_schema_multi_turn. It shares only name with personSchema.
+var careerChangeSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"new_occupation": {"type": "string"},
+		"years_in_previous": {"type": "integer"}
+	},
+	"required": ["name", "new_occupation", "years_in_previous"],
+	"additionalProperties": false
+}`)
+
+var (
+	personValidator       = jsonschema.MustCompile(personSchema)
+	careerChangeValidator = jsonschema.MustCompile(careerChangeSchema)
+)
+
+// schemaError is a schema validation failure along with its failure code.
+type schemaError struct {
+	code string
+	msg  string
+}
+
+func (e *schemaError) Error() string { return e.msg }
+
+// schemaCodes are the failure codes of schema violations, by the keyword
+// that failed.
+type schemaCodes struct {
+	missing   string // required
+	wrongType string // type
+	extraProp string // additionalProperties
+	invalid   string // any other keyword, e.g. enum or minimum
+}
+
+// outputSchemaCodes are the failure codes of structured output.
+var outputSchemaCodes = schemaCodes{
+	missing:   CodeSchemaMissingField,
+	wrongType: CodeSchemaWrongType,
+	extraProp: CodeSchemaExtraProp,
+	invalid:   CodeSchemaValueInvalid,
+}
+
+// toolArgsSchemaCodes are the failure codes of tool call arguments.
+var toolArgsSchemaCodes = schemaCodes{
+	missing:   CodeToolCallArgsMissing,
+	wrongType: CodeToolCallArgsType,
+	extraProp: CodeToolCallArgsExtraProp,
+	invalid:   CodeToolCallArgsValue,
+}
+
+// validateSchema validates decoded structured output against a schema.
+func validateSchema(schema *jsonschema.Schema, value any) *schemaError {
+	return checkSchema(schema, value, outputSchemaCodes)
+}
+
+// validateToolArgs validates decoded tool call arguments against the
+// parameters schema of their tool.
+func validateToolArgs(schema *jsonschema.Schema, args any) *schemaError {
+	return checkSchema(schema, args, toolArgsSchemaCodes)
+}
+
+// checkSchema validates a decoded JSON value against a schema, with the
+// failure code of the keyword that failed.
+func checkSchema(schema *jsonschema.Schema, value any, codes schemaCodes) *schemaError {
+	var verr *jsonschema.ValidationError
+	if !errors.As(schema.Validate(value), &verr) {
+		return nil
+	}
+	code := codes.invalid
+	switch verr.Keyword {
+	case "required", "dependentRequired":
+		code = codes.missing
+	case "type":
+		code = codes.wrongType
+	case "additionalProperties":
+		code = codes.extraProp
+	}
+	return &schemaError{code, verr.Error()}
 }
@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/jsonschema"
+)
+
+// schemaFuzzTemperature samples diverse outputs, so that more paths through
+// the grammar are exercised.
+const schemaFuzzTemperature = 1.0
+
+// schemaFuzzSchema exercises the grammar features most prone to bugs:
+// nested objects, bounded arrays, enums, and numbers alongside free text.
+var schemaFuzzSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer"},
+		"title": {"type": "string"},
+		"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 4},
+		"status": {"type": "string", "enum": ["draft", "published", "archived"]},
+		"rating": {"type": "number"},
+		"author": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"email": {"type": "string"}
+			},
+			"required": ["name", "email"],
+			"additionalProperties": false
+		},
+		"notes": {"type": "string"}
+	},
+	"required": ["id", "title", "tags", "status", "rating", "author", "notes"],
+	"additionalProperties": false
+}`)
+
+var schemaFuzzValidator = jsonschema.MustCompile(schemaFuzzSchema)
+
+// schemaFuzzPrompts ask for text that is hard to encode inside JSON
+// strings, and for values at the edges of their types, so that grammar
+// bugs at escapes and token boundaries surface.
+var schemaFuzzPrompts = []string{
+	"Describe a blog post whose title contains \"double quotes\" and an apostrophe.",
+	"Describe a blog post about Windows paths; put C:\\Users\\admin\\Documents in the notes.",
+	"Describe a blog post with notes spanning three separate lines.",
+	"Describe a blog post written in Japanese with an emoji in the title.",
+	"Describe a poorly received blog post with a negative rating.",
+	"Describe a blog post whose notes contain a JSON snippet like {\"key\": [1, 2]}.",
+	"Describe a blog post whose tags contain commas, colons, and braces.",
+	"Describe a blog post about tabs and backslashes: notes should contain \\t and \\n literally.",
+	"Describe a blog post with a very large id and a rating with many decimal places.",
+	"Describe a blog post whose author has an accented name like José Müller.",
+	"Describe an archived blog post with exactly four tags.",
+	"Describe a blog post whose title is a single word and whose notes are long, at least 60 words.",
+}
+
+// jsonSchemaFuzzEval requests schema-constrained output once for each of
+// many varied prompts and validates every response, since grammar bugs
+// that corrupt a few percent of outputs, often at chunk or token
+// boundaries of escaped text, slip past single-shot checks. It records the
+// compliance rate and every invalid sample.
+type jsonSchemaFuzzEval struct {
+	streaming bool
+}
+
+func (e *jsonSchemaFuzzEval) Name() string {
+	return "json_schema_fuzz"
+}
+
+func (e *jsonSchemaFuzzEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *jsonSchemaFuzzEval) Streaming() bool             { return e.streaming }
+
+func (e *jsonSchemaFuzzEval) Category() string {
+	return schemaCategory
+}
+
+func (e *jsonSchemaFuzzEval) Class() string {
+	return ClassStandard
+}
+
+func (e *jsonSchemaFuzzEval) Tags() []string {
+	return []string{TagSlow}
+}
+
+func (e *jsonSchemaFuzzEval) Run(ctx context.Context, c *client.Client) Result {
+	temperature := schemaFuzzTemperature
+	var invalid []Sample
+	for _, prompt := range schemaFuzzPrompts {
+		req := client.ChatCompletionRequest{
+			Messages: []client.Message{
+				{Role: "user", Content: prompt},
+			},
+			ResponseFormat: jsonSchemaFormat("blog_post", schemaFuzzSchema),
+			Temperature:    &temperature,
+		}
+
+		var content string
+		if e.streaming {
+			result, err := c.ChatCompletionStream(ctx, req)
+			if err != nil {
+				return Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeRequestFailed,
+					Message:  "request failed: " + err.Error(),
+				}
+			}
+			content = result.Content
+		} else {
+			resp, err := c.ChatCompletion(ctx, req)
+			if err != nil {
+				return Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeRequestFailed,
+					Message:  "request failed: " + err.Error(),
+				}
+			}
+			if len(resp.Choices) == 0 {
+				return Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeNoChoices,
+					Message:  "no choices in response",
+				}
+			}
+			content = resp.Choices[0].Message.Content
+		}
+
+		var value any
+		if err := json.Unmarshal([]byte(content), &value); err != nil {
+			invalid = append(invalid, Sample{
+				Prompt:  prompt,
+				Code:    CodeSchemaInvalidJSON,
+				Message: "response is not valid JSON: " + err.Error(),
+				Output:  content,
+			})
+			continue
+		}
+		if err := validateSchema(schemaFuzzValidator, value); err != nil {
+			invalid = append(invalid, Sample{
+				Prompt:  prompt,
+				Code:    err.code,
+				Message: err.Error(),
+				Output:  content,
+			})
+		}
+	}
+
+	total := len(schemaFuzzPrompts)
+	valid := total - len(invalid)
+	scores := map[string]float64{
+		"compliance_rate": float64(valid) / float64(total),
+		"samples":         float64(total),
+	}
+
+	if len(invalid) > 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeSchemaNoncompliant,
+			Message: fmt.Sprintf("%d/%d responses matched the schema; first invalid: %s: %s",
+				valid, total, invalid[0].Code, invalid[0].Message),
+			Scores:  scores,
+			Samples: invalid,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("all %d responses matched the schema", total),
+		Scores:   scores,
+	}
+}
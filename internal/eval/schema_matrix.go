@@ -0,0 +1,298 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/jsonschema"
+)
+
+// schemaMatrixCase is a structured output eval of one family of schema
+// features, each of which a server must translate into its grammar.
+type schemaMatrixCase struct {
+	name   string
+	prompt string
+	schema json.RawMessage
+	// check, if set, returns why a response valid under the schema is
+	// still wrong, or "".
+	check func(value map[string]any) string
+}
+
+// schemaMatrixCases cover the schema features that grammar converters
+// handle separately, so that a gap in one shows up as its own failure.
+var schemaMatrixCases = []schemaMatrixCase{
+	{
+		name:   "json_schema_enum",
+		prompt: "Triage this bug report: \"The checkout page crashes for every user when paying by card; we are losing sales.\" Give its severity, component, status, and priority.",
+		// Values sharing prefixes ("in", "in_progress") trip up converters
+		// that build enum alternatives without lookahead
+		schema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"severity": {"type": "string", "enum": ["low", "medium", "high", "critical"]},
+		"component": {"enum": ["frontend", "backend", "payments", "database"]},
+		"status": {"type": "string", "enum": ["in", "in_progress", "in_review", "done"]},
+		"priority": {"type": "integer", "enum": [1, 2, 3]},
+		"regression": {"enum": [true, false, null]}
+	},
+	"required": ["severity", "component", "status", "priority", "regression"],
+	"additionalProperties": false
+}`),
+	},
+	{
+		name:   "json_schema_array_of_objects",
+		prompt: "Write an invoice for a bakery order of 2 loaves of sourdough, 12 croissants, and 1 birthday cake, with a line item for each.",
+		schema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"invoice_number": {"type": "string"},
+		"line_items": {
+			"type": "array",
+			"minItems": 3,
+			"maxItems": 5,
+			"items": {
+				"type": "object",
+				"properties": {
+					"description": {"type": "string"},
+					"quantity": {"type": "integer", "minimum": 1},
+					"unit_price": {"type": "number", "minimum": 0}
+				},
+				"required": ["description", "quantity", "unit_price"],
+				"additionalProperties": false
+			}
+		},
+		"total": {"type": "number", "minimum": 0}
+	},
+	"required": ["invoice_number", "line_items", "total"],
+	"additionalProperties": false
+}`),
+		check: func(value map[string]any) string {
+			if items, _ := value["line_items"].([]any); len(items) != 3 {
+				return fmt.Sprintf("expected 3 line items, got %d", len(items))
+			}
+			return ""
+		},
+	},
+	{
+		name:   "json_schema_nested",
+		prompt: "Describe the engineering department of a fictional company: its platform team and that team's lead, with the lead's contact details.",
+		schema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"company": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"department": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"team": {
+							"type": "object",
+							"properties": {
+								"name": {"type": "string"},
+								"size": {"type": "integer", "minimum": 1},
+								"lead": {
+									"type": "object",
+									"properties": {
+										"name": {"type": "string"},
+										"email": {"type": "string"},
+										"remote": {"type": "boolean"}
+									},
+									"required": ["name", "email", "remote"],
+									"additionalProperties": false
+								}
+							},
+							"required": ["name", "size", "lead"],
+							"additionalProperties": false
+						}
+					},
+					"required": ["name", "team"],
+					"additionalProperties": false
+				}
+			},
+			"required": ["name", "department"],
+			"additionalProperties": false
+		}
+	},
+	"required": ["company"],
+	"additionalProperties": false
+}`),
+	},
+	{
+		name:   "json_schema_any_of",
+		prompt: "Record a payment of 250.00 EUR made by bank transfer from the account with IBAN DE89370400440532013000.",
+		schema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"amount": {"type": "number"},
+		"currency": {"type": "string", "enum": ["USD", "EUR", "GBP"]},
+		"method": {
+			"anyOf": [
+				{
+					"type": "object",
+					"properties": {
+						"type": {"const": "card"},
+						"last4": {"type": "string", "pattern": "^[0-9]{4}$"}
+					},
+					"required": ["type", "last4"],
+					"additionalProperties": false
+				},
+				{
+					"type": "object",
+					"properties": {
+						"type": {"const": "bank_transfer"},
+						"iban": {"type": "string"}
+					},
+					"required": ["type", "iban"],
+					"additionalProperties": false
+				},
+				{"type": "null"}
+			]
+		},
+		"reference": {"anyOf": [{"type": "string"}, {"type": "integer"}]}
+	},
+	"required": ["amount", "currency", "method", "reference"],
+	"additionalProperties": false
+}`),
+		// A converter that always takes the first alternative produces a
+		// card payment
+		check: func(value map[string]any) string {
+			method, _ := value["method"].(map[string]any)
+			if method["type"] != "bank_transfer" {
+				got, _ := json.Marshal(value["method"])
+				return "expected the bank_transfer alternative of method, got " + string(got)
+			}
+			return ""
+		},
+	},
+	{
+		name:   "json_schema_string_formats",
+		prompt: "Create a fictional server login audit record.",
+		schema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"event_id": {"type": "string", "format": "uuid"},
+		"user_email": {"type": "string", "format": "email"},
+		"login_date": {"type": "string", "format": "date"},
+		"login_time": {"type": "string", "format": "time"},
+		"recorded_at": {"type": "string", "format": "date-time"},
+		"source_ip": {"type": "string", "format": "ipv4"}
+	},
+	"required": ["event_id", "user_email", "login_date", "login_time", "recorded_at", "source_ip"],
+	"additionalProperties": false
+}`),
+	},
+	{
+		name:   "json_schema_numeric_ranges",
+		prompt: "Suggest thermostat settings for a bedroom at night in winter.",
+		schema: json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"temperature_c": {"type": "number", "minimum": 10, "maximum": 30},
+		"humidity_pct": {"type": "integer", "minimum": 30, "maximum": 60},
+		"setback_c": {"type": "number", "exclusiveMinimum": -5, "exclusiveMaximum": 0},
+		"fan_level": {"type": "integer", "minimum": 0, "maximum": 5},
+		"schedule_step_minutes": {"type": "integer", "minimum": 15, "maximum": 120, "multipleOf": 15}
+	},
+	"required": ["temperature_c", "humidity_pct", "setback_c", "fan_level", "schedule_step_minutes"],
+	"additionalProperties": false
+}`),
+	},
+}
+
+// schemaMatrixValidators are the compiled schemas of schemaMatrixCases, by
+// eval name.
+var schemaMatrixValidators = func() map[string]*jsonschema.Schema {
+	validators := make(map[string]*jsonschema.Schema, len(schemaMatrixCases))
+	for _, tc := range schemaMatrixCases {
+		validators[tc.name] = jsonschema.MustCompile(tc.schema)
+	}
+	return validators
+}()
+
+// schemaMatrixEvals returns an eval of each schema matrix case.
+func schemaMatrixEvals() []Eval {
+	evals := make([]Eval, len(schemaMatrixCases))
+	for i := range schemaMatrixCases {
+		evals[i] = &schemaMatrixEval{schemaCase: &schemaMatrixCases[i]}
+	}
+	return evals
+}
+
+// schemaMatrixEval verifies that structured output matches the schema of
+// its case.
+type schemaMatrixEval struct {
+	schemaCase *schemaMatrixCase
+	streaming  bool
+}
+
+func (e *schemaMatrixEval) Name() string {
+	return e.schemaCase.name
+}
+
+func (e *schemaMatrixEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *schemaMatrixEval) Streaming() bool             { return e.streaming }
+
+func (e *schemaMatrixEval) Category() string {
+	return schemaCategory
+}
+
+func (e *schemaMatrixEval) Class() string {
+	return ClassStandard
+}
+
+func (e *schemaMatrixEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: e.schemaCase.prompt},
+		},
+		ResponseFormat: jsonSchemaFormat(e.schemaCase.name, e.schemaCase.schema),
+	}
+
+	content, failed := completionContent(ctx, c, e, e.streaming, req)
+	if failed != nil {
+		return *failed
+	}
+
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeSchemaInvalidJSON,
+			Message:  "response is not valid JSON: " + err.Error(),
+		}
+	}
+
+	if err := validateSchema(schemaMatrixValidators[e.schemaCase.name], parsed); err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     err.code,
+			Message:  err.Error(),
+		}
+	}
+
+	if e.schemaCase.check != nil {
+		if msg := e.schemaCase.check(parsed); msg != "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeSchemaValueInvalid,
+				Message:  msg,
+			}
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
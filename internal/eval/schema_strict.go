@@ -0,0 +1,107 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/jsonschema"
+)
+
+// movieSchema is the schema of the strict and non-strict evals. The prompt
+// asks for more fields than it declares, and invites leaving one out.
+var movieSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"title": {"type": "string"},
+		"year": {"type": "integer"},
+		"director": {"type": "string"}
+	},
+	"required": ["title", "year", "director"],
+	"additionalProperties": false
+}`)
+
+var movieValidator = jsonschema.MustCompile(movieSchema)
+
+// strictSchemaPrompt pulls the model away from movieSchema, so that only a
+// server enforcing the schema returns output matching it.
+const strictSchemaPrompt = "Describe the movie Inception as JSON with its title, year, director, genre, and main cast. Leave out the director if you are not sure who it is."
+
+// strictSchemaEval compares structured output with strict true and false.
+// With strict: true the server must hold the output to the schema, without
+// the additional properties the prompt asks for and with every required
+// field. With strict: false the schema is only guidance, so the output need
+// only be valid JSON; where it departs from the schema is noted.
+type strictSchemaEval struct {
+	strict    bool
+	streaming bool
+}
+
+func (e *strictSchemaEval) Name() string {
+	if e.strict {
+		return "json_schema_strict"
+	}
+	return "json_schema_non_strict"
+}
+
+func (e *strictSchemaEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *strictSchemaEval) Streaming() bool             { return e.streaming }
+
+func (e *strictSchemaEval) Category() string {
+	return schemaCategory
+}
+
+func (e *strictSchemaEval) Class() string {
+	return ClassStandard
+}
+
+func (e *strictSchemaEval) Run(ctx context.Context, c *client.Client) Result {
+	format := jsonSchemaFormat("movie", movieSchema)
+	format.JSONSchema.Strict = &e.strict
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: strictSchemaPrompt},
+		},
+		ResponseFormat: format,
+	}
+
+	content, failed := completionContent(ctx, c, e, e.streaming, req)
+	if failed != nil {
+		return *failed
+	}
+
+	var parsed any
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeSchemaInvalidJSON,
+			Message:  "response is not valid JSON: " + err.Error(),
+		}
+	}
+
+	err := validateSchema(movieValidator, parsed)
+	if err == nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+		}
+	}
+	if e.strict {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     err.code,
+			Message:  "strict output does not match the schema: " + err.Error(),
+		}
+	}
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Notes:    []string{"non-strict output departs from the schema: " + err.Error()},
+	}
+}
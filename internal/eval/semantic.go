@@ -0,0 +1,54 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/textmetrics"
+)
+
+// semanticSimilarity embeds candidate and reference and returns their cosine
+// similarity. The caller must check c.CanEmbed first.
+func semanticSimilarity(ctx context.Context, c *client.Client, candidate, reference string) (float64, error) {
+	vectors, err := c.Embeddings(ctx, []string{candidate, reference})
+	if err != nil {
+		return 0, fmt.Errorf("embed: %w", err)
+	}
+	return textmetrics.Cosine(vectors[0], vectors[1]), nil
+}
+
+// checkSemantic asserts that candidate is semantically similar to reference,
+// recording the similarity in scores under name. It returns a failing Result
+// if the embedding request fails or the similarity is below threshold, and
+// nil if the check passes or no embedding endpoint is configured.
+func checkSemantic(ctx context.Context, c *client.Client, e Eval, scores map[string]float64, name, candidate, reference string, threshold float64) *Result {
+	if !c.CanEmbed() {
+		return nil
+	}
+
+	similarity, err := semanticSimilarity(ctx, c, candidate, reference)
+	if err != nil {
+		return &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "embedding request failed: " + err.Error(),
+			Scores:   scores,
+		}
+	}
+
+	scores[name] = similarity
+	if similarity < threshold {
+		return &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentNotSimilar,
+			Message:  fmt.Sprintf("%s %.3f below threshold %.2f", name, similarity, threshold),
+			Scores:   scores,
+		}
+	}
+	return nil
+}
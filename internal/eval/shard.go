@@ -0,0 +1,54 @@
+package eval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Shard selects one of Count equal parts of a run, so that a large suite
+// can be split across machines. Index counts from 1. The zero Shard
+// selects the whole run.
+type Shard struct {
+	Index int `json:"index"`
+	Count int `json:"count"`
+}
+
+// ParseShard parses a shard given as "index/count", e.g. "2/5".
+func ParseShard(s string) (Shard, error) {
+	index, count, ok := strings.Cut(s, "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("invalid shard %q (expected index/count, e.g. 2/5)", s)
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard index %q: %w", index, err)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard count %q: %w", count, err)
+	}
+	if n < 1 || i < 1 || i > n {
+		return Shard{}, fmt.Errorf("invalid shard %q (index must be from 1 to count)", s)
+	}
+	return Shard{Index: i, Count: n}, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// apply returns the evals of the shard: every Count-th eval, starting at
+// position Index. Dealing evals in turn keeps shards within one eval of
+// each other in size, and since the selected evals are in registration
+// order, the same flags yield the same partition on every machine.
+func (s Shard) apply(evals []Eval) []Eval {
+	if s.Count <= 1 {
+		return evals
+	}
+	var out []Eval
+	for i := s.Index - 1; i < len(evals); i += s.Count {
+		out = append(out, evals[i])
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package eval
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Shard
+		wantErr bool
+	}{
+		{in: "1/1", want: Shard{Index: 1, Count: 1}},
+		{in: "2/5", want: Shard{Index: 2, Count: 5}},
+		{in: "3/3", want: Shard{Index: 3, Count: 3}},
+		{in: "0/3", wantErr: true},
+		{in: "4/3", wantErr: true},
+		{in: "-1/3", wantErr: true},
+		{in: "1/0", wantErr: true},
+		{in: "a/b", wantErr: true},
+		{in: "1/b", wantErr: true},
+		{in: "3", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "1/2/3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseShard(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseShard(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseShard(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseShard(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+			if got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+}
+
+// TestShardApplyPartitions checks that the shards of a run together hold
+// every eval exactly once, and are within one eval of each other in size.
+func TestShardApplyPartitions(t *testing.T) {
+	evals := AllEvals()
+	for _, count := range []int{1, 2, 3, 7, len(evals), len(evals) + 3} {
+		seen := make(map[Eval]int)
+		sizes := make([]int, count)
+		for index := 1; index <= count; index++ {
+			shard := Shard{Index: index, Count: count}.apply(evals)
+			sizes[index-1] = len(shard)
+			for _, e := range shard {
+				seen[e]++
+			}
+		}
+
+		for _, e := range evals {
+			if seen[e] != 1 {
+				t.Errorf("count %d: %s is in %d shards, want 1", count, e.Name(), seen[e])
+			}
+		}
+		if len(seen) != len(evals) {
+			t.Errorf("count %d: shards hold %d evals, want %d", count, len(seen), len(evals))
+		}
+		if slices.Max(sizes)-slices.Min(sizes) > 1 {
+			t.Errorf("count %d: shard sizes %v differ by more than one", count, sizes)
+		}
+	}
+}
+
+func TestZeroShardSelectsAll(t *testing.T) {
+	evals := AllEvals()
+	if got := (Shard{}).apply(evals); len(got) != len(evals) {
+		t.Errorf("zero shard selected %d evals, want all %d", len(got), len(evals))
+	}
+}
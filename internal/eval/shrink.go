@@ -0,0 +1,238 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// maxShrinkRuns bounds the number of times an eval is rerun while
+// shrinking one failure.
+const maxShrinkRuns = 50
+
+// Repro describes the minimized request written for a failed eval.
+type Repro struct {
+	// Path is the file holding the request body.
+	Path string
+	// Messages and Tools count what the minimized request kept of the
+	// failing request's TotalMessages and TotalTools.
+	Messages      int
+	TotalMessages int
+	Tools         int
+	TotalTools    int
+	// Runs is the number of times the eval was run while shrinking.
+	Runs int
+}
+
+// shrinkRun rewrites the chat requests of one run of an eval, removing
+// messages and tools from the target request. As the run's request logger
+// it records the status of the last response.
+type shrinkRun struct {
+	targetIndex int          // index of the chat request to shrink, or -1
+	keep        map[int]bool // units of the target request to keep
+
+	mu         sync.Mutex
+	count      int      // chat requests sent
+	sizes      [][2]int // messages and tools of each request, as built
+	lastBody   []byte   // body of the last request sent
+	targetBody []byte   // body of the target request as sent
+	lastStatus int      // status of the last response
+}
+
+func (s *shrinkRun) LogRequest(method, url string, body []byte, sent time.Time) {}
+func (s *shrinkRun) LogResponseHeaders(headers map[string]string)               {}
+func (s *shrinkRun) LogStreamTiming(ttft, itl time.Duration, chunks int)        {}
+func (s *shrinkRun) LogStreamChunks(jsonl []byte)                               {}
+
+func (s *shrinkRun) LogResponse(status int, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastStatus = status
+}
+
+func (s *shrinkRun) LogStreamResponse(status int, rawChunks []byte) {
+	s.LogResponse(status, rawChunks)
+}
+
+// rewrite is the client rewrite hook of the run.
+func (s *shrinkRun) rewrite(req *client.ChatCompletionRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.count
+	s.count++
+	s.sizes = append(s.sizes, [2]int{len(req.Messages), len(req.Tools)})
+
+	if i == s.targetIndex {
+		// Units are the request's messages, then its tools
+		var messages []client.Message
+		for j, m := range req.Messages {
+			if s.keep[j] {
+				messages = append(messages, m)
+			}
+		}
+		var tools []client.Tool
+		for j, t := range req.Tools {
+			if s.keep[len(req.Messages)+j] {
+				tools = append(tools, t)
+			}
+		}
+		req.Messages = messages
+		req.Tools = tools
+	}
+
+	body, _ := json.Marshal(req)
+	s.lastBody = body
+	if i == s.targetIndex {
+		s.targetBody = body
+	}
+}
+
+// shrink reruns a failed eval, removing messages and tools from the last
+// chat request it sends, to find a minimal request that still fails with
+// the same code and final response status. The minimized request is
+// written as a repro file. Removals are searched by delta debugging within
+// maxShrinkRuns runs. Evals that send no chat requests are not shrunk.
+func (r *Runner) shrink(e Eval, name string, streaming bool, failure Result) (*Repro, error) {
+	ctx := context.Background()
+	e = isolatedEval(e, streaming)
+	runs := 0
+	run := func(s *shrinkRun) bool {
+		runs++
+		res := e.Run(ctx, r.client.WithLogger(s).WithRewrite(s.rewrite))
+		return !res.Passed && res.Code == failure.Code
+	}
+
+	base := &shrinkRun{targetIndex: -1}
+	if !run(base) {
+		return nil, errors.New("failure did not reproduce")
+	}
+	if base.count == 0 {
+		return nil, nil
+	}
+	reproduces := func(s *shrinkRun) bool {
+		return run(s) && s.lastStatus == base.lastStatus
+	}
+
+	target := base.count - 1
+	totalMessages, totalTools := base.sizes[target][0], base.sizes[target][1]
+	best := base.lastBody
+
+	units := make([]int, totalMessages+totalTools)
+	for i := range units {
+		units[i] = i
+	}
+	kept := ddmin(units, func(keep []int) bool {
+		if runs >= maxShrinkRuns {
+			return false
+		}
+		s := &shrinkRun{targetIndex: target, keep: make(map[int]bool, len(keep))}
+		for _, u := range keep {
+			s.keep[u] = true
+		}
+		if !reproduces(s) || s.targetBody == nil {
+			return false
+		}
+		best = s.targetBody
+		return true
+	})
+
+	path, err := r.config.Logger.WriteRepro(name, best)
+	if err != nil {
+		return nil, err
+	}
+
+	repro := &Repro{
+		Path:          path,
+		TotalMessages: totalMessages,
+		TotalTools:    totalTools,
+		Runs:          runs,
+	}
+	for _, u := range kept {
+		if u < totalMessages {
+			repro.Messages++
+		} else {
+			repro.Tools++
+		}
+	}
+	return repro, nil
+}
+
+// isolatedEval returns a shallow copy of e in the given mode, so that
+// reruns are unaffected by runs of e in the other mode in parallel.
+func isolatedEval(e Eval, streaming bool) Eval {
+	v := reflect.ValueOf(e)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return e
+	}
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	isolated := cp.Interface().(Eval)
+	if sme, ok := isolated.(StreamModeEval); ok {
+		sme.SetStreaming(streaming)
+	}
+	return isolated
+}
+
+// ddmin returns a subset of units for which test still holds, such that
+// removing any one chunk at the final granularity makes it fail. test must
+// hold for units itself.
+func ddmin(units []int, test func(keep []int) bool) []int {
+	n := 2
+	for len(units) >= 2 {
+		chunks := splitUnits(units, n)
+
+		reduced := false
+		for _, chunk := range chunks {
+			if test(chunk) {
+				units, n, reduced = chunk, 2, true
+				break
+			}
+		}
+		if !reduced && n > 2 {
+			for i := range chunks {
+				complement := complementUnits(chunks, i)
+				if test(complement) {
+					units, n, reduced = complement, max(n-1, 2), true
+					break
+				}
+			}
+		}
+		if reduced {
+			continue
+		}
+		if n >= len(units) {
+			break
+		}
+		n = min(2*n, len(units))
+	}
+	return units
+}
+
+// splitUnits splits units into n chunks of near-equal size.
+func splitUnits(units []int, n int) [][]int {
+	chunks := make([][]int, 0, n)
+	start := 0
+	for i := range n {
+		end := start + (len(units)-start)/(n-i)
+		chunks = append(chunks, units[start:end])
+		start = end
+	}
+	return chunks
+}
+
+// complementUnits returns the units of all chunks but chunks[skip].
+func complementUnits(chunks [][]int, skip int) []int {
+	var units []int
+	for i, chunk := range chunks {
+		if i != skip {
+			units = append(units, chunk...)
+		}
+	}
+	return units
+}
@@ -0,0 +1,61 @@
+package eval
+
+import (
+	"fmt"
+	"time"
+)
+
+// SLO is a latency objective for an eval. Zero limits are not checked.
+type SLO struct {
+	// TTFT is the longest time to first token, checked in streaming mode.
+	TTFT time.Duration
+	// Total is the longest duration of one run of the eval.
+	Total time.Duration
+}
+
+// SLOs maps eval classes, categories, and eval names to latency
+// objectives. An eval exceeding its objective still passes, with the
+// violations listed on its result.
+type SLOs struct {
+	Classes    map[string]SLO
+	Categories map[string]SLO
+	Evals      map[string]SLO
+}
+
+// objective returns the SLO of an eval, taking each limit from the eval's
+// own entry if it sets it, else from its category's, else its class's.
+func (s SLOs) objective(e Eval) SLO {
+	var slo SLO
+	for _, entry := range []SLO{s.Classes[e.Class()], s.Categories[e.Category()], s.Evals[e.Name()]} {
+		if entry.TTFT > 0 {
+			slo.TTFT = entry.TTFT
+		}
+		if entry.Total > 0 {
+			slo.Total = entry.Total
+		}
+	}
+	return slo
+}
+
+// check returns how a result of the eval exceeded its SLO. A repeated
+// eval's slowest run is checked against the total limit.
+func (s SLOs) check(e Eval, result Result) []string {
+	slo := s.objective(e)
+
+	var violations []string
+	if ttft := result.Stats.TTFT; slo.TTFT > 0 && ttft > slo.TTFT {
+		violations = append(violations, fmt.Sprintf("TTFT %s exceeds %s", ttft.Round(time.Millisecond), slo.TTFT))
+	}
+
+	total := result.Duration
+	if len(result.Iterations) > 0 {
+		total = 0
+		for _, it := range result.Iterations {
+			total = max(total, it.Duration)
+		}
+	}
+	if slo.Total > 0 && total > slo.Total {
+		violations = append(violations, fmt.Sprintf("total %s exceeds %s", total.Round(time.Millisecond), slo.Total))
+	}
+	return violations
+}
@@ -0,0 +1,286 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const sseCategory = "Streaming"
+
+// sseEvals returns all SSE wire format evals.
+func sseEvals() []Eval {
+	return []Eval{
+		&sseWireFormatEval{},
+		&streamErrorSignalEval{},
+	}
+}
+
+// sseWireFormatEval inspects the raw bytes of a stream rather than the
+// parsed chunks: the Content-Type header, "data: " fields, blank-line event
+// separators, and the [DONE] terminator. Lenient parsers accept deviations
+// that break stricter clients and proxies.
+type sseWireFormatEval struct{}
+
+func (e *sseWireFormatEval) Name() string {
+	return "sse_wire_format"
+}
+
+func (e *sseWireFormatEval) Category() string {
+	return sseCategory
+}
+
+func (e *sseWireFormatEval) Class() string {
+	return ClassStandard
+}
+
+// IsStreamingOnly returns true because only streams have a wire format.
+func (e *sseWireFormatEval) IsStreamingOnly() bool {
+	return true
+}
+
+func (e *sseWireFormatEval) Run(ctx context.Context, c *client.Client) Result {
+	result, err := c.ChatCompletionStream(ctx, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Count from 1 to 10, separated by spaces."},
+		},
+	})
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(result.ContentType)
+	if err != nil || mediaType != "text/event-stream" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeSSEContentType,
+			Message:  fmt.Sprintf("expected Content-Type text/event-stream, got %q", result.ContentType),
+		}
+	}
+
+	if code, msg := checkSSEWire(result.Raw); code != "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     code,
+			Message:  msg,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// streamErrorMaxTokens is far more than any context window, so that a
+// generation that never stops exhausts the context mid-stream.
+const streamErrorMaxTokens = 1 << 24
+
+// streamErrorSignalEval provokes a server error mid-stream, by asking for
+// endless output with a max_tokens beyond any context window, and checks
+// that the server signals how the stream ended: with a finish_reason, an
+// error event or payload, or an HTTP trailer. A stream that just stops, or
+// ends with [DONE] and no finish_reason, is indistinguishable from a
+// complete response to clients.
+type streamErrorSignalEval struct{}
+
+func (e *streamErrorSignalEval) Name() string {
+	return "stream_error_signaled"
+}
+
+func (e *streamErrorSignalEval) Category() string {
+	return sseCategory
+}
+
+func (e *streamErrorSignalEval) Class() string {
+	return ClassStandard
+}
+
+// IsStreamingOnly returns true because only streams can fail mid-response.
+func (e *streamErrorSignalEval) IsStreamingOnly() bool {
+	return true
+}
+
+// IsDefaultDisabled returns true because the eval generates until the
+// server's context window is full.
+func (e *streamErrorSignalEval) IsDefaultDisabled() bool {
+	return true
+}
+
+func (e *streamErrorSignalEval) Tags() []string {
+	return []string{TagSlow}
+}
+
+func (e *streamErrorSignalEval) Run(ctx context.Context, c *client.Client) Result {
+	result, err := c.ChatCompletionStream(ctx, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Count upward from 1, one number per line. Never stop counting."},
+		},
+		MaxTokens: streamErrorMaxTokens,
+	})
+	if err != nil {
+		// A rejection before the stream started is signaled by its status
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   true,
+				Notes:    []string{fmt.Sprintf("no mid-stream error provoked: the request was rejected with status %d", statusErr.StatusCode)},
+			}
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error() + " (generating until the context is full may need a longer --timeout)",
+		}
+	}
+
+	for _, v := range result.Violations {
+		if v.Kind == client.ViolationErrorEvent || v.Kind == client.ViolationErrorPayload {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   true,
+				Message:  "signaled by " + v.Message,
+			}
+		}
+	}
+
+	reason := ""
+	if len(result.Choices) > 0 {
+		reason = result.Choices[0].FinishReason
+	}
+	switch {
+	case reason == "stop":
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+			Notes:    []string{"no mid-stream error provoked: the model stopped counting"},
+		}
+	case reason != "":
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+			Message:  fmt.Sprintf("signaled by finish_reason %q", reason),
+		}
+	case len(result.Trailer) > 0:
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+			Message:  fmt.Sprintf("signaled by HTTP trailer %v", result.Trailer),
+		}
+	}
+
+	how := "stopped without data: [DONE]"
+	if result.Done {
+		how = "ended with data: [DONE]"
+	}
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   false,
+		Code:     CodeStreamErrorUnsignaled,
+		Message:  fmt.Sprintf("stream %s after %d chunks, with no finish_reason, error event, or trailer", how, len(result.Chunks)),
+	}
+}
+
+// checkSSEWire checks raw stream bytes against the event stream format as
+// OpenAI clients expect it. It returns a failure code and message, or empty
+// strings if the stream conforms.
+func checkSSEWire(raw []byte) (code, msg string) {
+	// Lines may end in CRLF, LF, or CR
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return CodeSSEMalformed, "stream body is empty"
+	}
+
+	lines := strings.Split(text, "\n")
+	var data []string // data lines of the event being read
+	events := 0
+	done := false
+
+	for i, line := range lines {
+		n := i + 1
+
+		if line == "" {
+			// A blank line dispatches the pending event
+			if len(data) == 0 {
+				continue
+			}
+			payload := strings.Join(data, "\n")
+			data = nil
+			events++
+
+			if done {
+				return CodeSSEMalformed, fmt.Sprintf("event after [DONE] (line %d): %q", n-1, payload)
+			}
+			if payload == "[DONE]" {
+				done = true
+				continue
+			}
+			if !json.Valid([]byte(payload)) {
+				return CodeSSEMalformed, fmt.Sprintf("event ending at line %d is not valid JSON: %q", n-1, payload)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, ":"):
+			// Comment, e.g. a keep-alive
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		case strings.HasPrefix(line, "data:"):
+			return CodeSSEMalformed, fmt.Sprintf("line %d has no space after \"data:\"; many OpenAI clients require \"data: \": %q", n, line)
+		case strings.HasPrefix(line, "event:"), strings.HasPrefix(line, "id:"), strings.HasPrefix(line, "retry:"):
+			// Other valid SSE fields
+		default:
+			return CodeSSEMalformed, fmt.Sprintf("line %d is not an SSE field: %q", n, line)
+		}
+	}
+
+	// Data at the end of the body that no blank line terminated. The
+	// client stops reading at [DONE], so its blank line may not have been
+	// received.
+	if len(data) > 0 {
+		payload := strings.Join(data, "\n")
+		if payload != "[DONE]" || done {
+			return CodeSSEMalformed, fmt.Sprintf("final event is not terminated by a blank line: %q", payload)
+		}
+		events++
+		done = true
+	}
+
+	if events == 0 {
+		return CodeSSEMalformed, "stream contains no data events"
+	}
+	if !done {
+		return CodeSSEDoneMissing, "stream did not end with data: [DONE]"
+	}
+
+	return "", ""
+}
@@ -0,0 +1,124 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// Stability verdicts.
+const (
+	// StabilityStable means the server answered as well after the run as
+	// before it.
+	StabilityStable = "stable"
+	// StabilityDegraded means the server was unhealthy, failed the probe
+	// completion, or was much slower to respond after the run.
+	StabilityDegraded = "degraded"
+	// StabilityUnknown means the probe before the run failed, so there is
+	// nothing to compare with.
+	StabilityUnknown = "unknown"
+)
+
+// probeTimeout bounds each stability probe.
+const probeTimeout = 30 * time.Second
+
+// stabilityTTFTFactor is how many times slower than before the run the
+// time to first token may become before the server counts as degraded.
+// stabilityTTFTSlack is added to the allowance, so that jitter on a fast
+// server's few milliseconds is not taken for degradation.
+const (
+	stabilityTTFTFactor = 3
+	stabilityTTFTSlack  = 250 * time.Millisecond
+)
+
+// Probe is a quick check of the server: its /health endpoint and the time
+// to first token of a short streamed completion.
+type Probe struct {
+	// HealthStatus is the status code of /health, or 0 if it could not be
+	// requested.
+	HealthStatus int `json:"health_status,omitempty"`
+	// TTFTMS is the time to first token of the probe completion, in
+	// milliseconds.
+	TTFTMS int64 `json:"ttft_ms,omitempty"`
+	// Error describes why the probe completion failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// healthy returns true if the server reported no problem with its health:
+// it answered 200, or has no /health endpoint at all.
+func (p Probe) healthy() bool {
+	return p.HealthStatus == 0 || p.HealthStatus == http.StatusOK || p.HealthStatus == http.StatusNotFound
+}
+
+// ProbeServer checks the server's health and the time to first token of a
+// short streamed completion, for comparing the server before and after a
+// run.
+func ProbeServer(c *client.Client) Probe {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	var p Probe
+	if status, err := c.Health(ctx); err == nil {
+		p.HealthStatus = status
+	}
+
+	result, err := c.ChatCompletionStream(ctx, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Reply with the word OK."},
+		},
+		MaxTokens: 8,
+	})
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	if result.TTFT == 0 {
+		p.Error = "no tokens received"
+		return p
+	}
+	p.TTFTMS = result.TTFT.Milliseconds()
+	return p
+}
+
+// Stability compares probes of the server before and after a run, to tell
+// whether the run degraded it.
+type Stability struct {
+	Verdict string `json:"verdict"`
+	Start   Probe  `json:"start"`
+	End     Probe  `json:"end"`
+	// Reasons lists why the server counts as degraded, or why the verdict
+	// is unknown.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// CheckStability compares the probes from before and after a run.
+func CheckStability(start, end Probe) Stability {
+	s := Stability{Verdict: StabilityStable, Start: start, End: end}
+
+	if start.Error != "" {
+		s.Verdict = StabilityUnknown
+		s.Reasons = append(s.Reasons, "probe before the run failed: "+start.Error)
+		return s
+	}
+
+	if !end.healthy() {
+		s.Reasons = append(s.Reasons, fmt.Sprintf("/health returned %d after the run", end.HealthStatus))
+	}
+	if end.Error != "" {
+		s.Reasons = append(s.Reasons, "probe completion failed after the run: "+end.Error)
+	} else {
+		before := time.Duration(start.TTFTMS) * time.Millisecond
+		after := time.Duration(end.TTFTMS) * time.Millisecond
+		if after > stabilityTTFTFactor*before+stabilityTTFTSlack {
+			s.Reasons = append(s.Reasons, fmt.Sprintf("time to first token rose from %s to %s", before, after))
+		}
+	}
+
+	if len(s.Reasons) > 0 {
+		s.Verdict = StabilityDegraded
+	}
+	return s
+}
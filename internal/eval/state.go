@@ -0,0 +1,108 @@
+package eval
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// stateFile is the name of the run state file within a log directory.
+const stateFile = "state.jsonl"
+
+// RunState persists completed eval results incrementally so an interrupted
+// run can be resumed. Results are keyed by their mode-qualified name
+// (e.g. "single_tool_call (streaming)"). It is safe for concurrent use.
+type RunState struct {
+	path string
+
+	mu        sync.Mutex
+	completed map[string]Result
+}
+
+// NewRunState starts an empty run state in a log directory, discarding any
+// state file left there by an earlier run.
+func NewRunState(dir string) (*RunState, error) {
+	path := filepath.Join(dir, stateFile)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reset run state: %w", err)
+	}
+	return &RunState{
+		path:      path,
+		completed: make(map[string]Result),
+	}, nil
+}
+
+// OpenRunState opens the run state in a log directory, loading any results
+// recorded by a previous run in the same directory.
+func OpenRunState(dir string) (*RunState, error) {
+	s := &RunState{
+		path:      filepath.Join(dir, stateFile),
+		completed: make(map[string]Result),
+	}
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open run state: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			// A torn final line from a crash is expected; skip it
+			continue
+		}
+		s.completed[r.Name] = r
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read run state: %w", err)
+	}
+
+	return s, nil
+}
+
+// Completed returns the recorded result for an eval, if any.
+func (s *RunState) Completed(name string) (Result, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.completed[name]
+	return r, ok
+}
+
+// Len returns the number of completed evals.
+func (s *RunState) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.completed)
+}
+
+// Record appends a completed result to the state file.
+func (s *RunState) Record(r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal result: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open run state: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write run state: %w", err)
+	}
+
+	s.completed[r.Name] = r
+	return nil
+}
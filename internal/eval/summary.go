@@ -0,0 +1,145 @@
+package eval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CodeUnknown is used in breakdowns for failures that carry no code.
+const CodeUnknown = "UNKNOWN"
+
+// FailureCount is the number of failures sharing a failure code.
+type FailureCount struct {
+	Code  string `json:"code"`
+	Count int    `json:"count"`
+}
+
+// FailureBreakdown aggregates failed results by failure code.
+func FailureBreakdown(results []Result) []FailureCount {
+	var codes []string
+	for _, r := range results {
+		if !r.Passed {
+			codes = append(codes, r.Code)
+		}
+	}
+	return CountFailureCodes(codes)
+}
+
+// CountFailureCodes counts occurrences of each failure code, most frequent first.
+// Empty codes are counted as CodeUnknown.
+func CountFailureCodes(codes []string) []FailureCount {
+	counts := make(map[string]int)
+	for _, code := range codes {
+		if code == "" {
+			code = CodeUnknown
+		}
+		counts[code]++
+	}
+
+	breakdown := make([]FailureCount, 0, len(counts))
+	for code, count := range counts {
+		breakdown = append(breakdown, FailureCount{Code: code, Count: count})
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Count != breakdown[j].Count {
+			return breakdown[i].Count > breakdown[j].Count
+		}
+		return breakdown[i].Code < breakdown[j].Code
+	})
+	return breakdown
+}
+
+// FormatFailureBreakdown renders a breakdown as a single line, e.g.
+// "5 failures: 3× TOOLCALL_ARGS_INVALID, 2× REASONING_EMPTY".
+func FormatFailureBreakdown(breakdown []FailureCount) string {
+	total := 0
+	parts := make([]string, 0, len(breakdown))
+	for _, fc := range breakdown {
+		total += fc.Count
+		parts = append(parts, fmt.Sprintf("%d× %s", fc.Count, fc.Code))
+	}
+
+	noun := "failures"
+	if total == 1 {
+		noun = "failure"
+	}
+	return fmt.Sprintf("%d %s: %s", total, noun, strings.Join(parts, ", "))
+}
+
+// CategoryTime is the total eval time spent in a category.
+type CategoryTime struct {
+	Category string
+	Duration time.Duration
+	Count    int
+}
+
+// CategoryTimes sums eval durations per category, slowest first.
+func CategoryTimes(results []Result) []CategoryTime {
+	index := make(map[string]int)
+	var times []CategoryTime
+	for _, r := range results {
+		idx, ok := index[r.Category]
+		if !ok {
+			idx = len(times)
+			index[r.Category] = idx
+			times = append(times, CategoryTime{Category: r.Category})
+		}
+		times[idx].Duration += r.Duration
+		times[idx].Count++
+	}
+
+	sort.SliceStable(times, func(i, j int) bool {
+		return times[i].Duration > times[j].Duration
+	})
+	return times
+}
+
+// SlowestResults returns up to n results with the longest durations.
+func SlowestResults(results []Result, n int) []Result {
+	sorted := append([]Result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Duration > sorted[j].Duration
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// StreamingLatency is the mean streaming latency across evals that made
+// streaming requests.
+type StreamingLatency struct {
+	TTFT time.Duration
+	ITL  time.Duration
+	// Count is the number of evals with streaming latency data.
+	Count int
+}
+
+// MeanStreamingLatency averages TTFT and inter-token latency over results
+// that recorded them.
+func MeanStreamingLatency(results []Result) StreamingLatency {
+	var ttftTotal, itlTotal time.Duration
+	var ttftCount, itlCount int
+	for _, r := range results {
+		if r.Stats.TTFT > 0 {
+			ttftTotal += r.Stats.TTFT
+			ttftCount++
+		}
+		if r.Stats.ITL > 0 {
+			itlTotal += r.Stats.ITL
+			itlCount++
+		}
+	}
+
+	var l StreamingLatency
+	l.Count = ttftCount
+	if ttftCount > 0 {
+		l.TTFT = ttftTotal / time.Duration(ttftCount)
+	}
+	if itlCount > 0 {
+		l.ITL = itlTotal / time.Duration(itlCount)
+	}
+	return l
+}
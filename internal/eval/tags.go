@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Tags shared by evals across categories.
+const (
+	// TagTools marks evals that exercise tool calls.
+	TagTools = "tools"
+	// TagMultiTurn marks evals whose conversations carry earlier responses
+	// into later requests.
+	TagMultiTurn = "multi-turn"
+	// TagTemplate marks evals that check how the chat template renders a
+	// conversation.
+	TagTemplate = "template"
+	// TagSlow marks evals that send many or long requests.
+	TagSlow = "slow"
+)
+
+// Tags derived from how an eval runs.
+const (
+	// TagStreaming and TagBlocking mark the runs of an eval in each mode.
+	TagStreaming = "streaming"
+	TagBlocking  = "blocking"
+	// TagFundamental marks evals that check basic functionality.
+	TagFundamental = "fundamental"
+	// TagVision marks evals that send images.
+	TagVision = "vision"
+)
+
+// Tagged is an optional interface for evals with tags beyond those derived
+// from their category, class, flavor, and modes, such as TagTools for evals
+// outside the Tool Calling category that send tools.
+type Tagged interface {
+	Tags() []string
+}
+
+// EvalTags returns the sorted tags of an eval: its own, its category as a
+// slug (e.g. "tool-calling"), its class, its flavor if any, "fundamental"
+// for fundamental evals, "vision" for evals that send images, and "streaming" and "blocking" for the modes it
+// can run in.
+func EvalTags(e Eval) []string {
+	tags := baseTags(e)
+	if !IsBlockingOnly(e) {
+		tags = append(tags, TagStreaming)
+	}
+	if !IsStreamingOnly(e) {
+		tags = append(tags, TagBlocking)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// runTags returns the tags of an eval's run in one mode: its tags, with
+// only the tag of that mode.
+func runTags(e Eval, streaming bool) []string {
+	tags := baseTags(e)
+	if streaming {
+		return append(tags, TagStreaming)
+	}
+	return append(tags, TagBlocking)
+}
+
+// baseTags returns the tags of an eval other than its mode tags.
+func baseTags(e Eval) []string {
+	tags := []string{categorySlug(e.Category()), e.Class()}
+	if f := EvalFlavor(e); f != "" {
+		tags = append(tags, f)
+	}
+	if IsFundamental(e) {
+		tags = append(tags, TagFundamental)
+	}
+	// The Vision category's slug is the tag already
+	if RequiresVision(e) && !slices.Contains(tags, TagVision) {
+		tags = append(tags, TagVision)
+	}
+	if t, ok := e.(Tagged); ok {
+		tags = append(tags, t.Tags()...)
+	}
+	return tags
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// categorySlug returns a category name as a tag, e.g. "Tool Calling" as
+// "tool-calling" and "llama.cpp" as itself.
+func categorySlug(category string) string {
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(category), "-"), "-")
+}
+
+// AllTags returns every tag of the registered evals, sorted.
+func AllTags() []string {
+	var tags []string
+	for _, e := range AllEvals() {
+		tags = append(tags, EvalTags(e)...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// TagsMatch returns true if tags include every tag of include and none of
+// exclude.
+func TagsMatch(tags, include, exclude []string) bool {
+	for _, t := range include {
+		if !slices.Contains(tags, t) {
+			return false
+		}
+	}
+	for _, t := range exclude {
+		if slices.Contains(tags, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// AnyRunMatches returns true if the eval's run in some mode it supports
+// has every tag of include and none of exclude.
+func AnyRunMatches(e Eval, include, exclude []string) bool {
+	for _, streaming := range []bool{false, true} {
+		if (streaming && IsBlockingOnly(e)) || (!streaming && IsStreamingOnly(e)) {
+			continue
+		}
+		if TagsMatch(runTags(e, streaming), include, exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+// NameMatches returns true if an eval name matches a --filter regular
+// expression, which matches anywhere in the name; a plain substring such as
+// "tool_call" matches as before. An empty filter matches every name, and
+// an invalid one none; check filters with regexp.Compile first.
+func NameMatches(name, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
@@ -0,0 +1,367 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const templateCategory = "Chat Template"
+
+// templateEvals returns all chat template handling evals.
+func templateEvals() []Eval {
+	return []Eval{
+		&assistantFirstEval{},
+		&consecutiveRolesEval{},
+		&assistantPrefillEval{},
+		&templateConformanceEval{},
+	}
+}
+
+// templateRequest sends a conversation of unusual shape in the given mode
+// and returns the content of the first choice. On failure it returns a
+// non-nil Result. A server may reject such a conversation, which passes if
+// the rejection is a 4xx with an error message; a 5xx or an unexplained
+// rejection means template rendering broke.
+func templateRequest(ctx context.Context, c *client.Client, e Eval, streaming bool, req client.ChatCompletionRequest) (string, *Result) {
+	var content string
+	var err error
+
+	if streaming {
+		var result *client.StreamResult
+		result, err = c.ChatCompletionStream(ctx, req)
+		if err == nil {
+			content = result.Content
+		}
+	} else {
+		var resp *client.ChatCompletionResponse
+		resp, err = c.ChatCompletion(ctx, req)
+		if err == nil {
+			if len(resp.Choices) == 0 {
+				return "", &Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeNoChoices,
+					Message:  "no choices in response",
+				}
+			}
+			content = resp.Choices[0].Message.Content
+		}
+	}
+
+	if err != nil {
+		result := templateRejection(e, err)
+		return "", &result
+	}
+	return content, nil
+}
+
+// templateRejection classifies a failed request with an unusual
+// conversation shape.
+func templateRejection(e Eval, err error) Result {
+	var statusErr *client.StatusError
+	if !errors.As(err, &statusErr) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	status := statusErr.StatusCode
+	if status < http.StatusBadRequest || status >= http.StatusInternalServerError {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTemplateServerError,
+			Message:  fmt.Sprintf("conversation caused status %d instead of a response or 4xx rejection: %s", status, statusErr.Body),
+		}
+	}
+
+	msg := errorMessage(statusErr.Body)
+	if msg == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTemplateServerError,
+			Message:  fmt.Sprintf("conversation rejected with status %d but no error message: %q", status, statusErr.Body),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("conversation rejected cleanly (status %d): %s", status, msg),
+	}
+}
+
+// assistantFirstEval sends a conversation that opens with an assistant
+// message and no prior user turn, as agent frameworks do when resuming a
+// session. Templates that index the first message as a user turn can fail
+// to render it. The server must either answer using what the assistant
+// message said, or reject the conversation cleanly.
+type assistantFirstEval struct {
+	streaming bool
+}
+
+func (e *assistantFirstEval) Name() string {
+	return "assistant_first_message"
+}
+
+func (e *assistantFirstEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *assistantFirstEval) Streaming() bool             { return e.streaming }
+
+func (e *assistantFirstEval) Category() string {
+	return templateCategory
+}
+
+func (e *assistantFirstEval) Class() string {
+	return ClassStandard
+}
+
+func (e *assistantFirstEval) Tags() []string {
+	return []string{TagTemplate}
+}
+
+func (e *assistantFirstEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "assistant", Content: "Welcome back! Last time we were planning your trip to Lisbon. Shall we continue?"},
+			{Role: "user", Content: "Yes. Which city were we planning to visit? Answer with just the city name."},
+		},
+	}
+
+	content, failed := templateRequest(ctx, c, e, e.streaming, req)
+	if failed != nil {
+		return *failed
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "content is empty",
+		}
+	}
+
+	if !strings.Contains(strings.ToLower(content), "lisbon") {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTemplateMessageDropped,
+			Message:  fmt.Sprintf("answer %q does not name Lisbon, given only in the leading assistant message", content),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// consecutiveFact is a fact given in only one message of a conversation,
+// which an answer must repeat to show the message reached the model.
+type consecutiveFact struct {
+	message string // which message holds the fact
+	value   string
+}
+
+// consecutiveRolesEval sends two consecutive user messages followed by two
+// consecutive assistant messages. Templates differ widely here: some merge
+// the messages, some render each, and some silently keep only one. Each
+// message holds a fact the final answer needs, so a dropped message shows
+// in the answer. The server must either use all four or reject the
+// conversation cleanly.
+type consecutiveRolesEval struct {
+	streaming bool
+}
+
+func (e *consecutiveRolesEval) Name() string {
+	return "consecutive_same_role"
+}
+
+func (e *consecutiveRolesEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *consecutiveRolesEval) Streaming() bool             { return e.streaming }
+
+func (e *consecutiveRolesEval) Category() string {
+	return templateCategory
+}
+
+func (e *consecutiveRolesEval) Class() string {
+	return ClassStandard
+}
+
+func (e *consecutiveRolesEval) Tags() []string {
+	return []string{TagTemplate}
+}
+
+func (e *consecutiveRolesEval) Run(ctx context.Context, c *client.Client) Result {
+	facts := []consecutiveFact{
+		{"first user message", "Priya"},
+		{"second user message", "Oslo"},
+		{"first assistant message", "4812"},
+		{"second assistant message", "37"},
+	}
+
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Hi, my name is Priya."},
+			{Role: "user", Content: "I just moved to Oslo."},
+			{Role: "assistant", Content: "Welcome! Your gym locker code is 4812."},
+			{Role: "assistant", Content: "Your bike is parked in rack 37."},
+			{Role: "user", Content: "Remind me of my name, my city, my locker code, and my bike rack number, as a comma-separated list."},
+		},
+	}
+
+	content, failed := templateRequest(ctx, c, e, e.streaming, req)
+	if failed != nil {
+		return *failed
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "content is empty",
+		}
+	}
+
+	var dropped []string
+	lower := strings.ToLower(content)
+	for _, f := range facts {
+		if !strings.Contains(lower, strings.ToLower(f.value)) {
+			dropped = append(dropped, fmt.Sprintf("%s (%s)", f.message, f.value))
+		}
+	}
+	if len(dropped) > 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeTemplateMessageDropped,
+			Message:  fmt.Sprintf("answer %q lacks facts given only in the %s", content, strings.Join(dropped, ", ")),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  "consecutive user and assistant messages were all rendered, separately or merged",
+	}
+}
+
+// prefillText is the trailing assistant message of assistantPrefillEval. Its
+// continuation starts with the next prime, 5.
+const prefillText = "The first five prime numbers are 2, 3,"
+
+// firstNumberPattern matches the first number in a continuation.
+var firstNumberPattern = regexp.MustCompile(`[0-9]+`)
+
+// assistantPrefillEval sends a conversation ending with a partial assistant
+// message, which the server must continue rather than answer from scratch
+// or restart. llama.cpp continues a trailing assistant message on its own;
+// vLLM does so with continue_final_message, which is sent with the vllm
+// flavor. Servers without prefill may reject the conversation cleanly.
+type assistantPrefillEval struct {
+	streaming bool
+	flavor    string
+}
+
+func (e *assistantPrefillEval) Name() string {
+	return "assistant_prefill"
+}
+
+func (e *assistantPrefillEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *assistantPrefillEval) Streaming() bool             { return e.streaming }
+
+func (e *assistantPrefillEval) Category() string {
+	return templateCategory
+}
+
+func (e *assistantPrefillEval) Class() string {
+	return ClassStandard
+}
+
+func (e *assistantPrefillEval) Tags() []string {
+	return []string{TagTemplate}
+}
+
+func (e *assistantPrefillEval) configure(cfg RunnerConfig) {
+	e.flavor = cfg.Flavor
+}
+
+func (e *assistantPrefillEval) Run(ctx context.Context, c *client.Client) Result {
+	temperature := 0.0
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "List the first five prime numbers, separated by commas."},
+			{Role: "assistant", Content: prefillText},
+		},
+		Temperature: &temperature,
+		MaxTokens:   32,
+	}
+	if e.flavor == FlavorVLLM {
+		addGenerationPrompt := false
+		req.ContinueFinalMessage = true
+		req.AddGenerationPrompt = &addGenerationPrompt
+	}
+
+	content, failed := templateRequest(ctx, c, e, e.streaming, req)
+	if failed != nil {
+		return *failed
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "content is empty",
+		}
+	}
+
+	if strings.Contains(strings.ToLower(content), "prime numbers are") {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodePrefillRestarted,
+			Message:  fmt.Sprintf("response %q repeats the prefill %q instead of continuing it", content, prefillText),
+		}
+	}
+
+	if first := firstNumberPattern.FindString(content); first != "5" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodePrefillNotContinued,
+			Message:  fmt.Sprintf("response %q does not continue the prefill %q with 5", content, prefillText),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
@@ -0,0 +1,326 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// templateMessage is a chat message as sent to /apply-template. Maps
+// rather than client.Message let a variation set a field to its zero
+// value, such as an empty reasoning_content, instead of omitting it.
+type templateMessage map[string]any
+
+// Expected effects of a template variation on the rendered prompt.
+const (
+	// expectVerbatim means the variation adds text to a message, which
+	// should appear in the prompt exactly as added.
+	expectVerbatim = "verbatim"
+	// expectSame means the variation should not change the prompt.
+	expectSame = "same"
+	// expectAny means templates differ legitimately in how they render
+	// the variation, so any rendering is reported without a finding.
+	expectAny = "any"
+)
+
+// templateVariation is a systematic change to a base conversation.
+type templateVariation struct {
+	name        string
+	description string
+	expect      string
+	// base returns the conversation the variation changes.
+	base func() []templateMessage
+	// vary changes a copy of the base conversation in place.
+	vary func(messages []templateMessage)
+}
+
+// TemplateVariation is how a variation of a conversation changed the
+// prompt the server's chat template rendered from it.
+type TemplateVariation struct {
+	Name        string
+	Description string
+	// Changed reports whether the prompt differed from the base
+	// conversation's.
+	Changed bool
+	// Verbatim reports whether the prompt differed only by the text the
+	// variation added to a message.
+	Verbatim bool
+	// Finding describes how the rendering departs from what the
+	// variation should do, or is empty if it does not.
+	Finding string
+	// Diff holds the base and varied prompts, if they differ.
+	Diff *Diff
+}
+
+// Analysis conversations. Each variation edits one of them.
+func chatConversation() []templateMessage {
+	return []templateMessage{
+		{"role": "system", "content": "You are a helpful assistant."},
+		{"role": "user", "content": "What is 2 + 2?"},
+		{"role": "assistant", "content": "2 + 2 = 4."},
+		{"role": "user", "content": "And 3 + 3?"},
+	}
+}
+
+func reasoningConversation() []templateMessage {
+	messages := chatConversation()
+	messages[2]["reasoning_content"] = "The user asks for a sum of two small numbers."
+	return messages
+}
+
+func toolConversation() []templateMessage {
+	return []templateMessage{
+		{"role": "user", "content": "What is the weather in Paris?"},
+		{"role": "assistant", "tool_calls": []any{map[string]any{
+			"id":   "call_1",
+			"type": "function",
+			"function": map[string]any{
+				"name":      "get_weather",
+				"arguments": `{"city":"Paris"}`,
+			},
+		}}},
+		{"role": "tool", "tool_call_id": "call_1", "content": "Sunny, 22 C"},
+	}
+}
+
+// appendContent returns a vary function adding suffix to the string field
+// of the ith message.
+func appendContent(i int, field, suffix string) func([]templateMessage) {
+	return func(messages []templateMessage) {
+		messages[i][field] = messages[i][field].(string) + suffix
+	}
+}
+
+// prependContent returns a vary function adding prefix to the content of
+// the ith message.
+func prependContent(i int, prefix string) func([]templateMessage) {
+	return func(messages []templateMessage) {
+		messages[i]["content"] = prefix + messages[i]["content"].(string)
+	}
+}
+
+// setField returns a vary function setting a field of the ith message.
+func setField(i int, field string, value any) func([]templateMessage) {
+	return func(messages []templateMessage) {
+		messages[i][field] = value
+	}
+}
+
+// templateVariations are the variations template analyze renders.
+var templateVariations = []templateVariation{
+	{
+		name:        "system_trailing_newline",
+		description: "trailing newline in the system message",
+		expect:      expectVerbatim,
+		base:        chatConversation,
+		vary:        appendContent(0, "content", "\n"),
+	},
+	{
+		name:        "user_trailing_newline",
+		description: "trailing newline in the last user message",
+		expect:      expectVerbatim,
+		base:        chatConversation,
+		vary:        appendContent(3, "content", "\n"),
+	},
+	{
+		name:        "user_leading_newline",
+		description: "leading newline in the last user message",
+		expect:      expectVerbatim,
+		base:        chatConversation,
+		vary:        prependContent(3, "\n"),
+	},
+	{
+		name:        "user_trailing_spaces",
+		description: "trailing spaces in the last user message",
+		expect:      expectVerbatim,
+		base:        chatConversation,
+		vary:        appendContent(3, "content", "  "),
+	},
+	{
+		name:        "assistant_trailing_newline",
+		description: "trailing newline in an assistant message",
+		expect:      expectVerbatim,
+		base:        chatConversation,
+		vary:        appendContent(2, "content", "\n"),
+	},
+	{
+		name:        "assistant_leading_newline",
+		description: "leading newline in an assistant message",
+		expect:      expectVerbatim,
+		base:        chatConversation,
+		vary:        prependContent(2, "\n"),
+	},
+	{
+		name:        "empty_reasoning",
+		description: "empty reasoning_content rather than none in an assistant message",
+		expect:      expectSame,
+		base:        chatConversation,
+		vary:        setField(2, "reasoning_content", ""),
+	},
+	{
+		name:        "reasoning_trailing_newline",
+		description: "trailing newline in an assistant message's reasoning_content",
+		expect:      expectAny,
+		base:        reasoningConversation,
+		vary:        appendContent(2, "reasoning_content", "\n"),
+	},
+	{
+		name:        "tool_call_empty_content",
+		description: "empty content rather than none in an assistant message with tool calls",
+		expect:      expectSame,
+		base:        toolConversation,
+		vary:        setField(1, "content", ""),
+	},
+	{
+		name:        "tool_call_null_content",
+		description: "null content rather than none in an assistant message with tool calls",
+		expect:      expectSame,
+		base:        toolConversation,
+		vary:        setField(1, "content", nil),
+	},
+	{
+		name:        "tool_result_trailing_newline",
+		description: "trailing newline in a tool result",
+		expect:      expectVerbatim,
+		base:        toolConversation,
+		vary:        appendContent(2, "content", "\n"),
+	},
+}
+
+// AnalyzeTemplate renders conversations through /apply-template with
+// systematic whitespace and empty-field variations, reporting how each
+// variation changed the prompt. Evals whose names do not match filter are
+// skipped. It fails only if the server cannot render a base conversation.
+func AnalyzeTemplate(ctx context.Context, c *client.Client, filter string) ([]TemplateVariation, error) {
+	type rendering struct {
+		prompt string
+		err    error
+	}
+	bases := map[string]rendering{}
+
+	var results []TemplateVariation
+	for _, v := range templateVariations {
+		if !NameMatches(v.name, filter) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		base := v.base()
+		key := mustMarshal(base)
+		r, ok := bases[key]
+		if !ok {
+			r.prompt, r.err = c.ApplyTemplateRaw(ctx, json.RawMessage(key))
+			bases[key] = r
+		}
+		if r.err != nil {
+			return results, fmt.Errorf("render base conversation of %s: %w", v.name, r.err)
+		}
+
+		varied := copyMessages(base)
+		v.vary(varied)
+		result := TemplateVariation{Name: v.name, Description: v.description}
+		prompt, err := c.ApplyTemplateRaw(ctx, json.RawMessage(mustMarshal(varied)))
+		if err != nil {
+			result.Finding = "rendering failed: " + err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		result.Changed = prompt != r.prompt
+		if result.Changed {
+			result.Diff = &Diff{ALabel: "base", BLabel: v.name, A: r.prompt, B: prompt}
+			result.Verbatim = insertedOnly(r.prompt, prompt, addedText(base, varied))
+		}
+		result.Finding = templateFinding(v.expect, result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// templateFinding describes how a rendering departs from the expected
+// effect of its variation.
+func templateFinding(expect string, r TemplateVariation) string {
+	switch expect {
+	case expectVerbatim:
+		if !r.Changed {
+			return "the template strips the added whitespace"
+		}
+		if !r.Verbatim {
+			return "the prompt changed beyond the added whitespace"
+		}
+	case expectSame:
+		if r.Changed {
+			return "the prompt changed, though the message means the same"
+		}
+	}
+	return ""
+}
+
+// addedText returns the text a variation added to a message's string
+// field, or "" if it changed no string field by adding text.
+func addedText(base, varied []templateMessage) string {
+	for i := range base {
+		for field, v := range varied[i] {
+			after, ok := v.(string)
+			if !ok {
+				continue
+			}
+			before, _ := base[i][field].(string)
+			if after == before || len(after) <= len(before) {
+				continue
+			}
+			if strings.HasPrefix(after, before) {
+				return after[len(before):]
+			}
+			if strings.HasSuffix(after, before) {
+				return after[:len(after)-len(before)]
+			}
+		}
+	}
+	return ""
+}
+
+// insertedOnly reports whether b is a with added inserted at one position.
+func insertedOnly(a, b, added string) bool {
+	if added == "" || len(b) != len(a)+len(added) {
+		return false
+	}
+	// a[:i] == b[:i] holds throughout, since the insertion cannot start
+	// past the first difference
+	for i := 0; i <= len(a); i++ {
+		if b[i:i+len(added)] == added && a[i:] == b[i+len(added):] {
+			return true
+		}
+		if i < len(a) && a[i] != b[i] {
+			return false
+		}
+	}
+	return false
+}
+
+// copyMessages returns a copy of messages whose maps can be changed
+// without changing the originals.
+func copyMessages(messages []templateMessage) []templateMessage {
+	out := make([]templateMessage, len(messages))
+	for i, m := range messages {
+		out[i] = make(templateMessage, len(m))
+		for k, v := range m {
+			out[i][k] = v
+		}
+	}
+	return out
+}
+
+// mustMarshal encodes messages built from literals, which cannot fail.
+func mustMarshal(messages []templateMessage) string {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
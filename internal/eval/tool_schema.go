@@ -0,0 +1,338 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/jsonschema"
+)
+
+// shipmentSchema nests objects three deep, with enums, integer ranges, and
+// string patterns at the leaves.
+var shipmentSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"sender": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"street": {"type": "string", "minLength": 1},
+				"city": {"type": "string", "minLength": 1},
+				"state": {"type": "string", "pattern": "^[A-Z]{2}$", "description": "Two-letter state code"},
+				"postal_code": {"type": "string", "pattern": "^[0-9]{5}$"},
+				"country": {"type": "string", "enum": ["US", "CA", "MX"]}
+			},
+			"required": ["name", "street", "city", "state", "postal_code", "country"],
+			"additionalProperties": false
+		},
+		"recipient": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"street": {"type": "string", "minLength": 1},
+				"city": {"type": "string", "minLength": 1},
+				"state": {"type": "string", "pattern": "^[A-Z]{2}$", "description": "Two-letter state code"},
+				"postal_code": {"type": "string", "pattern": "^[0-9]{5}$"},
+				"country": {"type": "string", "enum": ["US", "CA", "MX"]}
+			},
+			"required": ["name", "street", "city", "state", "postal_code", "country"],
+			"additionalProperties": false
+		},
+		"package": {
+			"type": "object",
+			"properties": {
+				"weight_grams": {"type": "integer", "minimum": 1, "maximum": 30000},
+				"dimensions_cm": {
+					"type": "object",
+					"properties": {
+						"length": {"type": "integer", "minimum": 1, "maximum": 150},
+						"width": {"type": "integer", "minimum": 1, "maximum": 150},
+						"height": {"type": "integer", "minimum": 1, "maximum": 150}
+					},
+					"required": ["length", "width", "height"],
+					"additionalProperties": false
+				},
+				"contents": {
+					"type": "object",
+					"properties": {
+						"description": {"type": "string", "minLength": 1},
+						"category": {"type": "string", "enum": ["documents", "electronics", "clothing", "other"]},
+						"declared_value_usd": {"type": "number", "minimum": 0}
+					},
+					"required": ["description", "category", "declared_value_usd"],
+					"additionalProperties": false
+				}
+			},
+			"required": ["weight_grams", "dimensions_cm", "contents"],
+			"additionalProperties": false
+		},
+		"service": {"type": "string", "enum": ["ground", "express", "overnight"]},
+		"signature_required": {"type": "boolean"}
+	},
+	"required": ["sender", "recipient", "package", "service", "signature_required"],
+	"additionalProperties": false
+}`)
+
+var shipmentValidator = jsonschema.MustCompile(shipmentSchema)
+
+// orderSchema holds an array of objects with optional fields, patterns,
+// and bounded integers.
+var orderSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"customer_id": {"type": "string", "pattern": "^C[0-9]{4}$"},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"maxItems": 10,
+			"items": {
+				"type": "object",
+				"properties": {
+					"sku": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]{3}$"},
+					"quantity": {"type": "integer", "minimum": 1, "maximum": 99},
+					"size": {"type": "string", "enum": ["S", "M", "L", "XL"], "description": "Only for clothing"},
+					"gift_wrap": {"type": "boolean"}
+				},
+				"required": ["sku", "quantity"],
+				"additionalProperties": false
+			}
+		},
+		"priority": {"type": "string", "enum": ["low", "normal", "high"]}
+	},
+	"required": ["customer_id", "items", "priority"],
+	"additionalProperties": false
+}`)
+
+var orderValidator = jsonschema.MustCompile(orderSchema)
+
+// nestedSchemaToolCallEval checks that tool call arguments for a schema of
+// objects nested three deep validate against it, and carry the values
+// asked for.
+type nestedSchemaToolCallEval struct {
+	streaming bool
+}
+
+func (e *nestedSchemaToolCallEval) Name() string {
+	return "nested_schema_tool_call"
+}
+
+func (e *nestedSchemaToolCallEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *nestedSchemaToolCallEval) Streaming() bool             { return e.streaming }
+
+func (e *nestedSchemaToolCallEval) Category() string {
+	return toolCategory
+}
+
+func (e *nestedSchemaToolCallEval) Class() string {
+	return ClassStandard
+}
+
+func (e *nestedSchemaToolCallEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{
+				Role: "user",
+				Content: `Ship a laptop from Maria Lopez, 42 Elm Street, Austin, TX 78701, USA to Ken Tanaka, 9 Harbor Road, Seattle, WA 98101, USA.
+The box weighs 2500 grams and measures 40 x 30 x 10 cm. Declared value is $1200. Send it express and require a signature on delivery.`,
+			},
+		},
+		Tools: []client.Tool{
+			{
+				Type: "function",
+				Function: client.ToolFunction{
+					Name:        "create_shipment",
+					Description: "Create a shipment between two addresses",
+					Parameters:  shipmentSchema,
+				},
+			},
+		},
+		ToolChoice: "auto",
+	}
+
+	return runSchemaToolCallEval(ctx, c, e, e.streaming, req, shipmentValidator, func(args map[string]any) string {
+		pkg, _ := args["package"].(map[string]any)
+		contents, _ := pkg["contents"].(map[string]any)
+		switch {
+		case args["service"] != "express":
+			return fmt.Sprintf("service is %v, expected express", args["service"])
+		case args["signature_required"] != true:
+			return "signature_required is false, expected true"
+		case pkg["weight_grams"] != 2500.0:
+			return fmt.Sprintf("package.weight_grams is %v, expected 2500", pkg["weight_grams"])
+		case contents["category"] != "electronics":
+			return fmt.Sprintf("package.contents.category is %v, expected electronics", contents["category"])
+		}
+		return ""
+	})
+}
+
+// arraySchemaToolCallEval checks that tool call arguments holding an array
+// of objects, some with optional fields, validate against their schema and
+// list the items asked for.
+type arraySchemaToolCallEval struct {
+	streaming bool
+}
+
+func (e *arraySchemaToolCallEval) Name() string {
+	return "array_schema_tool_call"
+}
+
+func (e *arraySchemaToolCallEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *arraySchemaToolCallEval) Streaming() bool             { return e.streaming }
+
+func (e *arraySchemaToolCallEval) Category() string {
+	return toolCategory
+}
+
+func (e *arraySchemaToolCallEval) Class() string {
+	return ClassStandard
+}
+
+func (e *arraySchemaToolCallEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{
+				Role: "user",
+				Content: `Place an order for customer C1042 at normal priority:
+- 2 of TSH-101 in size M
+- 1 of TSH-102 in size XL, gift wrapped
+- 3 of MUG-200 (mugs have no size)`,
+			},
+		},
+		Tools: []client.Tool{
+			{
+				Type: "function",
+				Function: client.ToolFunction{
+					Name:        "create_order",
+					Description: "Place an order of one or more items for a customer",
+					Parameters:  orderSchema,
+				},
+			},
+		},
+		ToolChoice: "auto",
+	}
+
+	want := map[string]float64{"TSH-101": 2, "TSH-102": 1, "MUG-200": 3}
+	return runSchemaToolCallEval(ctx, c, e, e.streaming, req, orderValidator, func(args map[string]any) string {
+		items, _ := args["items"].([]any)
+		if len(items) != len(want) {
+			return fmt.Sprintf("items has %d entries, expected %d", len(items), len(want))
+		}
+		for i, it := range items {
+			item, _ := it.(map[string]any)
+			sku, _ := item["sku"].(string)
+			quantity, ok := want[sku]
+			if !ok {
+				return fmt.Sprintf("items[%d].sku is %s, which was not ordered", i, sku)
+			}
+			if item["quantity"] != quantity {
+				return fmt.Sprintf("items[%d] (%s) has quantity %v, expected %g", i, sku, item["quantity"], quantity)
+			}
+		}
+		return ""
+	})
+}
+
+// runSchemaToolCallEval sends req, which offers one tool, and checks that
+// the first tool call names it and that its arguments validate against
+// schema. check then inspects the decoded arguments, describing a wrong
+// value or returning "".
+func runSchemaToolCallEval(ctx context.Context, c *client.Client, e Eval, streaming bool, req client.ChatCompletionRequest, schema *jsonschema.Schema, check func(args map[string]any) string) Result {
+	var toolCalls []client.ToolCall
+	if streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		toolCalls = result.ToolCalls
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		if len(resp.Choices) == 0 {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeNoChoices,
+				Message:  "no choices in response",
+			}
+		}
+		toolCalls = resp.Choices[0].Message.ToolCalls
+	}
+
+	if len(toolCalls) == 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeToolCallMissing,
+			Message:  "expected tool call, got none",
+		}
+	}
+
+	tc := toolCalls[0]
+	name := req.Tools[0].Function.Name
+	if tc.Function.Name != name {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeToolCallWrongName,
+			Message:  fmt.Sprintf("expected tool name '%s', got '%s'", name, tc.Function.Name),
+		}
+	}
+
+	var args any
+	if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeToolCallArgsInvalid,
+			Message:  "tool arguments are not valid JSON: " + err.Error(),
+		}
+	}
+
+	if err := validateToolArgs(schema, args); err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     err.code,
+			Message:  "arguments do not match the schema: " + err.Error(),
+		}
+	}
+
+	// The schema requires an object, so args is one
+	if msg := check(args.(map[string]any)); msg != "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeToolCallArgsValue,
+			Message:  msg,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
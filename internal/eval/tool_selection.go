@@ -0,0 +1,230 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// Tool selection sampling: the eval passes if at least
+// toolSelectionThreshold of toolSelectionSamples responses, sampled at
+// toolSelectionTemperature, call the correct tool.
+const (
+	toolSelectionSamples     = 10
+	toolSelectionThreshold   = 0.8
+	toolSelectionTemperature = 1.0
+)
+
+// toolSelectionPrompt is answered by toolSelectionTools' get_stock_price,
+// though each distractor is plausible for part of it.
+const toolSelectionPrompt = "What is NVIDIA's stock trading at right now on NASDAQ?"
+
+// toolSelectionCorrect is the tool that answers toolSelectionPrompt.
+const toolSelectionCorrect = "get_stock_price"
+
+// noToolCall labels samples that answered without calling a tool.
+const noToolCall = "(no tool call)"
+
+// toolSelectionTools lists the correct tool among distractors that share
+// its vocabulary: companies, markets, prices, and the web.
+var toolSelectionTools = []client.Tool{
+	selectionTool("search_web", "Search the web and return the top results for a query",
+		`{"type": "object", "properties": {"query": {"type": "string"}}, "required": ["query"]}`),
+	selectionTool("get_company_profile", "Get a public company's profile: sector, headquarters, CEO, and employee count",
+		`{"type": "object", "properties": {"company": {"type": "string"}}, "required": ["company"]}`),
+	selectionTool("get_crypto_price", "Get the current price of a cryptocurrency in US dollars",
+		`{"type": "object", "properties": {"symbol": {"type": "string", "description": "Coin symbol, e.g. BTC"}}, "required": ["symbol"]}`),
+	selectionTool(toolSelectionCorrect, "Get the latest traded price of a stock listed on an exchange",
+		`{"type": "object", "properties": {"ticker": {"type": "string", "description": "Stock ticker, e.g. AAPL"}, "exchange": {"type": "string"}}, "required": ["ticker"]}`),
+	selectionTool("get_exchange_rate", "Get the current exchange rate between two currencies",
+		`{"type": "object", "properties": {"from": {"type": "string"}, "to": {"type": "string"}}, "required": ["from", "to"]}`),
+	selectionTool("get_market_news", "Get the latest financial news headlines about a company or market",
+		`{"type": "object", "properties": {"topic": {"type": "string"}}, "required": ["topic"]}`),
+}
+
+// selectionTool returns a function tool.
+func selectionTool(name, description, parameters string) client.Tool {
+	return client.Tool{
+		Type: "function",
+		Function: client.ToolFunction{
+			Name:        name,
+			Description: description,
+			Parameters:  json.RawMessage(parameters),
+		},
+	}
+}
+
+// toolSelectionEval samples the same request, offering the correct tool
+// among plausible distractors, many times at temperature 1. A single sample
+// passes or fails by luck as often as by the template's rendering of the
+// tools, so the eval scores how reliably the correct tool is chosen, and
+// records how often each tool was.
+type toolSelectionEval struct {
+	streaming bool
+}
+
+func (e *toolSelectionEval) Name() string {
+	return "tool_selection_distractors"
+}
+
+func (e *toolSelectionEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *toolSelectionEval) Streaming() bool             { return e.streaming }
+
+func (e *toolSelectionEval) Category() string {
+	return toolCategory
+}
+
+func (e *toolSelectionEval) Class() string {
+	return ClassStandard
+}
+
+func (e *toolSelectionEval) Tags() []string {
+	return []string{TagSlow}
+}
+
+func (e *toolSelectionEval) Run(ctx context.Context, c *client.Client) Result {
+	temperature := toolSelectionTemperature
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: toolSelectionPrompt},
+		},
+		Tools:       toolSelectionTools,
+		ToolChoice:  "auto",
+		Temperature: &temperature,
+	}
+
+	counts := make(map[string]int)
+	var wrong []Sample
+	for range toolSelectionSamples {
+		content, toolCalls, failed := e.send(ctx, c, req)
+		if failed != nil {
+			return *failed
+		}
+
+		chosen := noToolCall
+		if len(toolCalls) > 0 {
+			chosen = toolCalls[0].Function.Name
+		}
+		counts[chosen]++
+
+		switch {
+		case chosen == noToolCall:
+			wrong = append(wrong, Sample{
+				Prompt:  toolSelectionPrompt,
+				Code:    CodeToolCallMissing,
+				Message: "answered without calling a tool",
+				Output:  content,
+			})
+		case chosen != toolSelectionCorrect:
+			wrong = append(wrong, Sample{
+				Prompt:  toolSelectionPrompt,
+				Code:    CodeToolCallWrongName,
+				Message: fmt.Sprintf("called %s instead of %s", chosen, toolSelectionCorrect),
+				Output:  toolCalls[0].Function.Name + "(" + toolCalls[0].Function.Arguments + ")",
+			})
+		}
+	}
+
+	rate := float64(counts[toolSelectionCorrect]) / toolSelectionSamples
+	scores := map[string]float64{
+		"selection_rate": rate,
+		"samples":        toolSelectionSamples,
+	}
+	// Record the share of samples choosing each tool, for comparing
+	// servers and templates
+	for name, n := range counts {
+		key := "chose_" + name
+		if name == noToolCall {
+			key = "chose_none"
+		}
+		scores[key] = float64(n) / toolSelectionSamples
+	}
+	distribution := "chosen: " + selectionDistribution(counts)
+
+	if rate < toolSelectionThreshold {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeToolSelectionUnreliable,
+			Message: fmt.Sprintf("%s chosen in %d/%d samples, below the required %.0f%% (%s)",
+				toolSelectionCorrect, counts[toolSelectionCorrect], toolSelectionSamples, toolSelectionThreshold*100, distribution),
+			Scores:  scores,
+			Samples: wrong,
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message: fmt.Sprintf("%s chosen in %d/%d samples (%s)",
+			toolSelectionCorrect, counts[toolSelectionCorrect], toolSelectionSamples, distribution),
+		Scores:  scores,
+		Samples: wrong,
+	}
+}
+
+// send sends one sample, returning its content and tool calls, or a failed
+// result.
+func (e *toolSelectionEval) send(ctx context.Context, c *client.Client, req client.ChatCompletionRequest) (string, []client.ToolCall, *Result) {
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return "", nil, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		return result.Content, result.ToolCalls, nil
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return "", nil, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	if len(resp.Choices) == 0 {
+		return "", nil, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeNoChoices,
+			Message:  "no choices in response",
+		}
+	}
+	return resp.Choices[0].Message.Content, resp.Choices[0].Message.ToolCalls, nil
+}
+
+// selectionDistribution formats how many samples chose each tool, most
+// chosen first.
+func selectionDistribution(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, counts[name])
+	}
+	return strings.Join(parts, ", ")
+}
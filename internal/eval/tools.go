@@ -3,9 +3,11 @@ package eval
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/jsonschema"
 )
 
 const toolCategory = "Tool Calling"
@@ -19,6 +21,11 @@ func toolEvals() []Eval {
 		&requiredToolCallWithReasoningEval{},
 		&complexSchemaToolCallEval{},
 		&codeGenerationToolCallEval{},
+		&toolCallIDRoundTripEval{},
+		&streamingToolCallDeltasEval{},
+		&nestedSchemaToolCallEval{},
+		&arraySchemaToolCallEval{},
+		&toolSelectionEval{},
 	}
 }
 
@@ -42,6 +49,10 @@ func (e *singleToolCallEval) Class() string {
 	return ClassStandard
 }
 
+func (e *singleToolCallEval) Tags() []string {
+	return []string{TagTools}
+}
+
 func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
@@ -78,6 +89,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -89,6 +101,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -97,6 +110,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -109,6 +123,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallMissing,
 			Message:  "expected tool call, got none",
 		}
 	}
@@ -118,6 +133,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallCount,
 			Message:  "expected 1 tool call, got multiple",
 		}
 	}
@@ -130,6 +146,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallWrongName,
 			Message:  "expected tool name 'get_weather', got '" + tc.Function.Name + "'",
 		}
 	}
@@ -141,6 +158,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallArgsInvalid,
 			Message:  "tool arguments are not valid JSON: " + err.Error(),
 		}
 	}
@@ -151,6 +169,7 @@ func (e *singleToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallArgsMissing,
 			Message:  "tool arguments missing 'location' parameter",
 		}
 	}
@@ -182,6 +201,10 @@ func (e *parallelToolCallEval) Class() string {
 	return ClassStandard
 }
 
+func (e *parallelToolCallEval) Tags() []string {
+	return []string{TagTools}
+}
+
 func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
@@ -219,6 +242,7 @@ func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -230,6 +254,7 @@ func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -238,6 +263,7 @@ func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -250,6 +276,7 @@ func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallCount,
 			Message:  "expected at least 2 tool calls for parallel execution, got " + string(rune('0'+len(toolCalls))),
 		}
 	}
@@ -261,6 +288,7 @@ func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeToolCallWrongName,
 				Message:  "tool call " + string(rune('0'+i)) + " has wrong name: " + tc.Function.Name,
 			}
 		}
@@ -271,6 +299,7 @@ func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeToolCallArgsInvalid,
 				Message:  "tool call " + string(rune('0'+i)) + " has invalid JSON arguments",
 			}
 		}
@@ -280,6 +309,7 @@ func (e *parallelToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeToolCallArgsMissing,
 				Message:  "tool call " + string(rune('0'+i)) + " missing 'location' parameter",
 			}
 		}
@@ -312,6 +342,10 @@ func (e *requiredToolCallEval) Class() string {
 	return ClassStandard
 }
 
+func (e *requiredToolCallEval) Tags() []string {
+	return []string{TagTools}
+}
+
 func (e *requiredToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
@@ -348,6 +382,7 @@ func (e *requiredToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -359,6 +394,7 @@ func (e *requiredToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -367,6 +403,7 @@ func (e *requiredToolCallEval) Run(ctx context.Context, c *client.Client) Result
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -379,6 +416,7 @@ func (e *requiredToolCallEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallMissing,
 			Message:  "expected tool call with required tool_choice, got none",
 		}
 	}
@@ -391,6 +429,7 @@ func (e *requiredToolCallEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallWrongName,
 			Message:  "expected tool name 'get_weather', got '" + tc.Function.Name + "'",
 		}
 	}
@@ -402,6 +441,7 @@ func (e *requiredToolCallEval) Run(ctx context.Context, c *client.Client) Result
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallArgsInvalid,
 			Message:  "tool arguments are not valid JSON: " + err.Error(),
 		}
 	}
@@ -434,6 +474,10 @@ func (e *requiredToolCallWithReasoningEval) Class() string {
 	return ClassReasoning
 }
 
+func (e *requiredToolCallWithReasoningEval) Tags() []string {
+	return []string{TagTools}
+}
+
 func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.Client) Result {
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
@@ -471,6 +515,7 @@ func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.C
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -483,6 +528,7 @@ func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.C
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeRequestFailed,
 				Message:  "request failed: " + err.Error(),
 			}
 		}
@@ -491,6 +537,7 @@ func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.C
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
+				Code:     CodeNoChoices,
 				Message:  "no choices in response",
 			}
 		}
@@ -504,6 +551,7 @@ func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.C
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallMissing,
 			Message:  "expected tool call with required tool_choice, got none",
 		}
 	}
@@ -516,6 +564,7 @@ func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.C
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallWrongName,
 			Message:  "expected tool name 'get_weather', got '" + tc.Function.Name + "'",
 		}
 	}
@@ -527,6 +576,7 @@ func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.C
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeToolCallArgsInvalid,
 			Message:  "tool arguments are not valid JSON: " + err.Error(),
 		}
 	}
@@ -537,6 +587,7 @@ func (e *requiredToolCallWithReasoningEval) Run(ctx context.Context, c *client.C
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
+			Code:     CodeReasoningEmpty,
 			Message:  "reasoning_content is empty - constrained decoding may be suppressing reasoning",
 		}
 	}
@@ -570,6 +621,10 @@ func (e *complexSchemaToolCallEval) Class() string {
 	return ClassStandard
 }
 
+func (e *complexSchemaToolCallEval) Tags() []string {
+	return []string{TagTools}
+}
+
 // complexCateringSchema defines a realistic event catering tool schema with:
 // - Nested objects (venue with address, contact info)
 // - Arrays of complex objects (guests with dietary info)
@@ -710,6 +765,8 @@ const complexCateringSchema = `{
 	"required": ["event", "venue", "guests", "budget"]
 }`
 
+var complexCateringValidator = jsonschema.MustCompile([]byte(complexCateringSchema))
+
 func (e *complexSchemaToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
@@ -739,216 +796,12 @@ Our budget is $450 USD including tip. Please note that we'll need the room set u
 		ToolChoice: "auto",
 	}
 
-	var toolCalls []client.ToolCall
-
-	if e.streaming {
-		result, err := c.ChatCompletionStream(ctx, req)
-		if err != nil {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "request failed: " + err.Error(),
-			}
-		}
-		toolCalls = result.ToolCalls
-	} else {
-		resp, err := c.ChatCompletion(ctx, req)
-		if err != nil {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "request failed: " + err.Error(),
-			}
+	return runSchemaToolCallEval(ctx, c, e, e.streaming, req, complexCateringValidator, func(args map[string]any) string {
+		if guests, _ := args["guests"].([]any); len(guests) != 3 {
+			return fmt.Sprintf("expected 3 guests, got %d", len(guests))
 		}
-		if len(resp.Choices) == 0 {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "no choices in response",
-			}
-		}
-		toolCalls = resp.Choices[0].Message.ToolCalls
-	}
-
-	// Verify we got a tool call
-	if len(toolCalls) == 0 {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "expected tool call, got none",
-		}
-	}
-
-	tc := toolCalls[0]
-
-	// Verify tool name
-	if tc.Function.Name != "create_catering_request" {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "expected tool name 'create_catering_request', got '" + tc.Function.Name + "'",
-		}
-	}
-
-	// Parse and validate the complex nested structure
-	var args map[string]any
-	if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "tool arguments are not valid JSON: " + err.Error(),
-		}
-	}
-
-	// Validate top-level required fields exist
-	for _, field := range []string{"event", "venue", "guests", "budget"} {
-		if _, ok := args[field]; !ok {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "missing required top-level field: " + field,
-			}
-		}
-	}
-
-	// Validate nested event object
-	event, ok := args["event"].(map[string]any)
-	if !ok {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "event field is not an object",
-		}
-	}
-	for _, field := range []string{"name", "event_type", "date"} {
-		if _, ok := event[field]; !ok {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "event missing required field: " + field,
-			}
-		}
-	}
-
-	// Validate nested venue object with address
-	venue, ok := args["venue"].(map[string]any)
-	if !ok {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "venue field is not an object",
-		}
-	}
-	if _, ok := venue["name"]; !ok {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "venue missing required field: name",
-		}
-	}
-	address, ok := venue["address"].(map[string]any)
-	if !ok {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "venue.address field is not an object",
-		}
-	}
-	for _, field := range []string{"street", "city", "state"} {
-		if _, ok := address[field]; !ok {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "venue.address missing required field: " + field,
-			}
-		}
-	}
-
-	// Validate guests array
-	guests, ok := args["guests"].([]any)
-	if !ok {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "guests field is not an array",
-		}
-	}
-	if len(guests) != 3 {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "expected 3 guests, got " + string(rune('0'+len(guests))),
-		}
-	}
-
-	// Validate each guest has required fields
-	for i, g := range guests {
-		guest, ok := g.(map[string]any)
-		if !ok {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "guest " + string(rune('0'+i)) + " is not an object",
-			}
-		}
-		if _, ok := guest["name"]; !ok {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "guest " + string(rune('0'+i)) + " missing required field: name",
-			}
-		}
-		if _, ok := guest["dietary_restrictions"]; !ok {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "guest " + string(rune('0'+i)) + " missing required field: dietary_restrictions",
-			}
-		}
-	}
-
-	// Validate budget object
-	budget, ok := args["budget"].(map[string]any)
-	if !ok {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "budget field is not an object",
-		}
-	}
-	if _, ok := budget["total_amount"]; !ok {
-		return Result{
-			Name:     e.Name(),
-			Category: e.Category(),
-			Passed:   false,
-			Message:  "budget missing required field: total_amount",
-		}
-	}
-
-	return Result{
-		Name:     e.Name(),
-		Category: e.Category(),
-		Passed:   true,
-	}
+		return ""
+	})
 }
 
 // codeGenerationToolCallEval tests tool calling with long-form text output.
@@ -973,6 +826,10 @@ func (e *codeGenerationToolCallEval) Class() string {
 	return ClassStandard
 }
 
+func (e *codeGenerationToolCallEval) Tags() []string {
+	return []string{TagTools}
+}
+
 const codeGenerationSchema = `{
 	"type": "object",
 	"properties": {
@@ -1000,6 +857,8 @@ const codeGenerationSchema = `{
 	"required": ["language", "filename", "description", "code", "usage_example"]
 }`
 
+var codeGenerationValidator = jsonschema.MustCompile([]byte(codeGenerationSchema))
+
 func (e *codeGenerationToolCallEval) Run(ctx context.Context, c *client.Client) Result {
 	req := client.ChatCompletionRequest{
 		Messages: []client.Message{
@@ -1030,27 +889,105 @@ The code should be complete and production-ready.`,
 		ToolChoice: "auto",
 	}
 
+	return runSchemaToolCallEval(ctx, c, e, e.streaming, req, codeGenerationValidator, func(args map[string]any) string {
+		// Validate code is non-trivial (at least 500 chars for a proper implementation)
+		code := args["code"].(string)
+		if len(code) < 500 {
+			return "code appears incomplete (less than 500 characters)"
+		}
+
+		// Check for key implementation markers
+		requiredPatterns := []string{
+			"class TokenBucket",
+			"def acquire",
+			"def wait_for_token",
+			"Lock",
+		}
+		for _, pattern := range requiredPatterns {
+			if !strings.Contains(code, pattern) {
+				return "code missing expected pattern: " + pattern
+			}
+		}
+
+		if example := args["usage_example"].(string); len(strings.TrimSpace(example)) < 20 {
+			return "usage_example is too short"
+		}
+		return ""
+	})
+}
+
+// toolCallIDRoundTripEval verifies that tool call IDs can be relied on to
+// answer tool calls: each is non-empty and unique among parallel calls,
+// streamed IDs do not change between chunks, and a turn answering each
+// call by its ID is accepted. The answering turn is sent in the other
+// mode, so that IDs from streamed responses work in blocking requests and
+// vice versa.
+type toolCallIDRoundTripEval struct {
+	streaming bool
+}
+
+func (e *toolCallIDRoundTripEval) Name() string {
+	return "tool_call_id_roundtrip"
+}
+
+func (e *toolCallIDRoundTripEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *toolCallIDRoundTripEval) Streaming() bool             { return e.streaming }
+
+func (e *toolCallIDRoundTripEval) Category() string {
+	return toolCategory
+}
+
+func (e *toolCallIDRoundTripEval) Class() string {
+	return ClassStandard
+}
+
+func (e *toolCallIDRoundTripEval) Tags() []string {
+	return []string{TagTools, TagMultiTurn}
+}
+
+func (e *toolCallIDRoundTripEval) Run(ctx context.Context, c *client.Client) Result {
+	question := client.Message{Role: "user", Content: "What's the weather in both San Francisco and New York?"}
+	req1 := client.ChatCompletionRequest{
+		Messages:          []client.Message{question},
+		Tools:             []client.Tool{weatherTool},
+		ToolChoice:        "auto",
+		ParallelToolCalls: true,
+	}
+
 	var toolCalls []client.ToolCall
+	var reasoningContent string
 
 	if e.streaming {
-		result, err := c.ChatCompletionStream(ctx, req)
+		result, err := c.ChatCompletionStream(ctx, req1)
 		if err != nil {
 			return Result{
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
-				Message:  "request failed: " + err.Error(),
+				Code:     CodeRequestFailed,
+				Message:  "turn 1 request failed: " + err.Error(),
+			}
+		}
+		if msg := unstableToolCallID(result.Chunks); msg != "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeToolCallIDUnstable,
+				Message:  "turn 1: " + msg,
 			}
 		}
 		toolCalls = result.ToolCalls
+		reasoningContent = result.ReasoningContent
 	} else {
-		resp, err := c.ChatCompletion(ctx, req)
+		resp, err := c.ChatCompletion(ctx, req1)
 		if err != nil {
 			return Result{
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
-				Message:  "request failed: " + err.Error(),
+				Code:     CodeRequestFailed,
+				Message:  "turn 1 request failed: " + err.Error(),
 			}
 		}
 		if len(resp.Choices) == 0 {
@@ -1058,104 +995,213 @@ The code should be complete and production-ready.`,
 				Name:     e.Name(),
 				Category: e.Category(),
 				Passed:   false,
-				Message:  "no choices in response",
+				Code:     CodeNoChoices,
+				Message:  "turn 1: no choices in response",
 			}
 		}
 		toolCalls = resp.Choices[0].Message.ToolCalls
+		reasoningContent = resp.Choices[0].Message.ReasoningContent
 	}
 
-	// Verify we got a tool call
 	if len(toolCalls) == 0 {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "expected tool call, got none",
+			Code:     CodeToolCallMissing,
+			Message:  "turn 1: expected tool calls, got none",
 		}
 	}
 
-	tc := toolCalls[0]
+	// Uniqueness is only checked if the model made parallel calls;
+	// parallel_tool_calls checks that it does
+	seen := make(map[string]int)
+	for i, tc := range toolCalls {
+		if tc.ID == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeToolCallIDMissing,
+				Message:  fmt.Sprintf("turn 1: tool call %d (%s) has no ID", i+1, tc.Function.Name),
+			}
+		}
+		if j, ok := seen[tc.ID]; ok {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeToolCallIDDuplicate,
+				Message:  fmt.Sprintf("turn 1: tool calls %d and %d share ID %q", j+1, i+1, tc.ID),
+			}
+		}
+		seen[tc.ID] = i
+	}
 
-	// Verify tool name
-	if tc.Function.Name != "save_code" {
+	// Turn 2: answer every call by its ID, in the other mode
+	messages := []client.Message{
+		question,
+		{
+			Role:             "assistant",
+			ReasoningContent: reasoningContent,
+			ToolCalls:        toolCalls,
+		},
+	}
+	for i, tc := range toolCalls {
+		messages = append(messages, client.Message{
+			Role:       "tool",
+			ToolCallID: tc.ID,
+			Content:    fmt.Sprintf(`{"temperature": %d, "conditions": "sunny"}`, 60+i*10),
+		})
+	}
+	req2 := client.ChatCompletionRequest{
+		Messages:   messages,
+		Tools:      []client.Tool{weatherTool},
+		ToolChoice: "auto",
+	}
+
+	otherMode := "blocking"
+	if !e.streaming {
+		otherMode = "streaming"
+	}
+
+	var content string
+	var err error
+	if e.streaming {
+		var resp *client.ChatCompletionResponse
+		resp, err = c.ChatCompletion(ctx, req2)
+		if err == nil {
+			if len(resp.Choices) == 0 {
+				return Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeNoChoices,
+					Message:  "turn 2: no choices in response",
+				}
+			}
+			content = resp.Choices[0].Message.Content
+		}
+	} else {
+		var result *client.StreamResult
+		result, err = c.ChatCompletionStream(ctx, req2)
+		if err == nil {
+			content = result.Content
+		}
+	}
+	if err != nil {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "expected tool name 'save_code', got '" + tc.Function.Name + "'",
+			Code:     CodeToolCallIDRejected,
+			Message:  fmt.Sprintf("turn 2 (%s) answering %d tool calls by ID failed: %v", otherMode, len(toolCalls), err),
 		}
 	}
 
-	// Parse arguments
-	var args map[string]any
-	if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+	if strings.TrimSpace(content) == "" {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "tool arguments are not valid JSON: " + err.Error(),
+			Code:     CodeContentEmpty,
+			Message:  fmt.Sprintf("turn 2 (%s): expected content in response, got empty", otherMode),
 		}
 	}
 
-	// Validate required fields
-	for _, field := range []string{"language", "filename", "description", "code", "usage_example"} {
-		if _, ok := args[field]; !ok {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "missing required field: " + field,
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("%d tool call IDs round-tripped into a %s turn", len(toolCalls), otherMode),
+	}
+}
+
+// unstableToolCallID describes the first tool call of a stream's first
+// choice whose ID changed between chunks, or returns "" if none did.
+// Chunks after the first may omit the ID, but must not change it.
+func unstableToolCallID(chunks []client.ChatCompletionChunk) string {
+	ids := make(map[int]string)
+	for i, chunk := range chunks {
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			for _, d := range choice.Delta.ToolCalls {
+				if d.ID == "" {
+					continue
+				}
+				if prev, ok := ids[d.Index]; ok && prev != d.ID {
+					return fmt.Sprintf("tool call %d changed ID from %q to %q in chunk %d", d.Index+1, prev, d.ID, i+1)
+				}
+				ids[d.Index] = d.ID
 			}
 		}
 	}
+	return ""
+}
+
+// streamingToolCallDeltasEval checks the chunk protocol of streamed tool
+// calls as OpenAI streams them: the first delta of each call carries its
+// id, type, and function name, later deltas carry only argument fragments,
+// and calls are indexed contiguously from 0. Clients that assemble calls by
+// index, such as the OpenAI SDKs, mishandle streams that repeat the header
+// or skip an index, even though the assembled calls look the same.
+type streamingToolCallDeltasEval struct{}
+
+func (e *streamingToolCallDeltasEval) Name() string {
+	return "streaming_tool_call_deltas"
+}
+
+func (e *streamingToolCallDeltasEval) Category() string {
+	return toolCategory
+}
+
+func (e *streamingToolCallDeltasEval) Class() string {
+	return ClassStandard
+}
+
+// IsStreamingOnly returns true because only streams have deltas.
+func (e *streamingToolCallDeltasEval) IsStreamingOnly() bool {
+	return true
+}
 
-	// Validate code is non-trivial (at least 500 chars for a proper implementation)
-	code, ok := args["code"].(string)
-	if !ok {
+func (e *streamingToolCallDeltasEval) Run(ctx context.Context, c *client.Client) Result {
+	result, err := c.ChatCompletionStream(ctx, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "What's the weather in both San Francisco and New York?"},
+		},
+		Tools:             []client.Tool{weatherTool},
+		ToolChoice:        "auto",
+		ParallelToolCalls: true,
+	})
+	if err != nil {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "code field is not a string",
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
 		}
 	}
 
-	if len(code) < 500 {
+	if len(result.ToolCalls) == 0 {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "code appears incomplete (less than 500 characters)",
+			Code:     CodeToolCallMissing,
+			Message:  "expected tool calls, got none",
 		}
 	}
 
-	// Check for key implementation markers
-	requiredPatterns := []string{
-		"class TokenBucket",
-		"def acquire",
-		"def wait_for_token",
-		"Lock",
-	}
-
-	for _, pattern := range requiredPatterns {
-		if !strings.Contains(code, pattern) {
-			return Result{
-				Name:     e.Name(),
-				Category: e.Category(),
-				Passed:   false,
-				Message:  "code missing expected pattern: " + pattern,
-			}
-		}
-	}
-
-	// Validate usage_example is non-empty
-	example, ok := args["usage_example"].(string)
-	if !ok || len(strings.TrimSpace(example)) < 20 {
+	if code, msg := checkToolCallDeltas(result.Chunks); code != "" {
 		return Result{
 			Name:     e.Name(),
 			Category: e.Category(),
 			Passed:   false,
-			Message:  "usage_example is missing or too short",
+			Code:     code,
+			Message:  msg,
 		}
 	}
 
@@ -1163,5 +1209,62 @@ The code should be complete and production-ready.`,
 		Name:     e.Name(),
 		Category: e.Category(),
 		Passed:   true,
+		Message:  fmt.Sprintf("%d tool calls streamed following the delta protocol", len(result.ToolCalls)),
+	}
+}
+
+// checkToolCallDeltas checks the tool call deltas of a stream's first
+// choice, returning a failure code and message for the first deviation
+// from the delta protocol, or "" if there is none.
+func checkToolCallDeltas(chunks []client.ChatCompletionChunk) (code, msg string) {
+	started := make(map[int]bool)
+	for i, chunk := range chunks {
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			for _, d := range choice.Delta.ToolCalls {
+				if started[d.Index] {
+					var repeated []string
+					if d.ID != "" {
+						repeated = append(repeated, "id")
+					}
+					if d.Type != "" {
+						repeated = append(repeated, "type")
+					}
+					if d.Function.Name != "" {
+						repeated = append(repeated, "name")
+					}
+					if len(repeated) > 0 {
+						return CodeToolCallDeltaRepeated, fmt.Sprintf("chunk %d: tool call %d repeated its %s after its first delta",
+							i+1, d.Index, strings.Join(repeated, ", "))
+					}
+					continue
+				}
+
+				// A new call must take the next index
+				if d.Index != len(started) {
+					return CodeToolCallDeltaIndex, fmt.Sprintf("chunk %d: tool call started at index %d, expected %d",
+						i+1, d.Index, len(started))
+				}
+				started[d.Index] = true
+
+				var missing []string
+				if d.ID == "" {
+					missing = append(missing, "id")
+				}
+				if d.Type == "" {
+					missing = append(missing, "type")
+				}
+				if d.Function.Name == "" {
+					missing = append(missing, "name")
+				}
+				if len(missing) > 0 {
+					return CodeToolCallDeltaHeader, fmt.Sprintf("chunk %d: first delta of tool call %d has no %s",
+						i+1, d.Index, strings.Join(missing, ", "))
+				}
+			}
+		}
 	}
+	return "", ""
 }
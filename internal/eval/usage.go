@@ -0,0 +1,377 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const usageCategory = "Usage"
+
+// usageEvals returns all usage accounting evals.
+func usageEvals() []Eval {
+	return []Eval{
+		&usagePresentEval{},
+		&usageNotRequestedEval{},
+		&usageTotalsEval{},
+		&usageTokenCountEval{},
+	}
+}
+
+// usageRequest is a short prompt used by the usage evals.
+func usageRequest() client.ChatCompletionRequest {
+	return client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Say hello."},
+		},
+	}
+}
+
+// fetchUsage sends req and returns the reported usage. In streaming mode,
+// usage is requested via stream_options.include_usage and must arrive in the
+// final chunk. On failure it returns a non-nil Result.
+func fetchUsage(ctx context.Context, c *client.Client, e Eval, streaming bool) (*client.Usage, *Result) {
+	req := usageRequest()
+
+	if streaming {
+		req.StreamOptions = &client.StreamOptions{IncludeUsage: true}
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			return nil, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeRequestFailed,
+				Message:  "request failed: " + err.Error(),
+			}
+		}
+		if result.Usage == nil {
+			return nil, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeUsageMissing,
+				Message:  "no chunk carried usage despite stream_options.include_usage",
+			}
+		}
+		if last := result.Chunks[len(result.Chunks)-1]; last.Usage == nil {
+			return nil, &Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeUsageNotFinal,
+				Message:  "usage was sent before the final chunk",
+			}
+		}
+		return result.Usage, nil
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return nil, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	if resp.Usage == nil {
+		return nil, &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUsageMissing,
+			Message:  "response has no usage",
+		}
+	}
+	return resp.Usage, nil
+}
+
+// usagePresentEval verifies that usage is reported: always for non-streaming
+// responses, and in the final chunk when streaming with include_usage.
+type usagePresentEval struct {
+	streaming bool
+}
+
+func (e *usagePresentEval) Name() string {
+	return "usage_present"
+}
+
+func (e *usagePresentEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *usagePresentEval) Streaming() bool             { return e.streaming }
+
+func (e *usagePresentEval) Category() string {
+	return usageCategory
+}
+
+func (e *usagePresentEval) Class() string {
+	return ClassStandard
+}
+
+func (e *usagePresentEval) Run(ctx context.Context, c *client.Client) Result {
+	usage, failed := fetchUsage(ctx, c, e, e.streaming)
+	if failed != nil {
+		return *failed
+	}
+
+	if usage.PromptTokens == 0 || usage.CompletionTokens == 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUsageMissing,
+			Message:  fmt.Sprintf("usage has zero token counts (prompt_tokens=%d, completion_tokens=%d)", usage.PromptTokens, usage.CompletionTokens),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// usageNotRequestedEval verifies that a stream does not carry usage when
+// stream_options.include_usage is not set.
+type usageNotRequestedEval struct{}
+
+func (e *usageNotRequestedEval) Name() string {
+	return "usage_not_requested"
+}
+
+func (e *usageNotRequestedEval) Category() string {
+	return usageCategory
+}
+
+func (e *usageNotRequestedEval) Class() string {
+	return ClassStandard
+}
+
+func (e *usageNotRequestedEval) IsStreamingOnly() bool {
+	return true
+}
+
+func (e *usageNotRequestedEval) Run(ctx context.Context, c *client.Client) Result {
+	req := usageRequest()
+	// A non-nil, empty StreamOptions stops the client from requesting usage
+	req.StreamOptions = &client.StreamOptions{}
+
+	result, err := c.ChatCompletionStream(ctx, req)
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	for i, chunk := range result.Chunks {
+		if chunk.Usage != nil {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeUsageUnexpected,
+				Message:  fmt.Sprintf("chunk %d carried usage without stream_options.include_usage", i),
+			}
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// usageTotalsEval verifies that total_tokens equals prompt_tokens plus
+// completion_tokens.
+type usageTotalsEval struct {
+	streaming bool
+}
+
+func (e *usageTotalsEval) Name() string {
+	return "usage_totals"
+}
+
+func (e *usageTotalsEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *usageTotalsEval) Streaming() bool             { return e.streaming }
+
+func (e *usageTotalsEval) Category() string {
+	return usageCategory
+}
+
+func (e *usageTotalsEval) Class() string {
+	return ClassStandard
+}
+
+func (e *usageTotalsEval) Run(ctx context.Context, c *client.Client) Result {
+	usage, failed := fetchUsage(ctx, c, e, e.streaming)
+	if failed != nil {
+		return *failed
+	}
+
+	if usage.TotalTokens != usage.PromptTokens+usage.CompletionTokens {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUsageMismatch,
+			Message: fmt.Sprintf("total_tokens %d != prompt_tokens %d + completion_tokens %d",
+				usage.TotalTokens, usage.PromptTokens, usage.CompletionTokens),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// usageCountTolerance is the fraction by which completion_tokens may differ
+// from a count of the generated text before the accounting is taken for a
+// defect. usageCountSlack tokens are allowed on top, for end-of-sequence
+// and template tokens and for text that tokenizes differently on its own.
+const (
+	usageCountTolerance = 0.25
+	usageCountSlack     = 5
+)
+
+// usageTokenCountEval cross-checks streamed usage.completion_tokens against
+// the number of chunks carrying content or reasoning and, where the server
+// can tokenize text, against the token count of the generated text. Each
+// chunk carries at least one token, so more chunks than tokens means usage
+// undercounts; servers may batch tokens into chunks, so fewer chunks is
+// only noted.
+type usageTokenCountEval struct{}
+
+func (e *usageTokenCountEval) Name() string {
+	return "usage_token_count"
+}
+
+func (e *usageTokenCountEval) Category() string {
+	return usageCategory
+}
+
+func (e *usageTokenCountEval) Class() string {
+	return ClassStandard
+}
+
+func (e *usageTokenCountEval) IsStreamingOnly() bool {
+	return true
+}
+
+func (e *usageTokenCountEval) Run(ctx context.Context, c *client.Client) Result {
+	result, err := c.ChatCompletionStream(ctx, client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Count from 1 to 40 in words, separated by commas."},
+		},
+		MaxTokens:     400,
+		StreamOptions: &client.StreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	if result.Usage == nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUsageMissing,
+			Message:  "no chunk carried usage despite stream_options.include_usage",
+		}
+	}
+
+	chunks := 0
+	for _, chunk := range result.Chunks {
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 && (choice.Delta.Content != "" || choice.Delta.ReasoningContent != "") {
+				chunks++
+			}
+		}
+	}
+	if chunks == 0 {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeContentEmpty,
+			Message:  "no chunk carried content or reasoning",
+		}
+	}
+
+	completion := result.Usage.CompletionTokens
+	tokenized := -1
+	if tokens, err := c.Tokenize(ctx, result.ReasoningContent+result.Content); err == nil {
+		tokenized = len(tokens)
+	}
+
+	counts := fmt.Sprintf("completion_tokens=%d, content chunks=%d, /tokenize=%s", completion, chunks, tokenCount(tokenized))
+	if chunks > completion+usageCountSlack {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUsageTokenCount,
+			Message:  "usage counts fewer completion tokens than chunks carrying text: " + counts,
+		}
+	}
+	if tokenized >= 0 && !withinTolerance(completion, tokenized) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUsageTokenCount,
+			Message:  "usage disagrees with the token count of the generated text: " + counts,
+		}
+	}
+
+	var notes []string
+	if tokenized < 0 {
+		notes = append(notes, "token count of the generated text not checked, since /tokenize failed")
+	}
+	if !withinTolerance(completion, chunks) {
+		notes = append(notes, "the server batches tokens into chunks: "+counts)
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Scores: map[string]float64{
+			"completion_tokens": float64(completion),
+			"content_chunks":    float64(chunks),
+		},
+		Notes: notes,
+	}
+}
+
+// withinTolerance returns true if a reported token count is close enough
+// to a count of the generated text.
+func withinTolerance(reported, counted int) bool {
+	diff := reported - counted
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff) <= usageCountTolerance*float64(counted)+usageCountSlack
+}
+
+// tokenCount formats a token count, which is negative if unavailable.
+func tokenCount(n int) string {
+	if n < 0 {
+		return "unavailable"
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,185 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	evallog "github.com/aldehir/llm-serving-tests/internal/log"
+)
+
+// User-Agents compared with the suite's own. Gateways most often single
+// out the OpenAI SDK, to which they tailor responses.
+const (
+	openAISDKUserAgent = "OpenAI/Python 1.54.0"
+	curlUserAgent      = "curl/8.5.0"
+	// goUserAgent is sent when the client sets none.
+	goUserAgent = "Go-http-client/1.1"
+)
+
+// userAgentEval verifies that the server answers the same request the same
+// way whatever the User-Agent. Some gateways route, filter, or reshape
+// responses by client, so that results measured with this suite would not
+// hold for the clients that use the server.
+type userAgentEval struct {
+	streaming bool
+}
+
+func (e *userAgentEval) Name() string {
+	return "user_agent_invariance"
+}
+
+func (e *userAgentEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *userAgentEval) Streaming() bool             { return e.streaming }
+
+func (e *userAgentEval) Category() string {
+	return compatCategory
+}
+
+func (e *userAgentEval) Class() string {
+	return ClassStandard
+}
+
+// userAgentResponse is what the server answered with one User-Agent.
+type userAgentResponse struct {
+	userAgent string
+	content   string
+	model     string
+	shape     []string
+	err       error
+}
+
+func (e *userAgentEval) Run(ctx context.Context, c *client.Client) Result {
+	suiteUA := c.UserAgent()
+	if suiteUA == "" {
+		suiteUA = goUserAgent
+	}
+	otherUA := openAISDKUserAgent
+	if suiteUA == otherUA {
+		otherUA = curlUserAgent
+	}
+
+	a := e.send(ctx, c, suiteUA)
+	b := e.send(ctx, c, otherUA)
+
+	switch {
+	case a.err != nil && b.err != nil:
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + a.err.Error(),
+		}
+	case a.err != nil || b.err != nil:
+		failed, succeeded := a, b
+		if b.err != nil {
+			failed, succeeded = b, a
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUserAgentDependent,
+			Message:  fmt.Sprintf("request with User-Agent %q failed (%v), but succeeded with %q", failed.userAgent, failed.err, succeeded.userAgent),
+		}
+	}
+
+	if a.model != b.model {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUserAgentDependent,
+			Message:  fmt.Sprintf("answered by model %q with User-Agent %q, but by %q with %q", a.model, a.userAgent, b.model, b.userAgent),
+		}
+	}
+
+	if lost, gained := shapeDiff(a.shape, b.shape); len(lost) > 0 || len(gained) > 0 {
+		var changes []string
+		if len(lost) > 0 {
+			changes = append(changes, "lacked "+shapeList(lost))
+		}
+		if len(gained) > 0 {
+			changes = append(changes, "added "+shapeList(gained))
+		}
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeUserAgentDependent,
+			Message:  fmt.Sprintf("response to User-Agent %q %s, compared to %q", b.userAgent, strings.Join(changes, " and "), a.userAgent),
+		}
+	}
+
+	result := Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+	// Greedy decoding is not deterministic on every server, so different
+	// text alone does not show that the User-Agent mattered
+	if a.content != b.content {
+		result.Notes = []string{fmt.Sprintf("content differed between User-Agents %q and %q at temperature 0", a.userAgent, b.userAgent)}
+		result.Diffs = []Diff{{
+			ALabel: a.userAgent,
+			BLabel: b.userAgent,
+			A:      a.content,
+			B:      b.content,
+		}}
+	}
+	return result
+}
+
+// send sends the seeded greedy request with a User-Agent, capturing the
+// shape of the response alongside the eval's log.
+func (e *userAgentEval) send(ctx context.Context, c *client.Client, userAgent string) userAgentResponse {
+	capture := &responseCapture{}
+	var logger evallog.RequestLogger = capture
+	if l := c.Logger(); l != nil {
+		logger = teeLogger{l, capture}
+	}
+	c = c.WithUserAgent(userAgent).WithLogger(logger)
+
+	temperature := 0.0
+	seed := 42
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Greet a new user of a note-taking app in one short sentence."},
+		},
+		Temperature: &temperature,
+		Seed:        &seed,
+		MaxTokens:   64,
+	}
+
+	r := userAgentResponse{userAgent: userAgent}
+	if e.streaming {
+		result, err := c.ChatCompletionStream(ctx, req)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		r.content = result.Content
+		if len(result.Chunks) > 0 {
+			r.model = result.Chunks[0].Model
+		}
+	} else {
+		resp, err := c.ChatCompletion(ctx, req)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		if len(resp.Choices) == 0 {
+			r.err = errors.New("no choices in response")
+			return r
+		}
+		r.content = resp.Choices[0].Message.Content
+		r.model = resp.Model
+	}
+	if len(capture.shapes) > 0 {
+		r.shape = capture.shapes[0]
+	}
+	return r
+}
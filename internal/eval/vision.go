@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const visionCategory = "Vision"
+
+// Colors of the shapes in test images.
+var (
+	imageRed  = color.RGBA{R: 220, G: 20, B: 20, A: 255}
+	imageBlue = color.RGBA{R: 20, G: 60, B: 220, A: 255}
+)
+
+// imageSize is the width and height of test images, in pixels.
+const imageSize = 128
+
+// insideCircle reports whether a pixel at an offset from the image's
+// center lies in a centered circle.
+func insideCircle(dx, dy int) bool {
+	const radius = 40
+	return dx*dx+dy*dy <= radius*radius
+}
+
+// insideSquare reports whether a pixel at an offset from the image's
+// center lies in a centered square.
+func insideSquare(dx, dy int) bool {
+	const half = 36
+	return dx >= -half && dx < half && dy >= -half && dy < half
+}
+
+// shapeImageURL returns a data URL of a PNG image of a shape in the given
+// color on a white background, the shape being the pixels, by offset from
+// the center, for which inside returns true.
+func shapeImageURL(fill color.RGBA, inside func(dx, dy int) bool) string {
+	img := image.NewRGBA(image.Rect(0, 0, imageSize, imageSize))
+	for y := range imageSize {
+		for x := range imageSize {
+			if inside(x-imageSize/2, y-imageSize/2) {
+				img.Set(x, y, fill)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// blueSquareURL returns a data URL of a blue square on a white background.
+var blueSquareURL = sync.OnceValue(func() string {
+	return shapeImageURL(imageBlue, insideSquare)
+})
+
+// describesBlueSquare reports whether a response names the color and shape
+// of the blue square image.
+func describesBlueSquare(content string) bool {
+	lower := strings.ToLower(content)
+	return strings.Contains(lower, "blue") && (strings.Contains(lower, "square") || strings.Contains(lower, "rectangle") || strings.Contains(lower, "box"))
+}
+
+// visionEvals returns all vision evals.
+func visionEvals() []Eval {
+	return []Eval{
+		&visionDescribeEval{},
+		&visionMultiImageEval{},
+	}
+}
+
+// visionDescribeEval verifies that the model describes an image sent as an
+// image_url content part of a user message, as a base64 data URL.
+type visionDescribeEval struct {
+	streaming bool
+}
+
+func (e *visionDescribeEval) Name() string {
+	return "vision_describe"
+}
+
+func (e *visionDescribeEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *visionDescribeEval) Streaming() bool             { return e.streaming }
+
+func (e *visionDescribeEval) Category() string {
+	return visionCategory
+}
+
+func (e *visionDescribeEval) Class() string {
+	return ClassStandard
+}
+
+func (e *visionDescribeEval) RequiresVision() bool {
+	return true
+}
+
+func (e *visionDescribeEval) Run(ctx context.Context, c *client.Client) Result {
+	content, failed := sendVision(ctx, c, e, e.streaming, []client.ContentPart{
+		{Type: "text", Text: "What are the color and shape in this image? Answer in one short sentence."},
+		{Type: "image_url", ImageURL: &client.ImageURL{URL: screenshotURL()}},
+	})
+	if failed != nil {
+		return *failed
+	}
+
+	if !describesScreenshot(content) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeVisionUnsupported,
+			Message:  fmt.Sprintf("expected a description of a red circle, got %q", content),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// visionMultiImageEval verifies that the model tells apart two images in
+// one message: asked about the second, it must not describe the first.
+// Servers that map image placeholders to the wrong embeddings, or keep only
+// one image, fail it.
+type visionMultiImageEval struct {
+	streaming bool
+}
+
+func (e *visionMultiImageEval) Name() string {
+	return "vision_multi_image"
+}
+
+func (e *visionMultiImageEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *visionMultiImageEval) Streaming() bool             { return e.streaming }
+
+func (e *visionMultiImageEval) Category() string {
+	return visionCategory
+}
+
+func (e *visionMultiImageEval) Class() string {
+	return ClassStandard
+}
+
+func (e *visionMultiImageEval) RequiresVision() bool {
+	return true
+}
+
+func (e *visionMultiImageEval) Run(ctx context.Context, c *client.Client) Result {
+	content, failed := sendVision(ctx, c, e, e.streaming, []client.ContentPart{
+		{Type: "text", Text: "Here are two images."},
+		{Type: "image_url", ImageURL: &client.ImageURL{URL: screenshotURL()}},
+		{Type: "image_url", ImageURL: &client.ImageURL{URL: blueSquareURL()}},
+		{Type: "text", Text: "What are the color and shape in the second image only? Answer in one short sentence."},
+	})
+	if failed != nil {
+		return *failed
+	}
+
+	if describesBlueSquare(content) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   true,
+		}
+	}
+	if describesScreenshot(content) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeVisionImageMixup,
+			Message:  fmt.Sprintf("asked about the second image (a blue square), described the first (a red circle): %q", content),
+		}
+	}
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   false,
+		Code:     CodeVisionUnsupported,
+		Message:  fmt.Sprintf("expected a description of a blue square, got %q", content),
+	}
+}
+
+// sendVision sends a user message of the given content parts and returns
+// the response's content. On failure, it returns a non-nil Result; a 4xx
+// response fails with CodeVisionRejected.
+func sendVision(ctx context.Context, c *client.Client, e Eval, streaming bool, parts []client.ContentPart) (string, *Result) {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{{Role: "user", ContentParts: parts}},
+	}
+
+	var content string
+	var err error
+	if streaming {
+		var result *client.StreamResult
+		result, err = c.ChatCompletionStream(ctx, req)
+		if err == nil {
+			content = result.Content
+		}
+	} else {
+		var resp *client.ChatCompletionResponse
+		resp, err = c.ChatCompletion(ctx, req)
+		if err == nil {
+			if len(resp.Choices) == 0 {
+				return "", &Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeNoChoices,
+					Message:  "no choices in response",
+				}
+			}
+			content = resp.Choices[0].Message.Content
+		}
+	}
+
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError {
+		msg := errorMessage(statusErr.Body)
+		if msg == "" {
+			msg = statusErr.Body
+		}
+		return "", &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeVisionRejected,
+			Message:  fmt.Sprintf("request with an image rejected with status %d: %s", statusErr.StatusCode, msg),
+		}
+	}
+	if err != nil {
+		return "", &Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+	return content, nil
+}
@@ -0,0 +1,401 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+const vllmCategory = "vLLM"
+
+// vllmPhonePattern is the pattern sent as guided_regex.
+const vllmPhonePattern = `[0-9]{3}-[0-9]{3}-[0-9]{4}`
+
+// vllmColors are the options sent as guided_choice.
+var vllmColors = []string{"red", "green", "blue"}
+
+// vllmEvals returns all vLLM extension evals.
+func vllmEvals() []Eval {
+	return []Eval{
+		&vllmGuidedJSONEval{},
+		&vllmGuidedRegexEval{},
+		&vllmGuidedChoiceEval{},
+		&vllmBeamSearchEval{},
+	}
+}
+
+// vllmRequest sends req in the given mode and returns the content of the
+// first choice. On failure it returns a non-nil Result. A server that
+// rejects the extension field with a 4xx status and an error message does
+// not support it, which passes; a 5xx or an unexplained rejection fails.
+func vllmRequest(ctx context.Context, c *client.Client, e Eval, streaming bool, field string, req client.ChatCompletionRequest) (string, *Result) {
+	var content string
+	var err error
+
+	if streaming {
+		var result *client.StreamResult
+		result, err = c.ChatCompletionStream(ctx, req)
+		if err == nil {
+			content = result.Content
+		}
+	} else {
+		var resp *client.ChatCompletionResponse
+		resp, err = c.ChatCompletion(ctx, req)
+		if err == nil {
+			if len(resp.Choices) == 0 {
+				return "", &Result{
+					Name:     e.Name(),
+					Category: e.Category(),
+					Passed:   false,
+					Code:     CodeNoChoices,
+					Message:  "no choices in response",
+				}
+			}
+			content = resp.Choices[0].Message.Content
+		}
+	}
+
+	if err != nil {
+		result := vllmRejection(e, field, err)
+		return "", &result
+	}
+	return content, nil
+}
+
+// vllmRejection classifies a failed request using an extension field.
+func vllmRejection(e Eval, field string, err error) Result {
+	var statusErr *client.StatusError
+	if !errors.As(err, &statusErr) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeRequestFailed,
+			Message:  "request failed: " + err.Error(),
+		}
+	}
+
+	status := statusErr.StatusCode
+	if status < http.StatusBadRequest || status >= http.StatusInternalServerError {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeExtensionError,
+			Message:  fmt.Sprintf("%s caused status %d instead of a 4xx rejection: %s", field, status, statusErr.Body),
+		}
+	}
+
+	msg := errorMessage(statusErr.Body)
+	if msg == "" {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeExtensionError,
+			Message:  fmt.Sprintf("%s rejected with status %d but no error message: %q", field, status, statusErr.Body),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+		Message:  fmt.Sprintf("%s not supported (status %d): %s", field, status, msg),
+	}
+}
+
+// errorMessage extracts the message of an error response body, in either
+// the OpenAI form {"error": {"message": ...}} or the older vLLM form
+// {"object": "error", "message": ...}. It returns "" if there is none.
+func errorMessage(body string) string {
+	var resp struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		return ""
+	}
+	if resp.Message != "" {
+		return resp.Message
+	}
+
+	if apiErr, err := client.ParseAPIError([]byte(body)); err == nil {
+		return apiErr.Message
+	}
+	var plain string
+	if err := json.Unmarshal(resp.Error, &plain); err == nil {
+		return plain
+	}
+	return ""
+}
+
+// vllmGuidedJSONEval verifies that guided_json constrains the output to a
+// JSON schema.
+type vllmGuidedJSONEval struct {
+	streaming bool
+}
+
+func (e *vllmGuidedJSONEval) Name() string {
+	return "vllm_guided_json"
+}
+
+func (e *vllmGuidedJSONEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *vllmGuidedJSONEval) Streaming() bool             { return e.streaming }
+
+func (e *vllmGuidedJSONEval) Category() string {
+	return vllmCategory
+}
+
+func (e *vllmGuidedJSONEval) Class() string {
+	return ClassStandard
+}
+
+func (e *vllmGuidedJSONEval) Flavor() string {
+	return FlavorVLLM
+}
+
+func (e *vllmGuidedJSONEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Generate a fictional person with a name, age, and occupation."},
+		},
+		Extra: map[string]any{"guided_json": personSchema},
+	}
+
+	content, failed := vllmRequest(ctx, c, e, e.streaming, "guided_json", req)
+	if failed != nil {
+		return *failed
+	}
+
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeSchemaInvalidJSON,
+			Message:  "response is not valid JSON: " + err.Error(),
+		}
+	}
+
+	if err := validateSchema(personValidator, parsed); err != nil {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     err.code,
+			Message:  err.Error(),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// vllmGuidedRegexEval verifies that guided_regex constrains the output to
+// match a regular expression in full.
+type vllmGuidedRegexEval struct {
+	streaming bool
+}
+
+func (e *vllmGuidedRegexEval) Name() string {
+	return "vllm_guided_regex"
+}
+
+func (e *vllmGuidedRegexEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *vllmGuidedRegexEval) Streaming() bool             { return e.streaming }
+
+func (e *vllmGuidedRegexEval) Category() string {
+	return vllmCategory
+}
+
+func (e *vllmGuidedRegexEval) Class() string {
+	return ClassStandard
+}
+
+func (e *vllmGuidedRegexEval) Flavor() string {
+	return FlavorVLLM
+}
+
+func (e *vllmGuidedRegexEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Give me a phone number for a fictional business."},
+		},
+		MaxTokens: 64,
+		Extra:     map[string]any{"guided_regex": vllmPhonePattern},
+	}
+
+	content, failed := vllmRequest(ctx, c, e, e.streaming, "guided_regex", req)
+	if failed != nil {
+		return *failed
+	}
+
+	re := regexp.MustCompile(`^(?:` + vllmPhonePattern + `)$`)
+	if !re.MatchString(content) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeExtensionInvalid,
+			Message:  fmt.Sprintf("output %q does not match guided_regex %s", content, vllmPhonePattern),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// vllmGuidedChoiceEval verifies that guided_choice constrains the output to
+// exactly one of the given options.
+type vllmGuidedChoiceEval struct {
+	streaming bool
+}
+
+func (e *vllmGuidedChoiceEval) Name() string {
+	return "vllm_guided_choice"
+}
+
+func (e *vllmGuidedChoiceEval) SetStreaming(streaming bool) { e.streaming = streaming }
+func (e *vllmGuidedChoiceEval) Streaming() bool             { return e.streaming }
+
+func (e *vllmGuidedChoiceEval) Category() string {
+	return vllmCategory
+}
+
+func (e *vllmGuidedChoiceEval) Class() string {
+	return ClassStandard
+}
+
+func (e *vllmGuidedChoiceEval) Flavor() string {
+	return FlavorVLLM
+}
+
+func (e *vllmGuidedChoiceEval) Run(ctx context.Context, c *client.Client) Result {
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "What color is a ripe tomato?"},
+		},
+		MaxTokens: 16,
+		Extra:     map[string]any{"guided_choice": vllmColors},
+	}
+
+	content, failed := vllmRequest(ctx, c, e, e.streaming, "guided_choice", req)
+	if failed != nil {
+		return *failed
+	}
+
+	if !slices.Contains(vllmColors, content) {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeExtensionInvalid,
+			Message:  fmt.Sprintf("output %q is not one of guided_choice [%s]", content, strings.Join(vllmColors, ", ")),
+		}
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
+
+// vllmBeamSearchEval verifies that use_beam_search returns n distinct,
+// non-empty beams.
+type vllmBeamSearchEval struct{}
+
+func (e *vllmBeamSearchEval) Name() string {
+	return "vllm_beam_search"
+}
+
+func (e *vllmBeamSearchEval) Category() string {
+	return vllmCategory
+}
+
+func (e *vllmBeamSearchEval) Class() string {
+	return ClassStandard
+}
+
+func (e *vllmBeamSearchEval) Flavor() string {
+	return FlavorVLLM
+}
+
+// IsBlockingOnly returns true because vLLM does not stream beam search.
+func (e *vllmBeamSearchEval) IsBlockingOnly() bool {
+	return true
+}
+
+func (e *vllmBeamSearchEval) Run(ctx context.Context, c *client.Client) Result {
+	const beams = 2
+	temperature := 0.0
+	req := client.ChatCompletionRequest{
+		Messages: []client.Message{
+			{Role: "user", Content: "Suggest a name for a pet goldfish."},
+		},
+		N:           beams,
+		Temperature: &temperature,
+		MaxTokens:   32,
+		Extra:       map[string]any{"use_beam_search": true},
+	}
+
+	resp, err := c.ChatCompletion(ctx, req)
+	if err != nil {
+		return vllmRejection(e, "use_beam_search", err)
+	}
+
+	if len(resp.Choices) != beams {
+		return Result{
+			Name:     e.Name(),
+			Category: e.Category(),
+			Passed:   false,
+			Code:     CodeExtensionInvalid,
+			Message:  fmt.Sprintf("expected %d beams, got %d choices", beams, len(resp.Choices)),
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, choice := range resp.Choices {
+		content := choice.Message.Content
+		if strings.TrimSpace(content) == "" {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeContentEmpty,
+				Message:  fmt.Sprintf("beam %d is empty", i),
+			}
+		}
+		if seen[content] {
+			return Result{
+				Name:     e.Name(),
+				Category: e.Category(),
+				Passed:   false,
+				Code:     CodeExtensionInvalid,
+				Message:  fmt.Sprintf("beams are not distinct: %q returned twice", content),
+			}
+		}
+		seen[content] = true
+	}
+
+	return Result{
+		Name:     e.Name(),
+		Category: e.Category(),
+		Passed:   true,
+	}
+}
@@ -0,0 +1,223 @@
+// Package hygiene scans model outputs for text that should not be there:
+// PII, credentials, and leaked system prompts, matched by regex packs, or
+// whatever an external moderation endpoint flags. Findings are reported
+// apart from eval results, since no eval asks for them.
+package hygiene
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Output is one model output to scan.
+type Output struct {
+	// Text is the output's reasoning, content, and tool call arguments.
+	Text string
+	// System holds the system prompts of the request that produced Text.
+	System []string
+}
+
+// Finding is something a scanner flagged in an output.
+type Finding struct {
+	// Eval is the eval whose output it was, with its mode.
+	Eval    string `json:"eval"`
+	Scanner string `json:"scanner"`
+	// Kind is what was found, e.g. "email" or "aws_access_key".
+	Kind string `json:"kind"`
+	// Excerpt is the flagged text, masked where it may be sensitive.
+	Excerpt string `json:"excerpt"`
+}
+
+// Scanner flags text in outputs.
+type Scanner interface {
+	// Name names the scanner in findings, e.g. "pii".
+	Name() string
+	Scan(ctx context.Context, out Output) ([]Finding, error)
+}
+
+// SystemPromptPack is the built-in pack that flags leaked system prompts.
+const SystemPromptPack = "system-prompt"
+
+// Packs returns the names of the built-in packs.
+func Packs() []string {
+	names := []string{SystemPromptPack}
+	for name := range regexPacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewPack returns the built-in pack with the given name.
+func NewPack(name string) (Scanner, error) {
+	if name == SystemPromptPack {
+		return systemPromptScanner{}, nil
+	}
+	patterns, ok := regexPacks[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown pack %q (built-in: %s)", name, strings.Join(Packs(), ", "))
+	}
+	return &RegexScanner{name: name, patterns: patterns}, nil
+}
+
+// pattern is one kind of text a regex pack flags.
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+	// valid, if set, rejects matches that only look like the kind
+	valid func(match string) bool
+}
+
+// regexPacks are the built-in regex packs.
+var regexPacks = map[string][]pattern{
+	"credentials": {
+		{kind: "openai_key", re: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+		{kind: "aws_access_key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{kind: "github_token", re: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{kind: "private_key", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+		{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+		{kind: "bearer_token", re: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`)},
+	},
+	"pii": {
+		{kind: "email", re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`), valid: func(m string) bool {
+			return !exampleDomain.MatchString(m)
+		}},
+		{kind: "us_ssn", re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+		{kind: "phone_number", re: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?\(?\b\d{3}\)?[ .-]\d{3}[ .-]\d{4}\b`)},
+		{kind: "credit_card", re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhn},
+	},
+}
+
+// exampleDomain matches addresses at the reserved example domains, which
+// are placeholders rather than PII.
+var exampleDomain = regexp.MustCompile(`(?i)@(?:[a-z0-9-]+\.)*example\.(?:com|org|net)$`)
+
+// RegexScanner flags matches of a set of regular expressions.
+type RegexScanner struct {
+	name     string
+	patterns []pattern
+}
+
+// NewRegexScanner returns a scanner flagging matches of patterns, keyed by
+// the kind of text they match.
+func NewRegexScanner(name string, patterns map[string]string) (*RegexScanner, error) {
+	s := &RegexScanner{name: name}
+	kinds := make([]string, 0, len(patterns))
+	for kind := range patterns {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		re, err := regexp.Compile(patterns[kind])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: invalid pattern %q: %w", name, kind, patterns[kind], err)
+		}
+		s.patterns = append(s.patterns, pattern{kind: kind, re: re})
+	}
+	return s, nil
+}
+
+func (s *RegexScanner) Name() string {
+	return s.name
+}
+
+func (s *RegexScanner) Scan(ctx context.Context, out Output) ([]Finding, error) {
+	var findings []Finding
+	for _, p := range s.patterns {
+		for _, m := range p.re.FindAllString(out.Text, -1) {
+			if p.valid != nil && !p.valid(m) {
+				continue
+			}
+			findings = append(findings, Finding{Scanner: s.name, Kind: p.kind, Excerpt: mask(m)})
+		}
+	}
+	return findings, nil
+}
+
+// systemPromptMinLength is the shortest system prompt sentence whose
+// appearance in an output counts as a leak, so that short instructions
+// echoed in passing do not.
+const systemPromptMinLength = 24
+
+// systemPromptScanner flags outputs repeating a sentence of the request's
+// system prompts verbatim, ignoring case and spacing.
+type systemPromptScanner struct{}
+
+func (systemPromptScanner) Name() string {
+	return SystemPromptPack
+}
+
+func (systemPromptScanner) Scan(ctx context.Context, out Output) ([]Finding, error) {
+	text := normalizeSpace(out.Text)
+	var findings []Finding
+	for _, prompt := range out.System {
+		for _, sentence := range sentences(prompt) {
+			if len(sentence) >= systemPromptMinLength && strings.Contains(text, sentence) {
+				findings = append(findings, Finding{Scanner: SystemPromptPack, Kind: "system_prompt", Excerpt: truncate(sentence, 80)})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// sentenceEnd matches the punctuation and line breaks ending sentences.
+var sentenceEnd = regexp.MustCompile(`[.!?\n]+`)
+
+// sentences splits text into normalized sentences.
+func sentences(text string) []string {
+	parts := sentenceEnd.Split(text, -1)
+	var out []string
+	for _, p := range parts {
+		if p = normalizeSpace(p); p != "" && !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// normalizeSpace lowercases text and collapses its whitespace.
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// mask keeps the first four and last two characters of a match, which
+// identify it without repeating it.
+func mask(s string) string {
+	if len(s) <= 8 {
+		return "***"
+	}
+	return s[:4] + "***" + s[len(s)-2:]
+}
+
+// truncate shortens s to at most n bytes, marking the cut.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum of card
+// numbers.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
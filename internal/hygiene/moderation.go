@@ -0,0 +1,111 @@
+package hygiene
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ModerationPack is the pack name of the moderation endpoint scanner.
+const ModerationPack = "moderation"
+
+// moderationTimeout bounds each request to a moderation endpoint.
+const moderationTimeout = 30 * time.Second
+
+// ModerationScanner sends outputs to an OpenAI-compatible moderation
+// endpoint and flags the categories it reports.
+type ModerationScanner struct {
+	url    string
+	apiKey string
+	model  string
+	http   *http.Client
+}
+
+// NewModerationScanner returns a scanner posting to url, e.g.
+// https://api.openai.com/v1/moderations. An empty model uses the
+// endpoint's default.
+func NewModerationScanner(url, apiKey, model string) *ModerationScanner {
+	return &ModerationScanner{
+		url:    url,
+		apiKey: apiKey,
+		model:  model,
+		http:   &http.Client{Timeout: moderationTimeout},
+	}
+}
+
+func (s *ModerationScanner) Name() string {
+	return ModerationPack
+}
+
+func (s *ModerationScanner) Scan(ctx context.Context, out Output) ([]Finding, error) {
+	if out.Text == "" {
+		return nil, nil
+	}
+
+	reqBody, err := json.Marshal(struct {
+		Input string `json:"input"`
+		Model string `json:"model,omitempty"`
+	}{out.Text, s.model})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("moderation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read moderation response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("moderation endpoint returned status %d: %s", resp.StatusCode, body)
+	}
+
+	var result struct {
+		Results []struct {
+			Flagged    bool            `json:"flagged"`
+			Categories map[string]bool `json:"categories"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parse moderation response: %w", err)
+	}
+
+	var findings []Finding
+	for _, r := range result.Results {
+		if !r.Flagged {
+			continue
+		}
+		var categories []string
+		for category, flagged := range r.Categories {
+			if flagged {
+				categories = append(categories, category)
+			}
+		}
+		if len(categories) == 0 {
+			categories = []string{"flagged"}
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			findings = append(findings, Finding{Scanner: ModerationPack, Kind: category, Excerpt: truncate(out.Text, 80)})
+		}
+	}
+	return findings, nil
+}
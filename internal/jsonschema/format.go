@@ -0,0 +1,50 @@
+package jsonschema
+
+import (
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// timeRE matches an RFC 3339 full-time, e.g. 14:30:00Z or
+	// 14:30:00.5+02:00.
+	timeRE = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]:([0-5][0-9]|60)(\.[0-9]+)?([Zz]|[+-]([01][0-9]|2[0-3]):[0-5][0-9])$`)
+	uuidRE = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// matchesFormat reports whether s is a valid value of format. Unknown
+// formats match any string.
+func matchesFormat(format, s string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "time":
+		return timeRE.MatchString(s)
+	case "date-time":
+		date, clock, ok := strings.Cut(s, "T")
+		if !ok {
+			date, clock, ok = strings.Cut(s, "t")
+		}
+		return ok && matchesFormat("date", date) && timeRE.MatchString(clock)
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Name == "" && addr.Address == s
+	case "uuid":
+		return uuidRE.MatchString(s)
+	case "ipv4":
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is4()
+	case "ipv6":
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is6() && addr.Zone() == ""
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package jsonschema
+
+import "testing"
+
+func TestMatchesFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		want   bool
+	}{
+		{"date", "2024-02-29", true},
+		{"date", "2023-02-29", false},
+		{"date", "2024-2-1", false},
+		{"date", "2024-01-01T00:00:00Z", false},
+
+		{"time", "12:00:00Z", true},
+		{"time", "12:00:00.250+02:00", true},
+		{"time", "23:59:60z", true},
+		{"time", "12:00:00", false},
+		{"time", "24:00:00Z", false},
+		{"time", "12:00Z", false},
+
+		{"date-time", "2024-01-15T09:30:00Z", true},
+		{"date-time", "2024-01-15t09:30:00-05:00", true},
+		{"date-time", "2024-01-15 09:30:00Z", false},
+		{"date-time", "2024-01-15T09:30:00", false},
+		{"date-time", "2024-13-15T09:30:00Z", false},
+
+		{"email", "ada@example.com", true},
+		{"email", "Ada <ada@example.com>", false},
+		{"email", "ada.example.com", false},
+
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123E4567-E89B-12D3-A456-426614174000", true},
+		{"uuid", "123e4567e89b12d3a456426614174000", false},
+		{"uuid", "123e4567-e89b-12d3-a456-42661417400g", false},
+
+		{"ipv4", "192.168.0.1", true},
+		{"ipv4", "256.0.0.1", false},
+		{"ipv4", "::1", false},
+
+		{"ipv6", "2001:db8::1", true},
+		{"ipv6", "::ffff:192.0.2.1", true},
+		{"ipv6", "fe80::1%eth0", false},
+		{"ipv6", "192.0.2.1", false},
+
+		{"uri", "https://example.com/path?q=1", true},
+		{"uri", "urn:isbn:0451450523", true},
+		{"uri", "/relative/path", false},
+		{"uri", "", false},
+
+		{"hostname", "anything at all", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.value, func(t *testing.T) {
+			if got := matchesFormat(tt.format, tt.value); got != tt.want {
+				t.Errorf("matchesFormat(%q, %q) = %v, want %v", tt.format, tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,626 @@
+// Package jsonschema validates decoded JSON values against JSON Schema
+// (draft 2020-12), for checking structured output and tool call arguments
+// against the schemas sent to the server.
+//
+// It implements the validation keywords that constrain generated values:
+// type, enum, const, the object, array, string, and numeric keywords, the
+// allOf, anyOf, oneOf, not, and if/then/else applicators, boolean schemas,
+// and $ref to definitions within the same schema. Patterns are ECMA-262
+// regular expressions, translated to RE2. The formats that
+// grammar-constrained servers enforce (date, time, date-time, email, uuid,
+// ipv4, ipv6, and uri) are asserted; other formats and annotations such as
+// description are ignored. Compile rejects schemas using keywords it does
+// not implement, such as unevaluatedProperties, rather than accept values
+// they forbid.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+}
+
+// ValidationError describes the first way a value failed its schema.
+type ValidationError struct {
+	// Path locates the failing value, e.g. $.items[2].quantity.
+	Path string
+	// Keyword is the schema keyword that failed, e.g. "required".
+	Keyword string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Compile parses a JSON Schema document.
+func Compile(data []byte) (*Schema, error) {
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	s := &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.compile(root, "#"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MustCompile is like Compile but panics on error, for schemas defined in
+// code.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// unsupported lists keywords whose meaning this package does not
+// implement. A schema using them is rejected, since ignoring them would
+// accept values they forbid.
+var unsupported = []string{
+	"unevaluatedProperties",
+	"unevaluatedItems",
+	"$dynamicRef",
+	"$dynamicAnchor",
+	"$recursiveRef",
+	"$recursiveAnchor",
+}
+
+// Keywords whose values are subschemas, by the shape of their values.
+var (
+	schemaKeywords     = []string{"additionalProperties", "items", "contains", "propertyNames", "not", "if", "then", "else"}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "dependentSchemas", "$defs", "definitions"}
+)
+
+// compile checks a schema node, at the JSON pointer ptr, for keywords it
+// does not support and for $refs that do not resolve, and compiles its
+// regular expressions, so that broken schemas are reported once rather
+// than on each validation.
+func (s *Schema) compile(node any, ptr string) error {
+	n, ok := node.(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, kw := range unsupported {
+		if _, ok := n[kw]; ok {
+			return fmt.Errorf("%s: unsupported keyword %s", ptr, kw)
+		}
+	}
+	if items, ok := n["items"].([]any); ok {
+		return fmt.Errorf("%s: items as an array of %d schemas is not supported; use prefixItems", ptr, len(items))
+	}
+	if ref, ok := n["$ref"].(string); ok {
+		if _, err := s.resolve(ref); err != nil {
+			return fmt.Errorf("%s: %w", ptr, err)
+		}
+	}
+
+	var patterns []string
+	if p, ok := n["pattern"].(string); ok {
+		patterns = append(patterns, p)
+	}
+	if pp, ok := n["patternProperties"].(map[string]any); ok {
+		for p := range pp {
+			patterns = append(patterns, p)
+		}
+	}
+	for _, p := range patterns {
+		re, err := compilePattern(p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ptr, err)
+		}
+		s.patterns[p] = re
+	}
+
+	for _, kw := range schemaKeywords {
+		if sub, ok := n[kw]; ok {
+			if err := s.compile(sub, ptr+"/"+kw); err != nil {
+				return err
+			}
+		}
+	}
+	for _, kw := range schemaListKeywords {
+		subs, _ := n[kw].([]any)
+		for i, sub := range subs {
+			if err := s.compile(sub, fmt.Sprintf("%s/%s/%d", ptr, kw, i)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, kw := range schemaMapKeywords {
+		subs, _ := n[kw].(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(subs)) {
+			if err := s.compile(subs[name], ptr+"/"+kw+"/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	// Draft 7 dependencies, as a schema or a list of required names
+	if deps, ok := n["dependencies"].(map[string]any); ok {
+		for _, name := range slices.Sorted(maps.Keys(deps)) {
+			if err := s.compile(deps[name], ptr+"/dependencies/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks a value decoded by encoding/json against the schema,
+// returning a *ValidationError for the first failure found, or nil.
+func (s *Schema) Validate(value any) error {
+	if err := s.validate(s.root, value, "$", 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Properties returns the names of the properties the root schema declares,
+// sorted.
+func (s *Schema) Properties() []string {
+	node, _ := s.root.(map[string]any)
+	props, _ := node["properties"].(map[string]any)
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// maxRefDepth bounds $ref resolution, so that a schema referring to itself
+// without consuming the value cannot recurse forever.
+const maxRefDepth = 64
+
+func (s *Schema) validate(node, value any, path string, refs int) *ValidationError {
+	fail := func(keyword, format string, args ...any) *ValidationError {
+		return &ValidationError{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)}
+	}
+
+	switch n := node.(type) {
+	case bool:
+		if !n {
+			return fail("false", "no value is allowed here")
+		}
+		return nil
+	case map[string]any:
+		node := n
+		if ref, ok := node["$ref"].(string); ok {
+			if refs >= maxRefDepth {
+				return fail("$ref", "$ref %s nests too deeply", ref)
+			}
+			target, err := s.resolve(ref)
+			if err != nil {
+				return fail("$ref", "%v", err)
+			}
+			if err := s.validate(target, value, path, refs+1); err != nil {
+				return err
+			}
+		}
+		return s.validateObject(node, value, path, refs, fail)
+	default:
+		return nil
+	}
+}
+
+func (s *Schema) validateObject(node map[string]any, value any, path string, refs int, fail func(string, string, ...any) *ValidationError) *ValidationError {
+	if t, ok := node["type"]; ok && !matchesType(t, value) {
+		return fail("type", "must be %s, got %s", describeType(t), TypeName(value))
+	}
+	if enum, ok := node["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return equal(e, value) }) {
+		return fail("enum", "%s is not one of %s", compact(value), compact(enum))
+	}
+	if c, ok := node["const"]; ok && !equal(c, value) {
+		return fail("const", "%s is not %s", compact(value), compact(c))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if err := s.validateProperties(node, v, path, refs, fail); err != nil {
+			return err
+		}
+	case []any:
+		if err := s.validateItems(node, v, path, refs, fail); err != nil {
+			return err
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if lo, ok := number(node["minLength"]); ok && float64(n) < lo {
+			return fail("minLength", "has %d characters, fewer than minLength %g", n, lo)
+		}
+		if hi, ok := number(node["maxLength"]); ok && float64(n) > hi {
+			return fail("maxLength", "has %d characters, more than maxLength %g", n, hi)
+		}
+		if p, ok := node["pattern"].(string); ok && !s.patterns[p].MatchString(v) {
+			return fail("pattern", "%s does not match pattern %s", strconv.Quote(v), p)
+		}
+		if f, ok := node["format"].(string); ok && !matchesFormat(f, v) {
+			return fail("format", "%s is not a valid %s", strconv.Quote(v), f)
+		}
+	case float64:
+		if lo, ok := number(node["minimum"]); ok && v < lo {
+			return fail("minimum", "%g is less than minimum %g", v, lo)
+		}
+		if hi, ok := number(node["maximum"]); ok && v > hi {
+			return fail("maximum", "%g is greater than maximum %g", v, hi)
+		}
+		if lo, ok := number(node["exclusiveMinimum"]); ok && v <= lo {
+			return fail("exclusiveMinimum", "%g is not greater than %g", v, lo)
+		}
+		if hi, ok := number(node["exclusiveMaximum"]); ok && v >= hi {
+			return fail("exclusiveMaximum", "%g is not less than %g", v, hi)
+		}
+		if m, ok := number(node["multipleOf"]); ok && m > 0 {
+			if q := v / m; math.Abs(q-math.Round(q)) > 1e-9 {
+				return fail("multipleOf", "%g is not a multiple of %g", v, m)
+			}
+		}
+	}
+
+	if all, ok := node["allOf"].([]any); ok {
+		for _, sub := range all {
+			if err := s.validate(sub, value, path, refs); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := node["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if s.validate(sub, value, path, refs) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fail("anyOf", "matches none of the %d anyOf schemas", len(anyOf))
+		}
+	}
+	if oneOf, ok := node["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if s.validate(sub, value, path, refs) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fail("oneOf", "matches %d of the oneOf schemas, expected exactly 1", matched)
+		}
+	}
+	if not, ok := node["not"]; ok && s.validate(not, value, path, refs) == nil {
+		return fail("not", "matches a schema it must not match")
+	}
+	if cond, ok := node["if"]; ok {
+		branch := "then"
+		if s.validate(cond, value, path, refs) != nil {
+			branch = "else"
+		}
+		if sub, ok := node[branch]; ok {
+			if err := s.validate(sub, value, path, refs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateProperties(node, obj map[string]any, path string, refs int, fail func(string, string, ...any) *ValidationError) *ValidationError {
+	if required, ok := node["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				return fail("required", "missing required property %s", name)
+			}
+		}
+	}
+	if lo, ok := number(node["minProperties"]); ok && float64(len(obj)) < lo {
+		return fail("minProperties", "has %d properties, fewer than minProperties %g", len(obj), lo)
+	}
+	if hi, ok := number(node["maxProperties"]); ok && float64(len(obj)) > hi {
+		return fail("maxProperties", "has %d properties, more than maxProperties %g", len(obj), hi)
+	}
+	if err := s.validateDependencies(node, obj, path, refs, fail); err != nil {
+		return err
+	}
+
+	props, _ := node["properties"].(map[string]any)
+	patternProps, _ := node["patternProperties"].(map[string]any)
+	additional, hasAdditional := node["additionalProperties"]
+
+	// Check properties in name order, so errors are stable
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		propPath := path + "." + name
+		if pn, ok := node["propertyNames"]; ok {
+			if err := s.validate(pn, name, propPath, refs); err != nil {
+				return fail("propertyNames", "property name %s: %s", strconv.Quote(name), err.Message)
+			}
+		}
+		matched := false
+		if sub, ok := props[name]; ok {
+			matched = true
+			if err := s.validate(sub, obj[name], propPath, refs); err != nil {
+				return err
+			}
+		}
+		for p, sub := range patternProps {
+			if s.patterns[p].MatchString(name) {
+				matched = true
+				if err := s.validate(sub, obj[name], propPath, refs); err != nil {
+					return err
+				}
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if additional == false {
+			return fail("additionalProperties", "unexpected property %s", name)
+		}
+		if err := s.validate(additional, obj[name], propPath, refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDependencies checks the properties and schemas that the presence
+// of a property requires, given by dependentRequired and dependentSchemas,
+// or by draft 7 dependencies.
+func (s *Schema) validateDependencies(node, obj map[string]any, path string, refs int, fail func(string, string, ...any) *ValidationError) *ValidationError {
+	required := make(map[string]any)
+	schemas := make(map[string]any)
+	if deps, ok := node["dependentRequired"].(map[string]any); ok {
+		maps.Copy(required, deps)
+	}
+	if deps, ok := node["dependentSchemas"].(map[string]any); ok {
+		maps.Copy(schemas, deps)
+	}
+	if deps, ok := node["dependencies"].(map[string]any); ok {
+		for name, dep := range deps {
+			if _, ok := dep.([]any); ok {
+				required[name] = dep
+			} else {
+				schemas[name] = dep
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(required)) {
+		if _, ok := obj[name]; !ok {
+			continue
+		}
+		names, _ := required[name].([]any)
+		for _, r := range names {
+			dep, _ := r.(string)
+			if _, ok := obj[dep]; !ok {
+				return fail("dependentRequired", "has property %s but not %s, which it requires", name, dep)
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(schemas)) {
+		if _, ok := obj[name]; !ok {
+			continue
+		}
+		if err := s.validate(schemas[name], obj, path, refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateItems(node map[string]any, arr []any, path string, refs int, fail func(string, string, ...any) *ValidationError) *ValidationError {
+	if lo, ok := number(node["minItems"]); ok && float64(len(arr)) < lo {
+		return fail("minItems", "has %d items, fewer than minItems %g", len(arr), lo)
+	}
+	if hi, ok := number(node["maxItems"]); ok && float64(len(arr)) > hi {
+		return fail("maxItems", "has %d items, more than maxItems %g", len(arr), hi)
+	}
+	if node["uniqueItems"] == true {
+		for i := range arr {
+			for j := range i {
+				if equal(arr[i], arr[j]) {
+					return fail("uniqueItems", "items %d and %d are equal", j, i)
+				}
+			}
+		}
+	}
+
+	prefix, _ := node["prefixItems"].([]any)
+	for i, item := range arr {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(prefix) {
+			if err := s.validate(prefix[i], item, itemPath, refs); err != nil {
+				return err
+			}
+			continue
+		}
+		if items, ok := node["items"]; ok {
+			if err := s.validate(items, item, itemPath, refs); err != nil {
+				return err
+			}
+		}
+	}
+
+	if contains, ok := node["contains"]; ok {
+		matches := 0
+		for _, item := range arr {
+			if s.validate(contains, item, path, refs) == nil {
+				matches++
+			}
+		}
+		lo, ok := number(node["minContains"])
+		if !ok {
+			lo = 1
+		}
+		if float64(matches) < lo {
+			if lo == 1 {
+				return fail("contains", "has no item matching the contains schema")
+			}
+			return fail("minContains", "has %d items matching the contains schema, fewer than minContains %g", matches, lo)
+		}
+		if hi, ok := number(node["maxContains"]); ok && float64(matches) > hi {
+			return fail("maxContains", "has %d items matching the contains schema, more than maxContains %g", matches, hi)
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema a $ref points to. Only references within the
+// schema, as JSON pointers such as #/$defs/address, are supported.
+func (s *Schema) resolve(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %s (only references within the schema are supported)", ref)
+	}
+	node := s.root
+	if pointer == "" {
+		return node, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("$ref %s not found", ref)
+			}
+			node = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("$ref %s not found", ref)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("$ref %s not found", ref)
+		}
+	}
+	return node, nil
+}
+
+// matchesType reports whether value has the type, or one of the types,
+// named by t.
+func matchesType(t, value any) bool {
+	switch t := t.(type) {
+	case string:
+		return isType(t, value)
+	case []any:
+		return slices.ContainsFunc(t, func(name any) bool {
+			s, _ := name.(string)
+			return isType(s, value)
+		})
+	}
+	return true
+}
+
+func isType(name string, value any) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		v, ok := value.(float64)
+		return ok && v == math.Trunc(v) && !math.IsInf(v, 0)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+// describeType names the type or types of a type keyword for messages.
+func describeType(t any) string {
+	if types, ok := t.([]any); ok {
+		names := make([]string, 0, len(types))
+		for _, name := range types {
+			names = append(names, fmt.Sprint(name))
+		}
+		return "one of " + strings.Join(names, ", ")
+	}
+	return withArticle(fmt.Sprint(t))
+}
+
+func withArticle(name string) string {
+	switch name {
+	case "object", "array", "integer":
+		return "an " + name
+	case "null":
+		return name
+	}
+	return "a " + name
+}
+
+// TypeName names the JSON type of a decoded value, distinguishing integers
+// from other numbers.
+func TypeName(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// number returns a numeric keyword's value.
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+// equal compares decoded JSON values.
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// compact formats a decoded value as JSON for messages.
+func compact(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
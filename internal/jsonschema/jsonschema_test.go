@@ -0,0 +1,169 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		// keyword is the keyword that fails, or "" if the value is valid
+		keyword string
+	}{
+		{"true schema", `true`, `1`, ""},
+		{"false schema", `false`, `1`, "false"},
+
+		{"type", `{"type":"string"}`, `"a"`, ""},
+		{"type mismatch", `{"type":"string"}`, `1`, "type"},
+		{"type list", `{"type":["string","null"]}`, `null`, ""},
+		{"integer", `{"type":"integer"}`, `2.0`, ""},
+		{"integer fraction", `{"type":"integer"}`, `2.5`, "type"},
+		{"enum", `{"enum":["a",1]}`, `1`, ""},
+		{"enum mismatch", `{"enum":["a",1]}`, `"b"`, "enum"},
+		{"const", `{"const":{"a":[1]}}`, `{"a":[1]}`, ""},
+		{"const mismatch", `{"const":{"a":[1]}}`, `{"a":[2]}`, "const"},
+
+		{"minLength unicode", `{"minLength":3}`, `"héé"`, ""},
+		{"minLength", `{"minLength":3}`, `"ab"`, "minLength"},
+		{"maxLength", `{"maxLength":2}`, `"abc"`, "maxLength"},
+		{"pattern", `{"pattern":"^[0-9]{5}$"}`, `"12345"`, ""},
+		{"pattern mismatch", `{"pattern":"^[0-9]{5}$"}`, `"1234"`, "pattern"},
+		{"pattern unanchored", `{"pattern":"b"}`, `"abc"`, ""},
+		{"format", `{"format":"date"}`, `"2024-02-30"`, "format"},
+		{"unknown format", `{"format":"hostname"}`, `"not a host!"`, ""},
+
+		{"minimum", `{"minimum":1}`, `1`, ""},
+		{"minimum below", `{"minimum":1}`, `0.5`, "minimum"},
+		{"maximum", `{"maximum":1}`, `2`, "maximum"},
+		{"exclusiveMinimum", `{"exclusiveMinimum":1}`, `1`, "exclusiveMinimum"},
+		{"exclusiveMaximum", `{"exclusiveMaximum":100}`, `100`, "exclusiveMaximum"},
+		{"exclusiveMaximum below", `{"exclusiveMaximum":100}`, `99.9`, ""},
+		{"multipleOf", `{"multipleOf":0.1}`, `0.3`, ""},
+		{"multipleOf mismatch", `{"multipleOf":5}`, `12`, "multipleOf"},
+
+		{"required", `{"required":["a"]}`, `{"a":1}`, ""},
+		{"required missing", `{"required":["a"]}`, `{"b":1}`, "required"},
+		{"minProperties", `{"minProperties":2}`, `{"a":1}`, "minProperties"},
+		{"maxProperties", `{"maxProperties":1}`, `{"a":1,"b":2}`, "maxProperties"},
+		{"properties", `{"properties":{"a":{"type":"string"}}}`, `{"a":1}`, "type"},
+		{"patternProperties", `{"patternProperties":{"^x-":{"type":"string"}}}`, `{"x-a":1}`, "type"},
+		{"additionalProperties false", `{"properties":{"a":{}},"additionalProperties":false}`, `{"a":1,"b":2}`, "additionalProperties"},
+		{"additionalProperties schema", `{"properties":{"a":{}},"additionalProperties":{"type":"integer"}}`, `{"a":"x","b":2}`, ""},
+		{"additionalProperties patterned", `{"patternProperties":{"^b":{}},"additionalProperties":false}`, `{"b":1}`, ""},
+		{"propertyNames", `{"propertyNames":{"pattern":"^[a-z]+$"}}`, `{"ab":1}`, ""},
+		{"propertyNames mismatch", `{"propertyNames":{"pattern":"^[a-z]+$"}}`, `{"aB":1}`, "propertyNames"},
+		{"propertyNames maxLength", `{"propertyNames":{"maxLength":2}}`, `{"abc":1}`, "propertyNames"},
+		{"dependentRequired", `{"dependentRequired":{"card":["cvv"]}}`, `{"card":1,"cvv":2}`, ""},
+		{"dependentRequired absent", `{"dependentRequired":{"card":["cvv"]}}`, `{"cash":1}`, ""},
+		{"dependentRequired missing", `{"dependentRequired":{"card":["cvv"]}}`, `{"card":1}`, "dependentRequired"},
+		{"dependentSchemas", `{"dependentSchemas":{"card":{"required":["cvv"]}}}`, `{"card":1}`, "required"},
+		{"dependencies list", `{"dependencies":{"card":["cvv"]}}`, `{"card":1}`, "dependentRequired"},
+		{"dependencies schema", `{"dependencies":{"card":{"properties":{"cvv":{"type":"string"}}}}}`, `{"card":1,"cvv":2}`, "type"},
+
+		{"minItems", `{"minItems":2}`, `[1]`, "minItems"},
+		{"maxItems", `{"maxItems":1}`, `[1,2]`, "maxItems"},
+		{"uniqueItems", `{"uniqueItems":true}`, `[{"a":1},{"a":1}]`, "uniqueItems"},
+		{"uniqueItems distinct", `{"uniqueItems":true}`, `[1,"1"]`, ""},
+		{"items", `{"items":{"type":"integer"}}`, `[1,"2"]`, "type"},
+		{"prefixItems", `{"prefixItems":[{"type":"string"}],"items":{"type":"integer"}}`, `["a",1,2]`, ""},
+		{"prefixItems mismatch", `{"prefixItems":[{"type":"string"}],"items":{"type":"integer"}}`, `[1,1]`, "type"},
+		{"items false", `{"prefixItems":[{}],"items":false}`, `[1,2]`, "false"},
+		{"contains", `{"contains":{"type":"string"}}`, `[1,"a"]`, ""},
+		{"contains none", `{"contains":{"type":"string"}}`, `[1,2]`, "contains"},
+		{"minContains", `{"contains":{"type":"string"},"minContains":2}`, `["a",1]`, "minContains"},
+		{"minContains zero", `{"contains":{"type":"string"},"minContains":0}`, `[1]`, ""},
+		{"maxContains", `{"contains":{"type":"string"},"maxContains":1}`, `["a","b"]`, "maxContains"},
+
+		{"allOf", `{"allOf":[{"type":"integer"},{"minimum":2}]}`, `1`, "minimum"},
+		{"anyOf", `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, `1`, ""},
+		{"anyOf none", `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, `1.5`, "anyOf"},
+		{"oneOf", `{"oneOf":[{"type":"integer"},{"minimum":2}]}`, `1`, ""},
+		{"oneOf both", `{"oneOf":[{"type":"integer"},{"minimum":2}]}`, `3`, "oneOf"},
+		{"not", `{"not":{"type":"string"}}`, `"a"`, "not"},
+		{"if then", `{"if":{"properties":{"kind":{"const":"card"}}},"then":{"required":["number"]},"else":{"required":["iban"]}}`, `{"kind":"card","number":"1"}`, ""},
+		{"if then mismatch", `{"if":{"properties":{"kind":{"const":"card"}}},"then":{"required":["number"]},"else":{"required":["iban"]}}`, `{"kind":"card","iban":"1"}`, "required"},
+		{"if else mismatch", `{"if":{"properties":{"kind":{"const":"card"}}},"then":{"required":["number"]},"else":{"required":["iban"]}}`, `{"kind":"bank","number":"1"}`, "required"},
+		{"then without if", `{"then":false}`, `1`, ""},
+
+		{"ref", `{"$defs":{"pos":{"type":"integer","minimum":1}},"properties":{"n":{"$ref":"#/$defs/pos"}}}`, `{"n":0}`, "minimum"},
+		{"ref recursive", `{"type":"object","properties":{"child":{"$ref":"#"}},"required":["name"]}`, `{"name":"a","child":{"name":"b","child":{}}}`, "required"},
+		{"ref escaped", `{"definitions":{"a/b":{"type":"string"}},"$ref":"#/definitions/a~1b"}`, `1`, "type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			var value any
+			if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			err = s.Validate(value)
+			if tt.keyword == "" {
+				if err != nil {
+					t.Errorf("Validate(%s) = %v, want valid", tt.value, err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate(%s) = %v, want a %s failure", tt.value, err, tt.keyword)
+			}
+			if verr.Keyword != tt.keyword {
+				t.Errorf("Validate(%s) failed %s (%v), want %s", tt.value, verr.Keyword, verr, tt.keyword)
+			}
+		})
+	}
+}
+
+func TestValidationErrorPath(t *testing.T) {
+	s := MustCompile([]byte(`{"properties":{"items":{"items":{"properties":{"quantity":{"type":"integer"}}}}}}`))
+	err := s.Validate(map[string]any{"items": []any{
+		map[string]any{"quantity": 1.0},
+		map[string]any{"quantity": "two"},
+	}})
+	if err == nil || !strings.HasPrefix(err.Error(), "$.items[1].quantity: ") {
+		t.Errorf("error = %v, want it at $.items[1].quantity", err)
+	}
+}
+
+func TestCompileRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"unevaluatedProperties", `{"properties":{"a":{}},"unevaluatedProperties":false}`, "unsupported keyword unevaluatedProperties"},
+		{"nested unevaluatedItems", `{"properties":{"a":{"unevaluatedItems":false}}}`, "#/properties/a: unsupported keyword unevaluatedItems"},
+		{"dynamicRef", `{"$dynamicRef":"#meta"}`, "unsupported keyword $dynamicRef"},
+		{"items array", `{"items":[{"type":"string"}]}`, "use prefixItems"},
+		{"external ref", `{"$ref":"https://example.com/schema.json"}`, "only references within the schema"},
+		{"missing ref", `{"anyOf":[{"$ref":"#/$defs/missing"}]}`, "#/anyOf/0: $ref #/$defs/missing not found"},
+		{"lookahead", `{"pattern":"^(?=.*[0-9]).+$"}`, "lookaround is not supported"},
+		{"backreference", `{"pattern":"^(a)\\1$"}`, "backreferences are not supported"},
+		{"invalid pattern", `{"patternProperties":{"(":{}}}`, "invalid pattern"},
+		{"invalid JSON", `{`, "parse schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compile(%s) = %v, want an error containing %q", tt.schema, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileIgnoresKeywordsInValues(t *testing.T) {
+	// Keywords inside enum, const, and property names are data, not schema
+	schema := `{"enum":[{"unevaluatedProperties":false,"pattern":"("}],"properties":{"unevaluatedItems":{"type":"string"}}}`
+	if _, err := Compile([]byte(schema)); err != nil {
+		t.Errorf("Compile: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package jsonschema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// ecmaSpace lists the characters ECMA-262 \s matches, as the body of a
+// character class. RE2's \s is ASCII only.
+const ecmaSpace = `\t\n\v\f\r \x{a0}\x{1680}\x{2000}-\x{200a}\x{2028}\x{2029}\x{202f}\x{205f}\x{3000}\x{feff}`
+
+// compilePattern compiles a pattern keyword, written as an ECMA-262
+// regular expression as the specification requires, by translating it to
+// the RE2 syntax of package regexp. The two agree on most syntax; this
+// translates where they differ in meaning (".", "\s", "[^]") or in
+// spelling ("\uXXXX", "\cX"). ECMA-262 features RE2 cannot express,
+// lookaround and backreferences, are reported as errors rather than
+// matched differently.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	translated, err := translatePattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	re, err := regexp.Compile(translated)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// translatePattern rewrites an ECMA-262 regular expression in RE2 syntax.
+func translatePattern(p string) (string, error) {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(p) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			i++
+			esc, n, err := translateEscape(p[i:], inClass)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(esc)
+			i += n - 1
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+			b.WriteByte(c)
+		case c == '[':
+			// [] matches nothing and [^] anything; RE2 rejects both
+			switch {
+			case strings.HasPrefix(p[i:], "[]"):
+				b.WriteString(`[^\x{0}-\x{10FFFF}]`)
+				i++
+			case strings.HasPrefix(p[i:], "[^]"):
+				b.WriteString(`[\x{0}-\x{10FFFF}]`)
+				i += 2
+			default:
+				inClass = true
+				b.WriteByte(c)
+				if strings.HasPrefix(p[i+1:], "^") {
+					b.WriteByte('^')
+					i++
+				}
+			}
+		case c == '.':
+			// ECMA-262 . excludes every line terminator, not only \n
+			b.WriteString(`[^\n\r\x{2028}\x{2029}]`)
+		case c == '(' && strings.HasPrefix(p[i:], "(?"):
+			rest := p[i+2:]
+			switch {
+			case strings.HasPrefix(rest, "="), strings.HasPrefix(rest, "!"),
+				strings.HasPrefix(rest, "<="), strings.HasPrefix(rest, "<!"):
+				return "", fmt.Errorf("lookaround is not supported")
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if inClass {
+		return "", fmt.Errorf("unterminated character class")
+	}
+	return b.String(), nil
+}
+
+// translateEscape translates the escape sequence at the start of s, after
+// its backslash, returning the RE2 text and the number of bytes of s it
+// consumed.
+func translateEscape(s string, inClass bool) (string, int, error) {
+	c := s[0]
+	switch {
+	case c == 'u':
+		r, n, err := unicodeEscape(s)
+		if err != nil {
+			return "", 0, err
+		}
+		return fmt.Sprintf(`\x{%x}`, r), n, nil
+	case c == 'c' && len(s) > 1 && isASCIILetter(s[1]):
+		return fmt.Sprintf(`\x{%x}`, s[1]%32), 2, nil
+	case c == 's':
+		if inClass {
+			return ecmaSpace, 1, nil
+		}
+		return "[" + ecmaSpace + "]", 1, nil
+	case c == 'S':
+		if inClass {
+			return "", 0, fmt.Errorf(`\S inside a character class is not supported`)
+		}
+		return "[^" + ecmaSpace + "]", 1, nil
+	case c == 'b' && inClass:
+		// Backspace, not a word boundary, inside a class
+		return `\x{8}`, 1, nil
+	case c == '0' && (len(s) == 1 || !isDigit(s[1])):
+		return `\x{0}`, 1, nil
+	case c >= '1' && c <= '9', c == 'k' && len(s) > 1 && s[1] == '<':
+		return "", 0, fmt.Errorf("backreferences are not supported")
+	case c == '/':
+		return "/", 1, nil
+	}
+	return `\` + string(c), 1, nil
+}
+
+// unicodeEscape decodes a \uXXXX or \u{X...} escape, after its backslash,
+// joining a surrogate pair written as two escapes.
+func unicodeEscape(s string) (rune, int, error) {
+	if rest, ok := strings.CutPrefix(s, "u{"); ok {
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return 0, 0, fmt.Errorf(`unterminated \u{ escape`)
+		}
+		v, err := strconv.ParseUint(rest[:end], 16, 32)
+		if err != nil || v > 0x10FFFF {
+			return 0, 0, fmt.Errorf(`invalid \u{%s} escape`, rest[:end])
+		}
+		return rune(v), end + 3, nil
+	}
+	if len(s) < 5 {
+		return 0, 0, fmt.Errorf(`invalid \u escape`)
+	}
+	v, err := strconv.ParseUint(s[1:5], 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf(`invalid \u escape \%s`, s[:5])
+	}
+	r := rune(v)
+	if utf16.IsSurrogate(r) && len(s) >= 11 && s[5:7] == `\u` {
+		if lo, err := strconv.ParseUint(s[7:11], 16, 16); err == nil {
+			if pair := utf16.DecodeRune(r, rune(lo)); pair != unicode.ReplacementChar {
+				return pair, 11, nil
+			}
+		}
+	}
+	return r, 5, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
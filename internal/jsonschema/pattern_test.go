@@ -0,0 +1,66 @@
+package jsonschema
+
+import "testing"
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		value   string
+		want    bool
+	}{
+		{`^[0-9]{5}$`, "12345", true},
+		{`^[0-9]{5}$`, "123456", false},
+		{`^\d+$`, "١٢٣", false}, // \d is ASCII in ECMA-262 too
+		{`^\w+$`, "héllo", false},
+		{`^a.c$`, "abc", true},
+		{`^a.c$`, "a\rc", false},
+		{`^a.c$`, "a c", false},
+		{`^a\sc$`, "a c", true},
+		{`^a\sc$`, "a　c", true},
+		{`^a[\s_]c$`, "a c", true},
+		{`^a\Sc$`, "a c", false},
+		{`^a\Sc$`, "abc", true},
+		{`^caf\u00e9$`, "café", true},
+		{`^\uD83D\uDE00$`, "😀", true},
+		{`^\u{1F600}$`, "😀", true},
+		{`^😀$`, "😀", true},
+		{`^a\/b$`, "a/b", true},
+		{`^\cJ$`, "\n", true},
+		{`^[\b]$`, "\b", true},
+		{`^a[^]c$`, "a\nc", true},
+		{`^a[]c$`, "abc", false},
+		{`^[^a-z]+$`, "ABC", true},
+		{`^(?<year>[0-9]{4})-[0-9]{2}$`, "2024-01", true},
+		{`^a\0$`, "a\x00", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := compilePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("compilePattern(%q): %v", tt.pattern, err)
+			}
+			if got := re.MatchString(tt.value); got != tt.want {
+				t.Errorf("%q matches %q = %v, want %v", tt.pattern, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilePatternUnsupported(t *testing.T) {
+	for _, pattern := range []string{
+		`^(?=.*\d)`,
+		`^(?!x)`,
+		`(?<=a)b`,
+		`(?<!a)b`,
+		`(a)\1`,
+		`(?<x>a)\k<x>`,
+		`[\S]`,
+		`[a-z`,
+		`a\`,
+		`\u12`,
+	} {
+		if _, err := compilePattern(pattern); err == nil {
+			t.Errorf("compilePattern(%q) succeeded, want an error", pattern)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Load reads the eval results recorded in a log directory. Results come
+// from evals.jsonl if present; older runs, which predate it, are
+// reconstructed from the per-eval .log files, taking turns from the eval's
+// turns.jsonl where there is one.
+func Load(dir string) ([]EvalResult, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open log directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	evals, err := readEvalsFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	if evals != nil {
+		return evals, nil
+	}
+
+	files, err := FilesWithSuffix(dir, ".log")
+	if err != nil {
+		return nil, err
+	}
+
+	type loaded struct {
+		started time.Time
+		result  EvalResult
+	}
+	var logs []loaded
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", filepath.Base(file), err)
+		}
+		result, started, ok := parseEvalLog(data)
+		if !ok {
+			// Not an eval log, or one cut off before its result
+			continue
+		}
+		base := strings.TrimSuffix(filepath.Base(file), ".log")
+		if result.Name == "" {
+			result.Name = base
+		}
+		exchanges, err := readExchanges(filepath.Join(dir, base+turnsSuffix))
+		if err != nil {
+			return nil, err
+		}
+		if exchanges != nil {
+			result.Turns = turnsFromExchanges(exchanges)
+		}
+		// Logs cut off before a valid start time sort first
+		t, _ := time.Parse(time.RFC3339, started)
+		logs = append(logs, loaded{t, result})
+	}
+
+	// Restore run order, comparing instants so that logs written in
+	// different zones, such as across a DST change, still sort correctly
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].started.Before(logs[j].started)
+	})
+
+	evals = make([]EvalResult, len(logs))
+	for i, l := range logs {
+		evals[i] = l.result
+	}
+	return evals, nil
+}
+
+// readEvalsFile reads evals.jsonl from dir, returning nil if it does not
+// exist. Later records of the same eval supersede earlier ones.
+func readEvalsFile(dir string) ([]EvalResult, error) {
+	f, err := os.Open(filepath.Join(dir, evalsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", evalsFile, err)
+	}
+	defer f.Close()
+
+	evals := []EvalResult{}
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var ev EvalResult
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			// A torn final line from a crash is expected; skip it
+			continue
+		}
+		if i, ok := index[ev.Name]; ok {
+			evals[i] = ev
+			continue
+		}
+		index[ev.Name] = len(evals)
+		evals = append(evals, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", evalsFile, err)
+	}
+
+	return evals, nil
+}
+
+// readExchanges reads an eval's turns.jsonl, returning nil if it does not
+// exist.
+func readExchanges(path string) ([]Exchange, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	exchanges := []Exchange{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var ex Exchange
+		if err := json.Unmarshal(scanner.Bytes(), &ex); err != nil {
+			continue
+		}
+		exchanges = append(exchanges, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+
+	return exchanges, nil
+}
+
+// LoadExchanges reads the exchanges recorded for the named eval in a log
+// directory. It returns nil if the eval has no turns.jsonl.
+func LoadExchanges(dir, name string) ([]Exchange, error) {
+	return readExchanges(filepath.Join(dir, name+turnsSuffix))
+}
+
+// turnsFromExchanges builds report turns from recorded exchanges the way
+// EvalLog does live: every blocking response, and chat completion streams
+// as a response synthesized from their chunks.
+func turnsFromExchanges(exchanges []Exchange) []TurnData {
+	var turns []TurnData
+	for _, ex := range exchanges {
+		response := ex.ResponseBody
+		if ex.StreamRaw != "" {
+			if !strings.HasSuffix(ex.URL, "/chat/completions") {
+				continue
+			}
+			lines := strings.Split(strings.ReplaceAll(ex.StreamRaw, "\r\n", "\n"), "\n")
+			response = reconstructFromChunks(sseToJSONL(lines))
+		}
+		turns = append(turns, TurnData{
+			URL:          ex.URL,
+			RequestBody:  ex.RequestBody,
+			ResponseBody: response,
+			Headers:      ex.Headers,
+		})
+	}
+	return turns
+}
+
+// parseEvalLog reconstructs an eval result from the text of its .log file,
+// as written by EvalLog. It also returns the eval's start time, and false
+// if the log has no result.
+func parseEvalLog(data []byte) (EvalResult, string, bool) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	var result EvalResult
+	var started string
+	var pendingURL string
+	var pendingRequest json.RawMessage
+	found := false
+
+	// block returns the lines from i up to the next blank line, and the
+	// index of that blank line.
+	block := func(i int) ([]string, int) {
+		start := i
+		for i < len(lines) && lines[i] != "" {
+			i++
+		}
+		return lines[start:i], i
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "=== Eval: ") && strings.HasSuffix(line, " ==="):
+			result.Name = strings.TrimSuffix(strings.TrimPrefix(line, "=== Eval: "), " ===")
+
+		case strings.HasPrefix(line, "Started: ") && started == "":
+			started = strings.TrimPrefix(line, "Started: ")
+
+		case line == ">>> REQUEST" && i+1 < len(lines):
+			i++
+			if fields := strings.Fields(lines[i]); len(fields) == 2 {
+				pendingURL = fields[1]
+			}
+			// A blank line separates the request line from the body
+			var body []string
+			body, i = block(i + 2)
+			pendingRequest = compactJSON(body)
+
+		case (line == "<<< RESPONSE" || line == "<<< STREAM RESPONSE") && i+1 < len(lines):
+			var headerLines []string
+			headerLines, i = block(i + 1)
+			headers := parseHeaderLines(headerLines)
+
+			var response json.RawMessage
+			if line == "<<< RESPONSE" {
+				var body []string
+				body, i = block(i + 1)
+				response = compactJSON(body)
+			} else {
+				// Raw SSE events are themselves separated by blank lines
+				start := i + 1
+				for i+1 < len(lines) && isSSELine(lines[i+1]) {
+					i++
+				}
+				if !strings.HasSuffix(pendingURL, "/chat/completions") {
+					// Only chat streams are kept as turns when logged live
+					pendingURL = ""
+					pendingRequest = nil
+					continue
+				}
+				response = reconstructFromChunks(sseToJSONL(lines[start : i+1]))
+			}
+			result.Turns = append(result.Turns, TurnData{
+				URL:          pendingURL,
+				RequestBody:  pendingRequest,
+				ResponseBody: response,
+				Headers:      headers,
+			})
+			pendingURL = ""
+			pendingRequest = nil
+
+		case strings.HasPrefix(line, "--- Iteration "):
+			if it, ok := parseIterationLine(line); ok {
+				result.Iterations = append(result.Iterations, it)
+			}
+
+		case line == "--- Scores":
+			var scoreLines []string
+			scoreLines, i = block(i + 1)
+			for _, s := range scoreLines {
+				name, value, ok := strings.Cut(s, ": ")
+				if !ok {
+					continue
+				}
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					if result.Scores == nil {
+						result.Scores = make(map[string]float64)
+					}
+					result.Scores[name] = v
+				}
+			}
+
+		case strings.HasPrefix(line, "--- Grid: "):
+			var gridLines []string
+			gridLines, i = block(i + 1)
+			result.Grid = parseGrid(line, gridLines)
+
+		case strings.HasPrefix(line, "--- Note: "):
+			result.Notes = append(result.Notes, strings.TrimPrefix(line, "--- Note: "))
+
+		case strings.HasPrefix(line, "--- SLO violation: "):
+			result.SLOViolations = append(result.SLOViolations, strings.TrimPrefix(line, "--- SLO violation: "))
+
+		case strings.HasPrefix(line, "--- Invalid sample: "):
+			var sampleLines []string
+			sampleLines, i = block(i + 1)
+			sample := Sample{}
+			sample.Code, sample.Message, _ = strings.Cut(strings.TrimPrefix(line, "--- Invalid sample: "), ": ")
+			for _, l := range sampleLines {
+				if v, ok := strings.CutPrefix(l, "Prompt: "); ok {
+					sample.Prompt, _ = strconv.Unquote(v)
+				} else if v, ok := strings.CutPrefix(l, "Output: "); ok {
+					sample.Output, _ = strconv.Unquote(v)
+				}
+			}
+			result.Samples = append(result.Samples, sample)
+
+		case strings.HasPrefix(line, "--- Server timings: "):
+			var t ServerTimings
+			if _, err := fmt.Sscanf(line, "--- Server timings: prompt %d tokens at %g tok/s, generation %d tokens at %g tok/s",
+				&t.PromptTokens, &t.PromptPerSecond, &t.PredictedTokens, &t.PredictedPerSecond); err == nil {
+				result.Timings = &t
+			}
+
+		case line == "=== Result: PASSED ===" || line == "=== Result: FAILED ===":
+			found = true
+			result.Passed = line == "=== Result: PASSED ==="
+			rest := lines[i+1:]
+			if len(rest) > 0 && strings.HasPrefix(rest[0], "Code: ") {
+				result.Code = strings.TrimPrefix(rest[0], "Code: ")
+				rest = rest[1:]
+			}
+			result.Message = strings.TrimRight(strings.Join(rest, "\n"), "\n")
+			i = len(lines)
+		}
+	}
+
+	return result, started, found
+}
+
+// compactJSON joins formatted JSON lines back into compact JSON, or returns
+// them as a JSON string if they are not valid JSON.
+func compactJSON(lines []string) json.RawMessage {
+	text := strings.Join(lines, "\n")
+	if text == "" {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(text)); err == nil {
+		return buf.Bytes()
+	}
+	quoted, _ := json.Marshal(text)
+	return quoted
+}
+
+// parseHeaderLines parses the status line and "name: value" header lines
+// that follow a response marker, returning the headers or nil.
+func parseHeaderLines(lines []string) map[string]string {
+	var headers map[string]string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Status: ") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+// isSSELine reports whether a line can belong to a raw SSE stream body.
+func isSSELine(line string) bool {
+	if line == "" {
+		return true
+	}
+	for _, prefix := range []string{"data:", ":", "event:", "id:", "retry:"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sseToJSONL converts logged SSE data lines to JSONL stream chunks.
+func sseToJSONL(lines []string) []byte {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "" || data == "[DONE]" {
+			continue
+		}
+		buf.WriteString(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// parseIterationLine parses a "--- Iteration N: STATUS [CODE]: message" line.
+func parseIterationLine(line string) (IterationResult, bool) {
+	_, rest, ok := strings.Cut(line, ": ")
+	if !ok {
+		return IterationResult{}, false
+	}
+
+	var it IterationResult
+	switch {
+	case strings.HasPrefix(rest, "PASSED"):
+		it.Passed = true
+		rest = strings.TrimPrefix(rest, "PASSED")
+	case strings.HasPrefix(rest, "FAILED"):
+		rest = strings.TrimPrefix(rest, "FAILED")
+	default:
+		return IterationResult{}, false
+	}
+
+	if strings.HasPrefix(rest, " [") {
+		if end := strings.Index(rest, "]"); end >= 0 {
+			it.Code = rest[2:end]
+			rest = rest[end+1:]
+		}
+	}
+	it.Message = strings.TrimPrefix(rest, ": ")
+	return it, true
+}
+
+// parseGrid parses a grid logged by LogGrid from its header line and the
+// column and row lines that follow.
+func parseGrid(header string, lines []string) *Grid {
+	labels := strings.TrimPrefix(header, "--- Grid: ")
+	rowLabel, colLabel, ok := strings.Cut(labels, " (rows) by ")
+	if !ok || len(lines) == 0 {
+		return nil
+	}
+
+	g := &Grid{
+		RowLabel: rowLabel,
+		ColLabel: strings.TrimSuffix(colLabel, " (columns)"),
+		Cols:     strings.Fields(lines[0]),
+	}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) != len(g.Cols)+1 {
+			continue
+		}
+		g.Rows = append(g.Rows, fields[0])
+		cells := make([]bool, len(g.Cols))
+		for j, mark := range fields[1:] {
+			cells[j] = mark == "PASS"
+		}
+		g.Cells = append(g.Cells, cells)
+	}
+	return g
+}
@@ -7,16 +7,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/textdiff"
 )
 
 // RequestLogger is the interface used by the client for logging requests/responses.
 type RequestLogger interface {
-	LogRequest(method, url string, body []byte)
+	LogRequest(method, url string, body []byte, sent time.Time)
+	LogResponseHeaders(headers map[string]string)
 	LogResponse(status int, body []byte)
 	LogStreamResponse(status int, rawChunks []byte)
+	LogStreamTiming(ttft, itl time.Duration, chunks int)
 	LogStreamChunks(jsonl []byte)
 }
 
@@ -25,36 +31,196 @@ type TurnData struct {
 	URL          string
 	RequestBody  json.RawMessage
 	ResponseBody json.RawMessage // synthesized from stream chunks for streaming
+	// Headers holds selected response headers, keyed by lowercase name.
+	Headers map[string]string `json:",omitempty"`
 }
 
-// EvalResult holds the structured result of an eval for report generation.
-type EvalResult struct {
-	Name    string
+// Exchange is one HTTP request and its response, as recorded in an eval's
+// turns.jsonl for tools that would otherwise parse the text log.
+type Exchange struct {
+	// Iteration is the 1-based run of a repeated eval, or 0 if not repeated.
+	Iteration   int `json:",omitempty"`
+	Method      string
+	URL         string
+	RequestBody json.RawMessage `json:",omitempty"`
+	Status      int
+	// Headers holds selected response headers, keyed by lowercase name.
+	Headers map[string]string `json:",omitempty"`
+	// ResponseBody is the body of a blocking response. Bodies that are not
+	// JSON are recorded as a JSON string.
+	ResponseBody json.RawMessage `json:",omitempty"`
+	// StreamRaw is the raw SSE body of a streaming response.
+	StreamRaw string `json:",omitempty"`
+	// Sent and Received are in UTC. Elapsed is measured on the monotonic
+	// clock, so unlike their difference it is immune to clock changes.
+	Sent     time.Time
+	Received time.Time
+	Elapsed  time.Duration
+}
+
+// IterationResult holds the outcome of one run of a repeated eval.
+type IterationResult struct {
 	Passed  bool
+	Code    string
+	Message string
+}
+
+// Grid is a two-dimensional pass/fail breakdown of an eval's checks.
+type Grid struct {
+	RowLabel string
+	ColLabel string
+	Rows     []string
+	Cols     []string
+	Cells    [][]bool
+}
+
+// Sample is one invalid response of an eval that checks many.
+type Sample struct {
+	Prompt  string
+	Code    string
+	Message string
+	Output  string
+}
+
+// Violation is a line of a stream that was skipped since it was not a
+// chunk, such as an error event.
+type Violation struct {
+	Kind    string
+	Line    int
+	Data    string
 	Message string
-	Turns   []TurnData
 }
 
+// Diff is a pair of outputs that should have matched.
+type Diff struct {
+	ALabel string
+	BLabel string
+	A      string
+	B      string
+}
+
+// Artifact is a file attached to an eval's result, at Path relative to the
+// run directory.
+type Artifact struct {
+	Name string
+	Path string
+}
+
+// artifactsSuffix names each eval's directory of artifacts.
+const artifactsSuffix = ".artifacts"
+
+// ServerTimings summarizes the server-reported throughput of an eval's
+// requests.
+type ServerTimings struct {
+	PromptTokens       int
+	PromptPerSecond    float64
+	PredictedTokens    int
+	PredictedPerSecond float64
+}
+
+// EvalResult holds the structured result of an eval for report generation.
+type EvalResult struct {
+	Name          string
+	Passed        bool
+	Code          string
+	Message       string
+	Iterations    []IterationResult  `json:",omitempty"`
+	Scores        map[string]float64 `json:",omitempty"`
+	Grid          *Grid              `json:",omitempty"`
+	Timings       *ServerTimings     `json:",omitempty"`
+	Notes         []string           `json:",omitempty"`
+	SLOViolations []string           `json:",omitempty"`
+	Samples       []Sample           `json:",omitempty"`
+	Violations    []Violation        `json:",omitempty"`
+	Diffs         []Diff             `json:",omitempty"`
+	Artifacts     []Artifact         `json:",omitempty"`
+	Turns         []TurnData
+}
+
+// evalsFile records completed evals incrementally so an interrupted run
+// can be resumed with its report data intact.
+const evalsFile = "evals.jsonl"
+
+// turnsSuffix names each eval's JSONL record of its HTTP exchanges.
+const turnsSuffix = ".turns.jsonl"
+
 // Logger handles request/response logging to files.
 type Logger struct {
 	dir   string
 	model string
+	label string
+
+	redactor *Redactor
 
 	mu    sync.Mutex
 	evals []EvalResult
 }
 
-// New creates a new Logger, creating the log directory.
-// Logs are grouped by model name: logs/<model>/<timestamp>/
-func New(model string) (*Logger, error) {
-	timestamp := time.Now().Format("2006-01-02_150405")
-	dir := filepath.Join("logs", model, timestamp)
-
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// runDirFormat names run directories by their UTC start time in ISO 8601
+// basic format, which sorts lexically and contains no colons, unlike the
+// extended format, for filesystems that forbid them.
+const runDirFormat = "20060102T150405Z"
+
+// New creates a new Logger, creating the log directory under root.
+// Logs are grouped by model name: <root>/<model>/<run>/, with the model
+// name made safe for use as a directory name by DirName. The run directory
+// is named by label if it is set, with a numeric suffix if a run already
+// has that label, and otherwise by the time the run started.
+func New(root, model, label string) (*Logger, error) {
+	started := time.Now().UTC()
+	modelDir := filepath.Join(root, DirName(model))
+	if err := os.MkdirAll(modelDir, 0755); err != nil {
 		return nil, fmt.Errorf("create log directory: %w", err)
 	}
 
-	return &Logger{dir: dir, model: model}, nil
+	name := started.Format(runDirFormat)
+	if label != "" {
+		name = DirName(label)
+	}
+	dir := filepath.Join(modelDir, name)
+	for i := 2; ; i++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("create log directory: %w", err)
+		}
+		dir = filepath.Join(modelDir, fmt.Sprintf("%s-%d", name, i))
+	}
+
+	l := &Logger{dir: dir, model: model, label: label}
+	if err := writeRunMeta(dir, RunMeta{Model: model, Label: label, Started: started}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Resume reopens an existing log directory, loading the evals recorded by
+// the interrupted run so that new results are appended to the same artifacts.
+func Resume(dir, model string) (*Logger, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open log directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	evals, err := readEvalsFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := ReadRunMeta(dir)
+	if err != nil {
+		return nil, err
+	}
+	var label string
+	if meta != nil {
+		label = meta.Label
+	}
+
+	return &Logger{dir: dir, model: model, label: label, evals: evals}, nil
 }
 
 // Dir returns the log directory path.
@@ -62,11 +228,22 @@ func (l *Logger) Dir() string {
 	return l.dir
 }
 
+// SetRedactor sets the Redactor that masks credentials in everything the
+// logger writes.
+func (l *Logger) SetRedactor(r *Redactor) {
+	l.redactor = r
+}
+
 // Model returns the model name.
 func (l *Logger) Model() string {
 	return l.model
 }
 
+// Label returns the run's label, or "" if it has none.
+func (l *Logger) Label() string {
+	return l.label
+}
+
 // Evals returns the collected eval results.
 func (l *Logger) Evals() []EvalResult {
 	l.mu.Lock()
@@ -74,11 +251,52 @@ func (l *Logger) Evals() []EvalResult {
 	return append([]EvalResult(nil), l.evals...)
 }
 
-// registerEval adds a completed eval result.
-func (l *Logger) registerEval(result EvalResult) {
+// registerEval adds a completed eval result and persists it to evals.jsonl.
+func (l *Logger) registerEval(result EvalResult) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.evals = append(l.evals, result)
+
+	// Replace any earlier record of the same eval (e.g. rerun after resume)
+	replaced := false
+	for i := range l.evals {
+		if l.evals[i].Name == result.Name {
+			l.evals[i] = result
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		l.evals = append(l.evals, result)
+	}
+
+	line, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal eval result: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(l.dir, evalsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", evalsFile, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write %s: %w", evalsFile, err)
+	}
+
+	return nil
+}
+
+// WriteRepro writes the body of a request reproducing an eval's failure to
+// <name>.repro.json in the log directory, with credentials masked, and
+// returns its path.
+func (l *Logger) WriteRepro(name string, body []byte) (string, error) {
+	path := filepath.Join(l.dir, name+".repro.json")
+	data := append(formatJSON(l.redactor.Bytes(body)), '\n')
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("write repro file: %w", err)
+	}
+	return path, nil
 }
 
 // StartEval starts logging for a new eval and returns an EvalLog handle.
@@ -90,7 +308,7 @@ func (l *Logger) StartEval(name string) *EvalLog {
 		name:   name,
 	}
 	el.buf.WriteString(fmt.Sprintf("=== Eval: %s ===\n", name))
-	el.buf.WriteString(fmt.Sprintf("Started: %s\n\n", time.Now().Format(time.RFC3339)))
+	el.buf.WriteString(fmt.Sprintf("Started: %s\n\n", time.Now().UTC().Format(time.RFC3339)))
 	return el
 }
 
@@ -101,17 +319,32 @@ type EvalLog struct {
 	name         string
 	buf          bytes.Buffer
 	streamChunks []byte
+	iteration    int
+	pending      Exchange
+	exchanges    []Exchange
 
 	// Structured data for report generation
 	pendingURL     string
 	pendingRequest json.RawMessage
+	pendingHeaders map[string]string
 	turns          []TurnData
+	iterations     []IterationResult
+	scores         map[string]float64
+	grid           *Grid
+	timings        *ServerTimings
+	notes          []string
+	sloViolations  []string
+	samples        []Sample
+	violations     []Violation
+	diffs          []Diff
+	artifacts      []pendingArtifact
 	passed         bool
+	code           string
 	message        string
 }
 
-// LogRequest logs an HTTP request.
-func (el *EvalLog) LogRequest(method, url string, body []byte) {
+// LogRequest logs an HTTP request sent at the given time.
+func (el *EvalLog) LogRequest(method, url string, body []byte, sent time.Time) {
 	el.buf.WriteString(">>> REQUEST\n")
 	el.buf.WriteString(fmt.Sprintf("%s %s\n", method, url))
 	el.buf.WriteString("\n")
@@ -121,33 +354,90 @@ func (el *EvalLog) LogRequest(method, url string, body []byte) {
 	// Capture for report
 	el.pendingURL = url
 	el.pendingRequest = append(json.RawMessage(nil), body...)
+	el.pendingHeaders = nil
+	el.pending = Exchange{
+		Iteration:   el.iteration,
+		Method:      method,
+		URL:         url,
+		RequestBody: jsonValue(body),
+		Sent:        sent,
+	}
+}
+
+// LogResponseHeaders records selected headers of the response about to be
+// logged, attaching them to its turn.
+func (el *EvalLog) LogResponseHeaders(headers map[string]string) {
+	el.pendingHeaders = headers
+}
+
+// writeHeaders writes the pending response headers in name order.
+func (el *EvalLog) writeHeaders() {
+	names := make([]string, 0, len(el.pendingHeaders))
+	for name := range el.pendingHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		el.buf.WriteString(fmt.Sprintf("%s: %s\n", name, el.pendingHeaders[name]))
+	}
 }
 
 // LogResponse logs an HTTP response.
 func (el *EvalLog) LogResponse(status int, body []byte) {
 	el.buf.WriteString("<<< RESPONSE\n")
 	el.buf.WriteString(fmt.Sprintf("Status: %d\n", status))
+	el.writeHeaders()
 	el.buf.WriteString("\n")
 	el.buf.Write(formatJSON(body))
 	el.buf.WriteString("\n\n")
 
+	ex := el.pending
+	ex.ResponseBody = jsonValue(body)
+	el.recordExchange(ex, status)
+
 	// Capture turn for report
 	el.turns = append(el.turns, TurnData{
 		URL:          el.pendingURL,
 		RequestBody:  el.pendingRequest,
 		ResponseBody: append(json.RawMessage(nil), body...),
+		Headers:      el.pendingHeaders,
 	})
 	el.pendingRequest = nil
 	el.pendingURL = ""
+	el.pendingHeaders = nil
 }
 
 // LogStreamResponse logs a streaming response.
 func (el *EvalLog) LogStreamResponse(status int, rawChunks []byte) {
 	el.buf.WriteString("<<< STREAM RESPONSE\n")
 	el.buf.WriteString(fmt.Sprintf("Status: %d\n", status))
+	el.writeHeaders()
 	el.buf.WriteString("\n")
 	el.buf.Write(rawChunks)
 	el.buf.WriteString("\n")
+
+	ex := el.pending
+	ex.StreamRaw = string(rawChunks)
+	el.recordExchange(ex, status)
+}
+
+// recordExchange completes an exchange with its response status, headers,
+// and receive time.
+func (el *EvalLog) recordExchange(ex Exchange, status int) {
+	ex.Status = status
+	ex.Headers = el.pendingHeaders
+	received := time.Now()
+	ex.Elapsed = received.Sub(ex.Sent)
+	ex.Sent = ex.Sent.UTC()
+	ex.Received = received.UTC()
+	el.exchanges = append(el.exchanges, ex)
+	el.pending = Exchange{}
+}
+
+// LogStreamTiming logs streaming latency metrics.
+func (el *EvalLog) LogStreamTiming(ttft, itl time.Duration, chunks int) {
+	el.buf.WriteString(fmt.Sprintf("Timing: TTFT %s, mean ITL %s over %d chunks\n\n",
+		ttft.Round(time.Microsecond), itl.Round(time.Microsecond), chunks))
 }
 
 // LogStreamChunks stores JSONL-formatted stream chunks for replay,
@@ -162,9 +452,11 @@ func (el *EvalLog) LogStreamChunks(jsonl []byte) {
 			URL:          el.pendingURL,
 			RequestBody:  el.pendingRequest,
 			ResponseBody: synthetic,
+			Headers:      el.pendingHeaders,
 		})
 		el.pendingRequest = nil
 		el.pendingURL = ""
+		el.pendingHeaders = nil
 	}
 }
 
@@ -180,47 +472,289 @@ func (el *EvalLog) LogValidation(description string, expected, actual any) {
 	el.buf.WriteString(fmt.Sprintf("Actual:   %v\n\n", actual))
 }
 
+// StartIteration marks the start of one run of a repeated eval.
+func (el *EvalLog) StartIteration(n, total int) {
+	el.iteration = n
+	el.buf.WriteString(fmt.Sprintf("### Iteration %d/%d ###\n\n", n, total))
+}
+
+// LogIteration logs the result of one run of a repeated eval.
+func (el *EvalLog) LogIteration(passed bool, code, message string) {
+	status := "PASSED"
+	if !passed {
+		status = "FAILED"
+	}
+
+	el.buf.WriteString(fmt.Sprintf("--- Iteration %d: %s", len(el.iterations)+1, status))
+	if code != "" {
+		el.buf.WriteString(fmt.Sprintf(" [%s]", code))
+	}
+	if message != "" {
+		el.buf.WriteString(": " + message)
+	}
+	el.buf.WriteString("\n\n")
+
+	el.iterations = append(el.iterations, IterationResult{
+		Passed:  passed,
+		Code:    code,
+		Message: message,
+	})
+}
+
+// LogScores logs the named measurements recorded by an eval.
+func (el *EvalLog) LogScores(scores map[string]float64) {
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	el.buf.WriteString("--- Scores\n")
+	for _, name := range names {
+		el.buf.WriteString(fmt.Sprintf("%s: %.3f\n", name, scores[name]))
+	}
+	el.buf.WriteString("\n")
+
+	el.scores = scores
+}
+
+// LogGrid logs a two-dimensional pass/fail breakdown of an eval's checks.
+func (el *EvalLog) LogGrid(rowLabel, colLabel string, rows, cols []string, cells [][]bool) {
+	el.buf.WriteString(fmt.Sprintf("--- Grid: %s (rows) by %s (columns)\n", rowLabel, colLabel))
+	el.buf.WriteString(fmt.Sprintf("%-8s", ""))
+	for _, col := range cols {
+		el.buf.WriteString(fmt.Sprintf(" %8s", col))
+	}
+	el.buf.WriteString("\n")
+	for i, row := range rows {
+		el.buf.WriteString(fmt.Sprintf("%-8s", row))
+		for j := range cols {
+			mark := "FAIL"
+			if i < len(cells) && j < len(cells[i]) && cells[i][j] {
+				mark = "PASS"
+			}
+			el.buf.WriteString(fmt.Sprintf(" %8s", mark))
+		}
+		el.buf.WriteString("\n")
+	}
+	el.buf.WriteString("\n")
+
+	el.grid = &Grid{
+		RowLabel: rowLabel,
+		ColLabel: colLabel,
+		Rows:     rows,
+		Cols:     cols,
+		Cells:    cells,
+	}
+}
+
+// LogTimings logs the server-reported throughput of the eval's requests.
+func (el *EvalLog) LogTimings(t ServerTimings) {
+	el.buf.WriteString(fmt.Sprintf("--- Server timings: prompt %d tokens at %.1f tok/s, generation %d tokens at %.1f tok/s\n\n",
+		t.PromptTokens, t.PromptPerSecond, t.PredictedTokens, t.PredictedPerSecond))
+	el.timings = &t
+}
+
+// LogNotes logs deviations from the OpenAI API tolerated by the server
+// flavor.
+func (el *EvalLog) LogNotes(notes []string) {
+	for _, note := range notes {
+		el.buf.WriteString(fmt.Sprintf("--- Note: %s\n", note))
+	}
+	el.buf.WriteString("\n")
+	el.notes = notes
+}
+
+// LogSLOViolations logs how the eval exceeded its latency objectives.
+func (el *EvalLog) LogSLOViolations(violations []string) {
+	for _, v := range violations {
+		el.buf.WriteString(fmt.Sprintf("--- SLO violation: %s\n", v))
+	}
+	el.buf.WriteString("\n")
+	el.sloViolations = violations
+}
+
+// LogComparison logs the eval's outcome on the comparison server and how
+// it diverged from the server under test.
+func (el *EvalLog) LogComparison(passed bool, code string, divergences []string) {
+	status := "PASSED"
+	if !passed {
+		status = "FAILED " + code
+	}
+	el.buf.WriteString(fmt.Sprintf("--- Comparison server: %s\n", status))
+	for _, d := range divergences {
+		el.buf.WriteString(fmt.Sprintf("--- Diverged: %s\n", d))
+	}
+	el.buf.WriteString("\n")
+}
+
+// LogSamples logs the invalid responses of an eval that checks many, with
+// the prompts that produced them.
+func (el *EvalLog) LogSamples(samples []Sample) {
+	for _, s := range samples {
+		el.buf.WriteString(fmt.Sprintf("--- Invalid sample: %s: %s\n", s.Code, s.Message))
+		el.buf.WriteString(fmt.Sprintf("Prompt: %s\n", strconv.Quote(s.Prompt)))
+		el.buf.WriteString(fmt.Sprintf("Output: %s\n\n", strconv.Quote(s.Output)))
+	}
+	el.samples = samples
+}
+
+// LogViolations logs the lines of the eval's streams that were skipped
+// since they were not chunks.
+func (el *EvalLog) LogViolations(violations []Violation) {
+	for _, v := range violations {
+		el.buf.WriteString(fmt.Sprintf("--- Stream violation (%s, line %d): %s\n", v.Kind, v.Line, v.Message))
+		el.buf.WriteString(fmt.Sprintf("Data: %s\n\n", strconv.Quote(v.Data)))
+	}
+	el.violations = violations
+}
+
+// LogDiffs logs outputs that should have matched, as word diffs.
+func (el *EvalLog) LogDiffs(diffs []Diff) {
+	for _, d := range diffs {
+		el.buf.WriteString(fmt.Sprintf("--- Diff: %s → %s\n", d.ALabel, d.BLabel))
+		el.buf.WriteString(textdiff.Plain(textdiff.Words(d.A, d.B)))
+		el.buf.WriteString("\n\n")
+	}
+	el.diffs = diffs
+}
+
+// pendingArtifact is an artifact to be written when the eval ends.
+type pendingArtifact struct {
+	name string
+	data []byte
+}
+
+// LogArtifact attaches a file to the eval's result. It is written to the
+// eval's artifacts directory by End.
+func (el *EvalLog) LogArtifact(name string, data []byte) {
+	el.buf.WriteString(fmt.Sprintf("--- Artifact: %s (%d bytes)\n\n", name, len(data)))
+	el.artifacts = append(el.artifacts, pendingArtifact{name: name, data: data})
+}
+
 // LogResult logs the eval result.
-func (el *EvalLog) LogResult(passed bool, message string) {
+func (el *EvalLog) LogResult(passed bool, code, message string) {
 	status := "PASSED"
 	if !passed {
 		status = "FAILED"
 	}
 
 	el.buf.WriteString(fmt.Sprintf("=== Result: %s ===\n", status))
+	if code != "" {
+		el.buf.WriteString(fmt.Sprintf("Code: %s\n", code))
+	}
 	if message != "" {
 		el.buf.WriteString(message)
 		el.buf.WriteString("\n")
 	}
 
 	el.passed = passed
+	el.code = code
 	el.message = message
 }
 
 // End finishes logging for this eval and writes to file.
 func (el *EvalLog) End() error {
+	r := el.logger.redactor
+
 	filename := filepath.Join(el.logger.dir, el.name+".log")
-	if err := os.WriteFile(filename, el.buf.Bytes(), 0644); err != nil {
+	if err := os.WriteFile(filename, r.Bytes(el.buf.Bytes()), 0644); err != nil {
 		return fmt.Errorf("write log file: %w", err)
 	}
 
 	// Write JSONL file for streaming responses
 	if len(el.streamChunks) > 0 {
 		jsonlFile := filepath.Join(el.logger.dir, el.name+".stream.jsonl")
-		if err := os.WriteFile(jsonlFile, el.streamChunks, 0644); err != nil {
+		if err := os.WriteFile(jsonlFile, r.Bytes(el.streamChunks), 0644); err != nil {
 			return fmt.Errorf("write stream jsonl file: %w", err)
 		}
 	}
 
+	// Write JSONL file of the raw exchanges
+	if len(el.exchanges) > 0 {
+		var buf bytes.Buffer
+		for _, ex := range el.exchanges {
+			line, err := json.Marshal(r.exchange(ex))
+			if err != nil {
+				return fmt.Errorf("marshal exchange: %w", err)
+			}
+			buf.Write(line)
+			buf.WriteByte('\n')
+		}
+		turnsFile := filepath.Join(el.logger.dir, el.name+turnsSuffix)
+		if err := os.WriteFile(turnsFile, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("write turns jsonl file: %w", err)
+		}
+	}
+
+	iterations := make([]IterationResult, len(el.iterations))
+	for i, it := range el.iterations {
+		it.Message = r.String(it.Message)
+		iterations[i] = it
+	}
+	var notes []string
+	for _, note := range el.notes {
+		notes = append(notes, r.String(note))
+	}
+	var samples []Sample
+	for _, s := range el.samples {
+		s.Prompt = r.String(s.Prompt)
+		s.Message = r.String(s.Message)
+		s.Output = r.String(s.Output)
+		samples = append(samples, s)
+	}
+	var violations []Violation
+	for _, v := range el.violations {
+		v.Data = r.String(v.Data)
+		v.Message = r.String(v.Message)
+		violations = append(violations, v)
+	}
+	var diffs []Diff
+	for _, d := range el.diffs {
+		d.A = r.String(d.A)
+		d.B = r.String(d.B)
+		diffs = append(diffs, d)
+	}
+	var turns []TurnData
+	for _, t := range el.turns {
+		turns = append(turns, r.turn(t))
+	}
+
+	// Write artifacts, recording their paths relative to the run directory
+	// for links from the report
+	var artifacts []Artifact
+	if len(el.artifacts) > 0 {
+		dir := el.name + artifactsSuffix
+		if err := os.MkdirAll(filepath.Join(el.logger.dir, dir), 0755); err != nil {
+			return fmt.Errorf("create artifacts directory: %w", err)
+		}
+		for _, a := range el.artifacts {
+			path := filepath.ToSlash(filepath.Join(dir, DirName(a.name)))
+			if err := os.WriteFile(filepath.Join(el.logger.dir, path), r.Bytes(a.data), 0644); err != nil {
+				return fmt.Errorf("write artifact %s: %w", a.name, err)
+			}
+			artifacts = append(artifacts, Artifact{Name: a.name, Path: path})
+		}
+	}
+
 	// Register structured data with parent logger
-	el.logger.registerEval(EvalResult{
-		Name:    el.name,
-		Passed:  el.passed,
-		Message: el.message,
-		Turns:   el.turns,
+	return el.logger.registerEval(EvalResult{
+		Name:          el.name,
+		Passed:        el.passed,
+		Code:          el.code,
+		Message:       r.String(el.message),
+		Iterations:    iterations,
+		Scores:        el.scores,
+		Grid:          el.grid,
+		Timings:       el.timings,
+		Notes:         notes,
+		SLOViolations: el.sloViolations,
+		Samples:       samples,
+		Violations:    violations,
+		Diffs:         diffs,
+		Artifacts:     artifacts,
+		Turns:         turns,
 	})
-
-	return nil
 }
 
 // Close is a no-op for Logger. Individual EvalLogs handle their own cleanup.
@@ -237,14 +771,29 @@ func formatJSON(data []byte) []byte {
 	return buf.Bytes()
 }
 
+// jsonValue returns data as JSON: itself if valid, otherwise as a JSON
+// string. Empty data yields nil.
+func jsonValue(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return append(json.RawMessage(nil), data...)
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
+
 // reconstructFromChunks builds a synthetic ChatCompletion-shaped response
 // from JSONL stream chunks. Uses generic maps to avoid importing client types.
 func reconstructFromChunks(jsonl []byte) json.RawMessage {
 	var content strings.Builder
 	var reasoningContent strings.Builder
 	toolCalls := make(map[int]map[string]any) // index -> tool call object
+	arguments := make(map[int]*strings.Builder)
 
 	scanner := bufio.NewScanner(bytes.NewReader(jsonl))
+	scanner.Buffer(nil, 16*1024*1024)
 	for scanner.Scan() {
 		line := scanner.Bytes()
 		if len(line) == 0 {
@@ -300,6 +849,7 @@ func reconstructFromChunks(jsonl []byte) json.RawMessage {
 						},
 					}
 					toolCalls[idx] = existing
+					arguments[idx] = &strings.Builder{}
 				}
 
 				if id, ok := tc["id"].(string); ok && id != "" {
@@ -315,7 +865,7 @@ func reconstructFromChunks(jsonl []byte) json.RawMessage {
 						efn["name"] = name
 					}
 					if args, ok := fn["arguments"].(string); ok {
-						efn["arguments"] = efn["arguments"].(string) + args
+						arguments[idx].WriteString(args)
 					}
 				}
 			}
@@ -336,6 +886,7 @@ func reconstructFromChunks(jsonl []byte) json.RawMessage {
 		var tcs []any
 		for i := 0; i < len(toolCalls); i++ {
 			if tc, ok := toolCalls[i]; ok {
+				tc["function"].(map[string]any)["arguments"] = arguments[i].String()
 				tcs = append(tcs, tc)
 			}
 		}
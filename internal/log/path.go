@@ -0,0 +1,70 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// reservedNames are device names Windows reserves in every directory,
+// with or without an extension.
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// DirName returns a single path element naming a model's log directory.
+// Model names such as "meta-llama/Llama-3-8B" would otherwise nest
+// directories, so path separators and characters Windows forbids in file
+// names become "_", as do trailing dots and spaces, which Windows strips.
+// Reserved device names such as "CON" or "nul.txt" get a "_" after the
+// device name. The model's real name is recorded in the run's artifacts.
+func DirName(model string) string {
+	var b strings.Builder
+	for _, r := range model {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			b.WriteByte('_')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+
+	trimmed := strings.TrimRight(name, ". ")
+	name = trimmed + strings.Repeat("_", len(name)-len(trimmed))
+	if name == "" {
+		return "_"
+	}
+
+	// A reserved name stays reserved whatever its extension, so the stem
+	// is changed rather than the end
+	stem, ext, hasExt := strings.Cut(name, ".")
+	if reservedNames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+		name = stem + "_"
+		if hasExt {
+			name += "." + ext
+		}
+	}
+	return name
+}
+
+// FilesWithSuffix returns the paths of the files in dir whose names end in
+// suffix, sorted by name. Unlike filepath.Glob, it does not interpret
+// characters such as "[" in dir, which may come from a model name.
+func FilesWithSuffix(dir, suffix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), suffix) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files, nil
+}
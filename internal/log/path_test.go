@@ -0,0 +1,83 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDirName(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"qwen3", "qwen3"},
+		{"meta-llama/Llama-3-8B", "meta-llama_Llama-3-8B"},
+		{"qwen3:8b", "qwen3_8b"},
+		{`models\qwen3`, "models_qwen3"},
+		{`a<b>c"d|e?f*g`, "a_b_c_d_e_f_g"},
+		{"tab\there", "tab_here"},
+		{"CON", "CON_"},
+		{"con", "con_"},
+		{"nul.txt", "nul_.txt"},
+		{"COM1.gguf", "COM1_.gguf"},
+		{"CONSOLE", "CONSOLE"},
+		{"model.", "model_"},
+		{"model. .", "model___"},
+		{"model ", "model_"},
+		{"...", "___"},
+		{"", "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := DirName(tt.model); got != tt.want {
+				t.Errorf("DirName(%q) = %q, want %q", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilesWithSuffix(t *testing.T) {
+	// Brackets are glob metacharacters, and must be taken literally
+	dir := filepath.Join(t.TempDir(), "model[1]")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"b.turns.jsonl", "a.turns.jsonl", "a.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "c.turns.jsonl"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		suffix string
+		want   []string
+	}{
+		{".turns.jsonl", []string{"a.turns.jsonl", "b.turns.jsonl"}},
+		{".log", []string{"a.log"}},
+		{".json", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			got, err := FilesWithSuffix(dir, tt.suffix)
+			if err != nil {
+				t.Fatalf("FilesWithSuffix: %v", err)
+			}
+			var want []string
+			for _, name := range tt.want {
+				want = append(want, filepath.Join(dir, name))
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("FilesWithSuffix(%q) = %q, want %q", tt.suffix, got, want)
+			}
+		})
+	}
+
+	if _, err := FilesWithSuffix(filepath.Join(dir, "missing"), ".log"); err == nil {
+		t.Error("FilesWithSuffix of a missing directory returned no error")
+	}
+}
@@ -0,0 +1,164 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redactedText replaces every secret in logged artifacts.
+const redactedText = "***"
+
+// minSecretLength is the length below which secret values are not masked
+// wherever they appear. A short key such as "test" would mask unrelated
+// words; it is still masked where credentials are sent, by the patterns
+// below.
+const minSecretLength = 8
+
+// Credentials masked regardless of configuration.
+var (
+	// Values of JSON fields such as "api_key", "x-api-key", or
+	// "authorization", as sent in --extra fields or echoed by servers
+	secretFieldPattern = regexp.MustCompile(`("(?i:[a-z_-]*api[_-]?key|authorization)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// Query parameters carrying keys
+	secretQueryPattern = regexp.MustCompile(`(?i)([?&][a-z_-]*api[_-]?key=)[^&\s"]*`)
+	// Bearer tokens anywhere, e.g. in an echoed Authorization header
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+	// Authorization header lines of the text log
+	authHeaderPattern = regexp.MustCompile(`(?im)^(authorization:[ \t]*)\S.*$`)
+)
+
+// credentialPatterns pairs each credential pattern with its replacement
+// and with keywords, one of which every match contains. Matching the
+// patterns against a long stream takes far longer than looking for the
+// keywords, and most logged text has none.
+var credentialPatterns = []struct {
+	re       *regexp.Regexp
+	repl     string
+	keywords []string
+}{
+	{secretFieldPattern, `${1}"` + redactedText + `"`, []string{"api", "authorization"}},
+	{secretQueryPattern, "${1}" + redactedText, []string{"api"}},
+	{bearerPattern, "${1}" + redactedText, []string{"bearer"}},
+	{authHeaderPattern, "${1}" + redactedText, []string{"authorization"}},
+}
+
+// Redactor masks credentials in logged requests and responses so that log
+// directories and reports can be shared. A nil Redactor masks nothing.
+type Redactor struct {
+	secrets  []string
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor masking the given secret values, such as
+// API keys, and text matching the given regular expressions, in addition
+// to Authorization headers, Bearer tokens, and api_key fields. Secrets
+// shorter than minSecretLength are only masked by those.
+func NewRedactor(secrets, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, s := range secrets {
+		if len(s) >= minSecretLength {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// String returns s with all credentials masked.
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedText)
+	}
+	for _, p := range credentialPatterns {
+		if containsAnyFold(s, p.keywords) {
+			s = p.re.ReplaceAllString(s, p.repl)
+		}
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(s) {
+			s = re.ReplaceAllLiteralString(s, redactedText)
+		}
+	}
+	return s
+}
+
+// containsAnyFold reports whether s contains any of the lowercase ASCII
+// keywords, ignoring case.
+func containsAnyFold(s string, keywords []string) bool {
+	for _, kw := range keywords {
+		for i := 0; i+len(kw) <= len(s); i++ {
+			if s[i]|0x20 == kw[0] && strings.EqualFold(s[i:i+len(kw)], kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Bytes returns data with all credentials masked.
+func (r *Redactor) Bytes(data []byte) []byte {
+	if r == nil || len(data) == 0 {
+		return data
+	}
+	return []byte(r.String(string(data)))
+}
+
+// JSON returns a JSON value with all credentials masked. If masking a
+// configured pattern breaks the JSON, the masked text is returned as a
+// JSON string instead.
+func (r *Redactor) JSON(data json.RawMessage) json.RawMessage {
+	if r == nil || len(data) == 0 {
+		return data
+	}
+	masked := r.Bytes(data)
+	if json.Valid(masked) || !json.Valid(data) {
+		return masked
+	}
+	quoted, _ := json.Marshal(string(masked))
+	return quoted
+}
+
+// headers returns response headers with credentials masked.
+func (r *Redactor) headers(headers map[string]string) map[string]string {
+	if r == nil || headers == nil {
+		return headers
+	}
+	masked := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if strings.EqualFold(name, "authorization") {
+			value = redactedText
+		}
+		masked[name] = r.String(value)
+	}
+	return masked
+}
+
+// turn returns a turn with credentials masked.
+func (r *Redactor) turn(t TurnData) TurnData {
+	t.URL = r.String(t.URL)
+	t.RequestBody = r.JSON(t.RequestBody)
+	t.ResponseBody = r.JSON(t.ResponseBody)
+	t.Headers = r.headers(t.Headers)
+	return t
+}
+
+// exchange returns an exchange with credentials masked.
+func (r *Redactor) exchange(ex Exchange) Exchange {
+	ex.URL = r.String(ex.URL)
+	ex.RequestBody = r.JSON(ex.RequestBody)
+	ex.ResponseBody = r.JSON(ex.ResponseBody)
+	ex.StreamRaw = r.String(ex.StreamRaw)
+	ex.Headers = r.headers(ex.Headers)
+	return ex
+}
@@ -0,0 +1,48 @@
+package log
+
+import "testing"
+
+func TestRedactorSecretLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		in     string
+		want   string
+	}{
+		{
+			name:   "long secret masked anywhere",
+			secret: "sk-abcdef123456",
+			in:     `{"echo":"key sk-abcdef123456 leaked"}`,
+			want:   `{"echo":"key *** leaked"}`,
+		},
+		{
+			name:   "short secret leaves words alone",
+			secret: "test",
+			in:     `{"content":"testing the latest tests"}`,
+			want:   `{"content":"testing the latest tests"}`,
+		},
+		{
+			name:   "short secret masked as bearer token",
+			secret: "sk-1",
+			in:     "Authorization: Bearer sk-1\nask-1 question",
+			want:   "Authorization: ***\nask-1 question",
+		},
+		{
+			name:   "short secret masked in api_key field",
+			secret: "test",
+			in:     `{"api_key":"test","content":"test"}`,
+			want:   `{"api_key":"***","content":"test"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRedactor([]string{tt.secret}, nil)
+			if err != nil {
+				t.Fatalf("NewRedactor: %v", err)
+			}
+			if got := r.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runFile describes the run a log directory holds, since the directory's
+// name may be a sanitized model name or a label rather than the model and
+// start time.
+const runFile = "run.json"
+
+// RunMeta describes the run in a log directory.
+type RunMeta struct {
+	// Model is the model as given, before sanitizing for the directory name.
+	Model string `json:"model"`
+	Label string `json:"label,omitempty"`
+	// Started is in UTC.
+	Started time.Time `json:"started"`
+}
+
+// writeRunMeta writes run.json to a log directory.
+func writeRunMeta(dir string, meta RunMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", runFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, runFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", runFile, err)
+	}
+	return nil
+}
+
+// ReadRunMeta reads the description of the run in a log directory. It
+// returns nil if there is none, as for runs by older versions.
+func ReadRunMeta(dir string) (*RunMeta, error) {
+	data, err := os.ReadFile(filepath.Join(dir, runFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", runFile, err)
+	}
+	var meta RunMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", runFile, err)
+	}
+	return &meta, nil
+}
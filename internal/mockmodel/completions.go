@@ -0,0 +1,120 @@
+package mockmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+func (s *Server) handleCompletion(w http.ResponseWriter, r *http.Request) {
+	var req client.CompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.MaxTokens < 0 {
+		writeError(w, http.StatusBadRequest, "max_tokens must not be negative")
+		return
+	}
+	if req.Logprobs != nil && (*req.Logprobs < 0 || *req.Logprobs > maxTopLogprobs) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("logprobs must be between 0 and %d", maxTopLogprobs))
+		return
+	}
+
+	model, ok := s.model(req.Model)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", req.Model))
+		return
+	}
+
+	// A prompt is answered as a user message is, without reasoning or
+	// tool calls, and cut at the first stop sequence
+	chat := s.reply(&client.ChatCompletionRequest{Messages: []client.Message{{Role: "user", Content: req.Prompt}}})
+	rep := reply{content: chat.content, finishReason: "stop"}.stopAt(req.Stop).truncate(req.MaxTokens)
+	tokens := tokenize(rep.content)
+	usage := &client.Usage{
+		PromptTokens:     len(tokenize(req.Prompt)),
+		CompletionTokens: len(tokens),
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	prefix := ""
+	if req.Echo {
+		prefix = req.Prompt
+	}
+
+	id := fmt.Sprintf("cmpl-mock-%d", s.nextID.Add(1))
+	w.Header().Set("X-Request-Id", id)
+
+	if req.Stream {
+		s.streamCompletion(w, id, model, &req, prefix, tokens, rep.finishReason, usage)
+		return
+	}
+
+	choice := client.CompletionChoice{Text: prefix + strings.Join(tokens, ""), FinishReason: rep.finishReason}
+	if req.Logprobs != nil {
+		choice.Logprobs = completionLogprobs(tokens, *req.Logprobs, len(prefix))
+	}
+	writeJSON(w, client.CompletionResponse{
+		ID:      id,
+		Object:  "text_completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []client.CompletionChoice{choice},
+		Usage:   usage,
+	})
+}
+
+// streamCompletion sends a text completion as SSE chunks: the echoed
+// prompt if any, one chunk per token, with its logprobs if requested, and
+// the finish reason, followed by usage if requested and [DONE].
+func (s *Server) streamCompletion(w http.ResponseWriter, id, model string, req *client.CompletionRequest, prefix string, tokens []string, finishReason string, usage *client.Usage) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+
+	created := time.Now().Unix()
+	send := func(choices []client.CompletionChoice, usage *client.Usage) {
+		if s.config.ChunkDelay > 0 {
+			time.Sleep(s.config.ChunkDelay)
+		}
+		data, _ := json.Marshal(client.CompletionResponse{
+			ID:      id,
+			Object:  "text_completion",
+			Created: created,
+			Model:   model,
+			Choices: choices,
+			Usage:   usage,
+		})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if prefix != "" {
+		send([]client.CompletionChoice{{Text: prefix}}, nil)
+	}
+	offset := len(prefix)
+	for _, token := range tokens {
+		choice := client.CompletionChoice{Text: token}
+		if req.Logprobs != nil {
+			choice.Logprobs = completionLogprobs([]string{token}, *req.Logprobs, offset)
+		}
+		send([]client.CompletionChoice{choice}, nil)
+		offset += len(token)
+	}
+	send([]client.CompletionChoice{{FinishReason: finishReason}}, nil)
+
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		send([]client.CompletionChoice{}, usage)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
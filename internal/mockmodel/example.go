@@ -0,0 +1,250 @@
+package mockmodel
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// example returns a value valid against common JSON schemas: the first of
+// an enum or union, the least number in range, minimal arrays, strings of
+// the schema's format or pattern, and every property of an object. It
+// does not resolve $ref.
+func example(schema json.RawMessage) any {
+	var s map[string]any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return map[string]any{}
+	}
+	return exampleValue(s)
+}
+
+func exampleValue(s map[string]any) any {
+	if v, ok := s["const"]; ok {
+		return v
+	}
+	if enum, ok := s["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	for _, key := range []string{"anyOf", "oneOf", "allOf"} {
+		if options, ok := s[key].([]any); ok && len(options) > 0 {
+			if first, ok := options[0].(map[string]any); ok {
+				return exampleValue(first)
+			}
+		}
+	}
+
+	typ, _ := s["type"].(string)
+	if types, ok := s["type"].([]any); ok {
+		for _, t := range types {
+			if t, ok := t.(string); ok && t != "null" {
+				typ = t
+				break
+			}
+		}
+	}
+	if typ == "" {
+		if _, ok := s["properties"]; ok {
+			typ = "object"
+		}
+	}
+
+	switch typ {
+	case "object":
+		obj := map[string]any{}
+		props, _ := s["properties"].(map[string]any)
+		for name, prop := range props {
+			if prop, ok := prop.(map[string]any); ok {
+				obj[name] = exampleValue(prop)
+			}
+		}
+		return obj
+	case "array":
+		items, _ := s["items"].(map[string]any)
+		n := 1
+		if minItems, ok := s["minItems"].(float64); ok {
+			n = max(n, int(minItems))
+		}
+		if maxItems, ok := s["maxItems"].(float64); ok {
+			n = min(n, int(maxItems))
+		}
+		arr := make([]any, n)
+		for i := range arr {
+			arr[i] = exampleValue(items)
+		}
+		return arr
+	case "integer", "number":
+		return exampleNumber(s, typ == "integer")
+	case "boolean":
+		return true
+	case "null":
+		return nil
+	case "string":
+		return exampleString(s)
+	}
+	return "example"
+}
+
+// exampleNumber returns the schema's multipleOf, or 1 without one, if that
+// is in range, and else the multiple nearest the bound it falls outside. A
+// number between two bounds, one exclusive, without multipleOf is their
+// midpoint.
+func exampleNumber(s map[string]any, integer bool) float64 {
+	lo, hasLo := s["minimum"].(float64)
+	hi, hasHi := s["maximum"].(float64)
+	loExclusive, hiExclusive := false, false
+	if x, ok := s["exclusiveMinimum"].(float64); ok && (!hasLo || x >= lo) {
+		lo, hasLo, loExclusive = x, true, true
+	}
+	if x, ok := s["exclusiveMaximum"].(float64); ok && (!hasHi || x <= hi) {
+		hi, hasHi, hiExclusive = x, true, true
+	}
+
+	step, hasStep := s["multipleOf"].(float64)
+	if !hasStep || step <= 0 {
+		step, hasStep = 1, false
+	}
+	if !integer && !hasStep && hasLo && hasHi && (loExclusive || hiExclusive) {
+		return (lo + hi) / 2
+	}
+
+	v := step
+	if hasLo && (v < lo || loExclusive && v <= lo) {
+		v = math.Ceil(lo/step) * step
+		if loExclusive && v <= lo {
+			v += step
+		}
+	}
+	if hasHi && (v > hi || hiExclusive && v >= hi) {
+		v = math.Floor(hi/step) * step
+		if hiExclusive && v >= hi {
+			v -= step
+		}
+	}
+	if integer {
+		v = math.Ceil(v)
+	}
+	return v
+}
+
+// exampleString returns a string of the schema's format or pattern and
+// its length.
+func exampleString(s map[string]any) string {
+	str := "example"
+	switch s["format"] {
+	case "date":
+		str = "2025-01-15"
+	case "date-time":
+		str = "2025-01-15T12:00:00Z"
+	case "time":
+		str = "12:00:00Z"
+	case "email":
+		str = "user@example.com"
+	case "uri", "url":
+		str = "https://example.com"
+	case "uuid":
+		str = "123e4567-e89b-12d3-a456-426614174000"
+	case "ipv4":
+		str = "192.0.2.1"
+	case "ipv6":
+		str = "2001:db8::1"
+	}
+
+	var re *regexp.Regexp
+	if pattern, ok := s["pattern"].(string); ok {
+		if match, ok := matchPattern(pattern); ok {
+			str = match
+			re = regexp.MustCompile(pattern)
+		}
+	}
+	if minLength, ok := s["minLength"].(float64); ok && len(str) < int(minLength) {
+		// A pattern anchored at its end may not allow padding
+		padded := str + strings.Repeat("x", int(minLength)-len(str))
+		if re == nil || re.MatchString(padded) {
+			str = padded
+		}
+	}
+	if maxLength, ok := s["maxLength"].(float64); ok && len(str) > int(maxLength) {
+		str = str[:int(maxLength)]
+	}
+	return str
+}
+
+// matchPattern returns a short string matching a regular expression: the
+// fewest repetitions allowed, the first alternative, and a letter or digit
+// from each character class where it has one. It reports false for a
+// pattern Go cannot parse, such as one with lookaround.
+func matchPattern(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if !writeMatch(&b, re.Simplify()) {
+		return "", false
+	}
+	// Anchors in the middle of a pattern can make it unsatisfiable
+	if !regexp.MustCompile(pattern).MatchString(b.String()) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// writeMatch writes a string matching re to b, reporting false if re
+// matches nothing.
+func writeMatch(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		b.WriteRune(classRune(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte('a')
+	case syntax.OpCapture:
+		return writeMatch(b, re.Sub[0])
+	case syntax.OpPlus:
+		return writeMatch(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for range re.Min {
+			if !writeMatch(b, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writeMatch(b, sub) {
+				return false
+			}
+		}
+	case syntax.OpAlternate:
+		return writeMatch(b, re.Sub[0])
+	}
+	// Empty matches, anchors, and the optional operators * and ? match the
+	// empty string
+	return true
+}
+
+// classRune returns a rune of a character class, given as pairs of
+// inclusive ranges: a, 0, or A if the class has them, else its first
+// printable rune.
+func classRune(ranges []rune) rune {
+	for _, r := range []rune{'a', '0', 'A'} {
+		for i := 0; i < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				return r
+			}
+		}
+	}
+	for i := 0; i < len(ranges); i += 2 {
+		if ranges[i+1] >= ' ' {
+			return max(ranges[i], ' ')
+		}
+	}
+	return ranges[0]
+}
@@ -0,0 +1,72 @@
+package mockmodel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aldehir/llm-serving-tests/internal/jsonschema"
+)
+
+func TestExampleValidates(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"pattern", `{"type": "string", "pattern": "^[0-9]{5}$"}`},
+		{"pattern with literal", `{"type": "string", "pattern": "^[A-Z]{3}-[0-9]{3}$"}`},
+		{"pattern and min length", `{"type": "string", "pattern": "^C[0-9]+", "minLength": 10}`},
+		{"any of", `{"anyOf": [{"type": "object", "properties": {"type": {"const": "card"}, "last4": {"type": "string", "pattern": "^[0-9]{4}$"}}, "required": ["type", "last4"]}, {"type": "null"}]}`},
+		{"exclusive bounds", `{"type": "number", "exclusiveMinimum": -5, "exclusiveMaximum": 0}`},
+		{"exclusive integer bounds", `{"type": "integer", "exclusiveMinimum": 1, "exclusiveMaximum": 3}`},
+		{"minimum above one", `{"type": "number", "minimum": 10, "maximum": 30}`},
+		{"maximum below one", `{"type": "integer", "maximum": -2}`},
+		{"multiple of", `{"type": "integer", "minimum": 15, "maximum": 120, "multipleOf": 15}`},
+		{"multiple of above minimum", `{"type": "number", "minimum": 1, "multipleOf": 0.25}`},
+		{"time", `{"type": "string", "format": "time"}`},
+		{"formats", `{"type": "object", "properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"email": {"type": "string", "format": "email"},
+			"date": {"type": "string", "format": "date"},
+			"at": {"type": "string", "format": "date-time"},
+			"ipv4": {"type": "string", "format": "ipv4"},
+			"ipv6": {"type": "string", "format": "ipv6"},
+			"uri": {"type": "string", "format": "uri"}
+		}}`},
+		{"array", `{"type": "array", "items": {"type": "string", "pattern": "^x+$"}, "minItems": 2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := jsonschema.Compile([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			value := example(json.RawMessage(tt.schema))
+			if err := schema.Validate(value); err != nil {
+				t.Errorf("example %v: %v", value, err)
+			}
+		})
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		ok      bool
+	}{
+		{`^[0-9]{5}$`, "00000", true},
+		{`^C[0-9]{4}$`, "C0000", true},
+		{`^(foo|bar)+\.txt$`, "foo.txt", true},
+		{`[^\s]`, "a", true},
+		{`a$b`, "", false},
+		{`(?=a)`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, ok := matchPattern(tt.pattern)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("matchPattern(%q) = %q, %v, want %q, %v", tt.pattern, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
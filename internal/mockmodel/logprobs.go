@@ -0,0 +1,90 @@
+package mockmodel
+
+import (
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// maxTopLogprobs is the most alternatives a request may ask for per token,
+// as in the OpenAI API.
+const maxTopLogprobs = 20
+
+// alternativeTokens are the less likely tokens offered in top_logprobs
+// after the sampled one, enough for maxTopLogprobs whichever was sampled.
+var alternativeTokens = []string{
+	" the", " a", " and", " of", " to", " in", " is", " it", " that", " for",
+	" on", " with", " as", " at", " by", " from", " or", " an", " be", " this",
+	" not",
+}
+
+// logprob returns the log probability the mock model gives a token: a
+// stable value in (-1, 0].
+func logprob(token string) float64 {
+	return -float64(tokenID(token)%100) / 100
+}
+
+// topLogprobs returns the n most likely tokens in place of token, most
+// likely first: token itself, then alternatives with lower logprobs.
+func topLogprobs(token string, n int) []client.TopLogprob {
+	if n <= 0 {
+		return nil
+	}
+	top := []client.TopLogprob{{Token: token, Logprob: logprob(token), Bytes: tokenBytes(token)}}
+	for _, alt := range alternativeTokens {
+		if len(top) == n {
+			break
+		}
+		if alt == token {
+			continue
+		}
+		top = append(top, client.TopLogprob{
+			Token:   alt,
+			Logprob: top[len(top)-1].Logprob - 1,
+			Bytes:   tokenBytes(alt),
+		})
+	}
+	return top
+}
+
+// chatLogprobs returns the logprobs of tokens in the chat format, with n
+// alternatives each.
+func chatLogprobs(tokens []string, n int) *client.Logprobs {
+	lp := &client.Logprobs{Content: []client.TokenLogprob{}}
+	for _, token := range tokens {
+		lp.Content = append(lp.Content, client.TokenLogprob{
+			Token:       token,
+			Logprob:     logprob(token),
+			Bytes:       tokenBytes(token),
+			TopLogprobs: topLogprobs(token, n),
+		})
+	}
+	return lp
+}
+
+// completionLogprobs returns the logprobs of tokens in the legacy text
+// completions format, with n alternatives each. offset is the position in
+// the text of the first token.
+func completionLogprobs(tokens []string, n, offset int) *client.CompletionLogprobs {
+	lp := &client.CompletionLogprobs{}
+	for _, token := range tokens {
+		p := logprob(token)
+		top := map[string]float64{}
+		for _, alt := range topLogprobs(token, n) {
+			top[alt.Token] = alt.Logprob
+		}
+		lp.Tokens = append(lp.Tokens, token)
+		lp.TokenLogprobs = append(lp.TokenLogprobs, &p)
+		lp.TopLogprobs = append(lp.TopLogprobs, top)
+		lp.TextOffset = append(lp.TextOffset, offset)
+		offset += len(token)
+	}
+	return lp
+}
+
+// tokenBytes returns the UTF-8 bytes of a token, as logprobs list them.
+func tokenBytes(token string) []int {
+	b := make([]int, len(token))
+	for i := range len(token) {
+		b[i] = int(token[i])
+	}
+	return b
+}
@@ -0,0 +1,154 @@
+package mockmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// reply is the mock model's response to a request.
+type reply struct {
+	content      string
+	reasoning    string
+	toolCalls    []client.ToolCall
+	finishReason string
+}
+
+// reply returns the response to req: the first scripted response matching
+// the last user message, or else a call of the tool the request asks for,
+// an example of the requested JSON schema, or an echo of the last message.
+func (s *Server) reply(req *client.ChatCompletionRequest) reply {
+	lastUser := ""
+	for _, m := range req.Messages {
+		if m.Role == "user" {
+			lastUser = messageText(m)
+		}
+	}
+	last := req.Messages[len(req.Messages)-1]
+
+	if r := s.config.Script.find(lastUser); r != nil {
+		rep := reply{content: r.Content, reasoning: r.ReasoningContent, finishReason: "stop"}
+		for _, tc := range r.ToolCalls {
+			args := string(tc.Arguments)
+			if args == "" {
+				args = "{}"
+			}
+			rep.toolCalls = append(rep.toolCalls, s.toolCall(tc.Name, args))
+		}
+		if len(rep.toolCalls) > 0 {
+			rep.finishReason = "tool_calls"
+		}
+		return rep
+	}
+
+	if tool := toolToCall(req, last.Role); tool != nil {
+		args, _ := json.Marshal(example(tool.Parameters))
+		return reply{
+			toolCalls:    []client.ToolCall{s.toolCall(tool.Name, string(args))},
+			finishReason: "tool_calls",
+		}
+	}
+
+	if f := req.ResponseFormat; f != nil {
+		switch {
+		case f.Type == "json_schema" && f.JSONSchema != nil:
+			data, _ := json.Marshal(example(f.JSONSchema.Schema))
+			return reply{content: string(data), finishReason: "stop"}
+		case f.Type == "json_object":
+			return reply{content: `{"echo": true}`, finishReason: "stop"}
+		}
+	}
+
+	if last.Role == "tool" {
+		return reply{content: "The tool returned: " + messageText(last), finishReason: "stop"}
+	}
+	return reply{content: "You said: " + lastUser, finishReason: "stop"}
+}
+
+// toolCall returns a tool call with a fresh id.
+func (s *Server) toolCall(name, args string) client.ToolCall {
+	return client.ToolCall{
+		ID:       fmt.Sprintf("call_mock_%d", s.nextID.Add(1)),
+		Type:     "function",
+		Function: client.ToolCallFunction{Name: name, Arguments: args},
+	}
+}
+
+// toolToCall returns the tool the request asks to be called: the tool
+// named by tool_choice, or the first tool when answering a user message
+// or when tool_choice is "required". It returns nil for tool_choice "none".
+func toolToCall(req *client.ChatCompletionRequest, lastRole string) *client.ToolFunction {
+	if len(req.Tools) == 0 {
+		return nil
+	}
+
+	switch choice := req.ToolChoice.(type) {
+	case string:
+		if choice == "none" {
+			return nil
+		}
+		if choice == "required" {
+			return &req.Tools[0].Function
+		}
+	case map[string]any:
+		if fn, ok := choice["function"].(map[string]any); ok {
+			for i := range req.Tools {
+				if req.Tools[i].Function.Name == fn["name"] {
+					return &req.Tools[i].Function
+				}
+			}
+		}
+	}
+
+	if lastRole != "user" {
+		return nil
+	}
+	return &req.Tools[0].Function
+}
+
+// truncate cuts the reply to at most limit tokens, reasoning first, with
+// finish_reason "length". Tool calls are dropped from a cut reply.
+func (r reply) truncate(limit int) reply {
+	if limit <= 0 || r.tokens() <= limit {
+		return r
+	}
+
+	reasoning := tokenize(r.reasoning)
+	content := tokenize(r.content)
+	if len(reasoning) > limit {
+		reasoning = reasoning[:limit]
+	}
+	content = content[:min(len(content), limit-len(reasoning))]
+
+	return reply{
+		reasoning:    strings.Join(reasoning, ""),
+		content:      strings.Join(content, ""),
+		finishReason: "length",
+	}
+}
+
+// stopAt cuts the reply's content before the first of the stop sequences,
+// with finish_reason "stop". Tool calls are dropped from a cut reply.
+func (r reply) stopAt(stops []string) reply {
+	cut := -1
+	for _, stop := range stops {
+		if i := strings.Index(r.content, stop); stop != "" && i >= 0 && (cut < 0 || i < cut) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return r
+	}
+	return reply{reasoning: r.reasoning, content: r.content[:cut], finishReason: "stop"}
+}
+
+// tokens returns the number of tokens the reply counts as.
+func (r reply) tokens() int {
+	n := len(tokenize(r.reasoning)) + len(tokenize(r.content))
+	for _, tc := range r.toolCalls {
+		n += 1 + len(tokenize(tc.Function.Arguments))
+	}
+	return n
+}
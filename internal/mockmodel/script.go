@@ -0,0 +1,75 @@
+package mockmodel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Script is a list of scripted responses, loaded from a JSON file.
+type Script struct {
+	Responses []Response `json:"responses"`
+}
+
+// Response is a scripted response, sent for requests whose last user
+// message matches Match.
+type Response struct {
+	// Match is a regular expression matched against the last user message.
+	// Empty matches every request.
+	Match            string     `json:"match,omitempty"`
+	Content          string     `json:"content,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ToolCall is a scripted tool call.
+type ToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// LoadScript reads a script file.
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+
+	// Reject unknown fields so that typos fail up front
+	var s Script
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("parse script %s: %w", path, err)
+	}
+
+	for i := range s.Responses {
+		r := &s.Responses[i]
+		if r.pattern, err = regexp.Compile(r.Match); err != nil {
+			return nil, fmt.Errorf("responses[%d].match: invalid pattern %q: %w", i, r.Match, err)
+		}
+		for j, tc := range r.ToolCalls {
+			if tc.Name == "" {
+				return nil, fmt.Errorf("responses[%d].tool_calls[%d]: name is required", i, j)
+			}
+		}
+	}
+	return &s, nil
+}
+
+// find returns the first response matching the last user message, or nil.
+func (s *Script) find(lastUser string) *Response {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Responses {
+		if r := &s.Responses[i]; r.pattern.MatchString(lastUser) {
+			return r
+		}
+	}
+	return nil
+}
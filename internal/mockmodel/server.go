@@ -0,0 +1,342 @@
+// Package mockmodel serves an echo model implementing enough of the OpenAI
+// chat and text completions APIs, with scripted responses, streaming, tool
+// calls, and logprobs, to run the suite, its reports, and its exports end
+// to end without a real model.
+package mockmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// DefaultModel is the model id served when none is configured.
+const DefaultModel = "mock"
+
+// Config configures a mock model server.
+type Config struct {
+	// Model is the model id listed by /models.
+	Model string
+	// Script holds scripted responses, tried before the echo behavior.
+	Script *Script
+	// ChunkDelay is the delay before each streamed chunk.
+	ChunkDelay time.Duration
+}
+
+// Server is the mock model HTTP handler.
+type Server struct {
+	config  Config
+	mux     *http.ServeMux
+	created int64
+	nextID  atomic.Int64
+}
+
+// New returns a mock model server.
+func New(cfg Config) *Server {
+	if cfg.Model == "" {
+		cfg.Model = DefaultModel
+	}
+	s := &Server{
+		config:  cfg,
+		mux:     http.NewServeMux(),
+		created: time.Now().Unix(),
+	}
+
+	// llama.cpp serves /health, /apply-template, and /tokenize at the root
+	s.mux.HandleFunc("GET /v1/models", s.handleModels)
+	s.mux.HandleFunc("POST /v1/chat/completions", s.handleChat)
+	s.mux.HandleFunc("POST /v1/completions", s.handleCompletion)
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("POST /apply-template", s.handleApplyTemplate)
+	s.mux.HandleFunc("POST /tokenize", s.handleTokenize)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, client.ModelList{
+		Object: "list",
+		Data: []client.Model{
+			{ID: s.config.Model, Object: "model", Created: s.created, OwnedBy: "llm-serve-test"},
+		},
+	})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleApplyTemplate(w http.ResponseWriter, r *http.Request) {
+	var req client.ApplyTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	writeJSON(w, client.ApplyTemplateResponse{Prompt: render(req.Messages)})
+}
+
+func (s *Server) handleTokenize(w http.ResponseWriter, r *http.Request) {
+	var req client.TokenizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	words := tokenize(req.Content)
+	ids := make([]int, len(words))
+	for i, word := range words {
+		ids[i] = tokenID(word)
+	}
+	writeJSON(w, client.TokenizeResponse{Tokens: ids})
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req client.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "messages must not be empty")
+		return
+	}
+	if msg := validate(&req); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	model, ok := s.model(req.Model)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", req.Model))
+		return
+	}
+	n := max(req.N, 1)
+	limit := req.MaxCompletionTokens
+	if limit == 0 {
+		limit = req.MaxTokens
+	}
+
+	rep := s.reply(&req).truncate(limit)
+	usage := &client.Usage{
+		PromptTokens:     len(tokenize(render(req.Messages))),
+		CompletionTokens: rep.tokens() * n,
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	id := fmt.Sprintf("chatcmpl-mock-%d", s.nextID.Add(1))
+	w.Header().Set("X-Request-Id", id)
+
+	if req.Stream {
+		s.stream(w, id, model, &req, rep, usage)
+		return
+	}
+
+	var logprobs *client.Logprobs
+	if req.Logprobs {
+		logprobs = chatLogprobs(tokenize(rep.content), req.TopLogprobs)
+	}
+
+	resp := client.ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Usage:   usage,
+	}
+	for i := range n {
+		resp.Choices = append(resp.Choices, client.Choice{
+			Index: i,
+			Message: client.ResponseMessage{
+				Role:             "assistant",
+				Content:          rep.content,
+				ReasoningContent: rep.reasoning,
+				ToolCalls:        rep.toolCalls,
+			},
+			FinishReason: rep.finishReason,
+			Logprobs:     logprobs,
+		})
+	}
+	writeJSON(w, resp)
+}
+
+// model returns the model a request names, or the served model if it
+// names none. It reports false for a model other than the served one.
+func (s *Server) model(name string) (string, bool) {
+	if name == "" {
+		return s.config.Model, true
+	}
+	return name, name == s.config.Model
+}
+
+// validate returns what is wrong with a request, or "" if nothing is.
+func validate(req *client.ChatCompletionRequest) string {
+	for i, m := range req.Messages {
+		switch m.Role {
+		case "system", "developer", "user", "assistant", "tool":
+		default:
+			return fmt.Sprintf("messages[%d]: invalid role %q", i, m.Role)
+		}
+	}
+	if req.MaxTokens < 0 || req.MaxCompletionTokens < 0 {
+		return "max_tokens must not be negative"
+	}
+	if req.TopLogprobs < 0 || req.TopLogprobs > maxTopLogprobs {
+		return fmt.Sprintf("top_logprobs must be between 0 and %d", maxTopLogprobs)
+	}
+	for i, t := range req.Tools {
+		if len(t.Function.Parameters) == 0 {
+			continue
+		}
+		var schema struct {
+			Properties map[string]any `json:"properties"`
+			Required   []string       `json:"required"`
+		}
+		if err := json.Unmarshal(t.Function.Parameters, &schema); err != nil {
+			return fmt.Sprintf("tools[%d].function.parameters: invalid schema: %v", i, err)
+		}
+	}
+	return ""
+}
+
+// stream sends the reply to req as SSE chunks: the role, one chunk per
+// token of reasoning and content, with its logprobs if requested, each
+// tool call, and the finish reason, followed by usage if requested and
+// [DONE].
+func (s *Server) stream(w http.ResponseWriter, id, model string, req *client.ChatCompletionRequest, rep reply, usage *client.Usage) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+
+	created := time.Now().Unix()
+	send := func(choices []client.ChunkChoice, usage *client.Usage) {
+		if s.config.ChunkDelay > 0 {
+			time.Sleep(s.config.ChunkDelay)
+		}
+		data, _ := json.Marshal(client.ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   model,
+			Choices: choices,
+			Usage:   usage,
+		})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	delta := func(index int, d client.ChunkDelta) {
+		send([]client.ChunkChoice{{Index: index, Delta: d}}, nil)
+	}
+
+	for i := range max(req.N, 1) {
+		delta(i, client.ChunkDelta{Role: "assistant"})
+		for _, token := range tokenize(rep.reasoning) {
+			delta(i, client.ChunkDelta{ReasoningContent: token})
+		}
+		for _, token := range tokenize(rep.content) {
+			choice := client.ChunkChoice{Index: i, Delta: client.ChunkDelta{Content: token}}
+			if req.Logprobs {
+				choice.Logprobs = chatLogprobs([]string{token}, req.TopLogprobs)
+			}
+			send([]client.ChunkChoice{choice}, nil)
+		}
+		for j, tc := range rep.toolCalls {
+			delta(i, client.ChunkDelta{ToolCalls: []client.ToolCallDelta{
+				{Index: j, ID: tc.ID, Type: tc.Type, Function: client.ToolCallFunctionDelta{Name: tc.Function.Name}},
+			}})
+			delta(i, client.ChunkDelta{ToolCalls: []client.ToolCallDelta{
+				{Index: j, Function: client.ToolCallFunctionDelta{Arguments: tc.Function.Arguments}},
+			}})
+		}
+		finish := rep.finishReason
+		send([]client.ChunkChoice{{Index: i, FinishReason: &finish}}, nil)
+	}
+
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		send([]client.ChunkChoice{}, usage)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// tokenPattern splits text into the mock model's tokens: words with their
+// leading whitespace.
+var tokenPattern = regexp.MustCompile(`\s*\S+`)
+
+// tokenize splits text into tokens. Trailing whitespace is dropped.
+func tokenize(text string) []string {
+	return tokenPattern.FindAllString(text, -1)
+}
+
+// tokenID returns a stable id for a token.
+func tokenID(token string) int {
+	// FNV-1a, folded into the range of a typical vocabulary
+	h := uint32(2166136261)
+	for i := 0; i < len(token); i++ {
+		h ^= uint32(token[i])
+		h *= 16777619
+	}
+	return int(h % 100000)
+}
+
+// render renders messages in a simple chat template, marking reasoning
+// with <think> and tool calls with <tool_call>, and ends with the
+// generation prompt.
+func render(messages []client.Message) string {
+	var b strings.Builder
+	for _, m := range messages {
+		fmt.Fprintf(&b, "<|%s|>\n", m.Role)
+		if m.ToolCallID != "" {
+			fmt.Fprintf(&b, "[%s] ", m.ToolCallID)
+		}
+		if m.ReasoningContent != "" {
+			fmt.Fprintf(&b, "<think>%s</think>\n", m.ReasoningContent)
+		}
+		for _, tc := range m.ToolCalls {
+			fmt.Fprintf(&b, "<tool_call>%s %s %s</tool_call>\n", tc.ID, tc.Function.Name, tc.Function.Arguments)
+		}
+		b.WriteString(messageText(m))
+		b.WriteString("\n")
+	}
+	b.WriteString("<|assistant|>\n")
+	return b.String()
+}
+
+// messageText returns the text of a message, joining text content parts.
+func messageText(m client.Message) string {
+	if len(m.ContentParts) == 0 {
+		return m.Content
+	}
+	var parts []string
+	for _, p := range m.ContentParts {
+		if p.Type == "text" {
+			parts = append(parts, p.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the OpenAI format.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{"message": msg, "type": "invalid_request_error", "code": status},
+	})
+}
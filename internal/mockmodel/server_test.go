@@ -0,0 +1,173 @@
+package mockmodel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+)
+
+// newTestClient serves a mock model and returns a client of it asking for
+// model.
+func newTestClient(t *testing.T, model string) *client.Client {
+	t.Helper()
+	srv := httptest.NewServer(New(Config{}))
+	t.Cleanup(srv.Close)
+	return client.New(client.Config{BaseURL: srv.URL + "/v1", Model: model})
+}
+
+func modeName(streaming bool) string {
+	if streaming {
+		return "streaming"
+	}
+	return "blocking"
+}
+
+// completion sends req blocking or streaming and returns the first choice
+// and the usage.
+func completion(t *testing.T, c *client.Client, req client.CompletionRequest, streaming bool) (client.CompletionChoice, *client.Usage) {
+	t.Helper()
+	if streaming {
+		result, err := c.CompletionStream(context.Background(), req)
+		if err != nil {
+			t.Fatalf("CompletionStream: %v", err)
+		}
+		return client.CompletionChoice{Text: result.Text, FinishReason: result.FinishReason, Logprobs: result.Logprobs}, result.Usage
+	}
+	resp, err := c.Completion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Completion: %v", err)
+	}
+	if len(resp.Choices) != 1 {
+		t.Fatalf("got %d choices, want 1", len(resp.Choices))
+	}
+	return resp.Choices[0], resp.Usage
+}
+
+func TestCompletion(t *testing.T) {
+	const prompt = "The three primary colors are red, yellow, and"
+	for _, streaming := range []bool{false, true} {
+		t.Run(modeName(streaming), func(t *testing.T) {
+			c := newTestClient(t, DefaultModel)
+			topN := 2
+			choice, usage := completion(t, c, client.CompletionRequest{
+				Prompt:    prompt,
+				MaxTokens: 3,
+				Echo:      true,
+				Logprobs:  &topN,
+			}, streaming)
+
+			if want := prompt + "You said: The"; choice.Text != want {
+				t.Errorf("text = %q, want %q", choice.Text, want)
+			}
+			if choice.FinishReason != "length" {
+				t.Errorf("finish_reason = %q, want length", choice.FinishReason)
+			}
+			if usage == nil || usage.CompletionTokens != 3 {
+				t.Fatalf("usage = %+v, want 3 completion tokens", usage)
+			}
+
+			lp := choice.Logprobs
+			if lp == nil {
+				t.Fatal("no logprobs")
+			}
+			if got := strings.Join(lp.Tokens, ""); got != "You said: The" {
+				t.Errorf("tokens = %q, want the generated text", got)
+			}
+			for i := range lp.Tokens {
+				if p := lp.TokenLogprobs[i]; p == nil || *p > 0 {
+					t.Errorf("token %d: logprob %v, want one at most 0", i, p)
+				}
+				if n := len(lp.TopLogprobs[i]); n != topN {
+					t.Errorf("token %d: %d top_logprobs, want %d", i, n, topN)
+				}
+			}
+			if lp.TextOffset[0] != len(prompt) {
+				t.Errorf("text_offset[0] = %d, want %d after the echoed prompt", lp.TextOffset[0], len(prompt))
+			}
+		})
+	}
+}
+
+func TestCompletionStop(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		t.Run(modeName(streaming), func(t *testing.T) {
+			c := newTestClient(t, DefaultModel)
+			choice, _ := completion(t, c, client.CompletionRequest{
+				Prompt: "1, 2, 3, 4,",
+				Stop:   []string{"3", "2"},
+			}, streaming)
+
+			if choice.Text != "You said: 1," {
+				t.Errorf("text = %q, want it cut before the first stop sequence", choice.Text)
+			}
+			if choice.FinishReason != "stop" {
+				t.Errorf("finish_reason = %q, want stop", choice.FinishReason)
+			}
+		})
+	}
+}
+
+func TestChatLogprobs(t *testing.T) {
+	const topN = 3
+	req := client.ChatCompletionRequest{
+		Messages:    []client.Message{{Role: "user", Content: "Count from 1 to 10."}},
+		Logprobs:    true,
+		TopLogprobs: topN,
+	}
+	for _, streaming := range []bool{false, true} {
+		t.Run(modeName(streaming), func(t *testing.T) {
+			c := newTestClient(t, DefaultModel)
+			var logprobs []client.TokenLogprob
+			var usage *client.Usage
+			if streaming {
+				result, err := c.ChatCompletionStream(context.Background(), req)
+				if err != nil {
+					t.Fatalf("ChatCompletionStream: %v", err)
+				}
+				logprobs, usage = result.Logprobs, result.Usage
+			} else {
+				resp, err := c.ChatCompletion(context.Background(), req)
+				if err != nil {
+					t.Fatalf("ChatCompletion: %v", err)
+				}
+				if lp := resp.Choices[0].Logprobs; lp != nil {
+					logprobs = lp.Content
+				}
+				usage = resp.Usage
+			}
+
+			if usage == nil || len(logprobs) != usage.CompletionTokens {
+				t.Fatalf("got logprobs for %d tokens, usage %+v", len(logprobs), usage)
+			}
+			for i, lp := range logprobs {
+				if len(lp.TopLogprobs) != topN {
+					t.Fatalf("token %d: %d top_logprobs, want %d", i, len(lp.TopLogprobs), topN)
+				}
+				if top := lp.TopLogprobs[0]; top.Token != lp.Token || top.Logprob != lp.Logprob {
+					t.Errorf("token %d: most likely alternative %+v is not the sampled token %q", i, top, lp.Token)
+				}
+				for j := 1; j < topN; j++ {
+					if lp.TopLogprobs[j].Logprob >= lp.TopLogprobs[j-1].Logprob {
+						t.Errorf("token %d: top_logprobs not in descending order", i)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownModel(t *testing.T) {
+	c := newTestClient(t, "other")
+	_, err := c.ChatCompletion(context.Background(), client.ChatCompletionRequest{
+		Messages: []client.Message{{Role: "user", Content: "Say hello."}},
+	})
+	var statusErr *client.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("got error %v, want a 404", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package profile stores named eval selections for reuse across runs.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Profile is a saved selection of evals.
+type Profile struct {
+	// Evals lists eval names (without mode suffix) to run.
+	Evals []string `json:"evals"`
+}
+
+// Dir returns the directory where profiles are stored.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(base, "llm-serve-test", "profiles"), nil
+}
+
+// path returns the file path of a named profile.
+func path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Load reads a named profile.
+func Load(name string) (*Profile, error) {
+	p, err := path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read profile: %w", err)
+	}
+
+	var prof Profile
+	if err := json.Unmarshal(data, &prof); err != nil {
+		return nil, fmt.Errorf("parse profile %q: %w", name, err)
+	}
+	return &prof, nil
+}
+
+// Save writes a named profile, replacing any existing profile of that name.
+func Save(name string, prof *Profile) error {
+	p, err := path(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("create profile directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(prof, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal profile: %w", err)
+	}
+
+	if err := os.WriteFile(p, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write profile: %w", err)
+	}
+	return nil
+}
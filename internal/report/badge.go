@@ -0,0 +1,121 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+)
+
+// badgeFile is the run's SVG badge, for embedding in READMEs and
+// dashboards.
+const badgeFile = "badge.svg"
+
+// Compatibility tiers of a run, from its pass rate.
+const (
+	// tierFull is a run whose evals all passed without compatibility
+	// notes or SLO violations.
+	tierFull = "full"
+	// tierCompatible is a run whose evals all passed, some with notes or
+	// SLO violations.
+	tierCompatible = "compatible"
+	// tierPartial is a run with at least partialPassRate of its evals
+	// passing.
+	tierPartial = "partial"
+	// tierIncompatible is a run with fewer evals passing.
+	tierIncompatible = "incompatible"
+)
+
+// partialPassRate is the lowest pass rate of the partial tier.
+const partialPassRate = 0.8
+
+// badgeColors maps tiers to badge colors.
+var badgeColors = map[string]string{
+	tierFull:         "#4c1",
+	tierCompatible:   "#97ca00",
+	tierPartial:      "#dfb317",
+	tierIncompatible: "#e05d44",
+}
+
+// compatibilityTier returns the tier of a run, or "" if it ran no evals.
+func compatibilityTier(data reportData) string {
+	switch {
+	case data.Total == 0:
+		return ""
+	case data.Passed == data.Total && data.Noted == 0 && data.SLOViolated == 0:
+		return tierFull
+	case data.Passed == data.Total:
+		return tierCompatible
+	case float64(data.Passed)/float64(data.Total) >= partialPassRate:
+		return tierPartial
+	}
+	return tierIncompatible
+}
+
+// passScore returns the percentage of evals that passed, rounded down so
+// that only a run passing everything scores 100.
+func passScore(data reportData) int {
+	if data.Total == 0 {
+		return 0
+	}
+	return data.Passed * 100 / data.Total
+}
+
+// writeBadge writes badge.svg for a run directory, showing the pass count,
+// compatibility tier, and score.
+func writeBadge(dir string, data reportData) error {
+	value, color := "no evals", "#9f9f9f"
+	if data.Tier != "" {
+		value = fmt.Sprintf("%d/%d | %s | %d%%", data.Passed, data.Total, data.Tier, passScore(data))
+		color = badgeColors[data.Tier]
+	}
+	svg := renderBadge("llm-serve-test", value, color)
+	if err := os.WriteFile(filepath.Join(dir, badgeFile), []byte(svg), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", badgeFile, err)
+	}
+	return nil
+}
+
+// renderBadge returns a flat two-part badge in the style of shields.io,
+// with label on grey and value on color.
+func renderBadge(label, value, color string) string {
+	const pad = 6
+	lw := textWidth(label) + 2*pad
+	vw := textWidth(value) + 2*pad
+	w := lw + vw
+	label, value = html.EscapeString(label), html.EscapeString(value)
+
+	// Text is drawn at 10x scale and shrunk, as shields.io does, for
+	// finer positioning
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="110">
+<text x="%[7]d" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="%[8]d">%[4]s</text><text x="%[7]d" y="140" transform="scale(.1)" textLength="%[8]d">%[4]s</text>
+<text x="%[9]d" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="%[10]d">%[5]s</text><text x="%[9]d" y="140" transform="scale(.1)" textLength="%[10]d">%[5]s</text>
+</g>
+</svg>
+`, w, lw, vw, label, value, color,
+		lw*5, (lw-2*pad)*10, (lw+vw/2)*10, (vw-2*pad)*10)
+}
+
+// textWidth estimates the width in pixels of text in 11px Verdana. The
+// badge's textLength attributes fit the text to the estimate in any font.
+func textWidth(s string) int {
+	var w float64
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '|' || r == 'i' || r == 'l' || r == 'j' || r == '.' || r == '/' || r == '-':
+			w += 4
+		case r == 'm' || r == 'w' || r == '%' || r == 'M' || r == 'W':
+			w += 10
+		case r >= 'A' && r <= 'Z':
+			w += 7.5
+		default:
+			w += 7
+		}
+	}
+	return int(w + 0.5)
+}
@@ -0,0 +1,85 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// csvHeader lists the columns written by WriteCSV.
+var csvHeader = []string{
+	"name",
+	"category",
+	"class",
+	"status",
+	"code",
+	"duration_ms",
+	"ttft_ms",
+	"itl_ms",
+	"prompt_tokens",
+	"completion_tokens",
+	"total_tokens",
+	"requests",
+	"message",
+}
+
+// WriteCSV writes one row per eval result to the given path.
+func WriteCSV(path string, results []eval.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create csv file: %w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(csvHeader); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	for _, r := range results {
+		status := "passed"
+		if !r.Passed {
+			status = "failed"
+		}
+
+		// Leave TTFT blank for evals that made no streaming requests
+		ttft := ""
+		if r.Stats.TTFT > 0 {
+			ttft = strconv.FormatInt(r.Stats.TTFT.Milliseconds(), 10)
+		}
+		itl := ""
+		if r.Stats.ITL > 0 {
+			itl = strconv.FormatFloat(float64(r.Stats.ITL)/float64(time.Millisecond), 'f', 2, 64)
+		}
+
+		row := []string{
+			r.Name,
+			r.Category,
+			r.Class,
+			status,
+			r.Code,
+			strconv.FormatInt(r.Duration.Milliseconds(), 10),
+			ttft,
+			itl,
+			strconv.Itoa(r.Stats.PromptTokens),
+			strconv.Itoa(r.Stats.CompletionTokens),
+			strconv.Itoa(r.Stats.PromptTokens + r.Stats.CompletionTokens),
+			strconv.Itoa(r.Stats.Requests),
+			r.Message,
+		}
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("write csv row: %w", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("flush csv: %w", err)
+	}
+
+	return nil
+}
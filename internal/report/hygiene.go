@@ -0,0 +1,48 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
+)
+
+// outputFindingsFile holds what the output scanners flagged during a run,
+// so that regenerated reports show it.
+const outputFindingsFile = "output-findings.json"
+
+// WriteOutputFindings records what the output scanners flagged in a log
+// directory, for the report to show. Nothing is written if nothing was
+// flagged.
+func WriteOutputFindings(dir string, findings []hygiene.Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", outputFindingsFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, outputFindingsFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", outputFindingsFile, err)
+	}
+	return nil
+}
+
+// readOutputFindings reads what the output scanners flagged during the run
+// in a log directory, returning nil if nothing was.
+func readOutputFindings(dir string) ([]hygiene.Finding, error) {
+	data, err := os.ReadFile(filepath.Join(dir, outputFindingsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", outputFindingsFile, err)
+	}
+	var findings []hygiene.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", outputFindingsFile, err)
+	}
+	return findings, nil
+}
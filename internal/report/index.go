@@ -0,0 +1,147 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// summaryFile is the per-run summary consumed by the multi-run index.
+const summaryFile = "summary.json"
+
+var indexTemplate = template.Must(template.New("index").Parse(indexHTMLTemplate))
+
+// runSummary is the compact description of a run written alongside report.html.
+type runSummary struct {
+	Model string `json:"model"`
+	Label string `json:"label,omitempty"`
+	// Timestamp is when the report was generated, in RFC 3339 format and
+	// UTC. Summaries written by older versions hold a local time in
+	// summaryLegacyFormat.
+	Timestamp string `json:"timestamp"`
+	Passed    int    `json:"passed"`
+	Total     int    `json:"total"`
+}
+
+// indexEntry represents one run in the index page.
+type indexEntry struct {
+	Run       string
+	Label     string
+	Timestamp string
+	Passed    int
+	Failed    int
+	Total     int
+
+	// generated orders runs; zero if the summary's time is unreadable
+	generated time.Time
+}
+
+// summaryLegacyFormat is the zoneless local time format of older summaries.
+const summaryLegacyFormat = "2006-01-02 15:04:05"
+
+// writeSummary writes summary.json for a run directory.
+func writeSummary(dir string, data reportData, generated time.Time) error {
+	summary := runSummary{
+		Model:     data.Model,
+		Label:     data.Label,
+		Timestamp: generated.UTC().Format(time.RFC3339),
+		Passed:    data.Passed,
+		Total:     data.Total,
+	}
+
+	jsonData, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal summary: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, summaryFile), jsonData, 0644); err != nil {
+		return fmt.Errorf("write summary file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteIndex generates index.html in a model log directory, linking the
+// report of every run found beneath it, newest first, with times shown in
+// loc.
+func WriteIndex(modelDir string, loc *time.Location) error {
+	// List run directories rather than globbing, since the model
+	// directory's name may contain glob metacharacters such as "["
+	runs, err := os.ReadDir(modelDir)
+	if err != nil {
+		return fmt.Errorf("read model directory: %w", err)
+	}
+	var files []string
+	for _, run := range runs {
+		if run.IsDir() {
+			files = append(files, filepath.Join(modelDir, run.Name(), summaryFile))
+		}
+	}
+
+	model := filepath.Base(modelDir)
+	var entries []indexEntry
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		var summary runSummary
+		if err := json.Unmarshal(raw, &summary); err != nil {
+			continue
+		}
+
+		if summary.Model != "" {
+			model = summary.Model
+		}
+		entry := indexEntry{
+			Run:       filepath.Base(filepath.Dir(file)),
+			Label:     summary.Label,
+			Timestamp: summary.Timestamp,
+			Passed:    summary.Passed,
+			Failed:    summary.Total - summary.Passed,
+			Total:     summary.Total,
+		}
+		if t, err := time.Parse(time.RFC3339, summary.Timestamp); err == nil {
+			entry.generated = t
+			entry.Timestamp = t.In(loc).Format(time.RFC3339)
+		} else if t, err := time.ParseInLocation(summaryLegacyFormat, summary.Timestamp, time.Local); err == nil {
+			// Best effort: the run may have been on a machine in another zone
+			entry.generated = t
+		}
+		entries = append(entries, entry)
+	}
+
+	// Newest first, by instant rather than by directory name, since older
+	// versions named run directories by local time in another format
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.generated.Equal(b.generated) {
+			return a.generated.After(b.generated)
+		}
+		return a.Run > b.Run
+	})
+
+	outPath := filepath.Join(modelDir, "index.html")
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create index file: %w", err)
+	}
+	defer f.Close()
+
+	if err := indexTemplate.Execute(f, struct {
+		Model string
+		Runs  []indexEntry
+	}{
+		Model: model,
+		Runs:  entries,
+	}); err != nil {
+		return fmt.Errorf("execute index template: %w", err)
+	}
+
+	return nil
+}
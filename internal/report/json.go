@@ -0,0 +1,184 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
+)
+
+// RunInfo describes a run for exports that stand on their own, away from
+// its log directory.
+type RunInfo struct {
+	// Version identifies the build of the suite that ran, e.g. a module
+	// version or VCS revision.
+	Version string `json:"version"`
+	Server  string `json:"server"`
+	Model   string `json:"model"`
+	Flavor  string `json:"flavor"`
+	Suite   string `json:"suite,omitempty"`
+	Label   string `json:"label,omitempty"`
+	// Shard is the part of the selected evals the run covered, if it was
+	// one of several.
+	Shard *eval.Shard `json:"shard,omitempty"`
+	// Started and Finished are in UTC. DurationMS is measured on the
+	// monotonic clock, so it stays accurate if the wall clock changes; for
+	// merged results it spans the earliest start to the latest finish.
+	// Finished and DurationMS are unset in the start event of a
+	// --results-endpoint.
+	Started    time.Time `json:"started"`
+	Finished   time.Time `json:"finished,omitzero"`
+	DurationMS int64     `json:"duration_ms,omitzero"`
+	// Stability is the server stability verdict, from probes before and
+	// after the run.
+	Stability *eval.Stability `json:"stability,omitempty"`
+	// Skipped lists the selected evals that were not run since the config
+	// file disables them.
+	Skipped []eval.Skip `json:"skipped,omitempty"`
+	// OutputFindings lists what the --scan-output scanners flagged in
+	// the run's outputs.
+	OutputFindings []hygiene.Finding `json:"output_findings,omitempty"`
+}
+
+// jsonResults is the document written by WriteJSON.
+type jsonResults struct {
+	Run     RunInfo      `json:"run"`
+	Passed  int          `json:"passed"`
+	Total   int          `json:"total"`
+	Results []jsonResult `json:"results"`
+}
+
+// jsonResult is one eval result in a JSON export.
+type jsonResult struct {
+	Name       string             `json:"name"`
+	Category   string             `json:"category"`
+	Class      string             `json:"class"`
+	Passed     bool               `json:"passed"`
+	Code       string             `json:"code,omitempty"`
+	Message    string             `json:"message,omitempty"`
+	DurationMS int64              `json:"duration_ms"`
+	Scores     map[string]float64 `json:"scores,omitempty"`
+	Notes      []string           `json:"notes,omitempty"`
+	// SLOViolations lists how the eval exceeded its latency objectives.
+	SLOViolations []string        `json:"slo_violations,omitempty"`
+	Iterations    []jsonIteration `json:"iterations,omitempty"`
+}
+
+// jsonIteration is one run of a repeated eval in a JSON export.
+type jsonIteration struct {
+	Passed     bool   `json:"passed"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// ResultsFile is a JSON export read back by LoadJSON.
+type ResultsFile struct {
+	Path    string
+	Run     RunInfo
+	Results []eval.Result
+
+	// digest identifies the file's content, to detect files given twice
+	digest [sha256.Size]byte
+}
+
+// WriteJSON writes the run's description and one entry per eval result to
+// the given path.
+func WriteJSON(path string, run RunInfo, results []eval.Result) error {
+	doc := jsonResults{
+		Run:     run,
+		Total:   len(results),
+		Results: make([]jsonResult, 0, len(results)),
+	}
+	for _, r := range results {
+		if r.Passed {
+			doc.Passed++
+		}
+		doc.Results = append(doc.Results, newJSONResult(r))
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal json results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write json results: %w", err)
+	}
+	return nil
+}
+
+// newJSONResult converts an eval result to its JSON export entry.
+func newJSONResult(r eval.Result) jsonResult {
+	var iterations []jsonIteration
+	for _, it := range r.Iterations {
+		iterations = append(iterations, jsonIteration{
+			Passed:     it.Passed,
+			Code:       it.Code,
+			Message:    it.Message,
+			DurationMS: it.Duration.Milliseconds(),
+		})
+	}
+	return jsonResult{
+		Name:       r.Name,
+		Category:   r.Category,
+		Class:      r.Class,
+		Passed:     r.Passed,
+		Code:       r.Code,
+		Message:    r.Message,
+		DurationMS: r.Duration.Milliseconds(),
+		Scores:     r.Scores,
+		Notes:      r.Notes,
+		Iterations: iterations,
+
+		SLOViolations: r.SLOViolations,
+	}
+}
+
+// LoadJSON reads results written by WriteJSON.
+func LoadJSON(path string) (*ResultsFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read json results: %w", err)
+	}
+	var doc jsonResults
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse json results %s: %w", path, err)
+	}
+
+	file := &ResultsFile{
+		Path:    path,
+		Run:     doc.Run,
+		Results: make([]eval.Result, 0, len(doc.Results)),
+		digest:  sha256.Sum256(data),
+	}
+	for _, r := range doc.Results {
+		var iterations []eval.Iteration
+		for _, it := range r.Iterations {
+			iterations = append(iterations, eval.Iteration{
+				Passed:   it.Passed,
+				Code:     it.Code,
+				Message:  it.Message,
+				Duration: time.Duration(it.DurationMS) * time.Millisecond,
+			})
+		}
+		file.Results = append(file.Results, eval.Result{
+			Name:       r.Name,
+			Category:   r.Category,
+			Class:      r.Class,
+			Passed:     r.Passed,
+			Code:       r.Code,
+			Message:    r.Message,
+			Duration:   time.Duration(r.DurationMS) * time.Millisecond,
+			Scores:     r.Scores,
+			Notes:      r.Notes,
+			Iterations: iterations,
+
+			SLOViolations: r.SLOViolations,
+		})
+	}
+	return file, nil
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr,omitempty"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one eval category.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr,omitempty"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase represents a single eval run.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitFailure describes why a test case failed.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped marks a test case that was not run.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes eval results as JUnit XML to the given path.
+// Each category becomes a testsuite and each eval a testcase. Skipped
+// evals become skipped testcases.
+func WriteJUnit(path string, results []eval.Result, skipped []eval.Skip) error {
+	var root junitTestSuites
+	suiteIndex := make(map[string]int)
+	var suiteTimes []float64
+	var total float64
+
+	suiteFor := func(category string) int {
+		idx, ok := suiteIndex[category]
+		if !ok {
+			idx = len(root.Suites)
+			suiteIndex[category] = idx
+			root.Suites = append(root.Suites, junitTestSuite{Name: category})
+			suiteTimes = append(suiteTimes, 0)
+		}
+		return idx
+	}
+
+	for _, r := range results {
+		idx := suiteFor(r.Category)
+		suite := &root.Suites[idx]
+
+		tc := junitTestCase{
+			Name:      r.Name,
+			ClassName: r.Category,
+			Time:      formatSeconds(r.Duration.Seconds()),
+		}
+		if !r.Passed {
+			tc.Failure = &junitFailure{
+				Message: r.Message,
+				Type:    r.Code,
+				Text:    r.Message,
+			}
+			suite.Failures++
+			root.Failures++
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		root.Tests++
+		suiteTimes[idx] += r.Duration.Seconds()
+		total += r.Duration.Seconds()
+	}
+
+	for _, sk := range skipped {
+		suite := &root.Suites[suiteFor(sk.Category)]
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      sk.Name,
+			ClassName: sk.Category,
+			Time:      formatSeconds(0),
+			Skipped:   &junitSkipped{Message: sk.Reason},
+		})
+		suite.Tests++
+		suite.Skipped++
+		root.Tests++
+		root.Skipped++
+	}
+
+	for i := range root.Suites {
+		root.Suites[i].Time = formatSeconds(suiteTimes[i])
+	}
+	root.Time = formatSeconds(total)
+
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal junit: %w", err)
+	}
+
+	out := append([]byte(xml.Header), data...)
+	out = append(out, '\n')
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("write junit file: %w", err)
+	}
+
+	return nil
+}
+
+// formatSeconds formats a duration in seconds with millisecond precision.
+func formatSeconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
@@ -0,0 +1,216 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+	"github.com/aldehir/llm-serving-tests/internal/log"
+)
+
+// mergedResultsFile is the name of the JSON results written by
+// Merged.Write, beside report.html.
+const mergedResultsFile = "results.json"
+
+// Merged combines the results files of several runs of one model, such as
+// the shards of a run or repeated runs.
+type Merged struct {
+	Run     RunInfo
+	Results []eval.Result
+	// Duplicates lists the evals found in more than one file, which were
+	// combined as repeated runs.
+	Duplicates []Duplicate
+	// Warnings describes files that were skipped or do not fit together,
+	// such as missing shards.
+	Warnings []string
+	// Incomplete is set if shards of the run are missing.
+	Incomplete bool
+}
+
+// Duplicate is an eval found in more than one merged file.
+type Duplicate struct {
+	Name  string
+	Files []string
+}
+
+// Merge combines results files of the same server and model. An eval in
+// several files passes only if the fraction of its runs that passed meets
+// threshold, as with --repeat.
+func Merge(files []*ResultsFile, threshold float64) (*Merged, error) {
+	m := &Merged{}
+
+	// Skip files given twice, which would count their runs twice
+	var unique []*ResultsFile
+	for _, f := range files {
+		i := slices.IndexFunc(unique, func(u *ResultsFile) bool { return u.digest == f.digest })
+		if i >= 0 {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("%s is identical to %s; skipped", f.Path, unique[i].Path))
+			continue
+		}
+		unique = append(unique, f)
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("no results files to merge")
+	}
+
+	first := unique[0]
+	m.Run = RunInfo{
+		Version:  first.Run.Version,
+		Server:   first.Run.Server,
+		Model:    first.Run.Model,
+		Flavor:   first.Run.Flavor,
+		Suite:    first.Run.Suite,
+		Label:    first.Run.Label,
+		Started:  first.Run.Started,
+		Finished: first.Run.Finished,
+	}
+	for _, f := range unique[1:] {
+		if f.Run.Model != first.Run.Model || f.Run.Server != first.Run.Server {
+			return nil, fmt.Errorf("%s is of %s at %s, but %s is of %s at %s; merge results of one model and server",
+				f.Path, f.Run.Model, f.Run.Server, first.Path, first.Run.Model, first.Run.Server)
+		}
+		if f.Run.Version != first.Run.Version {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("%s ran suite version %s, but %s ran %s",
+				f.Path, f.Run.Version, first.Path, first.Run.Version))
+		}
+		if f.Run.Flavor != first.Run.Flavor {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("%s ran with flavor %s, but %s with %s",
+				f.Path, f.Run.Flavor, first.Path, first.Run.Flavor))
+		}
+		if f.Run.Suite != m.Run.Suite {
+			m.Run.Suite = ""
+		}
+		if f.Run.Label != m.Run.Label {
+			m.Run.Label = ""
+		}
+		if f.Run.Started.Before(m.Run.Started) {
+			m.Run.Started = f.Run.Started
+		}
+		if f.Run.Finished.After(m.Run.Finished) {
+			m.Run.Finished = f.Run.Finished
+		}
+	}
+	m.Run.DurationMS = m.Run.Finished.Sub(m.Run.Started).Milliseconds()
+
+	m.checkShards(unique)
+
+	// Group runs by eval, in the order first seen
+	var names []string
+	runs := make(map[string][]eval.Result)
+	sources := make(map[string][]*ResultsFile)
+	for _, f := range unique {
+		for _, r := range f.Results {
+			if _, ok := runs[r.Name]; !ok {
+				names = append(names, r.Name)
+			}
+			runs[r.Name] = append(runs[r.Name], r)
+			sources[r.Name] = append(sources[r.Name], f)
+		}
+	}
+
+	for _, name := range names {
+		if len(runs[name]) == 1 {
+			m.Results = append(m.Results, runs[name][0])
+			continue
+		}
+
+		dup := Duplicate{Name: name}
+		var shards []string
+		for _, f := range sources[name] {
+			dup.Files = append(dup.Files, f.Path)
+			if f.Run.Shard != nil && !slices.Contains(shards, f.Run.Shard.String()) {
+				shards = append(shards, f.Run.Shard.String())
+			}
+		}
+		m.Duplicates = append(m.Duplicates, dup)
+		// Shards of one run partition it, so an eval in two of them
+		// means the shards selected different evals
+		if len(shards) > 1 {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("%s ran in shards %s; were they run with the same selection flags?",
+				name, strings.Join(shards, ", ")))
+		}
+		m.Results = append(m.Results, eval.CombineRuns(runs[name], threshold))
+	}
+
+	return m, nil
+}
+
+// checkShards warns about missing shards, and about shards of runs split
+// different ways.
+func (m *Merged) checkShards(files []*ResultsFile) {
+	var count int
+	seen := make(map[int]bool)
+	for _, f := range files {
+		s := f.Run.Shard
+		if s == nil {
+			continue
+		}
+		if count != 0 && s.Count != count {
+			m.Warnings = append(m.Warnings, fmt.Sprintf("%s is shard %s, but other files are shards of %d", f.Path, s, count))
+			continue
+		}
+		count = s.Count
+		seen[s.Index] = true
+	}
+
+	var missing []string
+	for i := 1; i <= count; i++ {
+		if !seen[i] {
+			missing = append(missing, eval.Shard{Index: i, Count: count}.String())
+		}
+	}
+	if len(missing) > 0 {
+		m.Incomplete = true
+		m.Warnings = append(m.Warnings, "missing shards "+strings.Join(missing, ", "))
+	}
+}
+
+// Passed returns the number of passing merged results.
+func (m *Merged) Passed() int {
+	n := 0
+	for _, r := range m.Results {
+		if r.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Write writes the merged results to dir as results.json, with an HTML
+// report showing times in loc. Merged results have no conversations to
+// show, since JSON results do not record them.
+func (m *Merged) Write(dir string, loc *time.Location) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create merge directory: %w", err)
+	}
+	if err := WriteJSON(filepath.Join(dir, mergedResultsFile), m.Run, m.Results); err != nil {
+		return err
+	}
+
+	evals := make([]log.EvalResult, 0, len(m.Results))
+	for _, r := range m.Results {
+		ev := log.EvalResult{
+			Name:    r.Name,
+			Passed:  r.Passed,
+			Code:    r.Code,
+			Message: r.Message,
+			Scores:  r.Scores,
+			Notes:   r.Notes,
+
+			SLOViolations: r.SLOViolations,
+		}
+		for _, it := range r.Iterations {
+			ev.Iterations = append(ev.Iterations, log.IterationResult{
+				Passed:  it.Passed,
+				Code:    it.Code,
+				Message: it.Message,
+			})
+		}
+		evals = append(evals, ev)
+	}
+	return WriteReport(dir, m.Run.Model, m.Run.Label, evals, loc)
+}
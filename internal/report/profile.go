@@ -0,0 +1,74 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// profileNode is a node in a flame graph, in the hierarchical JSON format
+// understood by d3-flame-graph and similar viewers. Values are milliseconds.
+type profileNode struct {
+	Name     string         `json:"name"`
+	Value    float64        `json:"value"`
+	Children []*profileNode `json:"children,omitempty"`
+}
+
+// WriteProfile writes a flame-style JSON breakdown of where eval time went:
+// run > category > eval > request / template / validation.
+func WriteProfile(path string, results []eval.Result) error {
+	root := &profileNode{Name: "run"}
+	categories := make(map[string]*profileNode)
+
+	for _, r := range results {
+		cat, ok := categories[r.Category]
+		if !ok {
+			cat = &profileNode{Name: r.Category}
+			categories[r.Category] = cat
+			root.Children = append(root.Children, cat)
+		}
+
+		// Whatever isn't spent waiting on the server is validation and eval logic
+		validation := r.Duration - r.Stats.RequestTime - r.Stats.TemplateTime
+		if validation < 0 {
+			validation = 0
+		}
+
+		node := &profileNode{Name: r.Name, Value: millis(r.Duration)}
+		for _, part := range []struct {
+			name string
+			d    time.Duration
+		}{
+			{"request", r.Stats.RequestTime},
+			{"template", r.Stats.TemplateTime},
+			{"validation", validation},
+		} {
+			if part.d > 0 {
+				node.Children = append(node.Children, &profileNode{Name: part.name, Value: millis(part.d)})
+			}
+		}
+
+		cat.Children = append(cat.Children, node)
+		cat.Value += node.Value
+		root.Value += node.Value
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal profile: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write profile file: %w", err)
+	}
+
+	return nil
+}
+
+// millis converts a duration to fractional milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,189 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+const (
+	// publishRetries is how many times a failed post is retried, with the
+	// backoff of --retries.
+	publishRetries = 3
+	// publishTimeout bounds each post, including retries.
+	publishTimeout = 2 * time.Minute
+	// publishQueue is how many events may wait to be posted before the run
+	// waits for the endpoint.
+	publishQueue = 256
+)
+
+// Publisher posts a run's results to a results API as they complete, for
+// dashboards that collect runs centrally. Each post is a JSON event: one
+// "start" event with the run's description, one "result" event per
+// result, and a final "summary" event. Events are posted in order from a
+// background goroutine, so a slow endpoint does not slow the run, and an
+// endpoint that fails does not fail it.
+type Publisher struct {
+	endpoint string
+	token    string
+	runID    string
+	http     *http.Client
+	retry    client.RetryPolicy
+
+	events chan publishEvent
+	done   chan struct{}
+
+	// Written by the posting goroutine, read after done is closed
+	seq       int
+	delivered int
+	firstErr  error
+
+	closeOnce sync.Once
+}
+
+// publishEvent is the body of one post.
+type publishEvent struct {
+	Event string `json:"event"`
+	// RunID identifies the run across its events.
+	RunID string `json:"run_id"`
+	// Seq numbers the run's events from 1, in the order posted.
+	Seq     int             `json:"seq"`
+	Run     *RunInfo        `json:"run,omitempty"`
+	Result  *jsonResult     `json:"result,omitempty"`
+	Summary *publishSummary `json:"summary,omitempty"`
+}
+
+// publishSummary is the outcome of a run, in its summary event.
+type publishSummary struct {
+	Passed   int                 `json:"passed"`
+	Total    int                 `json:"total"`
+	Failures []eval.FailureCount `json:"failures,omitempty"`
+}
+
+// NewPublisher returns a publisher posting to endpoint, an http or https
+// URL, with token as a bearer token if it is set.
+func NewPublisher(endpoint, token string) (*Publisher, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse results endpoint: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("results endpoint %q is not an http or https URL", endpoint)
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("generate run id: %w", err)
+	}
+
+	p := &Publisher{
+		endpoint: endpoint,
+		token:    token,
+		runID:    hex.EncodeToString(id[:]),
+		http:     &http.Client{},
+		retry:    client.DefaultRetryPolicy(publishRetries),
+		events:   make(chan publishEvent, publishQueue),
+		done:     make(chan struct{}),
+	}
+	go p.post()
+	return p, nil
+}
+
+// RunID returns the identifier sent with each of the run's events.
+func (p *Publisher) RunID() string {
+	return p.runID
+}
+
+// Start queues the start event, describing the run before any result.
+func (p *Publisher) Start(run RunInfo) {
+	p.events <- publishEvent{Event: "start", Run: &run}
+}
+
+// Result queues the result event of a completed result.
+func (p *Publisher) Result(r eval.Result) {
+	result := newJSONResult(r)
+	p.events <- publishEvent{Event: "result", Result: &result}
+}
+
+// Finish queues the summary event and waits for every queued event to be
+// posted or to fail. It returns how many events were delivered, of how
+// many, and the first error.
+func (p *Publisher) Finish(run RunInfo, results []eval.Result) (delivered, total int, err error) {
+	summary := &publishSummary{
+		Total:    len(results),
+		Failures: eval.FailureBreakdown(results),
+	}
+	for _, r := range results {
+		if r.Passed {
+			summary.Passed++
+		}
+	}
+	p.events <- publishEvent{Event: "summary", Run: &run, Summary: summary}
+
+	p.closeOnce.Do(func() { close(p.events) })
+	<-p.done
+	return p.delivered, p.seq, p.firstErr
+}
+
+// post posts queued events in order until the queue is closed.
+func (p *Publisher) post() {
+	defer close(p.done)
+	for ev := range p.events {
+		p.seq++
+		ev.RunID = p.runID
+		ev.Seq = p.seq
+		if err := p.send(ev); err != nil {
+			if p.firstErr == nil {
+				p.firstErr = fmt.Errorf("%s event %d: %w", ev.Event, ev.Seq, err)
+			}
+			continue
+		}
+		p.delivered++
+	}
+}
+
+// send posts one event, retrying transient failures.
+func (p *Publisher) send(ev publishEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// A retried event keeps its key, so the endpoint can drop duplicates
+	// of events it received but failed to acknowledge
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("%s-%d", p.runID, ev.Seq))
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.retry.Do(p.http, req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("post: %s", resp.Status)
+	}
+	return nil
+}
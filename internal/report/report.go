@@ -3,63 +3,229 @@ package report
 import (
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"os"
 	"path/filepath"
 	"strings"
-	"html/template"
 	"time"
 
+	"github.com/aldehir/llm-serving-tests/internal/client"
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+	"github.com/aldehir/llm-serving-tests/internal/hygiene"
 	"github.com/aldehir/llm-serving-tests/internal/log"
+	"github.com/aldehir/llm-serving-tests/internal/textdiff"
 )
 
 var reportTemplate = template.Must(template.New("report").Parse(htmlTemplate))
 
 // reportData is the top-level JSON structure injected into the HTML template.
 type reportData struct {
-	Model     string      `json:"model"`
-	Timestamp string      `json:"timestamp"`
-	Passed    int         `json:"passed"`
-	Total     int         `json:"total"`
-	Evals     []evalEntry `json:"evals"`
+	Model     string `json:"model"`
+	Label     string `json:"label,omitempty"`
+	Timestamp string `json:"timestamp"`
+	Passed    int    `json:"passed"`
+	Total     int    `json:"total"`
+	// Noted is the number of passed evals with compatibility notes.
+	Noted int `json:"noted,omitempty"`
+	// SLOViolated is the number of passed evals that exceeded their
+	// latency objectives.
+	SLOViolated int `json:"sloViolated,omitempty"`
+	// Tier is the run's compatibility tier, as shown on its badge.
+	Tier  string      `json:"tier,omitempty"`
+	Evals []evalEntry `json:"evals"`
+	// Failures is the breakdown of failed evals by failure code.
+	Failures []eval.FailureCount `json:"failures,omitempty"`
+	// Stability is the server stability verdict, from probes before and
+	// after the run.
+	Stability *eval.Stability `json:"stability,omitempty"`
+	// Skipped lists the evals skipped since the config file disables them.
+	Skipped []eval.Skip `json:"skipped,omitempty"`
+	// OutputFindings lists what the output scanners flagged.
+	OutputFindings []hygiene.Finding `json:"outputFindings,omitempty"`
 }
 
 // evalEntry represents one eval in the report.
 type evalEntry struct {
-	Name     string           `json:"name"`
-	Passed   bool             `json:"passed"`
-	Message  string           `json:"message,omitempty"`
+	Name     string            `json:"name"`
+	Passed   bool              `json:"passed"`
+	Code     string            `json:"code,omitempty"`
+	Message  string            `json:"message,omitempty"`
 	Tools    []json.RawMessage `json:"tools,omitempty"`
 	Messages []json.RawMessage `json:"messages"`
+	// Iterations lists per-run outcomes of a repeated eval. The
+	// conversation shown is from the last iteration.
+	Iterations []iterationEntry `json:"iterations,omitempty"`
+	// Scores holds named measurements recorded by the eval.
+	Scores map[string]float64 `json:"scores,omitempty"`
+	// Grid is a two-dimensional pass/fail breakdown of the eval's checks.
+	Grid *gridEntry `json:"grid,omitempty"`
+	// RequestIDs lists the server request ids of the eval's requests, for
+	// correlating with server logs.
+	RequestIDs []string `json:"requestIds,omitempty"`
+	// Timings is the server-reported throughput, from llama.cpp timings.
+	Timings *timingsEntry `json:"timings,omitempty"`
+	// Notes lists deviations from the OpenAI API tolerated by the server
+	// flavor.
+	Notes []string `json:"notes,omitempty"`
+	// SLOViolations lists how the eval exceeded its latency objectives.
+	SLOViolations []string `json:"sloViolations,omitempty"`
+	// Samples lists the invalid responses of an eval that checks many.
+	Samples []sampleEntry `json:"samples,omitempty"`
+	// Violations lists the stream lines skipped since they were not
+	// chunks.
+	Violations []violationEntry `json:"violations,omitempty"`
+	// Diffs shows outputs that should have matched as word diffs.
+	Diffs []diffEntry `json:"diffs,omitempty"`
+	// Artifacts links the files attached to the eval's result.
+	Artifacts []artifactEntry `json:"artifacts,omitempty"`
+}
+
+// timingsEntry represents server-reported throughput in the report.
+type timingsEntry struct {
+	PromptTokens       int     `json:"promptTokens"`
+	PromptPerSecond    float64 `json:"promptPerSecond"`
+	PredictedTokens    int     `json:"predictedTokens"`
+	PredictedPerSecond float64 `json:"predictedPerSecond"`
+}
+
+// gridEntry represents a pass/fail grid in the report.
+type gridEntry struct {
+	RowLabel string   `json:"rowLabel"`
+	ColLabel string   `json:"colLabel"`
+	Rows     []string `json:"rows"`
+	Cols     []string `json:"cols"`
+	Cells    [][]bool `json:"cells"`
+}
+
+// iterationEntry represents one run of a repeated eval in the report.
+type iterationEntry struct {
+	Passed  bool   `json:"passed"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// sampleEntry represents one invalid response in the report.
+type sampleEntry struct {
+	Prompt  string `json:"prompt"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+	Output  string `json:"output"`
+}
+
+// violationEntry represents one skipped stream line in the report.
+type violationEntry struct {
+	Kind    string `json:"kind"`
+	Line    int    `json:"line"`
+	Data    string `json:"data"`
+	Message string `json:"message"`
+}
+
+// diffEntry is a word diff of two outputs in the report.
+type diffEntry struct {
+	ALabel string          `json:"aLabel"`
+	BLabel string          `json:"bLabel"`
+	Spans  []textdiff.Span `json:"spans"`
 }
 
-// WriteReport generates report.html in the given directory from eval results.
-func WriteReport(dir, model string, evals []log.EvalResult) error {
+// artifactEntry links a file attached to an eval's result, at a path
+// relative to the report.
+type artifactEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// WriteReport generates report.html and badge.svg in the given directory
+// from eval results, showing times in loc. The report is titled by label if it is
+// set.
+func WriteReport(dir, model, label string, evals []log.EvalResult, loc *time.Location) error {
+	now := time.Now()
 	data := reportData{
 		Model:     model,
-		Timestamp: time.Now().Format("2006-01-02 15:04:05"),
+		Label:     label,
+		Timestamp: now.In(loc).Format(time.RFC3339),
 		Total:     len(evals),
 	}
 
+	var failureCodes []string
 	for _, ev := range evals {
 		if ev.Passed {
 			data.Passed++
+			if len(ev.Notes) > 0 {
+				data.Noted++
+			}
+			if len(ev.SLOViolations) > 0 {
+				data.SLOViolated++
+			}
+		} else {
+			failureCodes = append(failureCodes, ev.Code)
 		}
 
 		entry := evalEntry{
 			Name:    ev.Name,
 			Passed:  ev.Passed,
+			Code:    ev.Code,
 			Message: ev.Message,
+			Scores:  ev.Scores,
+			Notes:   ev.Notes,
+
+			SLOViolations: ev.SLOViolations,
+		}
+		if g := ev.Grid; g != nil {
+			entry.Grid = &gridEntry{
+				RowLabel: g.RowLabel,
+				ColLabel: g.ColLabel,
+				Rows:     g.Rows,
+				Cols:     g.Cols,
+				Cells:    g.Cells,
+			}
+		}
+		if t := ev.Timings; t != nil {
+			entry.Timings = &timingsEntry{
+				PromptTokens:       t.PromptTokens,
+				PromptPerSecond:    t.PromptPerSecond,
+				PredictedTokens:    t.PredictedTokens,
+				PredictedPerSecond: t.PredictedPerSecond,
+			}
+		}
+		for _, s := range ev.Samples {
+			entry.Samples = append(entry.Samples, sampleEntry(s))
+		}
+		for _, v := range ev.Violations {
+			entry.Violations = append(entry.Violations, violationEntry(v))
+		}
+		for _, d := range ev.Diffs {
+			entry.Diffs = append(entry.Diffs, diffEntry{
+				ALabel: d.ALabel,
+				BLabel: d.BLabel,
+				Spans:  textdiff.Words(d.A, d.B),
+			})
+		}
+		for _, a := range ev.Artifacts {
+			entry.Artifacts = append(entry.Artifacts, artifactEntry(a))
+		}
+		for _, it := range ev.Iterations {
+			entry.Iterations = append(entry.Iterations, iterationEntry{
+				Passed:  it.Passed,
+				Code:    it.Code,
+				Message: it.Message,
+			})
 		}
 
-		// Filter out apply-template turns
+		// Filter out apply-template and embedding turns
 		var turns []log.TurnData
 		for _, t := range ev.Turns {
-			if strings.Contains(t.URL, "/apply-template") {
+			if strings.Contains(t.URL, "/apply-template") || strings.HasSuffix(t.URL, "/embeddings") {
 				continue
 			}
 			turns = append(turns, t)
 		}
 
+		for _, t := range turns {
+			if id := client.RequestID(t.Headers); id != "" {
+				entry.RequestIDs = append(entry.RequestIDs, id)
+			}
+		}
+
 		// Extract tools from first turn's request
 		if len(turns) > 0 {
 			entry.Tools = extractTools(turns[0].RequestBody)
@@ -71,6 +237,36 @@ func WriteReport(dir, model string, evals []log.EvalResult) error {
 		data.Evals = append(data.Evals, entry)
 	}
 
+	if len(failureCodes) > 0 {
+		data.Failures = eval.CountFailureCodes(failureCodes)
+	}
+	data.Tier = compatibilityTier(data)
+
+	stability, err := readStability(dir)
+	if err != nil {
+		return err
+	}
+	data.Stability = stability
+
+	skipped, err := readSkipped(dir)
+	if err != nil {
+		return err
+	}
+	data.Skipped = skipped
+
+	findings, err := readOutputFindings(dir)
+	if err != nil {
+		return err
+	}
+	data.OutputFindings = findings
+
+	if err := writeSummary(dir, data, now); err != nil {
+		return err
+	}
+	if err := writeBadge(dir, data); err != nil {
+		return err
+	}
+
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("marshal report data: %w", err)
@@ -122,11 +318,17 @@ func buildConversation(turns []log.TurnData) []json.RawMessage {
 	// Extract messages from the last request
 	var req struct {
 		Messages []json.RawMessage `json:"messages"`
+		Prompt   string            `json:"prompt"`
 	}
 	if err := json.Unmarshal(lastTurn.RequestBody, &req); err != nil {
 		return nil
 	}
 
+	// Text completions have a prompt instead of messages
+	if len(req.Messages) == 0 && req.Prompt != "" {
+		return buildTextConversation(req.Prompt, lastTurn.ResponseBody)
+	}
+
 	messages := req.Messages
 
 	// Extract the assistant message from the last response
@@ -138,6 +340,24 @@ func buildConversation(turns []log.TurnData) []json.RawMessage {
 	return messages
 }
 
+// buildTextConversation presents a text completion as a user message
+// holding the prompt and an assistant message holding choices[0].text.
+func buildTextConversation(prompt string, respBody json.RawMessage) []json.RawMessage {
+	user, _ := json.Marshal(map[string]string{"role": "user", "content": prompt})
+	messages := []json.RawMessage{user}
+
+	var resp struct {
+		Choices []struct {
+			Text string `json:"text"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil || len(resp.Choices) == 0 {
+		return messages
+	}
+	assistant, _ := json.Marshal(map[string]string{"role": "assistant", "content": resp.Choices[0].Text})
+	return append(messages, assistant)
+}
+
 // extractAssistantMessage extracts choices[0].message from a response body.
 func extractAssistantMessage(respBody json.RawMessage) json.RawMessage {
 	if len(respBody) == 0 {
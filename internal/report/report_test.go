@@ -0,0 +1,46 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aldehir/llm-serving-tests/internal/log"
+)
+
+// benchEvals returns n eval results, a tenth of them failed, each with a
+// tool-calling conversation and a diff of two outputs.
+func benchEvals(n int) []log.EvalResult {
+	request := json.RawMessage(`{"model":"m","messages":[{"role":"system","content":"You are a helpful assistant."},{"role":"user","content":"What is the weather in Paris?"}],"tools":[{"type":"function","function":{"name":"get_weather","parameters":{"type":"object","properties":{"location":{"type":"string"}}}}}]}`)
+	response := json.RawMessage(`{"id":"c","choices":[{"index":0,"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_0","type":"function","function":{"name":"get_weather","arguments":"{\"location\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+
+	evals := make([]log.EvalResult, n)
+	for i := range evals {
+		evals[i] = log.EvalResult{
+			Name:   fmt.Sprintf("eval_%d (blocking)", i),
+			Passed: i%10 != 0,
+			Code:   "TOOLCALL_MISSING",
+			Turns: []log.TurnData{{
+				URL:          "http://localhost:8080/v1/chat/completions",
+				RequestBody:  request,
+				ResponseBody: response,
+				Headers:      map[string]string{"x-request-id": fmt.Sprintf("req-%d", i)},
+			}},
+			Diffs: []log.Diff{{ALabel: "a", BLabel: "b", A: text, B: strings.Replace(text, "lazy", "sleepy", 3)}},
+		}
+	}
+	return evals
+}
+
+func BenchmarkWriteReport(b *testing.B) {
+	evals := benchEvals(200)
+	dir := b.TempDir()
+	for b.Loop() {
+		if err := WriteReport(dir, "m", "", evals, time.UTC); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// skippedFile holds the evals a run skipped since the config file disables
+// them, so that regenerated reports show them.
+const skippedFile = "skipped.json"
+
+// WriteSkipped records the evals a run skipped in a log directory, for the
+// report to show. Nothing is written if none were skipped.
+func WriteSkipped(dir string, skipped []eval.Skip) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(skipped, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", skippedFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, skippedFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", skippedFile, err)
+	}
+	return nil
+}
+
+// readSkipped reads the evals skipped by the run in a log directory,
+// returning nil if there are none.
+func readSkipped(dir string) ([]eval.Skip, error) {
+	data, err := os.ReadFile(filepath.Join(dir, skippedFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", skippedFile, err)
+	}
+	var skipped []eval.Skip
+	if err := json.Unmarshal(data, &skipped); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", skippedFile, err)
+	}
+	return skipped, nil
+}
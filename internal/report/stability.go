@@ -0,0 +1,45 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aldehir/llm-serving-tests/internal/eval"
+)
+
+// stabilityFile holds the server stability verdict of a run, so that
+// regenerated reports show it.
+const stabilityFile = "stability.json"
+
+// WriteStability records the server stability verdict of the run in a log
+// directory, for the report to show.
+func WriteStability(dir string, s eval.Stability) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", stabilityFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, stabilityFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", stabilityFile, err)
+	}
+	return nil
+}
+
+// readStability reads the server stability verdict recorded in a log
+// directory, returning nil if there is none, as for merged results and
+// runs by older versions.
+func readStability(dir string) (*eval.Stability, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stabilityFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", stabilityFile, err)
+	}
+	var s eval.Stability
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", stabilityFile, err)
+	}
+	return &s, nil
+}
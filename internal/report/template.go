@@ -18,10 +18,34 @@ body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-
 .sidebar-header .summary { font-size: 13px; margin-top: 8px; }
 .summary .pass-count { color: #16a34a; font-weight: 600; }
 .summary .fail-count { color: #dc2626; font-weight: 600; }
+.summary .noted-count { color: #d97706; font-weight: 600; }
+.summary .slo-count { color: #ea580c; font-weight: 600; }
+.tier { font-size: 12px; margin-top: 4px; color: #666; }
+.tier span { font-weight: 600; }
+.tier .full { color: #16a34a; }
+.tier .compatible { color: #65a30d; }
+.tier .partial { color: #ca8a04; }
+.tier .incompatible { color: #dc2626; }
+.breakdown { margin-top: 6px; display: flex; flex-wrap: wrap; gap: 4px; }
+.breakdown-item { font-family: monospace; font-size: 11px; padding: 1px 6px; border-radius: 4px; background: #fee2e2; color: #991b1b; cursor: pointer; border: none; }
+.breakdown-item:hover { background: #fecaca; }
+.stability { font-size: 12px; margin-top: 6px; color: #666; }
+.stability .stable { color: #16a34a; font-weight: 600; }
+.stability .degraded { color: #dc2626; font-weight: 600; }
+.stability .unknown { color: #d97706; font-weight: 600; }
+.stability ul { margin: 4px 0 0 16px; }
+.skipped { font-size: 12px; margin-top: 6px; color: #666; }
+.skipped ul { margin: 4px 0 0 16px; }
+.skipped .reason { color: #d97706; }
+.hygiene { font-size: 12px; margin-top: 6px; color: #666; }
+.hygiene ul { margin: 4px 0 0 16px; }
+.hygiene .kind { color: #d97706; }
 
 .filter-bar { padding: 8px 16px; border-bottom: 1px solid #ddd; display: flex; gap: 8px; align-items: center; }
 .filter-bar input { flex: 1; padding: 6px 8px; border: 1px solid #ddd; border-radius: 4px; font-size: 13px; outline: none; }
 .filter-bar input:focus { border-color: #2563eb; }
+.search-count { font-size: 11px; color: #666; white-space: nowrap; }
+mark { background: #fde047; color: inherit; border-radius: 2px; }
 
 .filter-buttons { display: flex; padding: 8px 16px; gap: 4px; border-bottom: 1px solid #ddd; }
 .filter-btn { padding: 4px 10px; border: 1px solid #ddd; border-radius: 4px; background: #fff; cursor: pointer; font-size: 12px; }
@@ -35,7 +59,10 @@ body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-
 .badge { width: 8px; height: 8px; border-radius: 50%; flex-shrink: 0; }
 .badge.pass { background: #16a34a; }
 .badge.fail { background: #dc2626; }
+.badge.noted { background: #d97706; }
+.badge.slo { background: #ea580c; }
 .eval-name { overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+.eval-hits { margin-left: auto; font-size: 11px; color: #92400e; background: #fef3c7; border-radius: 8px; padding: 0 6px; flex-shrink: 0; }
 
 /* Main content */
 .main { flex: 1; overflow-y: auto; padding: 24px; }
@@ -48,6 +75,33 @@ body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-
 .eval-status.pass { background: #dcfce7; color: #166534; }
 .eval-status.fail { background: #fee2e2; color: #991b1b; }
 .eval-message { margin-bottom: 16px; padding: 10px 14px; background: #fee2e2; border-radius: 6px; font-size: 13px; color: #991b1b; }
+.iterations { margin-bottom: 16px; font-size: 13px; }
+.iterations summary { cursor: pointer; font-weight: 600; color: #666; padding: 8px 0; }
+.iteration { padding: 4px 0; display: flex; gap: 8px; align-items: baseline; }
+.iteration .eval-status { font-size: 11px; padding: 1px 8px; }
+.iteration-note { font-size: 12px; color: #888; padding-top: 4px; }
+.sample { padding: 6px 0; border-top: 1px solid #eee; }
+.sample pre { margin: 4px 0 0; padding: 6px 8px; background: #f5f5f5; border-radius: 4px; white-space: pre-wrap; word-break: break-all; font-size: 12px; }
+.diff { padding: 6px 0; border-top: 1px solid #eee; }
+.diff pre { margin: 4px 0 0; padding: 6px 8px; background: #f5f5f5; border-radius: 4px; white-space: pre-wrap; word-break: break-word; font-size: 12px; }
+.diff del { background: #fee2e2; color: #991b1b; }
+.diff ins { background: #dcfce7; color: #166534; text-decoration: none; }
+.diff-legend del, .diff-legend ins { padding: 0 4px; border-radius: 3px; }
+.grid { margin-bottom: 16px; border-collapse: collapse; font-size: 12px; }
+.grid caption { text-align: left; font-weight: 600; color: #666; padding-bottom: 6px; }
+.grid th { padding: 4px 10px; color: #666; font-weight: 600; }
+.grid td { padding: 4px 10px; text-align: center; font-weight: 600; border: 1px solid #fff; }
+.grid td.pass { background: #dcfce7; color: #166534; }
+.grid td.fail { background: #fee2e2; color: #991b1b; }
+.scores { margin-bottom: 16px; font-size: 13px; display: flex; flex-wrap: wrap; gap: 6px 16px; color: #555; }
+.scores span { font-family: monospace; }
+.request-ids { margin-bottom: 16px; font-size: 12px; color: #888; }
+.request-ids span { font-family: monospace; margin-left: 8px; }
+.notes { margin-bottom: 16px; padding: 10px 14px; background: #fef3c7; border-radius: 6px; font-size: 13px; color: #92400e; }
+.notes div + div { margin-top: 4px; }
+.slo-violations { margin-bottom: 16px; padding: 10px 14px; background: #ffedd5; border-radius: 6px; font-size: 13px; color: #9a3412; }
+.slo-violations div + div { margin-top: 4px; }
+.eval-code { font-family: monospace; font-size: 11px; font-weight: 600; margin-right: 8px; padding: 1px 6px; border-radius: 4px; background: #fecaca; }
 
 /* Tools panel */
 .tools-panel { margin-bottom: 16px; }
@@ -89,13 +143,22 @@ body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-
 
 <div class="sidebar">
   <div class="sidebar-header">
-    <h1>Eval Report</h1>
+    <h1 id="title">Eval Report</h1>
     <div class="meta" id="meta"></div>
     <div class="summary" id="summary"></div>
+    <div class="tier" id="tier"></div>
+    <div class="breakdown" id="breakdown"></div>
+    <div class="stability" id="stability"></div>
+    <div class="skipped" id="skipped"></div>
+    <div class="hygiene" id="hygiene"></div>
   </div>
   <div class="filter-bar">
     <input type="text" id="filter-input" placeholder="Filter evals...">
   </div>
+  <div class="filter-bar">
+    <input type="text" id="search-input" placeholder="Search conversations...">
+    <span class="search-count" id="search-count"></span>
+  </div>
   <div class="filter-buttons">
     <button class="filter-btn active" data-filter="all">All</button>
     <button class="filter-btn" data-filter="passed">Passed</button>
@@ -114,19 +177,71 @@ const DATA = {{.DataJSON}};
 function init() {
   if (!DATA) return;
 
+  if (DATA.label) {
+    document.getElementById("title").textContent = DATA.label;
+    document.title = DATA.label + " - Eval Report";
+  }
   document.getElementById("meta").textContent = DATA.model + " \u2014 " + DATA.timestamp;
   const passedSpan = '<span class="pass-count">' + DATA.passed + ' passed</span>';
   const failedCount = DATA.total - DATA.passed;
   const failedSpan = failedCount > 0 ? ', <span class="fail-count">' + failedCount + ' failed</span>' : '';
-  document.getElementById("summary").innerHTML = passedSpan + failedSpan + ' of ' + DATA.total + ' total';
+  const notedSpan = DATA.noted ? ' (<span class="noted-count">' + DATA.noted + ' with notes</span>)' : '';
+  const sloSpan = DATA.sloViolated ? ' (<span class="slo-count">' + DATA.sloViolated + ' with SLO violations</span>)' : '';
+  document.getElementById("summary").innerHTML = passedSpan + notedSpan + sloSpan + failedSpan + ' of ' + DATA.total + ' total';
+  if (DATA.tier) {
+    document.getElementById("tier").innerHTML = 'Compatibility: <span class="' + DATA.tier + '">' + DATA.tier + '</span> (' + Math.floor(DATA.passed * 100 / DATA.total) + '%)';
+  }
+
+  // Failure breakdown by code; clicking a code searches for it
+  var breakdown = document.getElementById("breakdown");
+  (DATA.failures || []).forEach(function(fc) {
+    var btn = document.createElement("button");
+    btn.className = "breakdown-item";
+    btn.textContent = fc.count + '\u00d7 ' + fc.code;
+    btn.addEventListener("click", function() {
+      var search = document.getElementById("search-input");
+      search.value = fc.code;
+      search.dispatchEvent(new Event("input"));
+    });
+    breakdown.appendChild(btn);
+  });
+
+  // Server stability verdict from probes before and after the run
+  if (DATA.stability) {
+    var st = DATA.stability;
+    var html = 'Server stability: <span class="' + st.verdict + '">' + st.verdict + '</span>';
+    if (!st.start.error && !st.end.error) {
+      html += ' (TTFT ' + (st.start.ttft_ms || 0) + 'ms before, ' + (st.end.ttft_ms || 0) + 'ms after)';
+    }
+    if (st.reasons) {
+      html += '<ul>' + st.reasons.map(function(r) { return '<li>' + escapeHtml(r) + '</li>'; }).join('') + '</ul>';
+    }
+    document.getElementById("stability").innerHTML = html;
+  }
+
+  // Evals disabled in the config file, with the reasons given
+  if (DATA.skipped) {
+    document.getElementById("skipped").innerHTML = DATA.skipped.length + ' skipped<ul>' + DATA.skipped.map(function(s) {
+      return '<li>' + escapeHtml(s.name) + ' <span class="reason">SKIPPED(' + escapeHtml(s.reason) + ')</span></li>';
+    }).join('') + '</ul>';
+  }
+
+  // What the output scanners flagged, apart from the eval results
+  if (DATA.outputFindings) {
+    document.getElementById("hygiene").innerHTML = 'Output hygiene: ' + DATA.outputFindings.length + ' flagged<ul>' + DATA.outputFindings.map(function(f) {
+      return '<li>' + escapeHtml(f.eval) + ' <span class="kind">' + escapeHtml(f.scanner + '/' + f.kind) + '</span> ' + escapeHtml(f.excerpt) + '</li>';
+    }).join('') + '</ul>';
+  }
 
   const list = document.getElementById("eval-list");
   DATA.evals.forEach(function(ev, i) {
+    ev.searchText = buildSearchText(ev).toLowerCase();
     const item = document.createElement("div");
     item.className = "eval-item";
     item.dataset.index = i;
     item.dataset.passed = ev.passed;
-    item.innerHTML = '<span class="badge ' + (ev.passed ? 'pass' : 'fail') + '"></span><span class="eval-name">' + escapeHtml(ev.name) + '</span>';
+    var badge = ev.passed ? (ev.sloViolations ? 'slo' : ev.notes ? 'noted' : 'pass') : 'fail';
+    item.innerHTML = '<span class="badge ' + badge + '"></span><span class="eval-name">' + escapeHtml(ev.name) + '</span><span class="eval-hits"></span>';
     item.addEventListener("click", function() { selectEval(i); });
     list.appendChild(item);
   });
@@ -134,6 +249,12 @@ function init() {
   // Filter input
   document.getElementById("filter-input").addEventListener("input", applyFilters);
 
+  // Conversation search input
+  document.getElementById("search-input").addEventListener("input", function() {
+    applyFilters();
+    if (selectedIndex >= 0) renderEval(DATA.evals[selectedIndex]);
+  });
+
   // Filter buttons
   document.querySelectorAll(".filter-btn").forEach(function(btn) {
     btn.addEventListener("click", function() {
@@ -147,19 +268,76 @@ function init() {
   if (DATA.evals.length > 0) selectEval(0);
 }
 
+var selectedIndex = -1;
+
+// buildSearchText collects all searchable text in an eval: the failure
+// message, message content, reasoning, tool call arguments and tool results.
+function buildSearchText(ev) {
+  var parts = [ev.name, ev.code || '', ev.message || ''].concat(ev.notes || [], ev.sloViolations || []);
+  (ev.iterations || []).forEach(function(it) {
+    parts.push(it.code || '', it.message || '');
+  });
+  (ev.samples || []).forEach(function(s) {
+    parts.push(s.code || '', s.message || '', s.prompt, s.output);
+  });
+  (ev.violations || []).forEach(function(v) {
+    parts.push(v.message, v.data);
+  });
+  (ev.diffs || []).forEach(function(d) {
+    d.spans.forEach(function(s) { parts.push(s.text); });
+  });
+  (ev.messages || []).forEach(function(msg) {
+    if (msg.content) parts.push(typeof msg.content === 'string' ? msg.content : JSON.stringify(msg.content));
+    if (msg.reasoning_content) parts.push(msg.reasoning_content);
+    if (msg.tool_call_id) parts.push(msg.tool_call_id);
+    (msg.tool_calls || []).forEach(function(tc) {
+      if (tc.id) parts.push(tc.id);
+      if (tc.function) {
+        parts.push(tc.function.name || '');
+        parts.push(tc.function.arguments || '');
+      }
+    });
+  });
+  return parts.join('\n');
+}
+
+function searchQuery() {
+  return document.getElementById("search-input").value.toLowerCase();
+}
+
+function countMatches(text, query) {
+  if (!query) return 0;
+  var count = 0;
+  var pos = text.indexOf(query);
+  while (pos !== -1) {
+    count++;
+    pos = text.indexOf(query, pos + query.length);
+  }
+  return count;
+}
+
 function applyFilters() {
   var text = document.getElementById("filter-input").value.toLowerCase();
+  var query = searchQuery();
   var statusFilter = document.querySelector(".filter-btn.active").dataset.filter;
+  var matchedEvals = 0;
   document.querySelectorAll(".eval-item").forEach(function(item) {
-    var name = DATA.evals[item.dataset.index].name.toLowerCase();
+    var ev = DATA.evals[item.dataset.index];
+    var name = ev.name.toLowerCase();
     var passed = item.dataset.passed === "true";
     var matchText = !text || name.indexOf(text) !== -1;
     var matchStatus = statusFilter === "all" || (statusFilter === "passed" && passed) || (statusFilter === "failed" && !passed);
-    item.classList.toggle("hidden", !(matchText && matchStatus));
+    var hits = countMatches(ev.searchText, query);
+    var matchSearch = !query || hits > 0;
+    item.querySelector(".eval-hits").textContent = query && hits > 0 ? hits : '';
+    item.classList.toggle("hidden", !(matchText && matchStatus && matchSearch));
+    if (query && hits > 0 && matchText && matchStatus) matchedEvals++;
   });
+  document.getElementById("search-count").textContent = query ? matchedEvals + ' evals' : '';
 }
 
 function selectEval(index) {
+  selectedIndex = index;
   document.querySelectorAll(".eval-item").forEach(function(item) {
     item.classList.toggle("selected", parseInt(item.dataset.index) === index);
   });
@@ -179,7 +357,126 @@ function renderEval(ev) {
 
   // Failure message
   if (!ev.passed && ev.message) {
-    html += '<div class="eval-message">' + escapeHtml(ev.message) + '</div>';
+    html += '<div class="eval-message">';
+    if (ev.code) html += '<span class="eval-code">' + escapeHtml(ev.code) + '</span>';
+    html += highlight(ev.message) + '</div>';
+  }
+
+  // Deviations tolerated by the server flavor
+  if (ev.notes) {
+    html += '<div class="notes">';
+    ev.notes.forEach(function(note) { html += '<div>Note: ' + highlight(note) + '</div>'; });
+    html += '</div>';
+  }
+
+  // Latency objectives the eval exceeded
+  if (ev.sloViolations) {
+    html += '<div class="slo-violations">';
+    ev.sloViolations.forEach(function(v) { html += '<div>SLO violation: ' + highlight(v) + '</div>'; });
+    html += '</div>';
+  }
+
+  // Iterations of a repeated eval
+  if (ev.iterations && ev.iterations.length > 0) {
+    var passedIters = ev.iterations.filter(function(it) { return it.passed; }).length;
+    html += '<details class="iterations"' + (ev.passed ? '' : ' open') + '><summary>Iterations (' + passedIters + '/' + ev.iterations.length + ' passed)</summary>';
+    ev.iterations.forEach(function(it, i) {
+      html += '<div class="iteration"><span>#' + (i + 1) + '</span>';
+      html += '<span class="eval-status ' + (it.passed ? 'pass' : 'fail') + '">' + (it.passed ? 'PASSED' : 'FAILED') + '</span>';
+      if (it.code) html += '<span class="eval-code">' + escapeHtml(it.code) + '</span>';
+      if (it.message) html += '<span>' + highlight(it.message) + '</span>';
+      html += '</div>';
+    });
+    html += '<div class="iteration-note">Conversation below is from the last iteration.</div></details>';
+  }
+
+  // Invalid responses of an eval that checks many
+  if (ev.samples && ev.samples.length > 0) {
+    html += '<details class="iterations" open><summary>Invalid samples (' + ev.samples.length + ')</summary>';
+    ev.samples.forEach(function(s) {
+      html += '<div class="sample"><div class="iteration">';
+      if (s.code) html += '<span class="eval-code">' + escapeHtml(s.code) + '</span>';
+      if (s.message) html += '<span>' + highlight(s.message) + '</span>';
+      html += '</div><div>Prompt: ' + highlight(s.prompt) + '</div>';
+      html += '<pre>' + escapeHtml(s.output) + '</pre></div>';
+    });
+    html += '</details>';
+  }
+
+  // Stream lines skipped since they were not chunks
+  if (ev.violations && ev.violations.length > 0) {
+    html += '<details class="iterations" open><summary>Stream violations (' + ev.violations.length + ')</summary>';
+    ev.violations.forEach(function(v) {
+      html += '<div class="sample"><div class="iteration">';
+      html += '<span class="eval-code">' + escapeHtml(v.kind) + '</span>';
+      html += '<span>line ' + v.line + ': ' + highlight(v.message) + '</span>';
+      html += '</div><pre>' + escapeHtml(v.data) + '</pre></div>';
+    });
+    html += '</details>';
+  }
+
+  // Outputs that should have matched, as word diffs
+  if (ev.diffs && ev.diffs.length > 0) {
+    html += '<details class="iterations"' + (ev.passed ? '' : ' open') + '><summary>Differences (' + ev.diffs.length + ')</summary>';
+    ev.diffs.forEach(function(d) {
+      html += '<div class="diff"><div class="diff-legend"><del>' + escapeHtml(d.aLabel) + '</del> &rarr; <ins>' + escapeHtml(d.bLabel) + '</ins></div><pre>';
+      d.spans.forEach(function(s) {
+        if (s.op === 'delete') html += '<del>' + escapeHtml(s.text) + '</del>';
+        else if (s.op === 'insert') html += '<ins>' + escapeHtml(s.text) + '</ins>';
+        else html += escapeHtml(s.text);
+      });
+      html += '</pre></div>';
+    });
+    html += '</details>';
+  }
+
+  // Files attached to the result as evidence for its verdict
+  if (ev.artifacts && ev.artifacts.length > 0) {
+    html += '<details class="iterations" open><summary>Artifacts (' + ev.artifacts.length + ')</summary>';
+    ev.artifacts.forEach(function(a) {
+      var href = a.path.split('/').map(encodeURIComponent).join('/');
+      html += '<div class="iteration"><a href="' + escapeHtml(href) + '" target="_blank">' + escapeHtml(a.name) + '</a></div>';
+    });
+    html += '</details>';
+  }
+
+  // Scores recorded by the eval
+  if (ev.scores) {
+    html += '<div class="scores">';
+    Object.keys(ev.scores).sort().forEach(function(name) {
+      html += '<span>' + escapeHtml(name) + ' ' + ev.scores[name].toFixed(3) + '</span>';
+    });
+    html += '</div>';
+  }
+
+  // Server-reported throughput (llama.cpp timings)
+  if (ev.timings) {
+    var t = ev.timings;
+    html += '<div class="scores"><span>prompt ' + t.promptTokens + ' tok @ ' + t.promptPerSecond.toFixed(1) + ' tok/s</span>';
+    html += '<span>generation ' + t.predictedTokens + ' tok @ ' + t.predictedPerSecond.toFixed(1) + ' tok/s</span></div>';
+  }
+
+  // Server request ids, for finding the requests in server logs
+  if (ev.requestIds) {
+    html += '<div class="request-ids">Request IDs:';
+    ev.requestIds.forEach(function(id) { html += '<span>' + escapeHtml(id) + '</span>'; });
+    html += '</div>';
+  }
+
+  // Pass/fail grid, e.g. needle retrieval by depth and context length
+  if (ev.grid) {
+    var g = ev.grid;
+    html += '<table class="grid"><caption>' + escapeHtml(g.rowLabel) + ' \u00d7 ' + escapeHtml(g.colLabel) + '</caption>';
+    html += '<tr><th></th>' + g.cols.map(function(c) { return '<th>' + escapeHtml(c) + '</th>'; }).join('') + '</tr>';
+    g.rows.forEach(function(r, i) {
+      html += '<tr><th>' + escapeHtml(r) + '</th>';
+      g.cols.forEach(function(c, j) {
+        var ok = g.cells[i] && g.cells[i][j];
+        html += '<td class="' + (ok ? 'pass' : 'fail') + '">' + (ok ? '\u2713' : '\u2717') + '</td>';
+      });
+      html += '</tr>';
+    });
+    html += '</table>';
   }
 
   // Tools
@@ -205,6 +502,15 @@ function renderEval(ev) {
 
   main.innerHTML = html;
   main.scrollTop = 0;
+
+  // Scroll to the first match and expand any collapsed reasoning containing matches
+  var first = main.querySelector("mark");
+  if (first) {
+    main.querySelectorAll("details.reasoning").forEach(function(d) {
+      if (d.querySelector("mark")) d.open = true;
+    });
+    first.scrollIntoView({block: "center"});
+  }
 }
 
 function renderMessage(msg) {
@@ -212,20 +518,20 @@ function renderMessage(msg) {
   var html = '<div class="message ' + role + '">';
   html += '<div class="msg-role">' + escapeHtml(role);
   if (role === 'tool' && msg.tool_call_id) {
-    html += ' <span style="font-weight:400;text-transform:none;letter-spacing:0">(call: ' + escapeHtml(msg.tool_call_id) + ')</span>';
+    html += ' <span style="font-weight:400;text-transform:none;letter-spacing:0">(call: ' + highlight(msg.tool_call_id) + ')</span>';
   }
   html += '</div>';
 
   // Reasoning content
   if (msg.reasoning_content) {
     html += '<details class="reasoning"><summary>Reasoning</summary>';
-    html += '<div class="reasoning-content">' + escapeHtml(msg.reasoning_content) + '</div>';
+    html += '<div class="reasoning-content">' + highlight(msg.reasoning_content) + '</div>';
     html += '</details>';
   }
 
   // Content
   if (msg.content) {
-    html += '<div class="msg-content">' + escapeHtml(msg.content) + '</div>';
+    html += '<div class="msg-content">' + highlight(msg.content) + '</div>';
   }
 
   // Tool calls
@@ -233,13 +539,13 @@ function renderMessage(msg) {
     msg.tool_calls.forEach(function(tc) {
       html += '<div class="tool-call">';
       html += '<div class="tc-header">';
-      html += '<span class="tc-name">' + escapeHtml(tc.function ? tc.function.name : '') + '</span>';
-      if (tc.id) html += '<span class="tc-id">' + escapeHtml(tc.id) + '</span>';
+      html += '<span class="tc-name">' + highlight(tc.function ? tc.function.name : '') + '</span>';
+      if (tc.id) html += '<span class="tc-id">' + highlight(tc.id) + '</span>';
       html += '</div>';
       if (tc.function && tc.function.arguments) {
         var args = tc.function.arguments;
         try { args = JSON.stringify(JSON.parse(args), null, 2); } catch(e) {}
-        html += '<div class="tc-args">' + escapeHtml(args) + '</div>';
+        html += '<div class="tc-args">' + highlight(args) + '</div>';
       }
       html += '</div>';
     });
@@ -249,6 +555,24 @@ function renderMessage(msg) {
   return html;
 }
 
+// highlight escapes s and wraps occurrences of the current search query in <mark>.
+function highlight(s) {
+  if (!s) return '';
+  s = String(s);
+  var query = searchQuery();
+  if (!query) return escapeHtml(s);
+  var lower = s.toLowerCase();
+  var out = '';
+  var last = 0;
+  var pos = lower.indexOf(query);
+  while (pos !== -1) {
+    out += escapeHtml(s.substring(last, pos)) + '<mark>' + escapeHtml(s.substring(pos, pos + query.length)) + '</mark>';
+    last = pos + query.length;
+    pos = lower.indexOf(query, last);
+  }
+  return out + escapeHtml(s.substring(last));
+}
+
 function escapeHtml(s) {
   if (!s) return '';
   return String(s).replace(/&/g,'&amp;').replace(/</g,'&lt;').replace(/>/g,'&gt;').replace(/"/g,'&quot;');
@@ -258,3 +582,51 @@ init();
 </script>
 </body>
 </html>`
+
+const indexHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Model}} - Eval Runs</title>
+<style>
+* { margin: 0; padding: 0; box-sizing: border-box; }
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f5f5f5; color: #1a1a1a; padding: 24px; }
+h1 { font-size: 18px; margin-bottom: 16px; }
+table { width: 100%; max-width: 900px; border-collapse: collapse; background: #fff; box-shadow: 0 1px 2px rgba(0,0,0,0.05); border-radius: 8px; overflow: hidden; }
+th, td { padding: 10px 16px; text-align: left; font-size: 13px; border-bottom: 1px solid #eee; }
+th { font-size: 11px; font-weight: 700; text-transform: uppercase; letter-spacing: 0.05em; color: #888; background: #fafafa; }
+a { color: #2563eb; text-decoration: none; }
+a:hover { text-decoration: underline; }
+.badge { display: inline-block; width: 8px; height: 8px; border-radius: 50%; margin-right: 8px; }
+.badge.pass { background: #16a34a; }
+.badge.fail { background: #dc2626; }
+.pass-count { color: #16a34a; font-weight: 600; }
+.fail-count { color: #dc2626; font-weight: 600; }
+.empty { color: #999; font-size: 14px; }
+</style>
+</head>
+<body>
+<h1>{{.Model}} &mdash; Eval Runs</h1>
+{{if .Runs}}
+<table>
+<thead>
+<tr><th>Run</th><th>Timestamp</th><th>Passed</th><th>Failed</th><th>Total</th></tr>
+</thead>
+<tbody>
+{{range .Runs}}
+<tr>
+<td><span class="badge {{if eq .Failed 0}}pass{{else}}fail{{end}}"></span><a href="{{.Run}}/report.html">{{if .Label}}{{.Label}}{{else}}{{.Run}}{{end}}</a></td>
+<td>{{.Timestamp}}</td>
+<td class="pass-count">{{.Passed}}</td>
+<td{{if gt .Failed 0}} class="fail-count"{{end}}>{{.Failed}}</td>
+<td>{{.Total}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{else}}
+<p class="empty">No runs found.</p>
+{{end}}
+</body>
+</html>`
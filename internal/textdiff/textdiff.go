@@ -0,0 +1,254 @@
+// Package textdiff computes word-level differences between two outputs that
+// should match, such as a response and its seeded rerun, so that reports
+// can show where they differ rather than only that they do.
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+)
+
+// Op is the kind of a span of a diff.
+type Op int
+
+const (
+	// Equal text is in both outputs.
+	Equal Op = iota
+	// Delete text is only in the first output.
+	Delete
+	// Insert text is only in the second output.
+	Insert
+)
+
+func (o Op) String() string {
+	switch o {
+	case Delete:
+		return "delete"
+	case Insert:
+		return "insert"
+	default:
+		return "equal"
+	}
+}
+
+// MarshalText encodes an op by name, for JSON.
+func (o Op) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// Span is a run of text with the same op.
+type Span struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxCells bounds the table used to align the differing middle of two
+// outputs. Beyond it, the middle is shown as replaced outright.
+const maxCells = 4_000_000
+
+// Words returns the word-level diff turning a into b. Words, runs of
+// whitespace, and punctuation characters are compared as units, so a
+// changed word shows as one deletion and one insertion rather than as
+// scattered characters.
+func Words(a, b string) []Span {
+	at, bt := tokenize(a), tokenize(b)
+
+	// Trim the common prefix and suffix, which are usually most of the
+	// outputs, before aligning what is left
+	pre := 0
+	for pre < len(at) && pre < len(bt) && at[pre] == bt[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(at)-pre && suf < len(bt)-pre && at[len(at)-1-suf] == bt[len(bt)-1-suf] {
+		suf++
+	}
+
+	var spans []Span
+	spans = appendSpan(spans, Equal, strings.Join(at[:pre], ""))
+	spans = append(spans, align(at[pre:len(at)-suf], bt[pre:len(bt)-suf])...)
+	spans = appendSpan(spans, Equal, strings.Join(at[len(at)-suf:], ""))
+	return spans
+}
+
+// align diffs two token sequences by their longest common subsequence,
+// putting each deletion before the insertion replacing it.
+func align(a, b []string) []Span {
+	var spans []Span
+	if len(a)*len(b) > maxCells {
+		spans = appendSpan(spans, Delete, strings.Join(a, ""))
+		return appendSpan(spans, Insert, strings.Join(b, ""))
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var del, ins strings.Builder
+	flush := func() {
+		spans = appendSpan(spans, Delete, del.String())
+		spans = appendSpan(spans, Insert, ins.String())
+		del.Reset()
+		ins.Reset()
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			spans = appendSpan(spans, Equal, a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			del.WriteString(a[i])
+			i++
+		default:
+			ins.WriteString(b[j])
+			j++
+		}
+	}
+	flush()
+	return spans
+}
+
+// appendSpan appends text to spans, extending the last span if it has the
+// same op.
+func appendSpan(spans []Span, op Op, text string) []Span {
+	if text == "" {
+		return spans
+	}
+	if n := len(spans); n > 0 && spans[n-1].Op == op {
+		spans[n-1].Text += text
+		return spans
+	}
+	return append(spans, Span{Op: op, Text: text})
+}
+
+// tokenize splits s into words, runs of whitespace, and single other
+// characters, which concatenate back to s.
+func tokenize(s string) []string {
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch {
+		case isWord(runes[i]):
+			for j < len(runes) && isWord(runes[j]) {
+				j++
+			}
+		case unicode.IsSpace(runes[i]):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Compact shortens the unchanged text of a diff to at most context words
+// on each side of a change, marking the cut text with "…".
+func Compact(spans []Span, context int) []Span {
+	if len(spans) < 2 {
+		return spans
+	}
+	out := make([]Span, 0, len(spans))
+	for i, s := range spans {
+		if s.Op != Equal {
+			out = append(out, s)
+			continue
+		}
+		tokens := tokenize(s.Text)
+		// Count words rather than tokens, so whitespace is free
+		keepHead, keepTail := wordTokens(tokens, context, true), wordTokens(tokens, context, false)
+		if i == 0 {
+			keepHead = 0
+		}
+		if i == len(spans)-1 {
+			keepTail = 0
+		}
+		if keepHead+keepTail >= len(tokens) {
+			out = append(out, s)
+			continue
+		}
+		text := strings.Join(tokens[:keepHead], "") + "…" + strings.Join(tokens[len(tokens)-keepTail:], "")
+		out = append(out, Span{Op: Equal, Text: text})
+	}
+	return out
+}
+
+// wordTokens returns how many tokens from the start of tokens, or from the
+// end if head is false, hold n words.
+func wordTokens(tokens []string, n int, head bool) int {
+	words := 0
+	for k := range tokens {
+		t := tokens[k]
+		if !head {
+			t = tokens[len(tokens)-1-k]
+		}
+		if !unicode.IsSpace([]rune(t)[0]) {
+			if words == n {
+				return k
+			}
+			words++
+		}
+	}
+	return len(tokens)
+}
+
+// Console renders a diff for the terminal: deletions in red and insertions
+// in green, or as Plain does if color is disabled.
+func Console(spans []Span) string {
+	if color.NoColor {
+		return Plain(spans)
+	}
+	deleted := color.New(color.FgRed, color.CrossedOut)
+	inserted := color.New(color.FgGreen, color.Underline)
+	var b strings.Builder
+	for _, s := range spans {
+		switch s.Op {
+		case Delete:
+			b.WriteString(deleted.Sprint(s.Text))
+		case Insert:
+			b.WriteString(inserted.Sprint(s.Text))
+		default:
+			b.WriteString(s.Text)
+		}
+	}
+	return b.String()
+}
+
+// Plain renders a diff with git's word diff markers, [-deleted-] and
+// {+inserted+}, for logs and uncolored output.
+func Plain(spans []Span) string {
+	var b strings.Builder
+	for _, s := range spans {
+		switch s.Op {
+		case Delete:
+			b.WriteString("[-" + s.Text + "-]")
+		case Insert:
+			b.WriteString("{+" + s.Text + "+}")
+		default:
+			b.WriteString(s.Text)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+// Package textmetrics scores the similarity of generated text to reference
+// text, for assertions about what a response covers.
+package textmetrics
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Score holds precision, recall, and F1 of a candidate against a reference.
+// Precision is measured against the candidate, recall against the reference.
+type Score struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+}
+
+// newScore computes the F1 of precision and recall.
+func newScore(precision, recall float64) Score {
+	s := Score{Precision: precision, Recall: recall}
+	if precision+recall > 0 {
+		s.F1 = 2 * precision * recall / (precision + recall)
+	}
+	return s
+}
+
+// Tokenize splits s into lowercase tokens of letters and digits. Punctuation
+// and whitespace separate tokens and are discarded.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// EditDistance returns the Levenshtein distance between a and b in runes.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// NormalizedEditDistance returns the edit distance between a and b divided by
+// the rune length of the longer string: 0 for identical strings, 1 for
+// strings with nothing in common.
+func NormalizedEditDistance(a, b string) float64 {
+	n := max(len([]rune(a)), len([]rune(b)))
+	if n == 0 {
+		return 0
+	}
+	return float64(EditDistance(a, b)) / float64(n)
+}
+
+// TokenF1 scores the token overlap of candidate and reference, counting each
+// token as many times as it appears in both. Token order is ignored.
+func TokenF1(candidate, reference string) Score {
+	cand, ref := Tokenize(candidate), Tokenize(reference)
+	if len(cand) == 0 || len(ref) == 0 {
+		return Score{}
+	}
+
+	counts := make(map[string]int, len(ref))
+	for _, t := range ref {
+		counts[t]++
+	}
+	overlap := 0
+	for _, t := range cand {
+		if counts[t] > 0 {
+			counts[t]--
+			overlap++
+		}
+	}
+
+	return newScore(float64(overlap)/float64(len(cand)), float64(overlap)/float64(len(ref)))
+}
+
+// RougeL scores candidate against reference by the longest common
+// subsequence of their tokens, so unlike TokenF1 it rewards matching order.
+func RougeL(candidate, reference string) Score {
+	cand, ref := Tokenize(candidate), Tokenize(reference)
+	if len(cand) == 0 || len(ref) == 0 {
+		return Score{}
+	}
+
+	lcs := float64(lcsLength(cand, ref))
+	return newScore(lcs/float64(len(cand)), lcs/float64(len(ref)))
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Cosine returns the cosine similarity of vectors a and b, or 0 if they
+// differ in length or either is zero.
+func Cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,116 @@
+package textmetrics
+
+import (
+	"math"
+	"testing"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b       string
+		want       int
+		normalized float64
+	}{
+		{"both empty", "", "", 0, 0},
+		{"empty a", "", "abc", 3, 1},
+		{"empty b", "abc", "", 3, 1},
+		{"identical", "same", "same", 0, 0},
+		{"substitutions and insertion", "kitten", "sitting", 3, 3.0 / 7},
+		{"nothing in common", "abc", "xyz", 3, 1},
+		{"accent is one rune", "café", "cafe", 1, 0.25},
+		{"cjk", "日本語", "日本", 1, 1.0 / 3},
+		{"emoji", "a👍b", "ab", 1, 1.0 / 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EditDistance(tt.a, tt.b); got != tt.want {
+				t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := NormalizedEditDistance(tt.a, tt.b); !approx(got, tt.normalized) {
+				t.Errorf("NormalizedEditDistance(%q, %q) = %g, want %g", tt.a, tt.b, got, tt.normalized)
+			}
+		})
+	}
+}
+
+func TestTokenF1(t *testing.T) {
+	tests := []struct {
+		name                 string
+		candidate, reference string
+		want                 Score
+	}{
+		{"both empty", "", "", Score{}},
+		{"empty candidate", "", "the cat", Score{}},
+		{"empty reference", "the cat", "", Score{}},
+		{"only punctuation", "...", "the cat", Score{}},
+		{"identical", "The cat sat.", "The cat sat.", Score{1, 1, 1}},
+		{"case and punctuation ignored", "Hello, World!", "hello world", Score{1, 1, 1}},
+		{"order ignored", "sat cat the", "the cat sat", Score{1, 1, 1}},
+		{"repeats counted once each", "the cat the", "the cat sat", Score{2.0 / 3, 2.0 / 3, 2.0 / 3}},
+		{"disjoint", "dog", "cat", Score{}},
+		{"unicode", "Ünïcode naïve café", "café naïve", Score{2.0 / 3, 1, 0.8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TokenF1(tt.candidate, tt.reference)
+			if !approx(got.Precision, tt.want.Precision) || !approx(got.Recall, tt.want.Recall) || !approx(got.F1, tt.want.F1) {
+				t.Errorf("TokenF1(%q, %q) = %+v, want %+v", tt.candidate, tt.reference, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRougeL(t *testing.T) {
+	tests := []struct {
+		name                 string
+		candidate, reference string
+		want                 Score
+	}{
+		{"both empty", "", "", Score{}},
+		{"empty candidate", "", "the cat", Score{}},
+		{"empty reference", "the cat", "", Score{}},
+		{"identical", "The cat sat.", "the cat sat", Score{1, 1, 1}},
+		{"order matters", "a c b d", "a b c d", Score{0.75, 0.75, 0.75}},
+		{"reversed", "sat cat the", "the cat sat", Score{1.0 / 3, 1.0 / 3, 1.0 / 3}},
+		{"prefix of reference", "the cat", "the cat sat on the mat", Score{1, 1.0 / 3, 0.5}},
+		{"disjoint", "dog", "cat", Score{}},
+		{"cjk", "日本 東京", "東京 日本", Score{0.5, 0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RougeL(tt.candidate, tt.reference)
+			if !approx(got.Precision, tt.want.Precision) || !approx(got.Recall, tt.want.Recall) || !approx(got.F1, tt.want.F1) {
+				t.Errorf("RougeL(%q, %q) = %+v, want %+v", tt.candidate, tt.reference, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"both empty", nil, nil, 0},
+		{"identical", []float64{1, 2, 3}, []float64{1, 2, 3}, 1},
+		{"scaled", []float64{1, 2}, []float64{2, 4}, 1},
+		{"orthogonal", []float64{1, 0}, []float64{0, 1}, 0},
+		{"opposite", []float64{1, -2}, []float64{-1, 2}, -1},
+		{"zero vector", []float64{0, 0}, []float64{1, 1}, 0},
+		{"length mismatch", []float64{1, 2}, []float64{1, 2, 3}, 0},
+		{"angle", []float64{1, 0}, []float64{1, 1}, 1 / math.Sqrt2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Cosine(tt.a, tt.b); !approx(got, tt.want) {
+				t.Errorf("Cosine(%v, %v) = %g, want %g", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+// Package tui implements the interactive eval selection screen.
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// ErrCancelled is returned by Select when the user quits without running.
+var ErrCancelled = errors.New("selection cancelled")
+
+// Item is a selectable eval.
+type Item struct {
+	Name     string
+	Category string
+	Class    string
+	// Disabled marks evals that are disabled by default.
+	Disabled bool
+}
+
+// SaveFunc saves the current selection as a named profile.
+type SaveFunc func(name string, selected []string) error
+
+// row is a line of the selection list: either a category header or an item.
+type row struct {
+	header string
+	item   int // index into items; -1 for headers
+}
+
+// selector holds the state of the selection screen.
+type selector struct {
+	items    []Item
+	rows     []row
+	selected map[string]bool
+	cursor   int
+	offset   int
+	status   string
+	save     SaveFunc
+	profile  string
+}
+
+// Select presents a checkbox list of items grouped by category and returns
+// the names of the selected items when the user presses enter. Items named
+// in initial start selected. If save is non-nil, the user can save the
+// selection as a named profile; profile is the default name offered.
+func Select(items []Item, initial []string, profile string, save SaveFunc) ([]string, error) {
+	fd := int(os.Stdin.Fd())
+	restore, err := makeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	s := &selector{
+		items:    items,
+		selected: make(map[string]bool),
+		save:     save,
+		profile:  profile,
+	}
+	for _, name := range initial {
+		s.selected[name] = true
+	}
+	category := ""
+	for i, it := range items {
+		if it.Category != category {
+			category = it.Category
+			s.rows = append(s.rows, row{header: category, item: -1})
+		}
+		s.rows = append(s.rows, row{item: i})
+	}
+
+	// Use the alternate screen so the run output starts on a clean terminal
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	buf := make([]byte, 16)
+	for {
+		s.render(terminalHeight(fd))
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("read input: %w", err)
+		}
+		s.status = ""
+
+		switch key := string(buf[:n]); key {
+		case "\x1b[A", "k":
+			s.move(-1)
+		case "\x1b[B", "j":
+			s.move(1)
+		case " ":
+			s.toggle()
+		case "a":
+			s.toggleAll()
+		case "s":
+			if s.save == nil {
+				break
+			}
+			name, ok := s.prompt("Save profile as: ", s.profile)
+			if !ok || name == "" {
+				break
+			}
+			if err := s.save(name, s.selection()); err != nil {
+				s.status = color.RedString("Save failed: %v", err)
+			} else {
+				s.profile = name
+				s.status = color.GreenString("Saved profile %q", name)
+			}
+		case "\r", "\n":
+			if sel := s.selection(); len(sel) > 0 {
+				return sel, nil
+			}
+			s.status = color.YellowString("Nothing selected")
+		case "q", "\x1b", "\x03":
+			return nil, ErrCancelled
+		}
+	}
+}
+
+// move moves the cursor by delta rows, clamped to the list.
+func (s *selector) move(delta int) {
+	s.cursor = min(max(s.cursor+delta, 0), len(s.rows)-1)
+}
+
+// toggle toggles the item under the cursor, or every item in the category
+// when the cursor is on a header.
+func (s *selector) toggle() {
+	r := s.rows[s.cursor]
+	if r.item >= 0 {
+		name := s.items[r.item].Name
+		s.selected[name] = !s.selected[name]
+		return
+	}
+
+	var names []string
+	for _, it := range s.items {
+		if it.Category == r.header {
+			names = append(names, it.Name)
+		}
+	}
+	s.setAll(names)
+}
+
+// toggleAll selects every item, or clears the selection if all are selected.
+func (s *selector) toggleAll() {
+	names := make([]string, len(s.items))
+	for i, it := range s.items {
+		names[i] = it.Name
+	}
+	s.setAll(names)
+}
+
+// setAll selects all names unless they are already all selected, in which
+// case it deselects them.
+func (s *selector) setAll(names []string) {
+	all := true
+	for _, name := range names {
+		if !s.selected[name] {
+			all = false
+			break
+		}
+	}
+	for _, name := range names {
+		s.selected[name] = !all
+	}
+}
+
+// selection returns the selected item names in list order.
+func (s *selector) selection() []string {
+	var names []string
+	for _, it := range s.items {
+		if s.selected[it.Name] {
+			names = append(names, it.Name)
+		}
+	}
+	return names
+}
+
+// render redraws the screen, scrolling the list to keep the cursor visible.
+func (s *selector) render(height int) {
+	const chrome = 4 // title, blank line, blank line, help
+	visible := max(height-chrome, 1)
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+visible {
+		s.offset = s.cursor - visible + 1
+	}
+
+	var b bytes.Buffer
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "Select evals (%d selected)", len(s.selection()))
+	if s.status != "" {
+		b.WriteString("  " + s.status)
+	}
+	b.WriteString("\r\n\r\n")
+
+	end := min(s.offset+visible, len(s.rows))
+	for i := s.offset; i < end; i++ {
+		r := s.rows[i]
+		cursor := "  "
+		if i == s.cursor {
+			cursor = color.CyanString("> ")
+		}
+
+		if r.item < 0 {
+			fmt.Fprintf(&b, "%s%s\r\n", cursor, color.New(color.Bold).Sprint(r.header))
+			continue
+		}
+
+		it := s.items[r.item]
+		check := "[ ]"
+		if s.selected[it.Name] {
+			check = color.GreenString("[x]")
+		}
+		line := fmt.Sprintf("%s  %s %-40s %s", cursor, check, it.Name, color.HiBlackString("[%s]", it.Class))
+		if it.Disabled {
+			line += color.HiBlackString(" (disabled by default)")
+		}
+		b.WriteString(line + "\r\n")
+	}
+
+	help := "↑/↓ move  space toggle  a all  enter run  q quit"
+	if s.save != nil {
+		help = "↑/↓ move  space toggle  a all  s save profile  enter run  q quit"
+	}
+	fmt.Fprintf(&b, "\r\n%s", color.HiBlackString(help))
+	os.Stdout.Write(b.Bytes())
+}
+
+// prompt reads a line of input at the bottom of the screen. It returns false
+// if the user cancels with escape or ctrl-c.
+func (s *selector) prompt(label, initial string) (string, bool) {
+	input := []rune(initial)
+	buf := make([]byte, 16)
+	fmt.Print("\x1b[?25h")
+	defer fmt.Print("\x1b[?25l")
+
+	for {
+		fmt.Printf("\r\x1b[2K%s%s", label, string(input))
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", false
+		}
+		switch key := string(buf[:n]); key {
+		case "\r", "\n":
+			return strings.TrimSpace(string(input)), true
+		case "\x1b", "\x03":
+			return "", false
+		case "\x7f", "\b":
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		default:
+			for _, r := range key {
+				if r >= ' ' && r != 0x7f {
+					input = append(input, r)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || windows)
+
+package tui
+
+import "errors"
+
+// makeRaw is unsupported on this platform.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("interactive selection is not supported on this platform")
+}
+
+// terminalHeight returns a default height on unsupported platforms.
+func terminalHeight(fd int) int {
+	return 24
+}
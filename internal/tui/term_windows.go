@@ -0,0 +1,53 @@
+//go:build windows
+
+package tui
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// makeRaw puts the console into raw mode, with virtual terminal input and
+// output so that arrow keys arrive and the screen is drawn with the same
+// escape sequences as on Unix terminals, and returns a function that
+// restores its previous state.
+func makeRaw(fd int) (func(), error) {
+	in := windows.Handle(fd)
+	var oldIn uint32
+	if err := windows.GetConsoleMode(in, &oldIn); err != nil {
+		return nil, fmt.Errorf("not a console: %w", err)
+	}
+	out := windows.Stdout
+	var oldOut uint32
+	if err := windows.GetConsoleMode(out, &oldOut); err != nil {
+		return nil, fmt.Errorf("not a console: %w", err)
+	}
+
+	raw := oldIn &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_LINE_INPUT | windows.ENABLE_PROCESSED_INPUT)
+	raw |= windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(in, raw); err != nil {
+		return nil, fmt.Errorf("set raw mode: %w", err)
+	}
+	if err := windows.SetConsoleMode(out, oldOut|windows.ENABLE_PROCESSED_OUTPUT|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+		windows.SetConsoleMode(in, oldIn)
+		return nil, fmt.Errorf("enable virtual terminal output: %w", err)
+	}
+
+	return func() {
+		windows.SetConsoleMode(in, oldIn)
+		windows.SetConsoleMode(out, oldOut)
+	}, nil
+}
+
+// terminalHeight returns the number of rows in the console window.
+func terminalHeight(fd int) int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Stdout, &info); err != nil {
+		return 24
+	}
+	if rows := int(info.Window.Bottom-info.Window.Top) + 1; rows > 1 {
+		return rows
+	}
+	return 24
+}